@@ -17,9 +17,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/redhat-marketplace/redhat-marketplace-operator/authchecker/v2/pkg/authchecker"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/authcheck"
 	"github.com/spf13/cobra"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -35,6 +37,8 @@ var (
 	group, kind, version, namespace string
 	retry                           int64
 
+	manifestFile, permissions, statusAddress string
+
 	log = logf.Log.WithName("authvalid_cmd")
 )
 
@@ -45,6 +49,25 @@ func init() {
 	rootCmd.Flags().StringVar(&version, "version", "v1", "kube version to list")
 	rootCmd.Flags().StringVar(&namespace, "namespace", "", "namespace to list")
 	rootCmd.Flags().Int64Var(&retry, "retry", 30, "retry count")
+	rootCmd.Flags().StringVar(&manifestFile, "manifest", "", "file of the permission manifest to check")
+	rootCmd.Flags().StringVar(&permissions, "permissions", "", "permission manifest to check, as json or yaml")
+	rootCmd.Flags().StringVar(&statusAddress, "status-address", fmt.Sprintf(":%d", authcheck.StatusPort), "address to serve the permission status on")
+}
+
+func loadManifest() (*authcheck.PermissionManifest, error) {
+	switch {
+	case permissions != "":
+		return authcheck.ParseManifest(strings.NewReader(permissions))
+	case manifestFile != "":
+		file, err := os.Open(manifestFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return authcheck.ParseManifest(file)
+	default:
+		return nil, nil
+	}
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -53,8 +76,32 @@ func run(cmd *cobra.Command, args []string) {
 		"kind", kind,
 		"namespace", namespace,
 		"retry", retry,
+		"manifest", manifestFile,
 	)
 
+	manifest, err := loadManifest()
+	if err != nil {
+		log.Error(err, "error loading permission manifest")
+		er(err)
+	}
+
+	if manifest != nil {
+		permissionChecker, err := InitializePermissionChecker(authchecker.PermissionCheckerConfig{
+			Manifest:  manifest,
+			RetryTime: time.Duration(retry) * time.Second,
+		})
+		if err != nil {
+			log.Error(err, "error starting permission checker")
+			er(err)
+		}
+
+		go func() {
+			if err := permissionChecker.Run(cmd.Context(), statusAddress); err != nil {
+				log.Error(err, "error checking permissions")
+			}
+		}()
+	}
+
 	authChecker, err := InitializeAuthChecker(authchecker.AuthCheckerConfig{
 		Namespace: namespace,
 		RetryTime: time.Duration(retry) * time.Second,
@@ -33,3 +33,11 @@ func InitializeAuthChecker(cfg authchecker.AuthCheckerConfig) (*authchecker.Auth
 		wire.InterfaceValue(new(logr.Logger), log),
 	))
 }
+
+func InitializePermissionChecker(cfg authchecker.PermissionCheckerConfig) (*authchecker.PermissionChecker, error) {
+	panic(wire.Build(
+		managers.ProvideCachedClientSet,
+		authchecker.NewPermissionChecker,
+		wire.InterfaceValue(new(logr.Logger), log),
+	))
+}
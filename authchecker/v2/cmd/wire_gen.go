@@ -10,6 +10,7 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/client"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/managers"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
@@ -40,3 +41,21 @@ func InitializeAuthChecker(cfg authchecker.AuthCheckerConfig) (*authchecker.Auth
 var (
 	_wireLoggerValue = log
 )
+
+func InitializePermissionChecker(cfg authchecker.PermissionCheckerConfig) (*authchecker.PermissionChecker, error) {
+	logger := _wireLogrLoggerValue
+	restConfig, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	permissionChecker := authchecker.NewPermissionChecker(logger, clientset, cfg)
+	return permissionChecker, nil
+}
+
+var (
+	_wireLogrLoggerValue = log
+)
@@ -0,0 +1,156 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authchecker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/authcheck"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PermissionChecker reviews the permissions of a manifest with
+// SelfSubjectAccessReviews and serves the result.
+type PermissionChecker struct {
+	client    kubernetes.Interface
+	manifest  *authcheck.PermissionManifest
+	retryTime time.Duration
+	logger    logr.Logger
+
+	mutex  sync.RWMutex
+	status *authcheck.Status
+}
+
+type PermissionCheckerConfig struct {
+	Manifest  *authcheck.PermissionManifest
+	RetryTime time.Duration
+}
+
+func NewPermissionChecker(
+	logger logr.Logger,
+	client kubernetes.Interface,
+	config PermissionCheckerConfig,
+) *PermissionChecker {
+	return &PermissionChecker{
+		client:    client,
+		manifest:  config.Manifest,
+		retryTime: config.RetryTime,
+		logger:    logger,
+	}
+}
+
+// Check reviews every permission of the manifest.
+func (p *PermissionChecker) Check(ctx context.Context) *authcheck.Status {
+	log := p.logger
+	status := &authcheck.Status{
+		CheckTime: metav1.Now(),
+		Results:   []authcheck.PermissionResult{},
+	}
+
+	for _, permission := range p.manifest.Checks() {
+		result := authcheck.PermissionResult{Permission: permission}
+
+		review, err := p.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   permission.Namespace,
+					Verb:        permission.Verb,
+					Group:       permission.Group,
+					Resource:    permission.Resource,
+					Subresource: permission.Subresource,
+				},
+			},
+		}, metav1.CreateOptions{})
+
+		if err != nil {
+			log.Error(err, "access review failed", "permission", permission.String())
+			result.Reason = err.Error()
+		} else {
+			result.Allowed = review.Status.Allowed
+			result.Reason = review.Status.Reason
+		}
+
+		if !result.Allowed {
+			log.Info("permission is missing", "permission", permission.String(), "reason", result.Reason)
+		}
+
+		status.Results = append(status.Results, result)
+	}
+
+	p.mutex.Lock()
+	p.status = status
+	p.mutex.Unlock()
+
+	return status
+}
+
+// ServeHTTP responds with the status of the last check.
+func (p *PermissionChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mutex.RLock()
+	status := p.status
+	p.mutex.RUnlock()
+
+	if status == nil {
+		http.Error(w, "permissions not checked yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		p.logger.Error(err, "failed to write the status")
+	}
+}
+
+// Run serves the status on address and checks the permissions every retry
+// period until the context is done.
+func (p *PermissionChecker) Run(ctx context.Context, address string) error {
+	log := p.logger
+
+	mux := http.NewServeMux()
+	mux.Handle(authcheck.StatusPath, p)
+	server := &http.Server{Addr: address, Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	log.Info("serving permission status", "address", address)
+
+	ticker := time.NewTicker(p.retryTime)
+	defer ticker.Stop()
+
+	p.Check(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			p.Check(ctx)
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		}
+	}
+}
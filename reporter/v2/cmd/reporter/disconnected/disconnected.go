@@ -0,0 +1,144 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disconnected
+
+import (
+	"io/ioutil"
+	"os"
+
+	"emperror.dev/errors"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/reporter/v2/pkg/reporter"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+	"github.com/spf13/cobra"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("reporter_disconnected_cmd")
+
+var archiveDir, signingKey, marketplaceKey, output, acknowledgements string
+
+var DisconnectedCmd = &cobra.Command{
+	Use:   "disconnected",
+	Short: "Manage the report archive of a disconnected cluster",
+	Long: `Exports the archived reports of a disconnected cluster as a signed bundle
+and imports the acknowledgements returned by Red Hat Marketplace.`,
+}
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the archived reports",
+	Long:  `Writes the reports that are not acknowledged to a signed tar.gz bundle`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Info("running the export command")
+
+		if signingKey == "" || output == "" {
+			log.Error(errors.New("signingKey or output not provided"), "signingKey or output not provided")
+			os.Exit(1)
+		}
+
+		keyData, err := ioutil.ReadFile(signingKey)
+		if err != nil {
+			log.Error(err, "failed to read signing key")
+			os.Exit(1)
+		}
+
+		signer, err := reporter.ParseSigningKey(keyData)
+		if err != nil {
+			log.Error(err, "failed to parse signing key")
+			os.Exit(1)
+		}
+
+		f, err := os.Create(output)
+		if err != nil {
+			log.Error(err, "failed to create bundle")
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		manifest, err := reporter.ExportArchive(archiveDir, signer, f)
+		if err != nil {
+			log.Error(err, "failed to export archive")
+			os.Exit(1)
+		}
+
+		log.Info("exported reports", "bundle", output, "count", len(manifest.Files))
+	},
+}
+
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the report acknowledgements",
+	Long: `Moves the acknowledged reports out of the archive so they are not exported again.
+The acknowledgements have to be signed by the Red Hat Marketplace public key and carry
+the manifest of a bundle signed by the signing key.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Info("running the import command")
+
+		if acknowledgements == "" || signingKey == "" || marketplaceKey == "" {
+			log.Error(errors.New("acknowledgements, signingKey or marketplaceKey not provided"), "acknowledgements, signingKey or marketplaceKey not provided")
+			os.Exit(1)
+		}
+
+		keyData, err := ioutil.ReadFile(signingKey)
+		if err != nil {
+			log.Error(err, "failed to read signing key")
+			os.Exit(1)
+		}
+
+		signer, err := reporter.ParseSigningKey(keyData)
+		if err != nil {
+			log.Error(err, "failed to parse signing key")
+			os.Exit(1)
+		}
+
+		publicKeyData, err := ioutil.ReadFile(marketplaceKey)
+		if err != nil {
+			log.Error(err, "failed to read marketplace key")
+			os.Exit(1)
+		}
+
+		publicKey, err := marketplace.ParsePublicKey(publicKeyData)
+		if err != nil {
+			log.Error(err, "failed to parse marketplace key")
+			os.Exit(1)
+		}
+
+		f, err := os.Open(acknowledgements)
+		if err != nil {
+			log.Error(err, "failed to open acknowledgements")
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		moved, err := reporter.ImportAcknowledgements(archiveDir, f, signer.Public(), publicKey)
+		if err != nil {
+			log.Error(err, "failed to import acknowledgements")
+			os.Exit(1)
+		}
+
+		log.Info("acknowledged reports", "files", moved)
+	},
+}
+
+func init() {
+	DisconnectedCmd.PersistentFlags().StringVar(&archiveDir, "archiveDir", "/var/lib/redhat-marketplace/reports", "folder of the report archive")
+	DisconnectedCmd.PersistentFlags().StringVar(&signingKey, "signingKey", "", "pem encoded private key that signs the bundle")
+	ExportCmd.Flags().StringVar(&output, "output", "", "file to write the bundle to")
+	ImportCmd.Flags().StringVar(&acknowledgements, "acknowledgements", "", "acknowledgements file from Red Hat Marketplace")
+	ImportCmd.Flags().StringVar(&marketplaceKey, "marketplaceKey", "", "pem encoded public key of Red Hat Marketplace that signs the acknowledgements")
+
+	DisconnectedCmd.AddCommand(ExportCmd)
+	DisconnectedCmd.AddCommand(ImportCmd)
+}
@@ -28,7 +28,7 @@ import (
 
 var log = logf.Log.WithName("reporter_report_cmd")
 
-var name, namespace, cafile, tokenFile, uploadTarget, localFilePath string
+var name, namespace, cafile, tokenFile, uploadTarget, uploadURL, localFilePath string
 var local, upload bool
 var retry int
 
@@ -54,6 +54,8 @@ var ReportCmd = &cobra.Command{
 		switch v := uploadTarget.(type) {
 		case *reporter.LocalFilePathUploader:
 			v.LocalFilePath = localFilePath
+		case *reporter.RedHatInsightsUploader:
+			v.URL = uploadURL
 		}
 
 		cfg := &reporter.Config{
@@ -94,6 +96,7 @@ func init() {
 	ReportCmd.Flags().StringVar(&cafile, "cafile", "", "cafile for prometheus")
 	ReportCmd.Flags().StringVar(&tokenFile, "tokenfile", "", "token file for prometheus")
 	ReportCmd.Flags().StringVar(&uploadTarget, "uploadTarget", "redhat-insights", "target to upload to")
+	ReportCmd.Flags().StringVar(&uploadURL, "uploadURL", reporter.DefaultUploadURL, "url of the redhat-insights upload")
 	ReportCmd.Flags().StringVar(&localFilePath, "localFilePath", ".", "target to upload to")
 	ReportCmd.Flags().BoolVar(&local, "local", false, "run locally")
 	ReportCmd.Flags().BoolVar(&upload, "upload", true, "to upload the payload")
@@ -0,0 +1,333 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+)
+
+const (
+	// ArchiveManifestName is the file in the exported bundle that lists the reports.
+	ArchiveManifestName = "manifest.json"
+	// ArchiveSignatureName is the file in the exported bundle that holds the manifest signature.
+	ArchiveSignatureName = "manifest.sig"
+	// AcknowledgedDirectory is the folder of the archive that holds the acknowledged reports.
+	AcknowledgedDirectory = "acknowledged"
+)
+
+// ArchiveManifest lists the reports of an exported bundle.
+type ArchiveManifest struct {
+	Created time.Time     `json:"created"`
+	Files   []ArchiveFile `json:"files"`
+}
+
+// ArchiveFile is a report in an exported bundle.
+type ArchiveFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Acknowledgements are the reports Red Hat Marketplace has accepted from
+// an exported bundle. They carry the signed manifest of the bundle so only
+// reports exported by the cluster, and unchanged since, are acknowledged.
+// Signature is the signature of Red Hat Marketplace over the rest of the
+// acknowledgements, which proves the marketplace accepted the reports.
+type Acknowledgements struct {
+	Manifest          []byte   `json:"manifest"`
+	ManifestSignature []byte   `json:"manifestSignature"`
+	Files             []string `json:"files"`
+	Signature         []byte   `json:"signature,omitempty"`
+}
+
+// SignedContent returns the data the marketplace signature is made over.
+func (a *Acknowledgements) SignedContent() ([]byte, error) {
+	return json.Marshal(&Acknowledgements{
+		Manifest:          a.Manifest,
+		ManifestSignature: a.ManifestSignature,
+		Files:             a.Files,
+	})
+}
+
+// SignAcknowledgements sets the signature of acks. Red Hat Marketplace signs the
+// acknowledgements it returns with its private key.
+func SignAcknowledgements(acks *Acknowledgements, signer crypto.Signer) error {
+	data, err := acks.SignedContent()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal acknowledgements")
+	}
+
+	signature, err := marketplace.Sign(data, signer)
+	if err != nil {
+		return err
+	}
+
+	acks.Signature = signature
+	return nil
+}
+
+// ParseSigningKey reads a PEM encoded RSA or EC private key.
+func ParseSigningKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("signing key is not pem encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signing key")
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signing key type is not supported")
+	}
+
+	return signer, nil
+}
+
+// ExportArchive writes the reports in archiveDir that have not been
+// acknowledged to a tar.gz bundle with a manifest signed by signer.
+func ExportArchive(archiveDir string, signer crypto.Signer, w io.Writer) (*ArchiveManifest, error) {
+	infos, err := ioutil.ReadDir(archiveDir)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read archive")
+	}
+
+	manifest := &ArchiveManifest{
+		Created: time.Now().UTC(),
+		Files:   []ArchiveFile{},
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(archiveDir, info.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read report")
+		}
+
+		if err := writeTarFile(tw, info.Name(), data); err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, ArchiveFile{
+			Name:   info.Name(),
+			SHA256: hex.EncodeToString(sum[:]),
+			Size:   int64(len(data)),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal manifest")
+	}
+
+	signature, err := marketplace.Sign(manifestData, signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign manifest")
+	}
+
+	if err := writeTarFile(tw, ArchiveManifestName, manifestData); err != nil {
+		return nil, err
+	}
+
+	if err := writeTarFile(tw, ArchiveSignatureName, signature); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close tar")
+	}
+
+	if err := gzw.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close gzip")
+	}
+
+	return manifest, nil
+}
+
+// VerifyArchiveManifest checks the signature of an exported manifest.
+func VerifyArchiveManifest(manifest, signature []byte, key crypto.PublicKey) error {
+	return marketplace.VerifySignature(manifest, signature, key)
+}
+
+// ImportAcknowledgements moves the acknowledged reports of archiveDir into
+// the acknowledged folder so they are not exported again. The acknowledgements
+// have to be signed by marketplaceKey and their manifest by clusterKey, the key
+// the bundle was exported with. Reports that aren't in the manifest or changed
+// since the export are not moved. It returns the names of the reports that were moved.
+func ImportAcknowledgements(
+	archiveDir string,
+	r io.Reader,
+	clusterKey crypto.PublicKey,
+	marketplaceKey crypto.PublicKey,
+) ([]string, error) {
+	acks := &Acknowledgements{}
+
+	if err := json.NewDecoder(r).Decode(acks); err != nil {
+		return nil, errors.Wrap(err, "failed to parse acknowledgements")
+	}
+
+	if len(acks.Signature) == 0 {
+		return nil, errors.New("acknowledgements are not signed")
+	}
+
+	signed, err := acks.SignedContent()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal acknowledgements")
+	}
+
+	if err := marketplace.VerifySignature(signed, acks.Signature, marketplaceKey); err != nil {
+		return nil, errors.Wrap(err, "failed to verify acknowledgements")
+	}
+
+	if err := VerifyArchiveManifest(acks.Manifest, acks.ManifestSignature, clusterKey); err != nil {
+		return nil, errors.Wrap(err, "failed to verify acknowledged manifest")
+	}
+
+	manifest := &ArchiveManifest{}
+	if err := json.Unmarshal(acks.Manifest, manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse acknowledged manifest")
+	}
+
+	exported := map[string]string{}
+	for _, file := range manifest.Files {
+		exported[file.Name] = file.SHA256
+	}
+
+	ackDir := filepath.Join(archiveDir, AcknowledgedDirectory)
+	if err := os.MkdirAll(ackDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create acknowledged folder")
+	}
+
+	moved := []string{}
+
+	for _, file := range acks.Files {
+		name := filepath.Base(file)
+		src := filepath.Join(archiveDir, name)
+
+		sha, ok := exported[name]
+		if !ok {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(src)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return moved, errors.Wrapf(err, "failed to read %s", name)
+		}
+
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != sha {
+			continue
+		}
+
+		if err := os.Rename(src, filepath.Join(ackDir, name)); err != nil {
+			return moved, errors.Wrapf(err, "failed to acknowledge %s", name)
+		}
+
+		moved = append(moved, name)
+	}
+
+	sort.Strings(moved)
+	return moved, nil
+}
+
+// ReadArchive returns the files of an exported bundle by name.
+func ReadArchive(r io.Reader) (map[string][]byte, error) {
+	gzr, err := gzip.NewReader(r)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read gzip")
+	}
+
+	defer gzr.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gzr)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read tar")
+		}
+
+		buf := &bytes.Buffer{}
+		if _, err := io.Copy(buf, tr); err != nil {
+			return nil, errors.Wrap(err, "failed to read file")
+		}
+
+		files[header.Name] = buf.Bytes()
+	}
+
+	return files, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrap(err, "failed to write header")
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return errors.Wrap(err, "failed to write file")
+	}
+
+	return nil
+}
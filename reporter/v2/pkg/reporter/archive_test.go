@@ -0,0 +1,178 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Archive", func() {
+	var (
+		archiveDir     string
+		key            *ecdsa.PrivateKey
+		marketplaceKey *ecdsa.PrivateKey
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		archiveDir, err = ioutil.TempDir("", "archive")
+		Expect(err).To(Succeed())
+
+		Expect(ioutil.WriteFile(filepath.Join(archiveDir, "report-1.tar.gz"), []byte("one"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(archiveDir, "report-2.tar.gz"), []byte("two"), 0644)).To(Succeed())
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(Succeed())
+
+		marketplaceKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(archiveDir)
+	})
+
+	It("should parse the signing key", func() {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		Expect(err).To(Succeed())
+
+		signer, err := ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		Expect(err).To(Succeed())
+		Expect(signer.Public()).To(Equal(key.Public()))
+	})
+
+	It("should export a signed bundle", func() {
+		buf := &bytes.Buffer{}
+
+		manifest, err := ExportArchive(archiveDir, key, buf)
+		Expect(err).To(Succeed())
+		Expect(manifest.Files).To(HaveLen(2))
+
+		files, err := ReadArchive(buf)
+		Expect(err).To(Succeed())
+		Expect(files).To(HaveKeyWithValue("report-1.tar.gz", []byte("one")))
+		Expect(files).To(HaveKeyWithValue("report-2.tar.gz", []byte("two")))
+		Expect(files).To(HaveKey(ArchiveManifestName))
+		Expect(files).To(HaveKey(ArchiveSignatureName))
+
+		Expect(VerifyArchiveManifest(files[ArchiveManifestName], files[ArchiveSignatureName], key.Public())).To(Succeed())
+		Expect(VerifyArchiveManifest([]byte("tampered"), files[ArchiveSignatureName], key.Public())).ToNot(Succeed())
+	})
+
+	It("should not export acknowledged reports", func() {
+		buf := &bytes.Buffer{}
+		_, err := ExportArchive(archiveDir, key, buf)
+		Expect(err).To(Succeed())
+
+		files, err := ReadArchive(buf)
+		Expect(err).To(Succeed())
+
+		// changed after the export
+		Expect(ioutil.WriteFile(filepath.Join(archiveDir, "report-2.tar.gz"), []byte("changed"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(archiveDir, "report-3.tar.gz"), []byte("three"), 0644)).To(Succeed())
+
+		acks := &Acknowledgements{
+			Manifest:          files[ArchiveManifestName],
+			ManifestSignature: files[ArchiveSignatureName],
+			Files:             []string{"report-1.tar.gz", "report-2.tar.gz", "report-3.tar.gz", "missing.tar.gz"},
+		}
+		Expect(SignAcknowledgements(acks, marketplaceKey)).To(Succeed())
+
+		data, err := json.Marshal(acks)
+		Expect(err).To(Succeed())
+
+		moved, err := ImportAcknowledgements(archiveDir, bytes.NewReader(data), key.Public(), marketplaceKey.Public())
+		Expect(err).To(Succeed())
+		Expect(moved).To(ConsistOf("report-1.tar.gz"))
+		Expect(filepath.Join(archiveDir, AcknowledgedDirectory, "report-1.tar.gz")).To(BeAnExistingFile())
+
+		manifest, err := ExportArchive(archiveDir, key, &bytes.Buffer{})
+		Expect(err).To(Succeed())
+		Expect(manifest.Files).To(HaveLen(2))
+		Expect(manifest.Files[0].Name).To(Equal("report-2.tar.gz"))
+	})
+
+	It("should reject acknowledgements of a manifest it didn't sign", func() {
+		buf := &bytes.Buffer{}
+		_, err := ExportArchive(archiveDir, key, buf)
+		Expect(err).To(Succeed())
+
+		files, err := ReadArchive(buf)
+		Expect(err).To(Succeed())
+
+		acks := &Acknowledgements{
+			Manifest:          files[ArchiveManifestName],
+			ManifestSignature: []byte("forged"),
+			Files:             []string{"report-1.tar.gz"},
+		}
+		Expect(SignAcknowledgements(acks, marketplaceKey)).To(Succeed())
+
+		data, err := json.Marshal(acks)
+		Expect(err).To(Succeed())
+
+		_, err = ImportAcknowledgements(archiveDir, bytes.NewReader(data), key.Public(), marketplaceKey.Public())
+		Expect(err).To(HaveOccurred())
+		Expect(filepath.Join(archiveDir, "report-1.tar.gz")).To(BeAnExistingFile())
+	})
+
+	It("should reject acknowledgements the marketplace didn't sign", func() {
+		buf := &bytes.Buffer{}
+		_, err := ExportArchive(archiveDir, key, buf)
+		Expect(err).To(Succeed())
+
+		files, err := ReadArchive(buf)
+		Expect(err).To(Succeed())
+
+		acks := &Acknowledgements{
+			Manifest:          files[ArchiveManifestName],
+			ManifestSignature: files[ArchiveSignatureName],
+			Files:             []string{"report-1.tar.gz"},
+		}
+
+		data, err := json.Marshal(acks)
+		Expect(err).To(Succeed())
+
+		_, err = ImportAcknowledgements(archiveDir, bytes.NewReader(data), key.Public(), marketplaceKey.Public())
+		Expect(err).To(HaveOccurred())
+
+		// signed with the cluster key
+		Expect(SignAcknowledgements(acks, key)).To(Succeed())
+		data, err = json.Marshal(acks)
+		Expect(err).To(Succeed())
+
+		_, err = ImportAcknowledgements(archiveDir, bytes.NewReader(data), key.Public(), marketplaceKey.Public())
+		Expect(err).To(HaveOccurred())
+		Expect(filepath.Join(archiveDir, "report-1.tar.gz")).To(BeAnExistingFile())
+	})
+
+	It("should reject invalid acknowledgements", func() {
+		_, err := ImportAcknowledgements(archiveDir, strings.NewReader("{"), key.Public(), marketplaceKey.Public())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -24,6 +24,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/imdario/mergo"
 	"github.com/mitchellh/mapstructure"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
 )
 
@@ -68,6 +69,15 @@ type ReportSourceMetadata struct {
 	RhmAccountID   string            `json:"rhmAccountId" mapstructure:"rhmAccountId"`
 	RhmEnvironment ReportEnvironment `json:"rhmEnvironment,omitempty" mapstructure:"rhmEnvironment,omitempty"`
 	Version        string            `json:"version,omitempty" mapstructure:"version,omitempty"`
+
+	// MeterDefinitionHashes maps namespace/name of each meter definition used by the
+	// report to the hash of its spec.
+	MeterDefinitionHashes map[string]string `json:"meterDefinitionHashes,omitempty" mapstructure:"meterDefinitionHashes,omitempty"`
+
+	// ClusterInventory describes the nodes, versions and marketplace operators of
+	// the cluster when the report was submitted. It is kept out of mapstructure
+	// so the flat metadata serializes it with its json names.
+	ClusterInventory *common.ClusterInventorySummary `json:"clusterInventory,omitempty" mapstructure:"-"`
 }
 
 type ReportFlatMetadata struct {
@@ -82,6 +92,9 @@ func (d ReportFlatMetadata) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return []byte{}, err
 	}
+	if d.Metadata.ClusterInventory != nil {
+		result["clusterInventory"] = d.Metadata.ClusterInventory
+	}
 	return json.Marshal(&result)
 }
 
@@ -93,6 +106,13 @@ func (d *ReportFlatMetadata) UnmarshalJSON(data []byte) error {
 	if err := mapstructure.Decode(jd, d); err != nil {
 		return err
 	}
+	inventory := struct {
+		ClusterInventory *common.ClusterInventorySummary `json:"clusterInventory"`
+	}{}
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return err
+	}
+	d.Metadata.ClusterInventory = inventory.ClusterInventory
 	return nil
 }
 
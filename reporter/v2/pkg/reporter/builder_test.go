@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 )
 
 var _ = Describe("Builder", func() {
@@ -78,6 +79,56 @@ var _ = Describe("Builder", func() {
 		Expect(u.SourceMetadata.RhmEnvironment).To(Equal(ReportSandboxEnv))
 	})
 
+	It("should serialize meter definition hashes to json", func() {
+		metadata := NewReportMetadata(uuid.New(), ReportSourceMetadata{
+			RhmClusterID:   "testCluster",
+			RhmEnvironment: ReportSandboxEnv,
+			RhmAccountID:   "testAccount",
+			MeterDefinitionHashes: map[string]string{
+				"foo/bar": "abc",
+			},
+		})
+
+		data, err := json.Marshal(metadata)
+		Expect(err).To(Succeed())
+
+		u := ReportMetadata{}
+		Expect(json.Unmarshal(data, &u)).To(Succeed())
+		Expect(u.SourceMetadata.MeterDefinitionHashes).To(HaveKeyWithValue("foo/bar", "abc"))
+	})
+
+	It("should serialize the cluster inventory to json", func() {
+		metadata := NewReportMetadata(uuid.New(), ReportSourceMetadata{
+			RhmClusterID: "testCluster",
+			RhmAccountID: "testAccount",
+			ClusterInventory: &common.ClusterInventorySummary{
+				NodeCount:         3,
+				KubernetesVersion: "v1.19.0",
+				Operators: []common.InstalledOperator{
+					{Package: "foo", Namespace: "bar", CSV: "foo.v1.0.0", Version: "1.0.0"},
+				},
+			},
+		})
+
+		data, err := json.Marshal(metadata)
+		Expect(err).To(Succeed())
+
+		u := ReportMetadata{}
+		Expect(json.Unmarshal(data, &u)).To(Succeed())
+		Expect(u.SourceMetadata.ClusterInventory).To(Equal(metadata.SourceMetadata.ClusterInventory))
+
+		data, err = json.Marshal(metadata.ToFlat())
+		Expect(err).To(Succeed())
+
+		flat := map[string]interface{}{}
+		Expect(json.Unmarshal(data, &flat)).To(Succeed())
+		Expect(flat).To(HaveKeyWithValue("clusterInventory", HaveKeyWithValue("nodeCount", BeEquivalentTo(3))))
+
+		uflat := ReportFlatMetadata{}
+		Expect(json.Unmarshal(data, &uflat)).To(Succeed())
+		Expect(uflat.Metadata.ClusterInventory).To(Equal(metadata.SourceMetadata.ClusterInventory))
+	})
+
 	It("should add metrics to a base", func() {
 
 		metricsReport.AddMetadata(metadata.ToFlat())
@@ -15,14 +15,12 @@
 package reporter
 
 import (
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/base64"
-	"io/ioutil"
 	"net/http"
 
 	"emperror.dev/errors"
 	"github.com/prometheus/client_golang/api"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
 )
 
 type PrometheusSecureClientConfig struct {
@@ -33,6 +31,15 @@ type PrometheusSecureClientConfig struct {
 	UserAuth *UserAuth
 
 	ServerCertFile string
+
+	// ServerCert is a PEM encoded CA bundle trusted in addition to ServerCertFile
+	ServerCert []byte
+
+	InsecureSkipVerify bool
+
+	// ServerName is verified against the server certificate in place of the
+	// host of Address
+	ServerName string
 }
 
 type UserAuth struct {
@@ -40,54 +47,35 @@ type UserAuth struct {
 }
 
 func NewSecureClient(config *PrometheusSecureClientConfig) (api.Client, error) {
-	tlsConfig, err := generateCACertPool(config.ServerCertFile)
+	httpTransport, err := transport.NewTransport(&transport.Config{
+		CAFiles:            []string{config.ServerCertFile},
+		CAData:             config.ServerCert,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+		ServerName:         config.ServerName,
+	})
 
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get tlsConfig")
+		return nil, errors.Wrap(err, "failed to get transport")
 	}
 
-	var transport http.RoundTripper
-
-	transport = &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}
+	var rt http.RoundTripper = httpTransport
 
 	if config.UserAuth != nil {
-		transport = WithBasicAuth(transport, config.UserAuth.Username, config.UserAuth.Password)
+		rt = WithBasicAuth(rt, config.UserAuth.Username, config.UserAuth.Password)
 	}
 
 	if config.Token != "" {
-		transport = WithBearerAuth(transport, config.Token)
+		rt = WithBearerAuth(rt, config.Token)
 	}
 
 	client, err := api.NewClient(api.Config{
 		Address:      config.Address,
-		RoundTripper: transport,
+		RoundTripper: rt,
 	})
 
 	return client, err
 }
 
-func generateCACertPool(files ...string) (*tls.Config, error) {
-	caCertPool, err := x509.SystemCertPool()
-
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get system cert pool")
-	}
-
-	for _, file := range files {
-		caCert, err := ioutil.ReadFile(file)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to load cert file")
-		}
-		caCertPool.AppendCertsFromPEM(caCert)
-	}
-
-	return &tls.Config{
-		RootCAs: caCertPool,
-	}, nil
-}
-
 type withHeader struct {
 	http.Header
 	rt http.RoundTripper
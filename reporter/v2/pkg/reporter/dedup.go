@@ -0,0 +1,238 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/api"
+	"github.com/prometheus/common/model"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+)
+
+// replicaClient is the client of a single Prometheus replica
+type replicaClient struct {
+	name string
+	api.Client
+}
+
+// dedupClient queries every replica of a Prometheus deployment. Range query
+// results are merged, so the gaps of a replica that was down are filled with
+// the samples of the others. Other requests are answered by the first replica
+// that responds.
+type dedupClient struct {
+	replicas []replicaClient
+}
+
+// NewDedupClient returns a client querying every replica, keyed by replica name.
+func NewDedupClient(replicas map[string]api.Client) api.Client {
+	client := &dedupClient{}
+
+	for name, replica := range replicas {
+		client.replicas = append(client.replicas, replicaClient{name: name, Client: replica})
+	}
+
+	sort.Slice(client.replicas, func(i, j int) bool {
+		return client.replicas[i].name < client.replicas[j].name
+	})
+
+	return client
+}
+
+func (c *dedupClient) URL(ep string, args map[string]string) *url.URL {
+	return c.replicas[0].URL(ep, args)
+}
+
+type replicaResponse struct {
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func (r *replicaResponse) ok() bool {
+	return r.err == nil && r.resp != nil && r.resp.StatusCode/100 == 2
+}
+
+type queryRangeResponse struct {
+	Status   string         `json:"status"`
+	Data     queryRangeData `json:"data"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+type queryRangeData struct {
+	ResultType model.ValueType `json:"resultType"`
+	Result     model.Matrix    `json:"result"`
+}
+
+func (c *dedupClient) Do(ctx context.Context, req *http.Request) (*http.Response, []byte, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	responses := make([]replicaResponse, len(c.replicas))
+
+	var wg sync.WaitGroup
+	for i := range c.replicas {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			replica := c.replicas[i]
+			base := replica.URL("", nil)
+
+			replicaReq := req.Clone(ctx)
+			replicaReq.URL.Scheme = base.Scheme
+			replicaReq.URL.Host = base.Host
+			replicaReq.Host = ""
+			if body != nil {
+				replicaReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+
+			resp, respBody, err := replica.Do(ctx, replicaReq)
+			responses[i] = replicaResponse{resp: resp, body: respBody, err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	var first *replicaResponse
+	names := []string{}
+	matrices := []model.Matrix{}
+	warnings := []string{}
+
+	for i := range responses {
+		response := &responses[i]
+		if !response.ok() {
+			logger.Info("prometheus replica query failed", "replica", c.replicas[i].name, "error", response.err)
+			continue
+		}
+
+		if first == nil {
+			first = response
+		}
+
+		result := queryRangeResponse{}
+		if err := json.Unmarshal(response.body, &result); err != nil ||
+			result.Status != "success" ||
+			result.Data.ResultType != model.ValMatrix {
+			continue
+		}
+
+		names = append(names, c.replicas[i].name)
+		matrices = append(matrices, result.Data.Result)
+		warnings = append(warnings, result.Warnings...)
+	}
+
+	if first == nil {
+		return responses[0].resp, responses[0].body, responses[0].err
+	}
+
+	if !strings.HasSuffix(req.URL.Path, "/query_range") || len(matrices) == 0 {
+		return first.resp, first.body, first.err
+	}
+
+	logger.V(4).Info("merging replica results", "replicas", names)
+
+	merged, err := json.Marshal(queryRangeResponse{
+		Status: "success",
+		Data: queryRangeData{
+			ResultType: model.ValMatrix,
+			Result:     mergeReplicaMatrices(matrices),
+		},
+		Warnings: warnings,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return first.resp, merged, nil
+}
+
+// mergeReplicaMatrices merges the series of the replicas that are identical
+// without the replica label. A series takes the samples of the replica with the
+// most samples, the samples of the other replicas fill its gaps. Replicas
+// earlier in the list win ties.
+func mergeReplicaMatrices(matrices []model.Matrix) model.Matrix {
+	type replicaSeries struct {
+		metric  model.Metric
+		samples [][]model.SamplePair
+	}
+
+	series := map[model.Fingerprint]*replicaSeries{}
+	order := []model.Fingerprint{}
+
+	for _, matrix := range matrices {
+		for _, stream := range matrix {
+			metric := stream.Metric.Clone()
+			delete(metric, model.LabelName(utils.PrometheusReplicaLabel))
+
+			fp := metric.Fingerprint()
+			s, ok := series[fp]
+			if !ok {
+				s = &replicaSeries{metric: metric}
+				series[fp] = s
+				order = append(order, fp)
+			}
+
+			s.samples = append(s.samples, stream.Values)
+		}
+	}
+
+	result := model.Matrix{}
+
+	for _, fp := range order {
+		s := series[fp]
+
+		sort.SliceStable(s.samples, func(i, j int) bool {
+			return len(s.samples[i]) > len(s.samples[j])
+		})
+
+		seen := map[model.Time]bool{}
+		values := []model.SamplePair{}
+
+		for _, samples := range s.samples {
+			for _, pair := range samples {
+				if seen[pair.Timestamp] {
+					continue
+				}
+
+				seen[pair.Timestamp] = true
+				values = append(values, pair)
+			}
+		}
+
+		sort.Slice(values, func(i, j int) bool {
+			return values[i].Timestamp.Before(values[j].Timestamp)
+		})
+
+		result = append(result, &model.SampleStream{Metric: s.metric, Values: values})
+	}
+
+	sort.Sort(result)
+	return result
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+var _ = Describe("DedupClient", func() {
+	pairs := func(values ...float64) []model.SamplePair {
+		result := []model.SamplePair{}
+		for i, v := range values {
+			if v < 0 {
+				continue
+			}
+			result = append(result, model.SamplePair{
+				Timestamp: model.Time(int64(i) * 3600 * 1000),
+				Value:     model.SampleValue(v),
+			})
+		}
+		return result
+	}
+
+	It("should fill the gaps of a replica from the others", func() {
+		merged := mergeReplicaMatrices([]model.Matrix{
+			{
+				{Metric: model.Metric{"pod": "a", "prometheus_replica": "prometheus-0"}, Values: pairs(1, -1, -1, 4)},
+				{Metric: model.Metric{"pod": "b"}, Values: pairs(1, 2)},
+			},
+			{
+				{Metric: model.Metric{"pod": "a", "prometheus_replica": "prometheus-1"}, Values: pairs(10, 2, 3, -1)},
+				{Metric: model.Metric{"pod": "b"}, Values: pairs(5, 6)},
+			},
+		})
+
+		Expect(merged).To(HaveLen(2))
+		Expect(merged[0].Metric).To(Equal(model.Metric{"pod": "a"}))
+		Expect(merged[0].Values).To(Equal(pairs(10, 2, 3, 4)))
+		Expect(merged[1].Metric).To(Equal(model.Metric{"pod": "b"}))
+		Expect(merged[1].Values).To(Equal(pairs(1, 2)))
+	})
+
+	Context("querying replicas", func() {
+		var (
+			servers []*httptest.Server
+			clients map[string]api.Client
+		)
+
+		BeforeEach(func() {
+			servers = []*httptest.Server{}
+			clients = map[string]api.Client{}
+
+			for i, values := range []string{
+				`[[0,"1"],[7200,"3"]]`,
+				`[[0,"1"],[3600,"2"]]`,
+			} {
+				values := values
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.URL.Path {
+					case "/api/v1/query_range":
+						Expect(r.ParseForm()).To(Succeed())
+						Expect(r.Form.Get("query")).To(Equal("up"))
+						fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"pod":"a"},"values":%s}]}}`, values)
+					default:
+						fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+					}
+				}))
+				servers = append(servers, server)
+
+				client, err := api.NewClient(api.Config{Address: server.URL})
+				Expect(err).To(Succeed())
+				clients[fmt.Sprintf("prometheus-%d", i)] = client
+			}
+		})
+
+		AfterEach(func() {
+			for _, server := range servers {
+				server.Close()
+			}
+		})
+
+		It("should merge the range queries of every replica", func() {
+			promAPI := v1.NewAPI(NewDedupClient(clients))
+
+			result, _, err := promAPI.QueryRange(context.TODO(), "up", v1.Range{
+				Start: time.Unix(0, 0),
+				End:   time.Unix(7200, 0),
+				Step:  time.Hour,
+			})
+			Expect(err).To(Succeed())
+
+			matrix := result.(model.Matrix)
+			Expect(matrix).To(HaveLen(1))
+			Expect(matrix[0].Values).To(Equal([]model.SamplePair{
+				{Timestamp: 0, Value: 1},
+				{Timestamp: 3600 * 1000, Value: 2},
+				{Timestamp: 7200 * 1000, Value: 3},
+			}))
+		})
+
+		It("should answer from the replicas that are up", func() {
+			servers[0].Close()
+			promAPI := v1.NewAPI(NewDedupClient(clients))
+
+			result, _, err := promAPI.QueryRange(context.TODO(), "up", v1.Range{
+				Start: time.Unix(0, 0),
+				End:   time.Unix(7200, 0),
+				Step:  time.Hour,
+			})
+			Expect(err).To(Succeed())
+			Expect(result.(model.Matrix)[0].Values).To(HaveLen(2))
+
+			_, _, err = promAPI.Query(context.TODO(), "up", time.Unix(0, 0))
+			Expect(err).To(Succeed())
+		})
+	})
+})
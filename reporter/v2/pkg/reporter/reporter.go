@@ -69,6 +69,10 @@ type MarketplaceReporter struct {
 	report            *marketplacev1alpha1.MeterReport
 	prometheusService *corev1.Service
 	*Config
+
+	// usedMeterDefinitions are the meter definitions with metrics in the
+	// report period, set by CollectMetrics
+	usedMeterDefinitions map[types.NamespacedName]bool
 }
 
 type ReportName types.NamespacedName
@@ -122,6 +126,8 @@ func (r *MarketplaceReporter) CollectMetrics(ctxIn context.Context) (map[MetricK
 
 	logger.Info("starting build queries")
 
+	r.usedMeterDefinitions = map[types.NamespacedName]bool{}
+
 	go r.retrieveMeterDefinitions(
 		meterDefsChan,
 		errorsChan,
@@ -283,6 +289,8 @@ func (r *MarketplaceReporter) retrieveMeterDefinitions(
 			max = max.Add(-time.Second)
 			promQuery := buildPromQuery(matrix.Metric, min, max)
 
+			r.usedMeterDefinitions[promQuery.query.MeterDef] = true
+
 			logger.Info("getting query", "query", promQuery.String(), "start", min, "end", max)
 			meterDefsChan <- promQuery
 		}
@@ -453,6 +461,14 @@ func (r *MarketplaceReporter) process(
 	})
 }
 
+// MeterDefinitionSnapshot returns the snapshot of the report limited to the
+// meter definitions with metrics in the report period.
+func (r *MarketplaceReporter) MeterDefinitionSnapshot() *common.MeterDefinitionSnapshot {
+	return r.report.Status.MeterDefinitionSnapshot.Filter(func(ref common.MeterDefinitionReference) bool {
+		return r.usedMeterDefinitions[types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}]
+	})
+}
+
 func (r *MarketplaceReporter) WriteReport(
 	source uuid.UUID,
 	metrics map[MetricKey]*MetricBase) ([]string, error) {
@@ -469,6 +485,9 @@ func (r *MarketplaceReporter) WriteReport(
 		RhmClusterID:   r.mktconfig.Spec.ClusterUUID,
 		RhmEnvironment: env,
 		Version:        version.Version,
+
+		MeterDefinitionHashes: r.MeterDefinitionSnapshot().SpecHashes(),
+		ClusterInventory:      r.report.Status.ClusterInventory,
 	})
 
 	var partitionSize = *r.MetricsPerFile
@@ -19,6 +19,7 @@ import (
 
 	"context"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -34,13 +35,21 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/meirf/gopart"
+	openshiftconfigv1 "github.com/openshift/api/config/v1"
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace/fake"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8sfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("Reporter", func() {
@@ -251,6 +260,102 @@ var _ = Describe("Reporter", func() {
 
 		close(done)
 	}, 20)
+
+	It("only keeps the meter definitions used by the report in the snapshot", func(done Done) {
+		sut.report.Status.MeterDefinitionSnapshot = &common.MeterDefinitionSnapshot{
+			ConfigMapName: "snapshot",
+			MeterDefinitions: []common.MeterDefinitionReference{
+				{Namespace: "bar", Name: "foo", SpecHash: "used"},
+				{Namespace: "bar", Name: "unused", SpecHash: "unused"},
+			},
+		}
+
+		_, _, err := sut.CollectMetrics(context.TODO())
+		Expect(err).To(Succeed())
+
+		snapshot := sut.MeterDefinitionSnapshot()
+		Expect(snapshot.ConfigMapName).To(Equal("snapshot"))
+		Expect(snapshot.SpecHashes()).To(Equal(map[string]string{"bar/foo": "used"}))
+
+		close(done)
+	}, 20)
+
+	It("uploads the report of a cluster registered with the marketplace", func(done Done) {
+		server := fake.NewServer()
+		server.Token = "token"
+		marketplaceServer := fake.NewTLSServer(server)
+		defer marketplaceServer.Close()
+
+		By("registering the cluster")
+		server.SetRegistration(config.Spec.RhmAccountID, config.Spec.ClusterUUID, marketplace.RegistrationStatusInstalled)
+
+		marketplaceClient, err := marketplace.NewMarketplaceClient(&marketplace.MarketplaceClientConfig{
+			Url:       marketplaceServer.URL,
+			Token:     "token",
+			Transport: marketplaceServer.Client().Transport.(*http.Transport),
+		})
+		Expect(err).To(Succeed())
+
+		registration, err := marketplaceClient.RegistrationStatus(&marketplace.MarketplaceClientAccount{
+			AccountId:   config.Spec.RhmAccountID,
+			ClusterUuid: config.Spec.ClusterUUID,
+		})
+		Expect(err).To(Succeed())
+		Expect(registration.RegistrationStatus).To(Equal(marketplace.RegistrationStatusInstalled))
+
+		By("writing the report")
+		results, _, err := sut.CollectMetrics(context.TODO())
+		Expect(err).To(Succeed())
+
+		files, err := sut.WriteReport(uuid.New(), results)
+		Expect(err).To(Succeed())
+
+		fileName := filepath.Join(dir2, "registered-upload.tar.gz")
+		Expect(TargzFolder(filepath.Dir(files[0]), fileName)).To(Succeed())
+
+		By("uploading the report to the marketplace")
+		caFile := filepath.Join(dir2, "ca.crt")
+		Expect(ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: marketplaceServer.Certificate().Raw,
+		}), 0600)).To(Succeed())
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftconfigv1.AddToScheme(scheme)).To(Succeed())
+
+		client := k8sfake.NewFakeClientWithScheme(scheme,
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "openshift-config"},
+				Data: map[string][]byte{
+					".dockerconfigjson": []byte(`{"auths":{"cloud.openshift.com":{"auth":"token"}}}`),
+				},
+			},
+			&openshiftconfigv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Spec:       openshiftconfigv1.ClusterVersionSpec{ClusterID: openshiftconfigv1.ClusterID(config.Spec.ClusterUUID)},
+			})
+		cc := reconcileutils.NewClientCommand(client, scheme, logger)
+
+		marketplaceUploader, err := ProvideUploader(context.TODO(), cc, logger, &RedHatInsightsUploader{
+			RedHatInsightsUploaderConfig: RedHatInsightsUploaderConfig{
+				URL:                 marketplaceServer.URL,
+				AdditionalCertFiles: []string{caFile},
+			},
+		})
+		Expect(err).To(Succeed())
+		Expect(marketplaceUploader.UploadFile(fileName)).To(Succeed())
+
+		uploads := server.Uploads()
+		Expect(uploads).To(HaveLen(1))
+		Expect(uploads[0].FileName).To(Equal("registered-upload.tar.gz"))
+
+		data, err := ioutil.ReadFile(fileName)
+		Expect(err).To(Succeed())
+		Expect(uploads[0].Data).To(Equal(data))
+
+		close(done)
+	}, 20)
 })
 
 // RoundTripFunc is a type that represents a round trip function call for std http lib
@@ -18,7 +18,9 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"path/filepath"
+	"strconv"
 
 	"emperror.dev/errors"
 	"github.com/google/uuid"
@@ -111,6 +113,15 @@ func (r *Task) Run() error {
 				OnContinue(Call(func() (ClientAction, error) {
 					report.Status.MetricUploadCount = ptr.Int(len(metrics))
 
+					// the snapshot keeps only the meter definitions the report used
+					report.Status.MeterDefinitionSnapshot = reporter.MeterDefinitionSnapshot()
+
+					// the usage summary only counts usage that was uploaded or
+					// archived for a disconnected upload
+					if r.Config.Upload {
+						report.Status.Usage, report.Status.UsageTruncated = SummarizeUsage(metrics)
+					}
+
 					report.Status.QueryErrorList = []string{}
 
 					for _, err := range errorList {
@@ -137,11 +148,17 @@ func (r *Task) Run() error {
 }
 
 func provideApiClient(
+	ctx context.Context,
+	cc ClientCommandRunner,
 	report *marketplacev1alpha1.MeterReport,
 	promService *corev1.Service,
 	config *Config,
 ) (api.Client, error) {
 
+	if report.Spec.ExternalPrometheus != nil {
+		return provideExternalApiClient(ctx, cc, report, config)
+	}
+
 	if config.Local {
 		client, err := api.NewClient(api.Config{
 			Address: "http://localhost:9090",
@@ -179,6 +196,33 @@ func provideApiClient(
 		auth = fmt.Sprintf(string(content))
 	}
 
+	replicas, err := getPrometheusReplicas(ctx, cc, promService, port)
+	if err != nil {
+		logger.Error(err, "failed to get the prometheus replicas, querying the service")
+	}
+
+	if len(replicas) > 1 {
+		clients := map[string]api.Client{}
+
+		for replica, address := range replicas {
+			conf, err := NewSecureClient(&PrometheusSecureClientConfig{
+				Address:        fmt.Sprintf("https://%s", address),
+				ServerCertFile: config.CaFile,
+				Token:          auth,
+				ServerName:     fmt.Sprintf("%s.%s.svc", name, namespace),
+			})
+
+			if err != nil {
+				return nil, err
+			}
+
+			clients[replica] = conf
+		}
+
+		logger.Info("querying the prometheus replicas", "replicas", len(clients))
+		return NewDedupClient(clients), nil
+	}
+
 	conf, err := NewSecureClient(&PrometheusSecureClientConfig{
 		Address:        fmt.Sprintf("https://%s.%s.svc:%v", name, namespace, port),
 		ServerCertFile: config.CaFile,
@@ -192,6 +236,145 @@ func provideApiClient(
 	return conf, nil
 }
 
+// getPrometheusReplicas returns the addresses of the ready pods behind the
+// service port, keyed by pod name.
+func getPrometheusReplicas(
+	ctx context.Context,
+	cc ClientCommandRunner,
+	promService *corev1.Service,
+	port int32,
+) (map[string]string, error) {
+	endpoints := &corev1.Endpoints{}
+
+	if result, _ := cc.Do(ctx, GetAction(types.NamespacedName{
+		Name:      promService.Name,
+		Namespace: promService.Namespace,
+	}, endpoints)); !result.Is(Continue) {
+		return nil, errors.Wrap(result, "failed to get endpoints")
+	}
+
+	var portName string
+	for _, p := range promService.Spec.Ports {
+		if p.Port == port {
+			portName = p.Name
+		}
+	}
+
+	replicas := map[string]string{}
+
+	for _, subset := range endpoints.Subsets {
+		var targetPort int32
+		for _, p := range subset.Ports {
+			if p.Name == portName {
+				targetPort = p.Port
+			}
+		}
+
+		if targetPort == 0 {
+			continue
+		}
+
+		for _, address := range subset.Addresses {
+			replica := address.IP
+			if address.TargetRef != nil {
+				replica = address.TargetRef.Name
+			}
+
+			replicas[replica] = net.JoinHostPort(address.IP, strconv.Itoa(int(targetPort)))
+		}
+	}
+
+	return replicas, nil
+}
+
+// serviceAccountTokenFile is the token mounted into every pod. The token passed with
+// --tokenfile is only valid for the operator's prometheus service.
+const serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+func provideExternalApiClient(
+	ctx context.Context,
+	cc ClientCommandRunner,
+	report *marketplacev1alpha1.MeterReport,
+	config *Config,
+) (api.Client, error) {
+	external := report.Spec.ExternalPrometheus
+	clientConfig := &PrometheusSecureClientConfig{
+		Address:            external.URL,
+		ServerCertFile:     config.CaFile,
+		InsecureSkipVerify: external.InsecureSkipVerify,
+	}
+
+	if external.CA != nil {
+		cm := &corev1.ConfigMap{}
+
+		if result, _ := cc.Do(ctx, GetAction(
+			types.NamespacedName{Name: external.CA.Name, Namespace: report.Namespace}, cm,
+		)); !result.Is(Continue) {
+			return nil, errors.Wrap(result, "failed to get prometheus ca configmap")
+		}
+
+		ca, ok := cm.Data[external.CA.Key]
+		if !ok {
+			return nil, errors.Errorf("prometheus ca configmap %s is missing key %s", external.CA.Name, external.CA.Key)
+		}
+
+		clientConfig.ServerCert = []byte(ca)
+	}
+
+	switch {
+	case external.BearerTokenSecret != nil:
+		token, err := getSecretValue(ctx, cc, report.Namespace, external.BearerTokenSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		clientConfig.Token = token
+	case external.BasicAuth != nil:
+		username, err := getSecretValue(ctx, cc, report.Namespace, &external.BasicAuth.Username)
+		if err != nil {
+			return nil, err
+		}
+
+		password, err := getSecretValue(ctx, cc, report.Namespace, &external.BasicAuth.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		clientConfig.UserAuth = &UserAuth{Username: username, Password: password}
+	default:
+		content, err := ioutil.ReadFile(serviceAccountTokenFile)
+		if err != nil {
+			return nil, err
+		}
+
+		clientConfig.Token = string(content)
+	}
+
+	return NewSecureClient(clientConfig)
+}
+
+func getSecretValue(
+	ctx context.Context,
+	cc ClientCommandRunner,
+	namespace string,
+	selector *corev1.SecretKeySelector,
+) (string, error) {
+	secret := &corev1.Secret{}
+
+	if result, _ := cc.Do(ctx, GetAction(
+		types.NamespacedName{Name: selector.Name, Namespace: namespace}, secret,
+	)); !result.Is(Continue) {
+		return "", errors.Wrap(result, "failed to get prometheus auth secret")
+	}
+
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", errors.Errorf("prometheus auth secret %s is missing key %s", selector.Name, selector.Key)
+	}
+
+	return string(value), nil
+}
+
 func getClientOptions() managers.ClientOptions {
 	return managers.ClientOptions{
 		Namespace:    "",
@@ -238,6 +421,11 @@ func getPrometheusService(
 ) (service *corev1.Service, returnErr error) {
 	service = &corev1.Service{}
 
+	if report.Spec.ExternalPrometheus != nil {
+		logger.Info("report uses an external prometheus")
+		return
+	}
+
 	if report.Spec.PrometheusService == nil {
 		returnErr = errors.New("cannot retrieve service as the report doesn't have a value for it")
 		return
@@ -32,9 +32,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/gotidy/ptr"
 	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/version"
-	"golang.org/x/net/http2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/jsonpath"
@@ -98,29 +98,22 @@ var _ Uploader = &RedHatInsightsUploader{}
 func NewRedHatInsightsUploader(
 	config *RedHatInsightsUploaderConfig,
 ) (Uploader, error) {
-	tlsConfig, err := generateCACertPool(config.AdditionalCertFiles...)
-
-	if err != nil {
-		return nil, err
-	}
-
-	client := &http.Client{}
-
 	// default to 2 unless otherwise overridden
 	if config.httpVersion == nil {
 		config.httpVersion = ptr.Int(2)
 	}
 
-	// Use the proper transport in the client
-	switch *config.httpVersion {
-	case 1:
-		client.Transport = &http.Transport{
-			TLSClientConfig: tlsConfig,
-		}
-	case 2:
-		client.Transport = &http2.Transport{
-			TLSClientConfig: tlsConfig,
-		}
+	httpTransport, err := transport.NewTransport(&transport.Config{
+		CAFiles:      config.AdditionalCertFiles,
+		DisableHTTP2: *config.httpVersion == 1,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Transport: httpTransport,
 	}
 
 	return &RedHatInsightsUploader{
@@ -140,6 +133,9 @@ const userAgentFmt = "marketplace-operator/%s cluster/%s"
 
 const uploadURL = "%s/api/ingress/v1/upload"
 
+// DefaultUploadURL is the production ingress the reports are uploaded to.
+const DefaultUploadURL = "https://cloud.redhat.com"
+
 func getUserAgent(version, clusterID string) string {
 	return fmt.Sprintf(userAgentFmt, version, clusterID)
 }
@@ -284,6 +280,14 @@ func ProvideUploader(
 			return nil, err
 		}
 
+		// the target overrides the production url, such as the url of the
+		// fake marketplace in tests
+		target := uploaderTarget.(*RedHatInsightsUploader)
+		if target.URL != "" {
+			config.URL = target.URL
+		}
+		config.AdditionalCertFiles = append(config.AdditionalCertFiles, target.AdditionalCertFiles...)
+
 		return NewRedHatInsightsUploader(config)
 	case *NoOpUploader:
 		return uploaderTarget.(Uploader), nil
@@ -344,7 +348,7 @@ func provideProductionInsightsConfig(
 	cloudToken := buf.String()
 
 	return &RedHatInsightsUploaderConfig{
-		URL:             "https://cloud.redhat.com",
+		URL:             DefaultUploadURL,
 		ClusterID:       string(clusterVersion.Spec.ClusterID), // get from cluster
 		OperatorVersion: version.Version,
 		Token:           cloudToken, // get from secret
@@ -0,0 +1,101 @@
+// Copyright 2020 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+)
+
+// maxUsageTotals is the most usage totals per resource written to the report
+// status. Past it the totals are per namespace, to keep the status small.
+const maxUsageTotals = 1000
+
+type usageKey struct {
+	meterGroup   string
+	meterKind    string
+	metric       string
+	namespace    string
+	resourceName string
+}
+
+// SummarizeUsage sums the values of each metric of the report per resource.
+// The metrics are the ones written to the report, so the totals match the
+// usage uploaded for billing. It returns true when there are too many totals
+// and they are summed per namespace instead.
+func SummarizeUsage(metrics map[MetricKey]*MetricBase) ([]common.UsageTotal, bool) {
+	bases := make([]*MetricBase, 0, len(metrics))
+	for _, base := range metrics {
+		bases = append(bases, base)
+	}
+
+	// sum in a stable order so the totals don't change between runs
+	sort.Slice(bases, func(i, j int) bool {
+		return bases[i].Key.MetricID < bases[j].Key.MetricID
+	})
+
+	totals := map[usageKey]float64{}
+
+	for _, base := range bases {
+		for name, value := range base.Metrics {
+			f, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+			if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+				continue
+			}
+
+			totals[usageKey{
+				meterGroup:   base.Key.MeterDomain,
+				meterKind:    base.Key.MeterKind,
+				metric:       name,
+				namespace:    base.Key.Namespace,
+				resourceName: base.Key.ResourceName,
+			}] += f
+		}
+	}
+
+	truncated := len(totals) > maxUsageTotals
+	if truncated {
+		logger.Info("summing usage per namespace past the limit", "totals", len(totals), "limit", maxUsageTotals)
+
+		namespaces := map[usageKey]float64{}
+		for key, value := range totals {
+			key.resourceName = ""
+			namespaces[key] += value
+		}
+		totals = namespaces
+	}
+
+	usage := make([]common.UsageTotal, 0, len(totals))
+	for key, value := range totals {
+		usage = append(usage, common.UsageTotal{
+			MeterGroup:   key.meterGroup,
+			MeterKind:    key.meterKind,
+			Metric:       key.metric,
+			Namespace:    key.namespace,
+			ResourceName: key.resourceName,
+			Value:        strconv.FormatFloat(value, 'f', -1, 64),
+		})
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Less(&usage[j])
+	})
+
+	return usage, truncated
+}
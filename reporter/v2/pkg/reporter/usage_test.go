@@ -0,0 +1,103 @@
+// Copyright 2020 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+)
+
+var _ = Describe("Usage", func() {
+	base := func(interval, namespace, resourceName string, keysAndValues ...interface{}) (MetricKey, *MetricBase) {
+		key := MetricKey{
+			IntervalStart: interval,
+			MeterDomain:   "apps.partner.metering.com",
+			MeterKind:     "App",
+			Namespace:     namespace,
+			ResourceName:  resourceName,
+		}
+		key.Init("cluster")
+
+		base := &MetricBase{Key: key}
+		Expect(base.AddMetrics(keysAndValues...)).To(Succeed())
+		return key, base
+	}
+
+	total := func(metric, namespace, resourceName, value string) common.UsageTotal {
+		return common.UsageTotal{
+			MeterGroup:   "apps.partner.metering.com",
+			MeterKind:    "App",
+			Metric:       metric,
+			Namespace:    namespace,
+			ResourceName: resourceName,
+			Value:        value,
+		}
+	}
+
+	It("should sum the metrics of each resource", func() {
+		metrics := map[MetricKey]*MetricBase{}
+		for _, m := range []struct {
+			interval, namespace, resourceName string
+			keysAndValues                     []interface{}
+		}{
+			{"1", "foo", "pod-a", []interface{}{"rpc_count", "1.5", "rpc_sum", "10"}},
+			{"2", "foo", "pod-a", []interface{}{"rpc_count", "2"}},
+			{"1", "foo", "pod-b", []interface{}{"rpc_count", "3"}},
+			{"1", "bar", "pod-c", []interface{}{"rpc_count", "NaN"}},
+		} {
+			key, b := base(m.interval, m.namespace, m.resourceName, m.keysAndValues...)
+			metrics[key] = b
+		}
+
+		usage, truncated := SummarizeUsage(metrics)
+		Expect(truncated).To(BeFalse())
+		Expect(usage).To(Equal([]common.UsageTotal{
+			total("rpc_count", "foo", "pod-a", "3.5"),
+			total("rpc_count", "foo", "pod-b", "3"),
+			total("rpc_sum", "foo", "pod-a", "10"),
+		}))
+	})
+
+	It("should sum per namespace past the maximum totals", func() {
+		metrics := map[MetricKey]*MetricBase{}
+		for i := 0; i <= maxUsageTotals; i++ {
+			key, b := base("1", fmt.Sprintf("ns-%d", i%2), fmt.Sprintf("pod-%d", i), "rpc_count", "1")
+			metrics[key] = b
+		}
+
+		usage, truncated := SummarizeUsage(metrics)
+		Expect(truncated).To(BeTrue())
+		Expect(usage).To(Equal([]common.UsageTotal{
+			total("rpc_count", "ns-0", "", fmt.Sprint(maxUsageTotals/2+1)),
+			total("rpc_count", "ns-1", "", fmt.Sprint(maxUsageTotals/2)),
+		}))
+	})
+
+	It("should keep every namespace total past the maximum", func() {
+		metrics := map[MetricKey]*MetricBase{}
+		for i := 0; i <= maxUsageTotals; i++ {
+			key, b := base("1", fmt.Sprintf("ns-%d", i), "pod", "rpc_count", "1")
+			metrics[key] = b
+		}
+
+		usage, truncated := SummarizeUsage(metrics)
+		Expect(truncated).To(BeTrue())
+		Expect(usage).To(HaveLen(maxUsageTotals + 1))
+		Expect(usage[0]).To(Equal(total("rpc_count", "ns-0", "", "1")))
+	})
+})
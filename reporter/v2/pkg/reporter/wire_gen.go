@@ -71,7 +71,7 @@ func NewReporter(task *Task) (*MarketplaceReporter, error) {
 	if err != nil {
 		return nil, err
 	}
-	client, err := provideApiClient(meterReport, service, reporterConfig)
+	client, err := provideApiClient(contextContext, clientCommandRunner, meterReport, service, reporterConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,38 @@
+#!/usr/bin/env bash
+# Builds the fake marketplace, loads it into the kind cluster and deploys it.
+# Run from the root of the repository.
+set -o pipefail
+
+if [ "${DOCKER_EXEC}" == "" ]; then
+	DOCKER_EXEC=$(command -v docker)
+fi
+
+sh -c "${DOCKER_EXEC} buildx" 1>/dev/null 2>/dev/null
+if [ $? -eq 0 ]; then
+	DOCKER="${DOCKER_EXEC} buildx"
+	ARGS="--load"
+else
+	DOCKER="${DOCKER_EXEC}"
+	ARGS="--load"
+fi
+
+NAMESPACE=${NAMESPACE:-openshift-redhat-marketplace}
+ACCOUNT_ID=${ACCOUNT_ID:-e2e-account}
+TAG=$(date +%s)
+
+kubectl delete -n ${NAMESPACE} --ignore-not-found -f ./tests/v2/mockmarketplace/mockmarketplace-deployment.yaml
+export DOCKER_BUILDKIT=1
+
+${DOCKER} build --tag mockmarketplace:${TAG} $ARGS -f ./v2/Dockerfile \
+	--build-arg path=./tests/v2/ \
+	--build-arg name="Mock Marketplace" \
+	--build-arg exec=./mockmarketplace \
+	--build-arg bin=mockmarketplace \
+	.
+
+kind load docker-image mockmarketplace:${TAG} --name test
+
+cat ./tests/v2/mockmarketplace/mockmarketplace-deployment.yaml |
+	sed "s/\${TAG}/${TAG}/" |
+	sed "s/\${ACCOUNT_ID}/${ACCOUNT_ID}/" |
+	kubectl apply -n ${NAMESPACE} -f -
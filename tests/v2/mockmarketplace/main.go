@@ -0,0 +1,94 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace/fake"
+	"github.com/spf13/pflag"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// This is a fake marketplace for kind and kuttl tests. Deploy it with
+// deploy.sh and point the operator at it with MARKETPLACE_URL and
+// MARKETPLACE_HTTP_INSECURE_MODE.
+
+var log = logf.Log.WithName("mockmarketplace")
+
+func main() {
+	addr := pflag.String("addr", ":8443", "address to listen on")
+	serverCertFile := pflag.String("server-cert", "", "Server cert")
+	serverKeyFile := pflag.String("server-key", "", "Server secret")
+	token := pflag.String("token", "", "bearer token required on requests, any token is accepted if empty")
+	registrations := pflag.StringSlice("register", []string{}, "installed clusters as accountId:clusterUUID")
+	installAccounts := pflag.StringSlice("install-accounts", []string{}, "accounts whose clusters are installed as soon as they are looked up")
+	pflag.Parse()
+
+	logf.SetLogger(zap.New())
+
+	server := fake.NewServer()
+	server.Token = *token
+
+	for _, registration := range *registrations {
+		parts := strings.SplitN(registration, ":", 2)
+		if len(parts) != 2 {
+			log.Info("skipping registration, expected accountId:clusterUUID", "registration", registration)
+			continue
+		}
+
+		server.SetRegistration(parts[0], parts[1], marketplace.RegistrationStatusInstalled)
+	}
+
+	var handler http.Handler = server
+	if len(*installAccounts) != 0 {
+		handler = installOnLookup(server, *installAccounts)
+	}
+
+	log.Info("starting", "addr", *addr)
+
+	var err error
+	if *serverCertFile != "" {
+		err = http.ListenAndServeTLS(*addr, *serverCertFile, *serverKeyFile, handler)
+	} else {
+		err = http.ListenAndServe(*addr, handler)
+	}
+
+	log.Error(err, "exiting")
+}
+
+// installOnLookup registers the clusters of the accounts when their
+// registration is looked up, so e2e tests don't need to know the cluster
+// UUID up front.
+func installOnLookup(server *fake.Server, accounts []string) http.Handler {
+	installed := map[string]bool{}
+	for _, account := range accounts {
+		installed[account] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.URL.Query().Get("accountId")
+		clusterUUID := r.URL.Query().Get("uuid")
+
+		if r.URL.Path == "/"+marketplace.RegistrationEndpoint && installed[accountID] && clusterUUID != "" {
+			server.SetRegistration(accountID, clusterUUID, marketplace.RegistrationStatusInstalled)
+		}
+
+		server.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ClusterInventorySummary describes the cluster a report was produced on.
+// +kubebuilder:object:generate:=true
+type ClusterInventorySummary struct {
+	// NodeCount is the number of nodes in the cluster.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	NodeCount int `json:"nodeCount"`
+
+	// NodeRoles is the capacity of the nodes grouped by role.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	NodeRoles []NodeRoleCapacity `json:"nodeRoles,omitempty"`
+
+	// Architectures is the number of nodes of each architecture.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Architectures []ArchitectureCount `json:"architectures,omitempty"`
+
+	// KubernetesVersion is the version of Kubernetes.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// KubernetesPlatform is the platform of Kubernetes.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	KubernetesPlatform string `json:"kubernetesPlatform,omitempty"`
+
+	// OpenshiftVersion is the version of OpenShift, empty on other distributions.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	OpenshiftVersion string `json:"openshiftVersion,omitempty"`
+
+	// Operators are the marketplace operators installed on the cluster.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Operators []InstalledOperator `json:"operators,omitempty"`
+}
+
+// NodeRoleCapacity is the capacity of the nodes of a role.
+// +kubebuilder:object:generate:=true
+type NodeRoleCapacity struct {
+	// Role is the node role, one of master, infra or worker.
+	Role string `json:"role"`
+
+	// NodeCount is the number of nodes of the role.
+	NodeCount int `json:"nodeCount"`
+
+	// CPU is the vCPU capacity of the nodes of the role.
+	CPU resource.Quantity `json:"cpu"`
+
+	// Memory is the memory capacity of the nodes of the role.
+	Memory resource.Quantity `json:"memory"`
+}
+
+// ArchitectureCount is the number of nodes of an architecture.
+type ArchitectureCount struct {
+	// Architecture is the architecture reported by the node.
+	Architecture string `json:"architecture"`
+
+	// NodeCount is the number of nodes of the architecture.
+	NodeCount int `json:"nodeCount"`
+}
+
+// InstalledOperator is a marketplace operator installed with a subscription.
+type InstalledOperator struct {
+	// Package is the package of the subscription.
+	Package string `json:"package"`
+
+	// Namespace is the namespace of the subscription.
+	Namespace string `json:"namespace"`
+
+	// CSV is the name of the installed ClusterServiceVersion.
+	// +optional
+	CSV string `json:"csv,omitempty"`
+
+	// Version is the version of the installed ClusterServiceVersion.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
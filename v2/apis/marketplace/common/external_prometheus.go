@@ -0,0 +1,51 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ExternalPrometheus is an existing Prometheus or Thanos Querier API used
+// for metering instead of the Prometheus installed by the operator.
+// Secrets and configmaps are read from the namespace of the resource.
+// +kubebuilder:object:generate:=true
+type ExternalPrometheus struct {
+	// URL of the Prometheus API. For OpenShift user workload monitoring this is
+	// https://thanos-querier.openshift-monitoring.svc:9091
+	URL string `json:"url"`
+
+	// CA is the configmap key holding the CA bundle used to verify the endpoint.
+	// The operator service CA is always trusted.
+	// +optional
+	CA *corev1.ConfigMapKeySelector `json:"ca,omitempty"`
+
+	// InsecureSkipVerify disables verification of the endpoint certificate.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// BearerTokenSecret is the secret key holding the token sent to the endpoint.
+	// If neither BearerTokenSecret nor BasicAuth is set, the service account token
+	// of the meter report job is used. The job runs as the
+	// redhat-marketplace-operator service account, which must be allowed to query
+	// the endpoint, for example with the cluster-monitoring-view role.
+	// +optional
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// BasicAuth is the username and password sent to the endpoint.
+	// +optional
+	BasicAuth *monitoringv1.BasicAuth `json:"basicAuth,omitempty"`
+}
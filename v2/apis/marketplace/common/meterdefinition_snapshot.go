@@ -0,0 +1,121 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MeterDefinitionSnapshot records the meter definitions that were in effect
+// when a report was submitted.
+// +kubebuilder:object:generate:=true
+type MeterDefinitionSnapshot struct {
+	// ConfigMapName is the name of the immutable configmap, in the report namespace,
+	// that holds a copy of the meter definition specs.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	ConfigMapName string `json:"configMapName"`
+
+	// ConfigMapShards are the names of the configmaps that hold the rest of a
+	// snapshot too large for a single configmap.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	ConfigMapShards []string `json:"configMapShards,omitempty"`
+
+	// CreatedAt is the time the snapshot was taken.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	CreatedAt metav1.Time `json:"createdAt"`
+
+	// MeterDefinitions are the references to the meter definitions in the snapshot.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	MeterDefinitions []MeterDefinitionReference `json:"meterDefinitions,omitempty"`
+}
+
+// MeterDefinitionReference identifies a specific revision of a meter definition.
+// +kubebuilder:object:generate:=true
+type MeterDefinitionReference struct {
+	// Namespace of the meter definition
+	Namespace string `json:"namespace"`
+
+	// Name of the meter definition
+	Name string `json:"name"`
+
+	// UID of the meter definition
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+
+	// ResourceVersion of the meter definition when the snapshot was taken
+	// +optional
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// Generation of the meter definition when the snapshot was taken
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// SpecHash is the sha256 of the meter definition spec
+	SpecHash string `json:"specHash"`
+}
+
+// Key returns the key used to store the meter definition in the snapshot configmap.
+func (m MeterDefinitionReference) Key() string {
+	return m.Namespace + "." + m.Name + ".json"
+}
+
+// SpecHashes returns a map of namespace/name to the spec hash of every meter definition
+// in the snapshot.
+func (s *MeterDefinitionSnapshot) SpecHashes() map[string]string {
+	if s == nil {
+		return nil
+	}
+
+	hashes := make(map[string]string, len(s.MeterDefinitions))
+	for _, ref := range s.MeterDefinitions {
+		hashes[ref.Namespace+"/"+ref.Name] = ref.SpecHash
+	}
+
+	return hashes
+}
+
+// Filter returns a copy of the snapshot with only the meter definitions that
+// keep returns true for.
+func (s *MeterDefinitionSnapshot) Filter(keep func(MeterDefinitionReference) bool) *MeterDefinitionSnapshot {
+	if s == nil {
+		return nil
+	}
+
+	filtered := s.DeepCopy()
+	filtered.MeterDefinitions = []MeterDefinitionReference{}
+
+	for _, ref := range s.MeterDefinitions {
+		if keep(ref) {
+			filtered.MeterDefinitions = append(filtered.MeterDefinitions, ref)
+		}
+	}
+
+	return filtered
+}
+
+// SortMeterDefinitionReferences sorts references by namespace and name.
+func SortMeterDefinitionReferences(refs []MeterDefinitionReference) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MeterDefinitionSnapshot", func() {
+	It("should return nil hashes for a nil snapshot", func() {
+		var snapshot *MeterDefinitionSnapshot
+		Expect(snapshot.SpecHashes()).To(BeNil())
+	})
+
+	It("should return hashes by namespace and name", func() {
+		snapshot := &MeterDefinitionSnapshot{
+			MeterDefinitions: []MeterDefinitionReference{
+				{Namespace: "b", Name: "foo", SpecHash: "2"},
+				{Namespace: "a", Name: "foo", SpecHash: "1"},
+			},
+		}
+
+		Expect(snapshot.SpecHashes()).To(Equal(map[string]string{
+			"a/foo": "1",
+			"b/foo": "2",
+		}))
+
+		SortMeterDefinitionReferences(snapshot.MeterDefinitions)
+		Expect(snapshot.MeterDefinitions[0].Namespace).To(Equal("a"))
+		Expect(snapshot.MeterDefinitions[0].Key()).To(Equal("a.foo.json"))
+	})
+
+	It("should filter the meter definitions", func() {
+		var snapshot *MeterDefinitionSnapshot
+		Expect(snapshot.Filter(func(MeterDefinitionReference) bool { return true })).To(BeNil())
+
+		snapshot = &MeterDefinitionSnapshot{
+			ConfigMapName: "snapshot",
+			MeterDefinitions: []MeterDefinitionReference{
+				{Namespace: "a", Name: "foo", SpecHash: "1"},
+				{Namespace: "b", Name: "foo", SpecHash: "2"},
+			},
+		}
+
+		filtered := snapshot.Filter(func(ref MeterDefinitionReference) bool { return ref.Namespace == "b" })
+		Expect(filtered.ConfigMapName).To(Equal("snapshot"))
+		Expect(filtered.SpecHashes()).To(Equal(map[string]string{"b/foo": "2"}))
+		Expect(snapshot.MeterDefinitions).To(HaveLen(2))
+	})
+})
@@ -70,6 +70,13 @@ const (
 	MeterDefConditionTypeHasResult           status.ConditionType   = "FoundMatches"
 	MeterDefConditionReasonNoResultsInStatus status.ConditionReason = "No results in status"
 	MeterDefConditionReasonResultsInStatus   status.ConditionReason = "Results in status"
+
+	// MeterDefConditionTypeSeriesBudgetWarning is true when the metering Prometheus
+	// is close to its series budget and the meter definition reads series from it.
+	MeterDefConditionTypeSeriesBudgetWarning  status.ConditionType   = "SeriesBudgetWarning"
+	MeterDefConditionReasonWithinSeriesBudget status.ConditionReason = "WithinSeriesBudget"
+	MeterDefConditionReasonNearSeriesBudget   status.ConditionReason = "NearSeriesBudget"
+	MeterDefConditionReasonOverSeriesBudget   status.ConditionReason = "OverSeriesBudget"
 )
 
 var (
@@ -0,0 +1,63 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+// UsageTotal is the sum of the values of a metric reported for a meter over
+// a report period. The namespace and resource name are empty when the total
+// covers more than one.
+// +kubebuilder:object:generate:=true
+type UsageTotal struct {
+	// MeterGroup is the group of the meter definition.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	MeterGroup string `json:"meterGroup"`
+
+	// MeterKind is the kind of the meter definition.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	MeterKind string `json:"meterKind"`
+
+	// Metric is the metric id of the meter.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Metric string `json:"metric"`
+
+	// Namespace of the metered resources.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ResourceName is the name of the metered resource.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// Value is the decimal sum of the reported values.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	Value string `json:"value"`
+}
+
+// Less orders the totals by meter group, kind, metric, namespace and resource.
+func (u *UsageTotal) Less(o *UsageTotal) bool {
+	switch {
+	case u.MeterGroup != o.MeterGroup:
+		return u.MeterGroup < o.MeterGroup
+	case u.MeterKind != o.MeterKind:
+		return u.MeterKind < o.MeterKind
+	case u.Metric != o.Metric:
+		return u.Metric < o.Metric
+	case u.Namespace != o.Namespace:
+		return u.Namespace < o.Namespace
+	default:
+		return u.ResourceName < o.ResourceName
+	}
+}
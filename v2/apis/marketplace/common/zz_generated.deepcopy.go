@@ -21,9 +21,72 @@ limitations under the License.
 package common
 
 import (
-	"github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/api/core/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInventorySummary) DeepCopyInto(out *ClusterInventorySummary) {
+	*out = *in
+	if in.NodeRoles != nil {
+		in, out := &in.NodeRoles, &out.NodeRoles
+		*out = make([]NodeRoleCapacity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]ArchitectureCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]InstalledOperator, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInventorySummary.
+func (in *ClusterInventorySummary) DeepCopy() *ClusterInventorySummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInventorySummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalPrometheus) DeepCopyInto(out *ExternalPrometheus) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerTokenSecret != nil {
+		in, out := &in.BearerTokenSecret, &out.BearerTokenSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(monitoringv1.BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalPrometheus.
+func (in *ExternalPrometheus) DeepCopy() *ExternalPrometheus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalPrometheus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Features) DeepCopyInto(out *Features) {
 	*out = *in
@@ -107,6 +170,47 @@ func (in *JobReference) DeepCopy() *JobReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeterDefinitionReference) DeepCopyInto(out *MeterDefinitionReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeterDefinitionReference.
+func (in *MeterDefinitionReference) DeepCopy() *MeterDefinitionReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MeterDefinitionReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeterDefinitionSnapshot) DeepCopyInto(out *MeterDefinitionSnapshot) {
+	*out = *in
+	if in.ConfigMapShards != nil {
+		in, out := &in.ConfigMapShards, &out.ConfigMapShards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	if in.MeterDefinitions != nil {
+		in, out := &in.MeterDefinitions, &out.MeterDefinitions
+		*out = make([]MeterDefinitionReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeterDefinitionSnapshot.
+func (in *MeterDefinitionSnapshot) DeepCopy() *MeterDefinitionSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(MeterDefinitionSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespacedNameReference) DeepCopyInto(out *NamespacedNameReference) {
 	*out = *in
@@ -127,19 +231,36 @@ func (in *NamespacedNameReference) DeepCopy() *NamespacedNameReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRoleCapacity) DeepCopyInto(out *NodeRoleCapacity) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRoleCapacity.
+func (in *NodeRoleCapacity) DeepCopy() *NodeRoleCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRoleCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceReference) DeepCopyInto(out *ServiceReference) {
 	*out = *in
 	out.TargetPort = in.TargetPort
 	if in.TLSConfig != nil {
 		in, out := &in.TLSConfig, &out.TLSConfig
-		*out = new(v1.TLSConfig)
+		*out = new(monitoringv1.TLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
 	in.BearerTokenSecret.DeepCopyInto(&out.BearerTokenSecret)
 	if in.BasicAuth != nil {
 		in, out := &in.BasicAuth, &out.BasicAuth
-		*out = new(v1.TLSConfig)
+		*out = new(monitoringv1.TLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -154,6 +275,21 @@ func (in *ServiceReference) DeepCopy() *ServiceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageTotal) DeepCopyInto(out *UsageTotal) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageTotal.
+func (in *UsageTotal) DeepCopy() *UsageTotal {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageTotal)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadResource) DeepCopyInto(out *WorkloadResource) {
 	*out = *in
@@ -0,0 +1,113 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conversion helps to write lossless conversions between the served
+// versions of the marketplace APIs.
+//
+// A kind with more than one version picks its storage version as the hub,
+// which implements conversion.Hub, and every other version implements
+// conversion.Convertible. A conversion that can't represent a field of the
+// source in the destination stores the source in the DataAnnotation of the
+// destination with MarshalData. The conversion back reads it with
+// UnmarshalData and restores the fields the destination dropped, then clears
+// the annotation with RemoveData. The round trips of a new version are tested
+// with SpokeHubSpoke and HubSpokeHub of the fuzzer package over fuzzed objects.
+//
+// To serve a new version of a kind:
+//
+//  1. add the version package with the types and mark the hub,
+//  2. implement ConvertTo and ConvertFrom on the spoke with this package,
+//  3. enable the conversion webhook patch of the CRD in config/crd,
+//  4. add the round trip tests for the kind.
+//
+// The operator injects the webhook CA into every marketplace CRD with a
+// conversion webhook, so no other change is needed to serve it.
+package conversion
+
+import (
+	"encoding/json"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DataAnnotation holds the source of a conversion that the destination version
+// can't fully represent.
+const DataAnnotation = "marketplace.redhat.com/conversion-data"
+
+// MarshalData stores the spec and status of src in the annotation of dst.
+// The metadata of src is not stored, it is converted as is.
+func MarshalData(src runtime.Object, dst metav1.Object) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(src)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert the conversion data")
+	}
+
+	delete(u, "apiVersion")
+	delete(u, "kind")
+	delete(u, "metadata")
+
+	data, err := json.Marshal(u)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the conversion data")
+	}
+
+	annotations := copyAnnotations(dst.GetAnnotations())
+	annotations[DataAnnotation] = string(data)
+	dst.SetAnnotations(annotations)
+
+	return nil
+}
+
+// UnmarshalData reads the data stored in the annotation of from into to. It
+// returns false if the annotation is not set.
+func UnmarshalData(from metav1.Object, to interface{}) (bool, error) {
+	data, ok := from.GetAnnotations()[DataAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), to); err != nil {
+		return false, errors.WrapWithDetails(err, "failed to unmarshal the conversion data",
+			"name", from.GetName(), "namespace", from.GetNamespace())
+	}
+
+	return true, nil
+}
+
+// RemoveData removes the annotation from obj. The annotations are copied
+// first, conversions share the metadata of the source.
+func RemoveData(obj metav1.Object) {
+	if _, ok := obj.GetAnnotations()[DataAnnotation]; !ok {
+		return
+	}
+
+	annotations := copyAnnotations(obj.GetAnnotations())
+	delete(annotations, DataAnnotation)
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	obj.SetAnnotations(annotations)
+}
+
+func copyAnnotations(annotations map[string]string) map[string]string {
+	cpy := make(map[string]string, len(annotations)+1)
+	for key, value := range annotations {
+		cpy[key] = value
+	}
+	return cpy
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conversion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Conversion data", func() {
+	var (
+		src *corev1.ConfigMap
+		dst *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		src = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Data: map[string]string{"key": "value"},
+		}
+
+		dst = &corev1.Secret{
+			ObjectMeta: src.ObjectMeta,
+		}
+	})
+
+	It("should store and read the data of the source", func() {
+		Expect(MarshalData(src, dst)).To(Succeed())
+		Expect(dst.Annotations).To(HaveKey(DataAnnotation))
+		Expect(dst.Annotations[DataAnnotation]).To(MatchJSON(`{"data":{"key":"value"}}`))
+
+		restored := &corev1.ConfigMap{}
+		ok, err := UnmarshalData(dst, restored)
+		Expect(err).To(Succeed())
+		Expect(ok).To(BeTrue())
+		Expect(restored.Data).To(Equal(src.Data))
+		Expect(restored.Name).To(BeEmpty())
+	})
+
+	It("should not change the annotations of the source", func() {
+		Expect(MarshalData(src, dst)).To(Succeed())
+		Expect(src.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+
+		cpy := dst.DeepCopy()
+		RemoveData(dst)
+		Expect(dst.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		Expect(cpy.Annotations).To(HaveKey(DataAnnotation))
+	})
+
+	It("should clear empty annotations", func() {
+		dst.Annotations = nil
+		Expect(MarshalData(src, dst)).To(Succeed())
+
+		RemoveData(dst)
+		Expect(dst.Annotations).To(BeNil())
+	})
+
+	It("should report missing data", func() {
+		ok, err := UnmarshalData(dst, &corev1.ConfigMap{})
+		Expect(err).To(Succeed())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should fail on invalid data", func() {
+		dst.Annotations = map[string]string{DataAnnotation: "{"}
+
+		_, err := UnmarshalData(dst, &corev1.ConfigMap{})
+		Expect(err).To(HaveOccurred())
+	})
+})
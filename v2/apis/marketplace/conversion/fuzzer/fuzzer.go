@@ -0,0 +1,146 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fuzzer round trips fuzzed objects through the conversions of the
+// marketplace APIs. It is only imported by tests, so the fuzzer is not linked
+// into the operator.
+package fuzzer
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	fuzz "github.com/google/gofuzz"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// RoundTripInput are the versions of a kind to round trip.
+type RoundTripInput struct {
+	// Hub is an empty object of the hub version.
+	Hub conversion.Hub
+	// Spoke is an empty object of the spoke version.
+	Spoke conversion.Convertible
+	// FuzzerFuncs are custom fuzz functions to generate objects the
+	// conversions accept, like valid enum values.
+	FuzzerFuncs []interface{}
+	// Iterations is the number of objects to round trip, 100 by default.
+	Iterations int
+	// Seed of the fuzzer, to reproduce a failure.
+	Seed int64
+}
+
+// NewFuzzer returns a fuzzer for API objects. The type meta is left empty,
+// the conversions don't set it, and durations are whole seconds so they
+// survive the conversion data.
+func NewFuzzer(seed int64, funcs ...interface{}) *fuzz.Fuzzer {
+	funcs = append([]interface{}{
+		func(t *metav1.TypeMeta, c fuzz.Continue) {
+			*t = metav1.TypeMeta{}
+		},
+		func(m *metav1.ObjectMeta, c fuzz.Continue) {
+			c.FuzzNoCustom(m)
+			m.ManagedFields = nil
+			m.Annotations = nil
+			c.Fuzz(&m.Annotations)
+		},
+		func(d *metav1.Duration, c fuzz.Continue) {
+			d.Duration = time.Duration(c.Int63n(24*60*60)) * time.Second
+		},
+	}, funcs...)
+
+	return fuzz.NewWithSeed(seed).
+		NilChance(0.2).
+		NumElements(0, 3).
+		Funcs(funcs...)
+}
+
+// SpokeHubSpoke converts fuzzed objects of the spoke to the hub and back and
+// returns an error if they differ.
+func SpokeHubSpoke(input RoundTripInput) error {
+	fuzzer := NewFuzzer(input.Seed, input.FuzzerFuncs...)
+
+	for i := 0; i < iterations(input); i++ {
+		spoke := input.Spoke.DeepCopyObject().(conversion.Convertible)
+		fuzzer.Fuzz(spoke)
+
+		before := spoke.DeepCopyObject()
+
+		hub := input.Hub.DeepCopyObject().(conversion.Hub)
+		if err := spoke.ConvertTo(hub); err != nil {
+			return errors.WrapWithDetails(err, "failed to convert to the hub", "iteration", i)
+		}
+
+		if !apiequality.Semantic.DeepEqual(before, spoke) {
+			return errors.NewWithDetails("conversion to the hub changed the spoke",
+				"iteration", i, "diff", diff.ObjectReflectDiff(before, spoke))
+		}
+
+		result := input.Spoke.DeepCopyObject().(conversion.Convertible)
+		if err := result.ConvertFrom(hub); err != nil {
+			return errors.WrapWithDetails(err, "failed to convert from the hub", "iteration", i)
+		}
+
+		if !apiequality.Semantic.DeepEqual(before, result) {
+			return errors.NewWithDetails("spoke changed on a round trip",
+				"iteration", i, "diff", diff.ObjectReflectDiff(before, result))
+		}
+	}
+
+	return nil
+}
+
+// HubSpokeHub converts fuzzed objects of the hub to the spoke and back and
+// returns an error if they differ.
+func HubSpokeHub(input RoundTripInput) error {
+	fuzzer := NewFuzzer(input.Seed, input.FuzzerFuncs...)
+
+	for i := 0; i < iterations(input); i++ {
+		hub := input.Hub.DeepCopyObject().(conversion.Hub)
+		fuzzer.Fuzz(hub)
+
+		before := hub.DeepCopyObject()
+
+		spoke := input.Spoke.DeepCopyObject().(conversion.Convertible)
+		if err := spoke.ConvertFrom(hub); err != nil {
+			return errors.WrapWithDetails(err, "failed to convert from the hub", "iteration", i)
+		}
+
+		if !apiequality.Semantic.DeepEqual(before, hub) {
+			return errors.NewWithDetails("conversion from the hub changed the hub",
+				"iteration", i, "diff", diff.ObjectReflectDiff(before, hub))
+		}
+
+		result := input.Hub.DeepCopyObject().(conversion.Hub)
+		if err := spoke.ConvertTo(result); err != nil {
+			return errors.WrapWithDetails(err, "failed to convert to the hub", "iteration", i)
+		}
+
+		if !apiequality.Semantic.DeepEqual(before, result) {
+			return errors.NewWithDetails("hub changed on a round trip",
+				"iteration", i, "diff", diff.ObjectReflectDiff(before, result))
+		}
+	}
+
+	return nil
+}
+
+func iterations(input RoundTripInput) int {
+	if input.Iterations == 0 {
+		return 100
+	}
+	return input.Iterations
+}
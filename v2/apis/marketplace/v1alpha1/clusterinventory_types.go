@@ -0,0 +1,71 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterInventorySpec defines the desired state of ClusterInventory
+// +k8s:openapi-gen=true
+type ClusterInventorySpec struct {
+}
+
+// ClusterInventoryStatus is the inventory of the cluster collected by the operator
+// +k8s:openapi-gen=true
+type ClusterInventoryStatus struct {
+	// Inventory is the latest inventory of the cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Inventory *common.ClusterInventorySummary `json:"inventory,omitempty"`
+
+	// LastUpdateTime is when the inventory last changed
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// ClusterInventory is the inventory of the nodes, versions and marketplace operators of the cluster
+// +kubebuilder:object:root=true
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=clusterinventories,scope=Namespaced
+// +kubebuilder:printcolumn:name="NODES",type=integer,JSONPath=`.status.inventory.nodeCount`
+// +kubebuilder:printcolumn:name="KUBERNETES",type=string,JSONPath=`.status.inventory.kubernetesVersion`
+// +kubebuilder:printcolumn:name="OPENSHIFT",type=string,JSONPath=`.status.inventory.openshiftVersion`
+// +kubebuilder:printcolumn:name="UPDATED",type=string,JSONPath=`.status.lastUpdateTime`
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Cluster Inventory"
+type ClusterInventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterInventorySpec   `json:"spec,omitempty"`
+	Status ClusterInventoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterInventoryList contains a list of ClusterInventory
+type ClusterInventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterInventory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterInventory{}, &ClusterInventoryList{})
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManagedClusterStatusSpec is the status summary pushed by a managed cluster
+// to the hub cluster.
+// +k8s:openapi-gen=true
+type ManagedClusterStatusSpec struct {
+	// ClusterUUID is the Red Hat Marketplace cluster identifier of the managed cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	ClusterUUID string `json:"clusterUUID"`
+
+	// ClusterName is the name of the managed cluster in the Red Hat Marketplace UI
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// RhmAccountID is the Red Hat Marketplace Account identifier of the managed cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	RhmAccountID string `json:"rhmAccountID,omitempty"`
+
+	// Registered is true when the managed cluster is registered with Red Hat Marketplace
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Registered bool `json:"registered"`
+
+	// LastReportName is the name of the last successful MeterReport
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	LastReportName string `json:"lastReportName,omitempty"`
+
+	// LastReportTime is the end time of the last successful MeterReport
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	LastReportTime *metav1.Time `json:"lastReportTime,omitempty"`
+
+	// MeterDefinitionCount is the number of MeterDefinitions on the managed cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	MeterDefinitionCount int `json:"meterDefinitionCount"`
+
+	// Errors are the registration and reporting errors of the managed cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+
+	// LastHeartbeatTime is when the managed cluster last pushed its summary
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+}
+
+// ManagedClusterStatusStatus is the state of the managed cluster observed by the hub
+// +k8s:openapi-gen=true
+type ManagedClusterStatusStatus struct {
+	// Conditions represent the latest available observations of the managed cluster
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	// +optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+}
+
+// ManagedClusterStatus is the status of a managed cluster on the hub cluster
+// +kubebuilder:object:root=true
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=managedclusterstatuses,scope=Namespaced
+// +kubebuilder:printcolumn:name="CLUSTER",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="REGISTERED",type=boolean,JSONPath=`.spec.registered`
+// +kubebuilder:printcolumn:name="LAST_REPORT",type=string,JSONPath=`.spec.lastReportTime`
+// +kubebuilder:printcolumn:name="METERDEFS",type=integer,JSONPath=`.spec.meterDefinitionCount`
+// +kubebuilder:printcolumn:name="REPORTING",type=string,JSONPath=`.status.conditions[?(@.type == "Reporting")].status`
+// +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.conditions[?(@.type == "Reporting")].reason`
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Managed Cluster Status"
+type ManagedClusterStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedClusterStatusSpec   `json:"spec,omitempty"`
+	Status ManagedClusterStatusStatus `json:"status,omitempty"`
+}
+
+const (
+	// ManagedClusterConditionReporting means the managed cluster is pushing its
+	// summary and producing reports.
+	ManagedClusterConditionReporting status.ConditionType = "Reporting"
+
+	// Reasons for the reporting condition
+	ManagedClusterReasonReporting       status.ConditionReason = "ReportingNormally"
+	ManagedClusterReasonHeartbeatMissed status.ConditionReason = "HeartbeatMissed"
+	ManagedClusterReasonReportOverdue   status.ConditionReason = "ReportOverdue"
+	ManagedClusterReasonNotRegistered   status.ConditionReason = "NotRegistered"
+)
+
+// +kubebuilder:object:root=true
+
+// ManagedClusterStatusList contains a list of ManagedClusterStatus
+type ManagedClusterStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagedClusterStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ManagedClusterStatus{}, &ManagedClusterStatusList{})
+}
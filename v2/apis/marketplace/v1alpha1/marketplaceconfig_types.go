@@ -17,6 +17,7 @@ package v1alpha1
 import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -62,6 +63,48 @@ type MarketplaceConfigSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.displayName="Disabled Features"
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="hidden"
 	Features *common.Features `json:"features,omitempty"`
+
+	// Disconnected runs registration and reporting without access to Red Hat Marketplace.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	Disconnected *DisconnectedSpec `json:"disconnected,omitempty"`
+
+	// Fleet pushes a status summary of this cluster to a hub cluster.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	Fleet *FleetSpec `json:"fleet,omitempty"`
+}
+
+// FleetSpec configures the hub cluster that collects the status of this cluster.
+type FleetSpec struct {
+	// HubKubeconfigSecret is the secret key that holds the kubeconfig of the hub cluster.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	HubKubeconfigSecret corev1.SecretKeySelector `json:"hubKubeconfigSecret"`
+
+	// HubNamespace is the namespace on the hub cluster the ManagedClusterStatus is written to.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	HubNamespace string `json:"hubNamespace"`
+}
+
+// DisconnectedSpec configures a cluster without access to Red Hat Marketplace.
+// The cluster is registered with an offline activation file and reports are kept
+// in a local archive until they are exported and transferred manually.
+type DisconnectedSpec struct {
+	// ActivationSecret is the secret key that holds the offline activation file
+	// downloaded from Red Hat Marketplace.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	ActivationSecret corev1.SecretKeySelector `json:"activationSecret"`
+
+	// MarketplacePublicKeySecret is the secret key that holds the PEM encoded public key
+	// of Red Hat Marketplace. The activation file and the report acknowledgements
+	// have to be signed with it.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	MarketplacePublicKeySecret corev1.SecretKeySelector `json:"marketplacePublicKeySecret"`
+
+	// ArchiveStorage is the storage for the report archive. Default size is 1Gi.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	ArchiveStorage *StorageSpec `json:"archiveStorage,omitempty"`
 }
 
 // MarketplaceConfigStatus defines the observed state of MarketplaceConfig
@@ -82,6 +125,11 @@ type MarketplaceConfigStatus struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	// +optional
 	MeterBaseSubConditions status.Conditions `json:"meterBaseSubConditions,omitempty"`
+
+	// PullSecretExpiration is the expiry time of the token in the redhat-marketplace-pull-secret.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	PullSecretExpiration *metav1.Time `json:"pullSecretExpiration,omitempty"`
 }
 
 // MarketplaceConfig is configuration manager for our Red Hat Marketplace controllers
@@ -118,6 +166,10 @@ const (
 	ConditionRegistered status.ConditionType = "Registered"
 	// ConditionRegistered means the cluster registered.
 	ConditionRegistrationError status.ConditionType = "RegistationError"
+	// ConditionPullSecretExpiring means the pull secret token expires soon or has expired.
+	ConditionPullSecretExpiring status.ConditionType = "PullSecretExpiring"
+	// ConditionFleetStatusError means the status of the cluster can't be pushed to the fleet hub.
+	ConditionFleetStatusError status.ConditionType = "FleetStatusError"
 
 	// Reasons for install
 	ReasonStartInstall          status.ConditionReason = "StartInstall"
@@ -135,6 +187,23 @@ const (
 	ReasonRegistrationError     status.ConditionReason = "HttpError"
 	ReasonOperatingNormally     status.ConditionReason = "OperatingNormally"
 	ReasonNoError               status.ConditionReason = ReasonOperatingNormally
+	ReasonAccountChanged        status.ConditionReason = "AccountChanged"
+
+	// Reasons for pull secret expiry
+	ReasonPullSecretValid        status.ConditionReason = "TokenValid"
+	ReasonPullSecretNoExpiration status.ConditionReason = "TokenNoExpiration"
+	ReasonPullSecretExpiringSoon status.ConditionReason = "TokenExpiringSoon"
+	ReasonPullSecretExpired      status.ConditionReason = "TokenExpired"
+
+	// Reasons for fleet status errors
+	ReasonFleetClusterUUIDMissing status.ConditionReason = "ClusterUUIDMissing"
+	ReasonFleetKubeconfigMissing  status.ConditionReason = "HubKubeconfigMissing"
+	ReasonFleetStatusPushed       status.ConditionReason = "StatusPushed"
+
+	// Reasons for marketplace config events
+	EventReasonRegistrationStateChanged = "RegistrationStateChanged"
+	EventReasonPullSecretExpiring       = "PullSecretExpiring"
+	EventReasonAccountChanged           = "AccountChanged"
 
 	// Enablement/Disablement of features conditions
 	// ConditionDeploymentEnabled means the particular option is enabled
@@ -16,6 +16,7 @@ package v1alpha1
 
 import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -44,6 +45,57 @@ type StorageSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:hidden"
 	// +optional
 	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+
+	// AutoGrow grows the Prometheus volumes when their usage crosses a threshold.
+	// Volumes are only grown when their storage class allows volume expansion.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	AutoGrow *StorageAutoGrowSpec `json:"autoGrow,omitempty"`
+}
+
+// StorageAutoGrowSpec configures the automatic growth of the Prometheus volumes.
+type StorageAutoGrowSpec struct {
+	// Disabled turns off the automatic growth of the volumes.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// UsageThreshold is the percent of the volume used before it is grown. Default is 80.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	UsageThreshold *int32 `json:"usageThreshold,omitempty"`
+
+	// GrowthPercent is the percent the volume is grown by. Default is 25.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	GrowthPercent *int32 `json:"growthPercent,omitempty"`
+
+	// MaxSize is the largest size the volume is grown to. Not limited by default.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=quantity
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+}
+
+const (
+	DefaultStorageUsageThreshold int32 = 80
+	DefaultStorageGrowthPercent  int32 = 25
+)
+
+// GetUsageThreshold returns the usage threshold or the default.
+func (s *StorageAutoGrowSpec) GetUsageThreshold() int32 {
+	if s == nil || s.UsageThreshold == nil {
+		return DefaultStorageUsageThreshold
+	}
+	return *s.UsageThreshold
+}
+
+// GetGrowthPercent returns the growth percent or the default.
+func (s *StorageAutoGrowSpec) GetGrowthPercent() int32 {
+	if s == nil || s.GrowthPercent == nil {
+		return DefaultStorageGrowthPercent
+	}
+	return *s.GrowthPercent
 }
 
 // PrometheusSpec contains configuration regarding prometheus
@@ -72,6 +124,81 @@ type PrometheusSpec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors.x-descriptors="urn:alm:descriptor:com.tectonic.ui:hidden"
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// RemoteWrite is the list of long-term stores the metering series are written to.
+	// Only the meterdef_* series and the metrics used by meter definition queries are sent.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	RemoteWrite []RemoteWriteSpec `json:"remoteWrite,omitempty"`
+
+	// RemoteRead is the list of long-term stores Prometheus reads back from, so
+	// reports can be generated for data that is no longer on the local volume.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	RemoteRead []RemoteReadSpec `json:"remoteRead,omitempty"`
+
+	// SeriesBudget is the number of head series the Prometheus deployment can hold.
+	// Meter definitions get a warning condition when the head series get close
+	// to the budget. Defaults to the memory limit, or request, divided by 8KiB.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SeriesBudget *int64 `json:"seriesBudget,omitempty"`
+}
+
+// RemoteWriteSpec is a remote write target for the metering Prometheus.
+type RemoteWriteSpec struct {
+	// URL of the endpoint to send samples to.
+	URL string `json:"url"`
+
+	// Name of the remote write queue, must be unique if specified.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// TLSConfig to use for the endpoint.
+	// +optional
+	TLSConfig *monitoringv1.TLSConfig `json:"tlsConfig,omitempty"`
+
+	// BasicAuth credentials for the endpoint.
+	// +optional
+	BasicAuth *monitoringv1.BasicAuth `json:"basicAuth,omitempty"`
+
+	// BearerTokenSecret is the secret key holding the bearer token for the endpoint.
+	// The secret must be in the namespace of the MeterBase.
+	// +optional
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// WriteRelabelConfigs are applied after the metering series filter.
+	// +optional
+	WriteRelabelConfigs []monitoringv1.RelabelConfig `json:"writeRelabelConfigs,omitempty"`
+}
+
+// RemoteReadSpec is a remote read endpoint for the metering Prometheus.
+type RemoteReadSpec struct {
+	// URL of the endpoint to read samples from.
+	URL string `json:"url"`
+
+	// Name of the remote read queue, must be unique if specified.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// TLSConfig to use for the endpoint.
+	// +optional
+	TLSConfig *monitoringv1.TLSConfig `json:"tlsConfig,omitempty"`
+
+	// BasicAuth credentials for the endpoint.
+	// +optional
+	BasicAuth *monitoringv1.BasicAuth `json:"basicAuth,omitempty"`
+
+	// BearerTokenSecret is the secret key holding the bearer token for the endpoint.
+	// The secret must be in the namespace of the MeterBase.
+	// +optional
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// ReadRecent also reads from the endpoint for time ranges that are
+	// still on the local volume.
+	// +optional
+	ReadRecent bool `json:"readRecent,omitempty"`
 }
 
 // MeterBaseSpec defines the desired state of MeterBase
@@ -90,12 +217,130 @@ type MeterBaseSpec struct {
 	// +optional
 	Prometheus *PrometheusSpec `json:"prometheus,omitempty"`
 
+	// ExternalPrometheus is an existing Prometheus or Thanos Querier used for
+	// metering. When set, the operator does not install its own Prometheus and
+	// only sets up the service monitors used for metering. A Prometheus the
+	// operator installed before is removed, its persistent volume claims are
+	// kept.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	ExternalPrometheus *common.ExternalPrometheus `json:"externalPrometheus,omitempty"`
+
 	// AdditionalConfigs are set by meter definitions and meterbase to what is available on the
 	// system.
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	// +optional
 	AdditionalScrapeConfigs *corev1.SecretKeySelector `json:"additionalScrapeConfigs,omitempty"`
+
+	// KubernetesMonitoring sets the kubelet and kube-state-metrics targets scraped
+	// on clusters without openshift-monitoring. It is ignored on OpenShift.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	KubernetesMonitoring *KubernetesMonitoringSpec `json:"kubernetesMonitoring,omitempty"`
+
+	// ScrapeSources selects the ServiceMonitors and PodMonitors of vendor exporters
+	// scraped by the metering Prometheus.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	ScrapeSources *ScrapeSourcesSpec `json:"scrapeSources,omitempty"`
+
+	// ScrapeLimits are the sample and label limits of each scrape source. A
+	// scrape over a limit fails and none of its samples are stored.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	ScrapeLimits *ScrapeLimitsSpec `json:"scrapeLimits,omitempty"`
+}
+
+// ScrapeLimitsSpec sets the limits of the metering scrape sources
+type ScrapeLimitsSpec struct {
+	// KubeStateMetrics limits the kube-state-metrics targets
+	// +optional
+	KubeStateMetrics *ScrapeLimit `json:"kubeStateMetrics,omitempty"`
+
+	// Kubelet limits the kubelet targets
+	// +optional
+	Kubelet *ScrapeLimit `json:"kubelet,omitempty"`
+
+	// MetricState limits the metric-state targets
+	// +optional
+	MetricState *ScrapeLimit `json:"metricState,omitempty"`
+
+	// ScrapeSources limits each monitor selected by spec.scrapeSources
+	// +optional
+	ScrapeSources *ScrapeLimit `json:"scrapeSources,omitempty"`
+}
+
+// ScrapeLimit limits what a target can return per scrape
+type ScrapeLimit struct {
+	// SampleLimit is the number of samples a scrape can return. Zero means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SampleLimit uint64 `json:"sampleLimit,omitempty"`
+
+	// LabelLimit is the number of labels a sample can have. Zero means no limit.
+	// Requires Prometheus 2.27 or later.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LabelLimit uint64 `json:"labelLimit,omitempty"`
+}
+
+// ScrapeSourcesSpec selects the monitors added to the metering scrape config.
+// A monitor may only scrape targets in its own namespace and may not read
+// files from the Prometheus pod; monitors that do are rejected.
+type ScrapeSourcesSpec struct {
+	// ServiceMonitorSelector selects the ServiceMonitors to scrape. No
+	// ServiceMonitors are selected when not set.
+	// +optional
+	ServiceMonitorSelector *metav1.LabelSelector `json:"serviceMonitorSelector,omitempty"`
+
+	// PodMonitorSelector selects the PodMonitors to scrape. No PodMonitors are
+	// selected when not set.
+	// +optional
+	PodMonitorSelector *metav1.LabelSelector `json:"podMonitorSelector,omitempty"`
+
+	// NamespaceSelector selects the namespaces the monitors are taken from.
+	// Only the namespace of the MeterBase is used when not set.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// KubernetesMonitoringSpec sets the scrape targets used in place of the
+// openshift-monitoring service monitors.
+type KubernetesMonitoringSpec struct {
+	// KubeStateMetrics is the kube-state-metrics service. When not set, the
+	// service labeled app.kubernetes.io/name=kube-state-metrics is used.
+	// +optional
+	KubeStateMetrics *MonitoredService `json:"kubeStateMetrics,omitempty"`
+
+	// Kubelet is the service holding the kubelet endpoints. Defaults to the
+	// kube-system/kubelet service maintained by the prometheus operator, scraped
+	// over https without verifying the kubelet certificates.
+	// +optional
+	Kubelet *MonitoredService `json:"kubelet,omitempty"`
+}
+
+// MonitoredService is a service scraped by Prometheus
+type MonitoredService struct {
+	// Name of the service
+	Name string `json:"name"`
+
+	// Namespace of the service
+	Namespace string `json:"namespace"`
+
+	// Port is the name of the service port serving metrics. Defaults to the
+	// first port of the service.
+	// +optional
+	Port string `json:"port,omitempty"`
+
+	// Scheme is http or https. Defaults to http for kube-state-metrics and
+	// https for the kubelet.
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+
+	// InsecureSkipVerify disables verification of the target certificates.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 // MeterBaseStatus defines the observed state of MeterBase.
@@ -126,6 +371,94 @@ type MeterBaseStatus struct {
 	// Total number of unavailable pods targeted by this Prometheus deployment.
 	// +optional
 	UnavailableReplicas *int32 `json:"unavailableReplicas,omitempty"`
+
+	// ScrapeSources are the monitors selected by spec.scrapeSources and whether
+	// they were added to the scrape config.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	ScrapeSources []ScrapeSourceStatus `json:"scrapeSources,omitempty"`
+
+	// PrometheusReplicas is the health of each Prometheus pod.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	PrometheusReplicas []PrometheusReplicaStatus `json:"prometheusReplicas,omitempty"`
+
+	// Storage is the disk usage of the Prometheus volumes.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Storage *PrometheusStorageStatus `json:"storage,omitempty"`
+}
+
+// PrometheusReplicaStatus is the health of a Prometheus pod
+type PrometheusReplicaStatus struct {
+	// Name of the pod
+	Name string `json:"name"`
+
+	// Ready is true when the pod is ready to serve queries
+	Ready bool `json:"ready"`
+
+	// Phase of the pod
+	// +optional
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+
+	// Node the pod runs on
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// PersistentVolumeClaim holding the data of the pod
+	// +optional
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+
+	// Restarts of the prometheus container
+	// +optional
+	Restarts int32 `json:"restarts,omitempty"`
+
+	// Message is the reason the pod is not ready
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PrometheusStorageStatus is the disk usage of the Prometheus volumes
+type PrometheusStorageStatus struct {
+	// Size is the size the volumes were grown to. It is used in place of
+	// spec.prometheus.storage.size when larger.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Capacity is the capacity of the smallest Prometheus volume
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// Used is the size of the TSDB blocks, write-ahead log and head chunks
+	// +optional
+	Used *resource.Quantity `json:"used,omitempty"`
+
+	// UsagePercent is the percent of the capacity used
+	// +optional
+	UsagePercent int32 `json:"usagePercent,omitempty"`
+
+	// LastGrowthTime is the last time the volumes were grown
+	// +optional
+	LastGrowthTime *metav1.Time `json:"lastGrowthTime,omitempty"`
+}
+
+// ScrapeSourceStatus is a monitor selected by spec.scrapeSources
+type ScrapeSourceStatus struct {
+	// Kind is ServiceMonitor or PodMonitor
+	Kind string `json:"kind"`
+
+	// Namespace of the monitor
+	Namespace string `json:"namespace"`
+
+	// Name of the monitor
+	Name string `json:"name"`
+
+	// Accepted is true when the monitor is part of the scrape config
+	Accepted bool `json:"accepted"`
+
+	// Message is the reason the monitor was rejected
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // MeterBase is the resource that sets up Metering for Red Hat Marketplace.
@@ -160,6 +493,22 @@ type MeterBaseList struct {
 	Items           []MeterBase `json:"items"`
 }
 
+// PrometheusStorageSize is the size of the Prometheus volumes, the spec size
+// or the size the volumes were grown to when larger.
+func (m *MeterBase) PrometheusStorageSize() resource.Quantity {
+	size := resource.Quantity{}
+	if m.Spec.Prometheus != nil {
+		size = m.Spec.Prometheus.Storage.Size.DeepCopy()
+	}
+
+	if m.Status.Storage != nil && m.Status.Storage.Size != nil &&
+		m.Status.Storage.Size.Cmp(size) > 0 {
+		size = m.Status.Storage.Size.DeepCopy()
+	}
+
+	return size
+}
+
 func init() {
 	SchemeBuilder.Register(&MeterBase{}, &MeterBaseList{})
 }
@@ -170,4 +519,38 @@ const (
 	ReasonMeterBasePrometheusInstall        status.ConditionReason = "StartMeterBasePrometheusInstall"
 	ReasonMeterBasePrometheusServiceInstall status.ConditionReason = "StartMeterBasePrometheusServiceInstall"
 	ReasonMeterBaseFinishInstall            status.ConditionReason = "FinishedMeterBaseInstall"
+
+	// Reasons for meter base events
+	MeterBaseEventReasonPVCResize       = "PrometheusPVCResize"
+	MeterBaseEventReasonPVCResizeNeeded = "PrometheusPVCResizeNeeded"
+	MeterBaseEventReasonStorageGrown    = "PrometheusStorageGrown"
+	MeterBaseEventReasonStorageFull     = "PrometheusStorageFull"
+	MeterBaseEventReasonSeriesBudget    = "PrometheusSeriesBudget"
+	MeterBaseEventReasonInvalidQuery    = "InvalidMeterQuery"
+
+	// ConditionStorageGrowth is false when the Prometheus volumes are over the
+	// usage threshold and can't be grown
+	ConditionStorageGrowth status.ConditionType = "StorageGrowth"
+
+	// Reasons for storage growth
+	ReasonStorageWithinThreshold    status.ConditionReason = "StorageWithinThreshold"
+	ReasonStorageGrown              status.ConditionReason = "StorageGrown"
+	ReasonStorageClassNotExpandable status.ConditionReason = "StorageClassNotExpandable"
+	ReasonStorageAtMaxSize          status.ConditionReason = "StorageAtMaxSize"
+
+	// ConditionMissingPermissions is true when the authcheck sidecar of a
+	// metering workload reports a permission it needs but does not have
+	ConditionMissingPermissions status.ConditionType = "MissingPermissions"
+
+	// Reasons for missing permissions
+	ReasonPermissionsMissing status.ConditionReason = "PermissionsMissing"
+	ReasonPermissionsGranted status.ConditionReason = "PermissionsGranted"
+
+	// ConditionMeterQueriesInvalid is true when meter definition queries can't be
+	// parsed, their metrics are not kept by the remote write filter
+	ConditionMeterQueriesInvalid status.ConditionType = "MeterQueriesInvalid"
+
+	// Reasons for invalid meter queries
+	ReasonMeterQueriesParsed    status.ConditionReason = "QueriesParsed"
+	ReasonMeterQueriesNotParsed status.ConditionReason = "QueriesNotParsed"
 )
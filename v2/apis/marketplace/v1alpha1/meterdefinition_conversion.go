@@ -18,15 +18,146 @@ import (
 	"time"
 
 	"emperror.dev/errors"
+	utilconversion "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/conversion"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this MeterDefinition to the Hub version (v1beta1).
+// The fields v1beta1 can't represent are stored in the conversion data
+// annotation of the result.
 func (src *MeterDefinition) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.MeterDefinition)
 
+	restored := &v1beta1.MeterDefinition{}
+	ok, err := utilconversion.UnmarshalData(src, restored)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ok && convertsFromBeta(&restored.Spec, &src.Spec):
+		// unchanged since it was converted from v1beta1
+		dst.Spec = restored.Spec
+	default:
+		if err := convertSpecToBeta(&src.Spec, &dst.Spec); err != nil {
+			return err
+		}
+
+		if ok {
+			restoreMeters(&dst.Spec, &restored.Spec)
+		}
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	utilconversion.RemoveData(dst)
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.WorkloadResources = src.Status.WorkloadResources
+
+	if ok {
+		dst.Status.SeriesCount = restored.Status.SeriesCount
+	}
+
+	if !convertsFromBeta(&dst.Spec, &src.Spec) {
+		return utilconversion.MarshalData(src, dst)
+	}
+
+	return nil
+}
+
+var _ conversion.Convertible = &MeterDefinition{}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+// The fields v1alpha1 can't represent are stored in the conversion data
+// annotation of the result.
+func (dst *MeterDefinition) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.MeterDefinition)
+
+	restored := &MeterDefinition{}
+	ok, err := utilconversion.UnmarshalData(src, restored)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ok && convertsToBeta(&restored.Spec, &src.Spec):
+		// unchanged since it was converted from v1alpha1
+		dst.Spec = restored.Spec
+	default:
+		if err := convertSpecFromBeta(&src.Spec, &dst.Spec); err != nil {
+			return errors.WrapWithDetails(err, "failed to convert to v1alpha1",
+				"name", src.Name,
+				"namespace", src.Namespace)
+		}
+
+		if ok {
+			dst.Spec.Version = restored.Spec.Version
+			dst.Spec.ServiceMeterLabels = restored.Spec.ServiceMeterLabels
+			dst.Spec.PodMeterLabels = restored.Spec.PodMeterLabels
+		}
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	utilconversion.RemoveData(dst)
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.WorkloadResources = src.Status.WorkloadResources
+
+	if src.Status.SeriesCount != nil || !convertsToBeta(&dst.Spec, &src.Spec) {
+		return utilconversion.MarshalData(src, dst)
+	}
+
+	return nil
+}
+
+// convertsToBeta returns true if the v1alpha1 spec converts to the v1beta1
+// spec without the conversion data.
+func convertsToBeta(alpha *MeterDefinitionSpec, beta *v1beta1.MeterDefinitionSpec) bool {
+	converted := v1beta1.MeterDefinitionSpec{}
+	if err := convertSpecToBeta(alpha, &converted); err != nil {
+		return false
+	}
+
+	return apiequality.Semantic.DeepEqual(&converted, beta)
+}
+
+// convertsFromBeta returns true if the v1beta1 spec converts to the v1alpha1
+// spec without the conversion data.
+func convertsFromBeta(beta *v1beta1.MeterDefinitionSpec, alpha *MeterDefinitionSpec) bool {
+	converted := MeterDefinitionSpec{}
+	if err := convertSpecFromBeta(beta, &converted); err != nil {
+		return false
+	}
+
+	return apiequality.Semantic.DeepEqual(&converted, alpha)
+}
+
+// restoreMeters restores the fields of the meters v1alpha1 doesn't have
+// after the workloads were changed in v1alpha1.
+func restoreMeters(dst, restored *v1beta1.MeterDefinitionSpec) {
+	for i := range dst.Meters {
+		meter := &dst.Meters[i]
+
+		for _, old := range restored.Meters {
+			if old.Name != meter.Name ||
+				old.Metric != meter.Metric ||
+				old.WorkloadType != meter.WorkloadType {
+				continue
+			}
+
+			meter.Description = old.Description
+			meter.GroupBy = old.GroupBy
+			meter.Without = old.Without
+			meter.Period = old.Period
+			meter.DateLabelOverride = old.DateLabelOverride
+			meter.ValueLabelOverride = old.ValueLabelOverride
+			break
+		}
+	}
+}
+
+func convertSpecToBeta(src *MeterDefinitionSpec, dst *v1beta1.MeterDefinitionSpec) error {
 	meters := []v1beta1.MeterWorkload{}
 
 	hrDuration, _ := time.ParseDuration("1h")
@@ -34,7 +165,7 @@ func (src *MeterDefinition) ConvertTo(dstRaw conversion.Hub) error {
 
 	var namespaceFilters *v1beta1.NamespaceFilter
 
-	switch src.Spec.WorkloadVertexType {
+	switch src.WorkloadVertexType {
 	case WorkloadVertexOperatorGroup:
 		namespaceFilters = &v1beta1.NamespaceFilter{
 			UseOperatorGroup: true,
@@ -42,13 +173,13 @@ func (src *MeterDefinition) ConvertTo(dstRaw conversion.Hub) error {
 	case WorkloadVertexNamespace:
 		namespaceFilters = &v1beta1.NamespaceFilter{
 			UseOperatorGroup: false,
-			LabelSelector:    src.Spec.VertexLabelSelector,
+			LabelSelector:    src.VertexLabelSelector,
 		}
 	}
 
 	resourceFilters := []v1beta1.ResourceFilter{}
 
-	for _, workload := range src.Spec.Workloads {
+	for _, workload := range src.Workloads {
 		filters := v1beta1.ResourceFilter{}
 
 		if workload.AnnotationSelector != nil {
@@ -94,45 +225,35 @@ func (src *MeterDefinition) ConvertTo(dstRaw conversion.Hub) error {
 		}
 	}
 
-	dst.ObjectMeta = src.ObjectMeta
-	dst.Spec.Group = string(src.Spec.Group)
-	dst.Spec.Kind = string(src.Spec.Kind)
-	dst.Spec.InstalledBy = src.Spec.InstalledBy
-	dst.Spec.Meters = meters
-	dst.Spec.ResourceFilters = resourceFilters
-	dst.Status.Conditions = src.Status.Conditions
-	dst.Status.WorkloadResources = src.Status.WorkloadResources
+	dst.Group = string(src.Group)
+	dst.Kind = string(src.Kind)
+	dst.InstalledBy = src.InstalledBy
+	dst.Meters = meters
+	dst.ResourceFilters = resourceFilters
 	return nil
 }
 
-var _ conversion.Convertible = &MeterDefinition{}
-
-// ConvertFrom converts from the Hub version (v1beta1) to this version.
-func (dst *MeterDefinition) ConvertFrom(srcRaw conversion.Hub) error {
-	src := srcRaw.(*v1beta1.MeterDefinition)
+func convertSpecFromBeta(src *v1beta1.MeterDefinitionSpec, dst *MeterDefinitionSpec) error {
 	workloads := []Workload{}
 
-	if len(src.Spec.ResourceFilters) == 0 {
-		return errors.NewWithDetails("failed to convert to v1alpha1",
-			"name", src.Name,
-			"namespace", src.Namespace,
-			"details", "no resource filters available")
+	if len(src.ResourceFilters) == 0 {
+		return errors.New("no resource filters available")
 	}
 
-	filter := src.Spec.ResourceFilters[0]
+	filter := src.ResourceFilters[0]
 
 	if filter.Namespace != nil && filter.Namespace.UseOperatorGroup == true {
-		dst.Spec.WorkloadVertexType = WorkloadVertexOperatorGroup
+		dst.WorkloadVertexType = WorkloadVertexOperatorGroup
 	}
 
 	if filter.Namespace != nil && filter.Namespace.UseOperatorGroup == false {
-		dst.Spec.WorkloadVertexType = WorkloadVertexNamespace
-		dst.Spec.VertexLabelSelector = filter.Namespace.LabelSelector
+		dst.WorkloadVertexType = WorkloadVertexNamespace
+		dst.VertexLabelSelector = filter.Namespace.LabelSelector
 	}
 
 	resourceMap := map[WorkloadType]v1beta1.ResourceFilter{}
 
-	for _, filter := range src.Spec.ResourceFilters {
+	for _, filter := range src.ResourceFilters {
 		workloadType, _ := ConvertWorkloadTypeAlpha(filter.WorkloadType)
 
 		if _, ok := resourceMap[workloadType]; !ok {
@@ -140,7 +261,7 @@ func (dst *MeterDefinition) ConvertFrom(srcRaw conversion.Hub) error {
 		}
 	}
 
-	for _, meter := range src.Spec.Meters {
+	for _, meter := range src.Meters {
 		workloadType, _ := ConvertWorkloadTypeAlpha(meter.WorkloadType)
 
 		workload := Workload{
@@ -172,14 +293,10 @@ func (dst *MeterDefinition) ConvertFrom(srcRaw conversion.Hub) error {
 		workloads = append(workloads, workload)
 	}
 
-	dst.ObjectMeta = src.ObjectMeta
-	dst.Spec.Group = src.Spec.Group
-	dst.Spec.Kind = src.Spec.Kind
-	dst.Spec.Workloads = workloads
-	dst.Spec.InstalledBy = src.Spec.InstalledBy
-	dst.Status.Conditions = src.Status.Conditions
-	dst.Status.WorkloadResources = src.Status.WorkloadResources
-
+	dst.Group = src.Group
+	dst.Kind = src.Kind
+	dst.Workloads = workloads
+	dst.InstalledBy = src.InstalledBy
 	return nil
 }
 
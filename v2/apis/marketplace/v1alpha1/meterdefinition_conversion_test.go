@@ -0,0 +1,184 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	utilconversion "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/conversion"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/conversion/fuzzer"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("MeterDefinition conversion", func() {
+	alphaWorkloadTypes := []WorkloadType{
+		WorkloadTypePod,
+		WorkloadTypeService,
+		WorkloadTypeServiceMonitor,
+		WorkloadTypePVC,
+	}
+
+	betaWorkloadTypes := []v1beta1.WorkloadType{
+		v1beta1.WorkloadTypePod,
+		v1beta1.WorkloadTypeService,
+		v1beta1.WorkloadTypePVC,
+	}
+
+	fuzzerFuncs := []interface{}{
+		func(t *WorkloadType, c fuzz.Continue) {
+			*t = alphaWorkloadTypes[c.Intn(len(alphaWorkloadTypes))]
+		},
+		func(t *v1beta1.WorkloadType, c fuzz.Continue) {
+			*t = betaWorkloadTypes[c.Intn(len(betaWorkloadTypes))]
+		},
+		func(s *v1beta1.MeterDefinitionSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+
+			// v1beta1 requires a resource filter
+			if len(s.ResourceFilters) == 0 {
+				s.ResourceFilters = make([]v1beta1.ResourceFilter, 1)
+				c.Fuzz(&s.ResourceFilters[0])
+			}
+		},
+	}
+
+	input := func() fuzzer.RoundTripInput {
+		return fuzzer.RoundTripInput{
+			Hub:         &v1beta1.MeterDefinition{},
+			Spoke:       &MeterDefinition{},
+			FuzzerFuncs: fuzzerFuncs,
+			Iterations:  500,
+			Seed:        GinkgoRandomSeed(),
+		}
+	}
+
+	var alpha *MeterDefinition
+
+	BeforeEach(func() {
+		alpha = &MeterDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "bar",
+			},
+			Spec: MeterDefinitionSpec{
+				Group:              "apps.partner.metering.com",
+				Kind:               "App",
+				WorkloadVertexType: WorkloadVertexOperatorGroup,
+				Workloads: []Workload{
+					{
+						Name:         "foo",
+						WorkloadType: WorkloadTypePod,
+						OwnerCRD: &common.GroupVersionKind{
+							APIVersion: "apps.partner.metering.com/v1",
+							Kind:       "App",
+						},
+						MetricLabels: []MeterLabelQuery{
+							{
+								Label:       "rpc_durations_seconds_sum",
+								Query:       "rpc_durations_seconds_sum",
+								Aggregation: "sum",
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should round trip v1alpha1 through v1beta1", func() {
+		Expect(fuzzer.SpokeHubSpoke(input())).To(Succeed())
+	})
+
+	It("should round trip v1beta1 through v1alpha1", func() {
+		Expect(fuzzer.HubSpokeHub(input())).To(Succeed())
+	})
+
+	It("should not store conversion data when nothing is lost", func() {
+		beta := &v1beta1.MeterDefinition{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+		Expect(beta.Annotations).ToNot(HaveKey(utilconversion.DataAnnotation))
+
+		result := &MeterDefinition{}
+		Expect(result.ConvertFrom(beta)).To(Succeed())
+		Expect(result.Annotations).ToNot(HaveKey(utilconversion.DataAnnotation))
+		Expect(result.Spec).To(Equal(alpha.Spec))
+	})
+
+	It("should store the v1alpha1 fields v1beta1 doesn't have", func() {
+		alpha.Spec.Version = pointer.StringPtr("1.0.0")
+		alpha.Spec.PodMeterLabels = []string{"pod"}
+
+		beta := &v1beta1.MeterDefinition{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+		Expect(beta.Annotations).To(HaveKey(utilconversion.DataAnnotation))
+
+		result := &MeterDefinition{}
+		Expect(result.ConvertFrom(beta)).To(Succeed())
+		Expect(result.Annotations).ToNot(HaveKey(utilconversion.DataAnnotation))
+		Expect(result.Spec).To(Equal(alpha.Spec))
+	})
+
+	It("should keep the v1alpha1 fields when the meters change in v1beta1", func() {
+		alpha.Spec.Version = pointer.StringPtr("1.0.0")
+
+		beta := &v1beta1.MeterDefinition{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+
+		beta.Spec.Meters[0].Query = "new_query"
+
+		result := &MeterDefinition{}
+		Expect(result.ConvertFrom(beta)).To(Succeed())
+		Expect(result.Spec.Version).To(Equal(alpha.Spec.Version))
+		Expect(result.Spec.Workloads[0].MetricLabels[0].Query).To(Equal("new_query"))
+	})
+
+	It("should keep the v1beta1 fields when the workloads change in v1alpha1", func() {
+		beta := &v1beta1.MeterDefinition{}
+		Expect(alpha.ConvertTo(beta)).To(Succeed())
+
+		beta.Spec.Meters[0].Description = "rpc duration"
+		beta.Spec.Meters[0].GroupBy = []string{"namespace"}
+		beta.Spec.Meters[0].Period = &metav1.Duration{Duration: 15 * time.Minute}
+		beta.Status.SeriesCount = pointer.Int64Ptr(10)
+
+		alpha = &MeterDefinition{}
+		Expect(alpha.ConvertFrom(beta)).To(Succeed())
+		Expect(alpha.Annotations).To(HaveKey(utilconversion.DataAnnotation))
+
+		alpha.Spec.Workloads[0].MetricLabels[0].Query = "new_query"
+		alpha.Spec.Workloads[0].MetricLabels = append(alpha.Spec.Workloads[0].MetricLabels, MeterLabelQuery{
+			Label:       "rpc_durations_seconds_count",
+			Query:       "rpc_durations_seconds_count",
+			Aggregation: "sum",
+		})
+
+		result := &v1beta1.MeterDefinition{}
+		Expect(alpha.ConvertTo(result)).To(Succeed())
+		Expect(result.Spec.Meters).To(HaveLen(2))
+		Expect(result.Spec.Meters[0].Query).To(Equal("new_query"))
+		Expect(result.Spec.Meters[0].Description).To(Equal("rpc duration"))
+		Expect(result.Spec.Meters[0].GroupBy).To(Equal([]string{"namespace"}))
+		Expect(result.Spec.Meters[0].Period.Duration).To(Equal(15 * time.Minute))
+		Expect(result.Spec.Meters[1].Description).To(BeEmpty())
+		Expect(result.Status.SeriesCount).To(Equal(pointer.Int64Ptr(10)))
+	})
+})
@@ -35,7 +35,13 @@ type MeterReportSpec struct {
 
 	// PrometheusService is the definition for the service labels.
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
-	PrometheusService *common.ServiceReference `json:"prometheusService"`
+	// +optional
+	PrometheusService *common.ServiceReference `json:"prometheusService,omitempty"`
+
+	// ExternalPrometheus is the Prometheus API to query instead of PrometheusService.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	ExternalPrometheus *common.ExternalPrometheus `json:"externalPrometheus,omitempty"`
 
 	// MeterDefinitions is the list of meterDefinitions included in the report
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
@@ -76,6 +82,38 @@ type MeterReportStatus struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	// +optional
 	QueryErrorList []string `json:"queryErrorList,omitempty"`
+
+	// MeterDefinitionSnapshot is the record of the meter definitions in effect
+	// when the report job was submitted. Once the report is written it only
+	// has the meter definitions the report used.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	MeterDefinitionSnapshot *common.MeterDefinitionSnapshot `json:"meterDefinitionSnapshot,omitempty"`
+
+	// ClusterInventory is the inventory of the cluster when the report job was submitted.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	ClusterInventory *common.ClusterInventorySummary `json:"clusterInventory,omitempty"`
+
+	// Usage is the total of each metric of the report per resource, summed
+	// from the values uploaded for billing. It is only set once the report is
+	// uploaded or archived. The totals are per namespace when the report has
+	// too many resources.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Usage []common.UsageTotal `json:"usage,omitempty"`
+
+	// UsageTruncated is true when the report has too many resources and the
+	// usage totals are per namespace.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	UsageTruncated bool `json:"usageTruncated,omitempty"`
+
+	// QueuePosition is the position of the report in the queue of reports
+	// waiting for a reporter job to be available. It is unset once the job is submitted.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	QueuePosition *int32 `json:"queuePosition,omitempty"`
 }
 
 const (
@@ -85,6 +123,16 @@ const (
 	ReportConditionReasonJobWaiting    status.ConditionReason = "Waiting"
 	ReportConditionReasonJobFinished   status.ConditionReason = "Finished"
 	ReportConditionReasonJobErrored    status.ConditionReason = "Errored"
+	ReportConditionReasonJobQueued     status.ConditionReason = "Queued"
+)
+
+const (
+	// Reasons for meter report events
+	ReportEventReasonJobSubmitted      = "JobSubmitted"
+	ReportEventReasonJobFailed         = "JobFailed"
+	ReportEventReasonJobRetried        = "JobRetried"
+	ReportEventReasonJobSucceeded      = "JobSucceeded"
+	ReportEventReasonPrometheusChanged = "PrometheusChanged"
 )
 
 var (
@@ -118,6 +166,12 @@ var (
 		Reason:  ReportConditionReasonJobErrored,
 		Message: "Job has errored",
 	}
+	ReportConditionJobQueued = status.Condition{
+		Type:    ReportConditionTypeJobRunning,
+		Status:  corev1.ConditionFalse,
+		Reason:  ReportConditionReasonJobQueued,
+		Message: "Job is queued until a running job finishes",
+	}
 )
 
 // +kubebuilder:object:root=true
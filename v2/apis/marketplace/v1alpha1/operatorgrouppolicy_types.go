@@ -0,0 +1,109 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorGroupPolicySpec defines the OperatorGroup used by the marketplace
+// subscriptions in the namespace of the policy.
+// +k8s:openapi-gen=true
+type OperatorGroupPolicySpec struct {
+	// OwnNamespace targets only the namespace of the policy. It can't be set
+	// together with TargetNamespaces.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	OwnNamespace bool `json:"ownNamespace,omitempty"`
+
+	// TargetNamespaces are the namespaces the operators watch. When empty and
+	// OwnNamespace is false, the operators watch all namespaces.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+
+	// AdoptExisting uses an OperatorGroup that was not created by the marketplace
+	// operator as is. When false, an existing OperatorGroup is reported as a conflict.
+	// Defaults to true.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	AdoptExisting *bool `json:"adoptExisting,omitempty"`
+}
+
+// OperatorGroupPolicyStatus is the state of the OperatorGroup of the namespace
+// +k8s:openapi-gen=true
+type OperatorGroupPolicyStatus struct {
+	// Conditions represent the latest available observations of the policy
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	// +optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+
+	// OperatorGroup is the name of the OperatorGroup used by the subscriptions
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	OperatorGroup string `json:"operatorGroup,omitempty"`
+}
+
+// OperatorGroupPolicy sets the OperatorGroup created for marketplace subscriptions in its namespace
+// +kubebuilder:object:root=true
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=operatorgrouppolicies,scope=Namespaced
+// +kubebuilder:printcolumn:name="OPERATORGROUP",type=string,JSONPath=`.status.operatorGroup`
+// +kubebuilder:printcolumn:name="READY",type=string,JSONPath=`.status.conditions[?(@.type == "Ready")].status`
+// +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.conditions[?(@.type == "Ready")].reason`
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="OperatorGroup Policy"
+type OperatorGroupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OperatorGroupPolicySpec   `json:"spec,omitempty"`
+	Status OperatorGroupPolicyStatus `json:"status,omitempty"`
+}
+
+// ShouldAdoptExisting returns if an OperatorGroup not created by the marketplace operator is used as is.
+func (p *OperatorGroupPolicy) ShouldAdoptExisting() bool {
+	return p.Spec.AdoptExisting == nil || *p.Spec.AdoptExisting
+}
+
+const (
+	// OperatorGroupPolicyConditionReady means the namespace has a single
+	// OperatorGroup that satisfies the policy.
+	OperatorGroupPolicyConditionReady status.ConditionType = "Ready"
+
+	// Reasons for the ready condition
+	OperatorGroupPolicyReasonCreated        status.ConditionReason = "Created"
+	OperatorGroupPolicyReasonAdopted        status.ConditionReason = "Adopted"
+	OperatorGroupPolicyReasonConflict       status.ConditionReason = "Conflict"
+	OperatorGroupPolicyReasonMultipleGroups status.ConditionReason = "MultipleOperatorGroups"
+	OperatorGroupPolicyReasonMultiplePolicy status.ConditionReason = "MultiplePolicies"
+	OperatorGroupPolicyReasonInvalid        status.ConditionReason = "InvalidPolicy"
+)
+
+// +kubebuilder:object:root=true
+
+// OperatorGroupPolicyList contains a list of OperatorGroupPolicy
+type OperatorGroupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorGroupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorGroupPolicy{}, &OperatorGroupPolicyList{})
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultProductInstallTimeout is how long an operator has to reach Succeeded
+const DefaultProductInstallTimeout = 20 * time.Minute
+
+// ProductInstallSpec lists the marketplace operators of a product
+// +k8s:openapi-gen=true
+type ProductInstallSpec struct {
+	// Operators are the marketplace operators of the product. They are installed
+	// in the namespace of the ProductInstall, each after its dependencies.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	Operators []ProductOperator `json:"operators"`
+
+	// Timeout is how long each operator has to reach Succeeded. Defaults to 20m.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// RollbackOnFailure uninstalls the operators installed by the ProductInstall
+	// when one of them fails. Defaults to true.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	RollbackOnFailure *bool `json:"rollbackOnFailure,omitempty"`
+}
+
+// ProductOperator is a marketplace operator of a product
+type ProductOperator struct {
+	// Name is the name of the Subscription created for the operator
+	Name string `json:"name"`
+
+	// Package is the package of the operator in the catalog
+	Package string `json:"package"`
+
+	// Channel is the channel to subscribe to
+	// +optional
+	Channel string `json:"channel,omitempty"`
+
+	// CatalogSource is the catalog source of the package
+	CatalogSource string `json:"catalogSource"`
+
+	// CatalogSourceNamespace is the namespace of the catalog source
+	CatalogSourceNamespace string `json:"catalogSourceNamespace"`
+
+	// StartingCSV pins the operator to a version. The Subscription uses manual
+	// approval and only the install plan of the starting CSV is approved.
+	// +optional
+	StartingCSV string `json:"startingCSV,omitempty"`
+
+	// DependsOn are the names of the operators installed before this one
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// ProductInstallPhase is the phase of a ProductInstall or of one of its operators
+type ProductInstallPhase string
+
+const (
+	ProductInstallPhasePending    ProductInstallPhase = "Pending"
+	ProductInstallPhaseInstalling ProductInstallPhase = "Installing"
+	ProductInstallPhaseSucceeded  ProductInstallPhase = "Succeeded"
+	ProductInstallPhaseFailed     ProductInstallPhase = "Failed"
+	ProductInstallPhaseRolledBack ProductInstallPhase = "RolledBack"
+)
+
+// ProductInstallStatus is the progress of the install
+// +k8s:openapi-gen=true
+type ProductInstallStatus struct {
+	// Phase is the phase of the install
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Phase ProductInstallPhase `json:"phase,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the status applies to
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the install
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	// +optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+
+	// Operators is the progress of each operator, in install order
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Operators []ProductOperatorStatus `json:"operators,omitempty"`
+}
+
+// ProductOperatorStatus is the progress of an operator
+type ProductOperatorStatus struct {
+	// Name is the name of the operator in the spec
+	Name string `json:"name"`
+
+	// Phase is the phase of the operator
+	Phase ProductInstallPhase `json:"phase"`
+
+	// CSV is the ClusterServiceVersion installed for the operator
+	// +optional
+	CSV string `json:"csv,omitempty"`
+
+	// StartTime is when the Subscription was created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Message is the reason of a failure
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ProductInstall installs the marketplace operators of a product in dependency order
+// +kubebuilder:object:root=true
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=productinstalls,scope=Namespaced
+// +kubebuilder:printcolumn:name="PHASE",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="AGE",type=date,JSONPath=`.metadata.creationTimestamp`
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Product Install"
+type ProductInstall struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProductInstallSpec   `json:"spec,omitempty"`
+	Status ProductInstallStatus `json:"status,omitempty"`
+}
+
+// GetTimeout returns how long each operator has to reach Succeeded.
+func (p *ProductInstall) GetTimeout() time.Duration {
+	if p.Spec.Timeout == nil {
+		return DefaultProductInstallTimeout
+	}
+	return p.Spec.Timeout.Duration
+}
+
+// ShouldRollbackOnFailure returns if the installed operators are removed on a failure.
+func (p *ProductInstall) ShouldRollbackOnFailure() bool {
+	return p.Spec.RollbackOnFailure == nil || *p.Spec.RollbackOnFailure
+}
+
+const (
+	// ProductInstallConditionInstalled means every operator of the product reached Succeeded.
+	// It turns false with the degraded reason when an installed operator later fails.
+	ProductInstallConditionInstalled status.ConditionType = "Installed"
+
+	// Reasons for the installed condition
+	ProductInstallReasonInstalling status.ConditionReason = "Installing"
+	ProductInstallReasonInstalled  status.ConditionReason = "Installed"
+	ProductInstallReasonInvalid    status.ConditionReason = "InvalidOperators"
+	ProductInstallReasonFailed     status.ConditionReason = "InstallFailed"
+	ProductInstallReasonRolledBack status.ConditionReason = "RolledBack"
+	ProductInstallReasonDegraded   status.ConditionReason = "Degraded"
+)
+
+// +kubebuilder:object:root=true
+
+// ProductInstallList contains a list of ProductInstall
+type ProductInstallList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProductInstall `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProductInstall{}, &ProductInstallList{})
+}
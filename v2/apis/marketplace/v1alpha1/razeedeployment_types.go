@@ -227,3 +227,11 @@ const (
 	ReasonRhmRemoteResourceS3DeploymentEnabled   status.ConditionReason = "EnabledRemoteResourceS3DeploymentInstall"
 	ReasonRhmRegistrationWatchkeeperEnabled      status.ConditionReason = "EnabledRegistrationWatchkeeperInstall"
 )
+
+const (
+	// Reasons for razee deployment events
+	RazeeEventReasonSecretMissing       = "OperatorSecretMissing"
+	RazeeEventReasonSecretValuesMissing = "DeploySecretValuesMissing"
+	RazeeEventReasonInstallFailed       = "RazeeInstallFailed"
+	RazeeEventReasonUninstallFailed     = "RazeeUninstallFailed"
+)
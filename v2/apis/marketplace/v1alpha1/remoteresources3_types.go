@@ -15,8 +15,10 @@
 package v1alpha1
 
 import (
+	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 //Auth allows you authenticate to remote storage locations using either HMAC or IAM authentication schemes.
@@ -47,6 +49,10 @@ type Hmac struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// +optional
 	SecretAccessKeyRef SecretAccessKeyRef `json:"secretAccessKeyRef,omitempty"`
+	// Region is the region used to sign the requests. Defaults to us-east-1.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	Region string `json:"region,omitempty"`
 }
 
 //Iam Allows you to connect to s3 buckets using an IAM provider and api key.
@@ -143,6 +149,23 @@ type RemoteResourceS3Spec struct {
 	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
 	// Requests array contains information regarding the location of your remote resource
 	Requests []Request `json:"requests,omitempty"`
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// Verification is the public key that signs every request. The public key
+	// configured on the operator is used when it is not set.
+	// +optional
+	Verification *Verification `json:"verification,omitempty"`
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// Prune deletes the applied objects that were removed from the remote resources. Defaults to true.
+	// +optional
+	Prune *bool `json:"prune,omitempty"`
+}
+
+// Verification holds the public key that signs the remote resources. The
+// signature of a request is fetched from its url with a .sig suffix.
+type Verification struct {
+	// PublicKeyRef is the secret key that holds the PEM encoded public key or certificate
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	PublicKeyRef corev1.SecretKeySelector `json:"publicKeyRef"`
 }
 
 // RemoteResourceS3Status defines the observed state of RemoteResourceS3
@@ -154,6 +177,58 @@ type RemoteResourceS3Status struct {
 	Touched *bool `json:"touched,omitempty"`
 	// RazeeLogs is the logs from the controller
 	RazeeLogs RazeeLogs `json:"razee-logs,omitempty"`
+	// Conditions represent the latest available observations of the sync
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	// +optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+	// LastSyncTime is when the remote resources were last applied
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// Objects are the objects applied from the remote resources
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Objects []AppliedObject `json:"objects,omitempty"`
+	// Requests is the state of each entry of the spec requests, in the same
+	// order. A status follows its request by url when the requests are reordered.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Requests []RequestStatus `json:"requests,omitempty"`
+}
+
+// RequestStatus is the observed state of a request
+type RequestStatus struct {
+	// URL is the url of the request
+	URL string `json:"url"`
+	// LastFetchTime is when the request was last fetched
+	// +optional
+	LastFetchTime *metav1.Time `json:"lastFetchTime,omitempty"`
+	// HTTPStatus is the http status of the last fetch
+	// +optional
+	HTTPStatus int `json:"httpStatus,omitempty"`
+	// ContentHash is the sha256 of the last fetched document
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+	// AppliedObjects is the number of objects applied from the request
+	AppliedObjects int `json:"appliedObjects"`
+	// LastError is the error of the last sync, empty when it succeeded
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// Conditions represent the latest available observations of the request
+	// +optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+}
+
+// AppliedObject is an object applied from the remote resources
+type AppliedObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// UID is the uid of the object when it was applied
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
 }
 
 // RazeeLogs holds log output from the RRS3 controller
@@ -174,6 +249,9 @@ type Log map[string]string
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=remoteresources3s,scope=Namespaced
+// +kubebuilder:printcolumn:name="SYNCED",type=string,JSONPath=`.status.conditions[?(@.type == "Synced")].status`
+// +kubebuilder:printcolumn:name="REASON",type=string,JSONPath=`.status.conditions[?(@.type == "Synced")].reason`
+// +kubebuilder:printcolumn:name="LAST_SYNC",type=string,JSONPath=`.status.lastSyncTime`
 // +operator-sdk:gen-csv:customresourcedefinitions.displayName="(Internal)RemoteResourceS3"
 type RemoteResourceS3 struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -192,6 +270,20 @@ type RemoteResourceS3List struct {
 	Items           []RemoteResourceS3 `json:"items"`
 }
 
+const (
+	// RemoteResourceS3ConditionSynced means the remote resources are applied.
+	RemoteResourceS3ConditionSynced status.ConditionType = "Synced"
+
+	// Reasons for the synced condition
+	RemoteResourceS3ReasonSynced             status.ConditionReason = "Synced"
+	RemoteResourceS3ReasonFetchFailed        status.ConditionReason = "FetchFailed"
+	RemoteResourceS3ReasonVerificationFailed status.ConditionReason = "VerificationFailed"
+	RemoteResourceS3ReasonApplyFailed        status.ConditionReason = "ApplyFailed"
+	RemoteResourceS3ReasonNotAllowed         status.ConditionReason = "NotAllowed"
+	RemoteResourceS3ReasonPruneFailed        status.ConditionReason = "PruneFailed"
+	RemoteResourceS3ReasonRazeeFailed        status.ConditionReason = "RazeeFailed"
+)
+
 func init() {
 	SchemeBuilder.Register(&RemoteResourceS3{}, &RemoteResourceS3List{})
 }
@@ -0,0 +1,227 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultUsageSummaryPeriods is the number of report periods summarized when
+// the spec doesn't set it.
+const DefaultUsageSummaryPeriods int32 = 7
+
+// UsageSummarySpec defines the desired state of UsageSummary
+// +k8s:openapi-gen=true
+type UsageSummarySpec struct {
+	// Periods is the number of the latest report periods to summarize.
+	// Defaults to 7.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=90
+	// +optional
+	Periods *int32 `json:"periods,omitempty"`
+
+	// RateCard are the internal prices of the metrics used to show the cost
+	// of the usage. It is not used for billing.
+	// +operator-sdk:gen-csv:customresourcedefinitions.specDescriptors=true
+	// +optional
+	RateCard *RateCard `json:"rateCard,omitempty"`
+}
+
+// RateCard is a list of prices per unit of metric.
+type RateCard struct {
+	// Currency of the prices, only shown with the costs.
+	// +optional
+	Currency string `json:"currency,omitempty"`
+
+	// Rates are the prices of the metrics. Metrics without a rate have no cost.
+	Rates []Rate `json:"rates"`
+}
+
+// Rate is the price of one unit of a metric.
+type Rate struct {
+	// MeterGroup is the group of the meter definition.
+	MeterGroup string `json:"meterGroup"`
+
+	// MeterKind is the kind of the meter definition.
+	MeterKind string `json:"meterKind"`
+
+	// Metric is the metric id of the meter.
+	Metric string `json:"metric"`
+
+	// Price is the decimal price of one unit of the metric.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Price string `json:"price"`
+}
+
+// UsageSummaryStatus is the usage reported for billing over the latest report periods
+// +k8s:openapi-gen=true
+type UsageSummaryStatus struct {
+	// Conditions represent the latest available observations of the summary.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors.x-descriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	// +optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+
+	// Periods is the usage of each report period, the latest first.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	Periods []UsagePeriod `json:"periods,omitempty"`
+
+	// LastUpdateTime is when the summary last changed.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// UsagePeriod is the usage of a report period.
+type UsagePeriod struct {
+	// Report is the name of the MeterReport of the period.
+	Report string `json:"report"`
+
+	// StartTime of the period.
+	StartTime metav1.Time `json:"startTime"`
+
+	// EndTime of the period.
+	EndTime metav1.Time `json:"endTime"`
+
+	// MeterDefinitions is the total of each metric.
+	// +optional
+	MeterDefinitions []UsageTotal `json:"meterDefinitions,omitempty"`
+
+	// Namespaces is the total of each metric per namespace.
+	// +optional
+	Namespaces []UsageTotal `json:"namespaces,omitempty"`
+
+	// Resources is the total of each metric per resource.
+	// +optional
+	Resources []UsageTotal `json:"resources,omitempty"`
+
+	// ResourcesTruncated is true when the report of the period has too many
+	// resources and only has the usage per namespace.
+	// +optional
+	ResourcesTruncated bool `json:"resourcesTruncated,omitempty"`
+
+	// Cost is the total cost of the period from the rate card, rounded to 4
+	// decimals.
+	// +optional
+	Cost string `json:"cost,omitempty"`
+}
+
+// UsageTotal is a usage total with its cost from the rate card.
+type UsageTotal struct {
+	common.UsageTotal `json:",inline"`
+
+	// Cost is the value times the price of the metric, rounded to 4
+	// decimals. It is empty when the metric has no rate.
+	// +optional
+	Cost string `json:"cost,omitempty"`
+}
+
+const (
+	UsageSummaryConditionTypeAvailable     status.ConditionType   = "UsageAvailable"
+	UsageSummaryConditionTypeTruncated     status.ConditionType   = "UsageTruncated"
+	UsageSummaryConditionTypeRateCardValid status.ConditionType   = "RateCardValid"
+	UsageSummaryReasonSummarized           status.ConditionReason = "Summarized"
+	UsageSummaryReasonNoReports            status.ConditionReason = "NoReports"
+	UsageSummaryReasonResourcesTruncated   status.ConditionReason = "ResourcesTruncated"
+	UsageSummaryReasonResourcesComplete    status.ConditionReason = "ResourcesComplete"
+	UsageSummaryReasonValidPrices          status.ConditionReason = "ValidPrices"
+	UsageSummaryReasonInvalidPrices        status.ConditionReason = "InvalidPrices"
+)
+
+var (
+	UsageSummaryConditionSummarized = status.Condition{
+		Type:    UsageSummaryConditionTypeAvailable,
+		Status:  corev1.ConditionTrue,
+		Reason:  UsageSummaryReasonSummarized,
+		Message: "Usage summarized from the completed reports",
+	}
+	UsageSummaryConditionNoReports = status.Condition{
+		Type:    UsageSummaryConditionTypeAvailable,
+		Status:  corev1.ConditionFalse,
+		Reason:  UsageSummaryReasonNoReports,
+		Message: "No completed reports with usage",
+	}
+	UsageSummaryConditionTruncated = status.Condition{
+		Type:    UsageSummaryConditionTypeTruncated,
+		Status:  corev1.ConditionTrue,
+		Reason:  UsageSummaryReasonResourcesTruncated,
+		Message: "Some periods only have the usage per namespace, their reports have too many resources",
+	}
+	UsageSummaryConditionNotTruncated = status.Condition{
+		Type:    UsageSummaryConditionTypeTruncated,
+		Status:  corev1.ConditionFalse,
+		Reason:  UsageSummaryReasonResourcesComplete,
+		Message: "Every period has the usage per resource",
+	}
+	UsageSummaryConditionRateCardValid = status.Condition{
+		Type:    UsageSummaryConditionTypeRateCardValid,
+		Status:  corev1.ConditionTrue,
+		Reason:  UsageSummaryReasonValidPrices,
+		Message: "Every price of the rate card is valid",
+	}
+	UsageSummaryConditionRateCardInvalid = status.Condition{
+		Type:    UsageSummaryConditionTypeRateCardValid,
+		Status:  corev1.ConditionFalse,
+		Reason:  UsageSummaryReasonInvalidPrices,
+		Message: "The metrics with invalid prices have no cost",
+	}
+)
+
+// UsageSummary is the usage reported for billing, a summary in the operator
+// namespace covers every namespace and a summary in any other namespace covers
+// only its own
+// +kubebuilder:object:root=true
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=usagesummaries,scope=Namespaced
+// +kubebuilder:printcolumn:name="AVAILABLE",type=string,JSONPath=`.status.conditions[?(@.type == "UsageAvailable")].status`
+// +kubebuilder:printcolumn:name="LATEST",type=string,JSONPath=`.status.periods[0].endTime`
+// +kubebuilder:printcolumn:name="COST",type=string,JSONPath=`.status.periods[0].cost`
+// +kubebuilder:printcolumn:name="UPDATED",type=string,JSONPath=`.status.lastUpdateTime`
+// +operator-sdk:gen-csv:customresourcedefinitions.displayName="Usage Summary"
+type UsageSummary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UsageSummarySpec   `json:"spec,omitempty"`
+	Status UsageSummaryStatus `json:"status,omitempty"`
+}
+
+// GetPeriods returns the number of report periods to summarize.
+func (u *UsageSummary) GetPeriods() int {
+	if u.Spec.Periods == nil {
+		return int(DefaultUsageSummaryPeriods)
+	}
+	return int(*u.Spec.Periods)
+}
+
+// +kubebuilder:object:root=true
+
+// UsageSummaryList contains a list of UsageSummary
+type UsageSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UsageSummary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&UsageSummary{}, &UsageSummaryList{})
+}
@@ -63,6 +63,21 @@ func (in *AccesKeyIDRef) DeepCopy() *AccesKeyIDRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedObject) DeepCopyInto(out *AppliedObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedObject.
+func (in *AppliedObject) DeepCopy() *AppliedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Auth) DeepCopyInto(out *Auth) {
 	*out = *in
@@ -108,6 +123,142 @@ func (in *CSVNamespacedName) DeepCopy() *CSVNamespacedName {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInventory) DeepCopyInto(out *ClusterInventory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInventory.
+func (in *ClusterInventory) DeepCopy() *ClusterInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInventoryList) DeepCopyInto(out *ClusterInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInventoryList.
+func (in *ClusterInventoryList) DeepCopy() *ClusterInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInventorySpec) DeepCopyInto(out *ClusterInventorySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInventorySpec.
+func (in *ClusterInventorySpec) DeepCopy() *ClusterInventorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInventorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInventoryStatus) DeepCopyInto(out *ClusterInventoryStatus) {
+	*out = *in
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(common.ClusterInventorySummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInventoryStatus.
+func (in *ClusterInventoryStatus) DeepCopy() *ClusterInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisconnectedSpec) DeepCopyInto(out *DisconnectedSpec) {
+	*out = *in
+	in.ActivationSecret.DeepCopyInto(&out.ActivationSecret)
+	in.MarketplacePublicKeySecret.DeepCopyInto(&out.MarketplacePublicKeySecret)
+	if in.ArchiveStorage != nil {
+		in, out := &in.ArchiveStorage, &out.ArchiveStorage
+		*out = new(StorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisconnectedSpec.
+func (in *DisconnectedSpec) DeepCopy() *DisconnectedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DisconnectedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetSpec) DeepCopyInto(out *FleetSpec) {
+	*out = *in
+	in.HubKubeconfigSecret.DeepCopyInto(&out.HubKubeconfigSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetSpec.
+func (in *FleetSpec) DeepCopy() *FleetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Header) DeepCopyInto(out *Header) {
 	{
@@ -162,6 +313,31 @@ func (in *Iam) DeepCopy() *Iam {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesMonitoringSpec) DeepCopyInto(out *KubernetesMonitoringSpec) {
+	*out = *in
+	if in.KubeStateMetrics != nil {
+		in, out := &in.KubeStateMetrics, &out.KubeStateMetrics
+		*out = new(MonitoredService)
+		**out = **in
+	}
+	if in.Kubelet != nil {
+		in, out := &in.Kubelet, &out.Kubelet
+		*out = new(MonitoredService)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesMonitoringSpec.
+func (in *KubernetesMonitoringSpec) DeepCopy() *KubernetesMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Log) DeepCopyInto(out *Log) {
 	{
@@ -183,6 +359,115 @@ func (in Log) DeepCopy() Log {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterStatus) DeepCopyInto(out *ManagedClusterStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterStatus.
+func (in *ManagedClusterStatus) DeepCopy() *ManagedClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagedClusterStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterStatusList) DeepCopyInto(out *ManagedClusterStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ManagedClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterStatusList.
+func (in *ManagedClusterStatusList) DeepCopy() *ManagedClusterStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagedClusterStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterStatusSpec) DeepCopyInto(out *ManagedClusterStatusSpec) {
+	*out = *in
+	if in.LastReportTime != nil {
+		in, out := &in.LastReportTime, &out.LastReportTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterStatusSpec.
+func (in *ManagedClusterStatusSpec) DeepCopy() *ManagedClusterStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterStatusStatus) DeepCopyInto(out *ManagedClusterStatusStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterStatusStatus.
+func (in *ManagedClusterStatusStatus) DeepCopy() *ManagedClusterStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MarketplaceConfig) DeepCopyInto(out *MarketplaceConfig) {
 	*out = *in
@@ -265,6 +550,16 @@ func (in *MarketplaceConfigSpec) DeepCopyInto(out *MarketplaceConfigSpec) {
 		*out = new(common.Features)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Disconnected != nil {
+		in, out := &in.Disconnected, &out.Disconnected
+		*out = new(DisconnectedSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fleet != nil {
+		in, out := &in.Fleet, &out.Fleet
+		*out = new(FleetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MarketplaceConfigSpec.
@@ -301,6 +596,10 @@ func (in *MarketplaceConfigStatus) DeepCopyInto(out *MarketplaceConfigStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PullSecretExpiration != nil {
+		in, out := &in.PullSecretExpiration, &out.PullSecretExpiration
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MarketplaceConfigStatus.
@@ -380,11 +679,31 @@ func (in *MeterBaseSpec) DeepCopyInto(out *MeterBaseSpec) {
 		*out = new(PrometheusSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalPrometheus != nil {
+		in, out := &in.ExternalPrometheus, &out.ExternalPrometheus
+		*out = new(common.ExternalPrometheus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AdditionalScrapeConfigs != nil {
 		in, out := &in.AdditionalScrapeConfigs, &out.AdditionalScrapeConfigs
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.KubernetesMonitoring != nil {
+		in, out := &in.KubernetesMonitoring, &out.KubernetesMonitoring
+		*out = new(KubernetesMonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScrapeSources != nil {
+		in, out := &in.ScrapeSources, &out.ScrapeSources
+		*out = new(ScrapeSourcesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScrapeLimits != nil {
+		in, out := &in.ScrapeLimits, &out.ScrapeLimits
+		*out = new(ScrapeLimitsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeterBaseSpec.
@@ -432,6 +751,21 @@ func (in *MeterBaseStatus) DeepCopyInto(out *MeterBaseStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ScrapeSources != nil {
+		in, out := &in.ScrapeSources, &out.ScrapeSources
+		*out = make([]ScrapeSourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrometheusReplicas != nil {
+		in, out := &in.PrometheusReplicas, &out.PrometheusReplicas
+		*out = make([]PrometheusReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(PrometheusStorageStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeterBaseStatus.
@@ -663,6 +997,11 @@ func (in *MeterReportSpec) DeepCopyInto(out *MeterReportSpec) {
 		*out = new(common.ServiceReference)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalPrometheus != nil {
+		in, out := &in.ExternalPrometheus, &out.ExternalPrometheus
+		*out = new(common.ExternalPrometheus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MeterDefinitions != nil {
 		in, out := &in.MeterDefinitions, &out.MeterDefinitions
 		*out = make([]MeterDefinition, len(*in))
@@ -717,6 +1056,26 @@ func (in *MeterReportStatus) DeepCopyInto(out *MeterReportStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MeterDefinitionSnapshot != nil {
+		in, out := &in.MeterDefinitionSnapshot, &out.MeterDefinitionSnapshot
+		*out = new(common.MeterDefinitionSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterInventory != nil {
+		in, out := &in.ClusterInventory, &out.ClusterInventory
+		*out = new(common.ClusterInventorySummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]common.UsageTotal, len(*in))
+		copy(*out, *in)
+	}
+	if in.QueuePosition != nil {
+		in, out := &in.QueuePosition, &out.QueuePosition
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeterReportStatus.
@@ -730,66 +1089,22 @@ func (in *MeterReportStatus) DeepCopy() *MeterReportStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
+func (in *MonitoredService) DeepCopyInto(out *MonitoredService) {
 	*out = *in
-	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	in.Storage.DeepCopyInto(&out.Storage)
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSpec.
-func (in *PrometheusSpec) DeepCopy() *PrometheusSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoredService.
+func (in *MonitoredService) DeepCopy() *MonitoredService {
 	if in == nil {
 		return nil
 	}
-	out := new(PrometheusSpec)
+	out := new(MonitoredService)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeConfigurationValues) DeepCopyInto(out *RazeeConfigurationValues) {
-	*out = *in
-	if in.IbmCosReaderKey != nil {
-		in, out := &in.IbmCosReaderKey, &out.IbmCosReaderKey
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.RazeeDashOrgKey != nil {
-		in, out := &in.RazeeDashOrgKey, &out.RazeeDashOrgKey
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.FileSourceURL != nil {
-		in, out := &in.FileSourceURL, &out.FileSourceURL
-		*out = new(string)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeConfigurationValues.
-func (in *RazeeConfigurationValues) DeepCopy() *RazeeConfigurationValues {
-	if in == nil {
-		return nil
-	}
-	out := new(RazeeConfigurationValues)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeDeployment) DeepCopyInto(out *RazeeDeployment) {
+func (in *OperatorGroupPolicy) DeepCopyInto(out *OperatorGroupPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -797,18 +1112,18 @@ func (in *RazeeDeployment) DeepCopyInto(out *RazeeDeployment) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeployment.
-func (in *RazeeDeployment) DeepCopy() *RazeeDeployment {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorGroupPolicy.
+func (in *OperatorGroupPolicy) DeepCopy() *OperatorGroupPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(RazeeDeployment)
+	out := new(OperatorGroupPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RazeeDeployment) DeepCopyObject() runtime.Object {
+func (in *OperatorGroupPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -816,31 +1131,31 @@ func (in *RazeeDeployment) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeDeploymentList) DeepCopyInto(out *RazeeDeploymentList) {
+func (in *OperatorGroupPolicyList) DeepCopyInto(out *OperatorGroupPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]RazeeDeployment, len(*in))
+		*out = make([]OperatorGroupPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeploymentList.
-func (in *RazeeDeploymentList) DeepCopy() *RazeeDeploymentList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorGroupPolicyList.
+func (in *OperatorGroupPolicyList) DeepCopy() *OperatorGroupPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(RazeeDeploymentList)
+	out := new(OperatorGroupPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RazeeDeploymentList) DeepCopyObject() runtime.Object {
+func (in *OperatorGroupPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -848,52 +1163,32 @@ func (in *RazeeDeploymentList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeDeploymentSpec) DeepCopyInto(out *RazeeDeploymentSpec) {
+func (in *OperatorGroupPolicySpec) DeepCopyInto(out *OperatorGroupPolicySpec) {
 	*out = *in
-	if in.DeploySecretName != nil {
-		in, out := &in.DeploySecretName, &out.DeploySecretName
-		*out = new(string)
-		**out = **in
-	}
-	if in.TargetNamespace != nil {
-		in, out := &in.TargetNamespace, &out.TargetNamespace
-		*out = new(string)
-		**out = **in
-	}
-	if in.DeployConfig != nil {
-		in, out := &in.DeployConfig, &out.DeployConfig
-		*out = new(RazeeConfigurationValues)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ChildUrl != nil {
-		in, out := &in.ChildUrl, &out.ChildUrl
-		*out = new(string)
-		**out = **in
+	if in.TargetNamespaces != nil {
+		in, out := &in.TargetNamespaces, &out.TargetNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.LegacyUninstallHasRun != nil {
-		in, out := &in.LegacyUninstallHasRun, &out.LegacyUninstallHasRun
+	if in.AdoptExisting != nil {
+		in, out := &in.AdoptExisting, &out.AdoptExisting
 		*out = new(bool)
 		**out = **in
 	}
-	if in.Features != nil {
-		in, out := &in.Features, &out.Features
-		*out = new(common.Features)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeploymentSpec.
-func (in *RazeeDeploymentSpec) DeepCopy() *RazeeDeploymentSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorGroupPolicySpec.
+func (in *OperatorGroupPolicySpec) DeepCopy() *OperatorGroupPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RazeeDeploymentSpec)
+	out := new(OperatorGroupPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeDeploymentStatus) DeepCopyInto(out *RazeeDeploymentStatus) {
+func (in *OperatorGroupPolicyStatus) DeepCopyInto(out *OperatorGroupPolicyStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -902,97 +1197,20 @@ func (in *RazeeDeploymentStatus) DeepCopyInto(out *RazeeDeploymentStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.JobConditions != nil {
-		in, out := &in.JobConditions, &out.JobConditions
-		*out = new(batchv1.JobCondition)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.JobState != nil {
-		in, out := &in.JobState, &out.JobState
-		*out = new(batchv1.JobStatus)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.MissingDeploySecretValues != nil {
-		in, out := &in.MissingDeploySecretValues, &out.MissingDeploySecretValues
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.RazeePrerequisitesCreated != nil {
-		in, out := &in.RazeePrerequisitesCreated, &out.RazeePrerequisitesCreated
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.LocalSecretVarsPopulated != nil {
-		in, out := &in.LocalSecretVarsPopulated, &out.LocalSecretVarsPopulated
-		*out = new(bool)
-		**out = **in
-	}
-	if in.RedHatMarketplaceSecretFound != nil {
-		in, out := &in.RedHatMarketplaceSecretFound, &out.RedHatMarketplaceSecretFound
-		*out = new(bool)
-		**out = **in
-	}
-	if in.RazeeJobInstall != nil {
-		in, out := &in.RazeeJobInstall, &out.RazeeJobInstall
-		*out = new(RazeeJobInstallStruct)
-		**out = **in
-	}
-	if in.NodesFromRazeeDeployments != nil {
-		in, out := &in.NodesFromRazeeDeployments, &out.NodesFromRazeeDeployments
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeploymentStatus.
-func (in *RazeeDeploymentStatus) DeepCopy() *RazeeDeploymentStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(RazeeDeploymentStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeJobInstallStruct) DeepCopyInto(out *RazeeJobInstallStruct) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeJobInstallStruct.
-func (in *RazeeJobInstallStruct) DeepCopy() *RazeeJobInstallStruct {
-	if in == nil {
-		return nil
-	}
-	out := new(RazeeJobInstallStruct)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RazeeLogs) DeepCopyInto(out *RazeeLogs) {
-	*out = *in
-	if in.Log != nil {
-		in, out := &in.Log, &out.Log
-		*out = make(Log, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeLogs.
-func (in *RazeeLogs) DeepCopy() *RazeeLogs {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorGroupPolicyStatus.
+func (in *OperatorGroupPolicyStatus) DeepCopy() *OperatorGroupPolicyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RazeeLogs)
+	out := new(OperatorGroupPolicyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteResourceS3) DeepCopyInto(out *RemoteResourceS3) {
+func (in *ProductInstall) DeepCopyInto(out *ProductInstall) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1000,18 +1218,18 @@ func (in *RemoteResourceS3) DeepCopyInto(out *RemoteResourceS3) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3.
-func (in *RemoteResourceS3) DeepCopy() *RemoteResourceS3 {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductInstall.
+func (in *ProductInstall) DeepCopy() *ProductInstall {
 	if in == nil {
 		return nil
 	}
-	out := new(RemoteResourceS3)
+	out := new(ProductInstall)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RemoteResourceS3) DeepCopyObject() runtime.Object {
+func (in *ProductInstall) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1019,31 +1237,31 @@ func (in *RemoteResourceS3) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteResourceS3List) DeepCopyInto(out *RemoteResourceS3List) {
+func (in *ProductInstallList) DeepCopyInto(out *ProductInstallList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]RemoteResourceS3, len(*in))
+		*out = make([]ProductInstall, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3List.
-func (in *RemoteResourceS3List) DeepCopy() *RemoteResourceS3List {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductInstallList.
+func (in *ProductInstallList) DeepCopy() *ProductInstallList {
 	if in == nil {
 		return nil
 	}
-	out := new(RemoteResourceS3List)
+	out := new(ProductInstallList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RemoteResourceS3List) DeepCopyObject() runtime.Object {
+func (in *ProductInstallList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1051,155 +1269,1116 @@ func (in *RemoteResourceS3List) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteResourceS3Spec) DeepCopyInto(out *RemoteResourceS3Spec) {
+func (in *ProductInstallSpec) DeepCopyInto(out *ProductInstallSpec) {
 	*out = *in
-	in.Auth.DeepCopyInto(&out.Auth)
-	if in.Requests != nil {
-		in, out := &in.Requests, &out.Requests
-		*out = make([]Request, len(*in))
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]ProductOperator, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3Spec.
-func (in *RemoteResourceS3Spec) DeepCopy() *RemoteResourceS3Spec {
-	if in == nil {
-		return nil
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
 	}
-	out := new(RemoteResourceS3Spec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteResourceS3Status) DeepCopyInto(out *RemoteResourceS3Status) {
-	*out = *in
-	if in.Touched != nil {
-		in, out := &in.Touched, &out.Touched
+	if in.RollbackOnFailure != nil {
+		in, out := &in.RollbackOnFailure, &out.RollbackOnFailure
 		*out = new(bool)
 		**out = **in
 	}
-	in.RazeeLogs.DeepCopyInto(&out.RazeeLogs)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3Status.
-func (in *RemoteResourceS3Status) DeepCopy() *RemoteResourceS3Status {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductInstallSpec.
+func (in *ProductInstallSpec) DeepCopy() *ProductInstallSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RemoteResourceS3Status)
+	out := new(ProductInstallSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Request) DeepCopyInto(out *Request) {
+func (in *ProductInstallStatus) DeepCopyInto(out *ProductInstallStatus) {
 	*out = *in
-	in.Options.DeepCopyInto(&out.Options)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]ProductOperatorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Request.
-func (in *Request) DeepCopy() *Request {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductInstallStatus.
+func (in *ProductInstallStatus) DeepCopy() *ProductInstallStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Request)
+	out := new(ProductInstallStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3Options) DeepCopyInto(out *S3Options) {
+func (in *ProductOperator) DeepCopyInto(out *ProductOperator) {
 	*out = *in
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]Header, len(*in))
-		for key, val := range *in {
-			var outVal map[string]string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(Header, len(*in))
-				for key, val := range *in {
-					(*out)[key] = val
-				}
-			}
-			(*out)[key] = outVal
-		}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Options.
-func (in *S3Options) DeepCopy() *S3Options {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductOperator.
+func (in *ProductOperator) DeepCopy() *ProductOperator {
 	if in == nil {
 		return nil
 	}
-	out := new(S3Options)
+	out := new(ProductOperator)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SecretAccessKeyRef) DeepCopyInto(out *SecretAccessKeyRef) {
+func (in *ProductOperatorStatus) DeepCopyInto(out *ProductOperatorStatus) {
 	*out = *in
-	in.ValueFrom.DeepCopyInto(&out.ValueFrom)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretAccessKeyRef.
-func (in *SecretAccessKeyRef) DeepCopy() *SecretAccessKeyRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductOperatorStatus.
+func (in *ProductOperatorStatus) DeepCopy() *ProductOperatorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretAccessKeyRef)
+	out := new(ProductOperatorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
+func (in *PrometheusReplicaStatus) DeepCopyInto(out *PrometheusReplicaStatus) {
 	*out = *in
-	if in.Class != nil {
-		in, out := &in.Class, &out.Class
-		*out = new(string)
-		**out = **in
-	}
-	out.Size = in.Size.DeepCopy()
-	if in.EmptyDir != nil {
-		in, out := &in.EmptyDir, &out.EmptyDir
-		*out = new(v1.EmptyDirVolumeSource)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
-func (in *StorageSpec) DeepCopy() *StorageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusReplicaStatus.
+func (in *PrometheusReplicaStatus) DeepCopy() *PrometheusReplicaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(StorageSpec)
+	out := new(PrometheusReplicaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ValueFrom) DeepCopyInto(out *ValueFrom) {
+func (in *PrometheusSpec) DeepCopyInto(out *PrometheusSpec) {
 	*out = *in
-	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueFrom.
-func (in *ValueFrom) DeepCopy() *ValueFrom {
-	if in == nil {
-		return nil
-	}
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		*out = make([]RemoteWriteSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteRead != nil {
+		in, out := &in.RemoteRead, &out.RemoteRead
+		*out = make([]RemoteReadSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SeriesBudget != nil {
+		in, out := &in.SeriesBudget, &out.SeriesBudget
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusSpec.
+func (in *PrometheusSpec) DeepCopy() *PrometheusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusStorageStatus) DeepCopyInto(out *PrometheusStorageStatus) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LastGrowthTime != nil {
+		in, out := &in.LastGrowthTime, &out.LastGrowthTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusStorageStatus.
+func (in *PrometheusStorageStatus) DeepCopy() *PrometheusStorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusStorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Rate) DeepCopyInto(out *Rate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rate.
+func (in *Rate) DeepCopy() *Rate {
+	if in == nil {
+		return nil
+	}
+	out := new(Rate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateCard) DeepCopyInto(out *RateCard) {
+	*out = *in
+	if in.Rates != nil {
+		in, out := &in.Rates, &out.Rates
+		*out = make([]Rate, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateCard.
+func (in *RateCard) DeepCopy() *RateCard {
+	if in == nil {
+		return nil
+	}
+	out := new(RateCard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeConfigurationValues) DeepCopyInto(out *RazeeConfigurationValues) {
+	*out = *in
+	if in.IbmCosReaderKey != nil {
+		in, out := &in.IbmCosReaderKey, &out.IbmCosReaderKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RazeeDashOrgKey != nil {
+		in, out := &in.RazeeDashOrgKey, &out.RazeeDashOrgKey
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FileSourceURL != nil {
+		in, out := &in.FileSourceURL, &out.FileSourceURL
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeConfigurationValues.
+func (in *RazeeConfigurationValues) DeepCopy() *RazeeConfigurationValues {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeConfigurationValues)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeDeployment) DeepCopyInto(out *RazeeDeployment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeployment.
+func (in *RazeeDeployment) DeepCopy() *RazeeDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RazeeDeployment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeDeploymentList) DeepCopyInto(out *RazeeDeploymentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RazeeDeployment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeploymentList.
+func (in *RazeeDeploymentList) DeepCopy() *RazeeDeploymentList {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeDeploymentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RazeeDeploymentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeDeploymentSpec) DeepCopyInto(out *RazeeDeploymentSpec) {
+	*out = *in
+	if in.DeploySecretName != nil {
+		in, out := &in.DeploySecretName, &out.DeploySecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TargetNamespace != nil {
+		in, out := &in.TargetNamespace, &out.TargetNamespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeployConfig != nil {
+		in, out := &in.DeployConfig, &out.DeployConfig
+		*out = new(RazeeConfigurationValues)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChildUrl != nil {
+		in, out := &in.ChildUrl, &out.ChildUrl
+		*out = new(string)
+		**out = **in
+	}
+	if in.LegacyUninstallHasRun != nil {
+		in, out := &in.LegacyUninstallHasRun, &out.LegacyUninstallHasRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = new(common.Features)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeploymentSpec.
+func (in *RazeeDeploymentSpec) DeepCopy() *RazeeDeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeDeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeDeploymentStatus) DeepCopyInto(out *RazeeDeploymentStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JobConditions != nil {
+		in, out := &in.JobConditions, &out.JobConditions
+		*out = new(batchv1.JobCondition)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobState != nil {
+		in, out := &in.JobState, &out.JobState
+		*out = new(batchv1.JobStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MissingDeploySecretValues != nil {
+		in, out := &in.MissingDeploySecretValues, &out.MissingDeploySecretValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RazeePrerequisitesCreated != nil {
+		in, out := &in.RazeePrerequisitesCreated, &out.RazeePrerequisitesCreated
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LocalSecretVarsPopulated != nil {
+		in, out := &in.LocalSecretVarsPopulated, &out.LocalSecretVarsPopulated
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RedHatMarketplaceSecretFound != nil {
+		in, out := &in.RedHatMarketplaceSecretFound, &out.RedHatMarketplaceSecretFound
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RazeeJobInstall != nil {
+		in, out := &in.RazeeJobInstall, &out.RazeeJobInstall
+		*out = new(RazeeJobInstallStruct)
+		**out = **in
+	}
+	if in.NodesFromRazeeDeployments != nil {
+		in, out := &in.NodesFromRazeeDeployments, &out.NodesFromRazeeDeployments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeDeploymentStatus.
+func (in *RazeeDeploymentStatus) DeepCopy() *RazeeDeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeDeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeJobInstallStruct) DeepCopyInto(out *RazeeJobInstallStruct) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeJobInstallStruct.
+func (in *RazeeJobInstallStruct) DeepCopy() *RazeeJobInstallStruct {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeJobInstallStruct)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RazeeLogs) DeepCopyInto(out *RazeeLogs) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = make(Log, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RazeeLogs.
+func (in *RazeeLogs) DeepCopy() *RazeeLogs {
+	if in == nil {
+		return nil
+	}
+	out := new(RazeeLogs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteReadSpec) DeepCopyInto(out *RemoteReadSpec) {
+	*out = *in
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(monitoringv1.TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(monitoringv1.BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerTokenSecret != nil {
+		in, out := &in.BearerTokenSecret, &out.BearerTokenSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteReadSpec.
+func (in *RemoteReadSpec) DeepCopy() *RemoteReadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteReadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteResourceS3) DeepCopyInto(out *RemoteResourceS3) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3.
+func (in *RemoteResourceS3) DeepCopy() *RemoteResourceS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteResourceS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemoteResourceS3) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteResourceS3List) DeepCopyInto(out *RemoteResourceS3List) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RemoteResourceS3, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3List.
+func (in *RemoteResourceS3List) DeepCopy() *RemoteResourceS3List {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteResourceS3List)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemoteResourceS3List) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteResourceS3Spec) DeepCopyInto(out *RemoteResourceS3Spec) {
+	*out = *in
+	in.Auth.DeepCopyInto(&out.Auth)
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]Request, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(Verification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3Spec.
+func (in *RemoteResourceS3Spec) DeepCopy() *RemoteResourceS3Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteResourceS3Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteResourceS3Status) DeepCopyInto(out *RemoteResourceS3Status) {
+	*out = *in
+	if in.Touched != nil {
+		in, out := &in.Touched, &out.Touched
+		*out = new(bool)
+		**out = **in
+	}
+	in.RazeeLogs.DeepCopyInto(&out.RazeeLogs)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]AppliedObject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]RequestStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceS3Status.
+func (in *RemoteResourceS3Status) DeepCopy() *RemoteResourceS3Status {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteResourceS3Status)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteWriteSpec) DeepCopyInto(out *RemoteWriteSpec) {
+	*out = *in
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(monitoringv1.TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(monitoringv1.BasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerTokenSecret != nil {
+		in, out := &in.BearerTokenSecret, &out.BearerTokenSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WriteRelabelConfigs != nil {
+		in, out := &in.WriteRelabelConfigs, &out.WriteRelabelConfigs
+		*out = make([]monitoringv1.RelabelConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteWriteSpec.
+func (in *RemoteWriteSpec) DeepCopy() *RemoteWriteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteWriteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Request) DeepCopyInto(out *Request) {
+	*out = *in
+	in.Options.DeepCopyInto(&out.Options)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Request.
+func (in *Request) DeepCopy() *Request {
+	if in == nil {
+		return nil
+	}
+	out := new(Request)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestStatus) DeepCopyInto(out *RequestStatus) {
+	*out = *in
+	if in.LastFetchTime != nil {
+		in, out := &in.LastFetchTime, &out.LastFetchTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestStatus.
+func (in *RequestStatus) DeepCopy() *RequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Options) DeepCopyInto(out *S3Options) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]Header, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(Header, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Options.
+func (in *S3Options) DeepCopy() *S3Options {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Options)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrapeLimit) DeepCopyInto(out *ScrapeLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrapeLimit.
+func (in *ScrapeLimit) DeepCopy() *ScrapeLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrapeLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrapeLimitsSpec) DeepCopyInto(out *ScrapeLimitsSpec) {
+	*out = *in
+	if in.KubeStateMetrics != nil {
+		in, out := &in.KubeStateMetrics, &out.KubeStateMetrics
+		*out = new(ScrapeLimit)
+		**out = **in
+	}
+	if in.Kubelet != nil {
+		in, out := &in.Kubelet, &out.Kubelet
+		*out = new(ScrapeLimit)
+		**out = **in
+	}
+	if in.MetricState != nil {
+		in, out := &in.MetricState, &out.MetricState
+		*out = new(ScrapeLimit)
+		**out = **in
+	}
+	if in.ScrapeSources != nil {
+		in, out := &in.ScrapeSources, &out.ScrapeSources
+		*out = new(ScrapeLimit)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrapeLimitsSpec.
+func (in *ScrapeLimitsSpec) DeepCopy() *ScrapeLimitsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrapeLimitsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrapeSourceStatus) DeepCopyInto(out *ScrapeSourceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrapeSourceStatus.
+func (in *ScrapeSourceStatus) DeepCopy() *ScrapeSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrapeSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScrapeSourcesSpec) DeepCopyInto(out *ScrapeSourcesSpec) {
+	*out = *in
+	if in.ServiceMonitorSelector != nil {
+		in, out := &in.ServiceMonitorSelector, &out.ServiceMonitorSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodMonitorSelector != nil {
+		in, out := &in.PodMonitorSelector, &out.PodMonitorSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScrapeSourcesSpec.
+func (in *ScrapeSourcesSpec) DeepCopy() *ScrapeSourcesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScrapeSourcesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretAccessKeyRef) DeepCopyInto(out *SecretAccessKeyRef) {
+	*out = *in
+	in.ValueFrom.DeepCopyInto(&out.ValueFrom)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretAccessKeyRef.
+func (in *SecretAccessKeyRef) DeepCopy() *SecretAccessKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretAccessKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutoGrowSpec) DeepCopyInto(out *StorageAutoGrowSpec) {
+	*out = *in
+	if in.UsageThreshold != nil {
+		in, out := &in.UsageThreshold, &out.UsageThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.GrowthPercent != nil {
+		in, out := &in.GrowthPercent, &out.GrowthPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutoGrowSpec.
+func (in *StorageAutoGrowSpec) DeepCopy() *StorageAutoGrowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAutoGrowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
+	*out = *in
+	if in.Class != nil {
+		in, out := &in.Class, &out.Class
+		*out = new(string)
+		**out = **in
+	}
+	out.Size = in.Size.DeepCopy()
+	if in.EmptyDir != nil {
+		in, out := &in.EmptyDir, &out.EmptyDir
+		*out = new(v1.EmptyDirVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoGrow != nil {
+		in, out := &in.AutoGrow, &out.AutoGrow
+		*out = new(StorageAutoGrowSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
+func (in *StorageSpec) DeepCopy() *StorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsagePeriod) DeepCopyInto(out *UsagePeriod) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.MeterDefinitions != nil {
+		in, out := &in.MeterDefinitions, &out.MeterDefinitions
+		*out = make([]UsageTotal, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]UsageTotal, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]UsageTotal, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsagePeriod.
+func (in *UsagePeriod) DeepCopy() *UsagePeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(UsagePeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSummary) DeepCopyInto(out *UsageSummary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSummary.
+func (in *UsageSummary) DeepCopy() *UsageSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UsageSummary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSummaryList) DeepCopyInto(out *UsageSummaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]UsageSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSummaryList.
+func (in *UsageSummaryList) DeepCopy() *UsageSummaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSummaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UsageSummaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSummarySpec) DeepCopyInto(out *UsageSummarySpec) {
+	*out = *in
+	if in.Periods != nil {
+		in, out := &in.Periods, &out.Periods
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RateCard != nil {
+		in, out := &in.RateCard, &out.RateCard
+		*out = new(RateCard)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSummarySpec.
+func (in *UsageSummarySpec) DeepCopy() *UsageSummarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSummarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageSummaryStatus) DeepCopyInto(out *UsageSummaryStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Periods != nil {
+		in, out := &in.Periods, &out.Periods
+		*out = make([]UsagePeriod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageSummaryStatus.
+func (in *UsageSummaryStatus) DeepCopy() *UsageSummaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageSummaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsageTotal) DeepCopyInto(out *UsageTotal) {
+	*out = *in
+	out.UsageTotal = in.UsageTotal
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsageTotal.
+func (in *UsageTotal) DeepCopy() *UsageTotal {
+	if in == nil {
+		return nil
+	}
+	out := new(UsageTotal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueFrom) DeepCopyInto(out *ValueFrom) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueFrom.
+func (in *ValueFrom) DeepCopy() *ValueFrom {
+	if in == nil {
+		return nil
+	}
 	out := new(ValueFrom)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Verification) DeepCopyInto(out *Verification) {
+	*out = *in
+	in.PublicKeyRef.DeepCopyInto(&out.PublicKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Verification.
+func (in *Verification) DeepCopy() *Verification {
+	if in == nil {
+		return nil
+	}
+	out := new(Verification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workload) DeepCopyInto(out *Workload) {
 	*out = *in
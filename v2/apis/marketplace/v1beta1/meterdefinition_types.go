@@ -18,6 +18,9 @@ package v1beta1
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
 	"strconv"
 
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
@@ -218,6 +221,12 @@ type MeterDefinitionStatus struct {
 	// this meter definition
 	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
 	WorkloadResources []common.WorkloadResource `json:"workloadResource,omitempty"`
+
+	// SeriesCount is the number of Prometheus head series of the metrics
+	// used by the meter queries.
+	// +operator-sdk:gen-csv:customresourcedefinitions.statusDescriptors=true
+	// +optional
+	SeriesCount *int64 `json:"seriesCount,omitempty"`
 }
 
 // MeterDefinition defines the meter workloads used to enable pay for
@@ -291,6 +300,34 @@ func (meterdef *MeterDefinition) ToPrometheusLabels() []*common.MeterDefPromethe
 	return allMdefs
 }
 
+// SpecHash returns a sha256 hash of the meter definition spec. It is used to
+// identify which revision of a meter definition was used for a report.
+func (meterdef *MeterDefinition) SpecHash() (string, error) {
+	data, err := json.Marshal(meterdef.Spec)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// ToReference returns a reference to this revision of the meter definition.
+func (meterdef *MeterDefinition) ToReference() (*common.MeterDefinitionReference, error) {
+	hash, err := meterdef.SpecHash()
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.MeterDefinitionReference{
+		Namespace:       meterdef.Namespace,
+		Name:            meterdef.Name,
+		UID:             meterdef.UID,
+		ResourceVersion: meterdef.ResourceVersion,
+		Generation:      meterdef.Generation,
+		SpecHash:        hash,
+	}, nil
+}
+
 func (meterdef *MeterDefinition) BuildMeterDefinitionFromString(
 	meterdefString, name, namespace, nameLabel, namespaceLabel string) error {
 	data := []byte(meterdefString)
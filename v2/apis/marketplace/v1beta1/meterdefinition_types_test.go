@@ -50,4 +50,24 @@ spec:
 		err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(mdefYaml)), 100).Decode(mdef)
 		Expect(err).To(Succeed())
 	})
+
+	It("should hash the spec", func() {
+		mdef := &MeterDefinition{}
+		err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(mdefYaml)), 100).Decode(mdef)
+		Expect(err).To(Succeed())
+
+		hash, err := mdef.SpecHash()
+		Expect(err).To(Succeed())
+		Expect(hash).To(HaveLen(64))
+
+		mdef.ResourceVersion = "2"
+		sameHash, err := mdef.SpecHash()
+		Expect(err).To(Succeed())
+		Expect(sameHash).To(Equal(hash))
+
+		mdef.Spec.Meters[0].Query = "foo"
+		newHash, err := mdef.SpecHash()
+		Expect(err).To(Succeed())
+		Expect(newHash).ToNot(Equal(hash))
+	})
 })
@@ -0,0 +1,319 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+	nodeRoleMaster = "master"
+	nodeRoleInfra  = "infra"
+	nodeRoleWorker = "worker"
+)
+
+// blank assignment to verify that ClusterInventoryReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ClusterInventoryReconciler{}
+
+// ClusterInventoryReconciler keeps the ClusterInventory of the cluster up to
+// date with its nodes, versions and marketplace operators.
+type ClusterInventoryReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	cfg *config.OperatorConfig
+}
+
+// Reconcile collects the inventory and writes it to the ClusterInventory status.
+func (r *ClusterInventoryReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ClusterInventory")
+
+	instance := &marketplacev1alpha1.ClusterInventory{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+
+		instance = &marketplacev1alpha1.ClusterInventory{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      request.Name,
+				Namespace: request.Namespace,
+			},
+		}
+
+		if err := r.Client.Create(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "failed to create clusterinventory")
+			return reconcile.Result{}, err
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	operators, err := r.installedOperators()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	inventory := clusterInventorySummary(nodes.Items, operators, r.cfg.Infrastructure)
+
+	if instance.Status.Inventory != nil && equality.Semantic.DeepEqual(*instance.Status.Inventory, inventory) {
+		reqLogger.Info("inventory unchanged")
+		return reconcile.Result{}, nil
+	}
+
+	instance.Status.Inventory = &inventory
+	instance.Status.LastUpdateTime = &metav1.Time{Time: time.Now()}
+
+	if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+		reqLogger.Error(err, "failed to update status")
+		return reconcile.Result{}, err
+	}
+
+	reqLogger.Info("updated inventory", "nodes", inventory.NodeCount, "operators", len(inventory.Operators))
+	return reconcile.Result{}, nil
+}
+
+// installedOperators returns the operators installed with a marketplace subscription.
+func (r *ClusterInventoryReconciler) installedOperators() ([]common.InstalledOperator, error) {
+	subs := &olmv1alpha1.SubscriptionList{}
+	err := r.Client.List(context.TODO(), subs, client.MatchingLabels{operatorTag: "true"})
+	if err != nil {
+		return nil, err
+	}
+
+	operators := make([]common.InstalledOperator, 0, len(subs.Items))
+
+	for _, sub := range subs.Items {
+		operator := common.InstalledOperator{
+			Namespace: sub.Namespace,
+		}
+
+		if sub.Spec != nil {
+			operator.Package = sub.Spec.Package
+		}
+
+		if installed := sub.Status.InstalledCSV; installed != "" {
+			operator.CSV = installed
+
+			csv := &olmv1alpha1.ClusterServiceVersion{}
+			err := r.Client.Get(context.TODO(), types.NamespacedName{Name: installed, Namespace: sub.Namespace}, csv)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+			if err == nil {
+				operator.Version = csv.Spec.Version.String()
+			}
+		}
+
+		operators = append(operators, operator)
+	}
+
+	sort.Slice(operators, func(i, j int) bool {
+		if operators[i].Namespace != operators[j].Namespace {
+			return operators[i].Namespace < operators[j].Namespace
+		}
+		return operators[i].Package < operators[j].Package
+	})
+
+	return operators, nil
+}
+
+// clusterInventorySummary returns the inventory of the nodes and operators.
+func clusterInventorySummary(
+	nodes []corev1.Node,
+	operators []common.InstalledOperator,
+	infrastructure *config.Infrastructure,
+) common.ClusterInventorySummary {
+	summary := common.ClusterInventorySummary{
+		NodeCount: len(nodes),
+		Operators: operators,
+	}
+
+	if infrastructure != nil {
+		summary.KubernetesVersion = infrastructure.KubernetesVersion()
+		summary.KubernetesPlatform = infrastructure.KubernetesPlatform()
+		summary.OpenshiftVersion = infrastructure.OpenshiftVersion()
+	}
+
+	roles := map[string]*common.NodeRoleCapacity{}
+	architectures := map[string]int{}
+
+	for _, node := range nodes {
+		role := nodeRole(&node)
+
+		capacity, ok := roles[role]
+		if !ok {
+			capacity = &common.NodeRoleCapacity{
+				Role:   role,
+				CPU:    resource.MustParse("0"),
+				Memory: resource.MustParse("0"),
+			}
+			roles[role] = capacity
+		}
+
+		capacity.NodeCount++
+		capacity.CPU.Add(*node.Status.Capacity.Cpu())
+		capacity.Memory.Add(*node.Status.Capacity.Memory())
+
+		architectures[node.Status.NodeInfo.Architecture]++
+	}
+
+	for _, capacity := range roles {
+		summary.NodeRoles = append(summary.NodeRoles, *capacity)
+	}
+	sort.Slice(summary.NodeRoles, func(i, j int) bool {
+		return summary.NodeRoles[i].Role < summary.NodeRoles[j].Role
+	})
+
+	for arch, count := range architectures {
+		summary.Architectures = append(summary.Architectures, common.ArchitectureCount{
+			Architecture: arch,
+			NodeCount:    count,
+		})
+	}
+	sort.Slice(summary.Architectures, func(i, j int) bool {
+		return summary.Architectures[i].Architecture < summary.Architectures[j].Architecture
+	})
+
+	return summary
+}
+
+// nodeRole returns the role a node is counted under. A node with several
+// roles is counted once, masters first and infra nodes second.
+func nodeRole(node *corev1.Node) string {
+	roles := map[string]bool{}
+	for label := range node.GetLabels() {
+		if strings.HasPrefix(label, nodeRoleLabelPrefix) {
+			roles[strings.TrimPrefix(label, nodeRoleLabelPrefix)] = true
+		}
+	}
+
+	switch {
+	case roles[nodeRoleMaster] || roles["control-plane"]:
+		return nodeRoleMaster
+	case roles[nodeRoleInfra]:
+		return nodeRoleInfra
+	default:
+		return nodeRoleWorker
+	}
+}
+
+func (r *ClusterInventoryReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *ClusterInventoryReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
+}
+
+func (r *ClusterInventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// every change maps to the single inventory in the operator namespace
+	mapFn := handler.ToRequestsFunc(
+		func(a handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{
+					Name:      utils.CLUSTER_INVENTORY_NAME,
+					Namespace: r.cfg.DeployedNamespace,
+				}},
+			}
+		})
+
+	nodePreds := predicate.Funcs{
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			oldNode, ok := evt.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := evt.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+
+			return nodeRole(oldNode) != nodeRole(newNode) ||
+				!equality.Semantic.DeepEqual(oldNode.Status.Capacity, newNode.Status.Capacity) ||
+				oldNode.Status.NodeInfo.Architecture != newNode.Status.NodeInfo.Architecture
+		},
+	}
+
+	subPreds := predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return evt.Meta.GetLabels()[operatorTag] == "true"
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return evt.MetaNew.GetLabels()[operatorTag] == "true" ||
+				evt.MetaOld.GetLabels()[operatorTag] == "true"
+		},
+		DeleteFunc: func(evt event.DeleteEvent) bool {
+			return evt.Meta.GetLabels()[operatorTag] == "true"
+		},
+		GenericFunc: func(evt event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&marketplacev1alpha1.ClusterInventory{}).
+		Watches(
+			&source.Kind{Type: &corev1.Node{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: mapFn,
+			},
+			builder.WithPredicates(nodePreds)).
+		Watches(
+			&source.Kind{Type: &olmv1alpha1.Subscription{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: mapFn,
+			},
+			builder.WithPredicates(subPreds)).
+		Complete(r)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ClusterInventoryController", func() {
+	node := func(name, arch, cpu, memory string, roles ...string) corev1.Node {
+		labels := map[string]string{}
+		for _, role := range roles {
+			labels[nodeRoleLabelPrefix+role] = ""
+		}
+
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+				NodeInfo: corev1.NodeSystemInfo{Architecture: arch},
+			},
+		}
+	}
+
+	It("should count a node under a single role", func() {
+		Expect(nodeRole(&corev1.Node{})).To(Equal(nodeRoleWorker))
+
+		n := node("a", "amd64", "1", "1Gi", "master", "worker")
+		Expect(nodeRole(&n)).To(Equal(nodeRoleMaster))
+
+		n = node("a", "amd64", "1", "1Gi", "infra", "worker")
+		Expect(nodeRole(&n)).To(Equal(nodeRoleInfra))
+	})
+
+	It("should summarize the nodes by role and architecture", func() {
+		operators := []common.InstalledOperator{
+			{Package: "foo", Namespace: "bar", CSV: "foo.v1.0.0", Version: "1.0.0"},
+		}
+
+		summary := clusterInventorySummary([]corev1.Node{
+			node("master-0", "amd64", "4", "16Gi", "master"),
+			node("worker-0", "amd64", "8", "32Gi", "worker"),
+			node("worker-1", "s390x", "8", "32Gi", "worker"),
+		}, operators, nil)
+
+		Expect(summary.NodeCount).To(Equal(3))
+		Expect(summary.Operators).To(Equal(operators))
+
+		Expect(summary.NodeRoles).To(HaveLen(2))
+		Expect(summary.NodeRoles[0].Role).To(Equal(nodeRoleMaster))
+		Expect(summary.NodeRoles[0].NodeCount).To(Equal(1))
+		Expect(summary.NodeRoles[1].Role).To(Equal(nodeRoleWorker))
+		Expect(summary.NodeRoles[1].NodeCount).To(Equal(2))
+		Expect(summary.NodeRoles[1].CPU.Cmp(resource.MustParse("16"))).To(Equal(0))
+		Expect(summary.NodeRoles[1].Memory.Cmp(resource.MustParse("64Gi"))).To(Equal(0))
+
+		Expect(summary.Architectures).To(Equal([]common.ArchitectureCount{
+			{Architecture: "amd64", NodeCount: 2},
+			{Architecture: "s390x", NodeCount: 1},
+		}))
+	})
+})
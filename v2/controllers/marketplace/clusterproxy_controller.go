@@ -0,0 +1,108 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// clusterProxyName is the name of the OpenShift cluster proxy
+const clusterProxyName = "cluster"
+
+// blank assignment to verify that ClusterProxyReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ClusterProxyReconciler{}
+
+// ClusterProxyReconciler keeps the proxy of the infrastructure in sync with
+// the OpenShift cluster proxy. The shared outbound transport and the pods
+// created afterwards use the new proxy.
+type ClusterProxyReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	cfg *config.OperatorConfig
+}
+
+// Reconcile reads the cluster proxy and stores it on the infrastructure.
+func (r *ClusterProxyReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Name", request.Name)
+	reqLogger.Info("Reconciling cluster proxy")
+
+	proxy := &openshiftconfigv1.Proxy{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, proxy)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			r.cfg.Infrastructure.SetProxy(nil)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	r.cfg.Infrastructure.SetProxy(transport.ProxyFromClusterProxy(proxy))
+	return reconcile.Result{}, nil
+}
+
+func (r *ClusterProxyReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *ClusterProxyReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
+}
+
+func (r *ClusterProxyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the proxy api only exists on openshift
+	if !r.cfg.Infrastructure.HasOpenshift() {
+		return nil
+	}
+
+	isClusterProxy := predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return evt.Meta.GetName() == clusterProxyName
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return evt.MetaNew.GetName() == clusterProxyName
+		},
+		DeleteFunc: func(evt event.DeleteEvent) bool {
+			return evt.Meta.GetName() == clusterProxyName
+		},
+		GenericFunc: func(evt event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusterproxy").
+		For(&openshiftconfigv1.Proxy{}, builder.WithPredicates(isClusterProxy)).
+		Complete(r)
+}
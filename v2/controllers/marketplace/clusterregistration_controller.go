@@ -17,7 +17,9 @@ package marketplace
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
@@ -27,18 +29,23 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // blank assignment to verify that ReconcileClusterRegistration implements reconcile.Reconciler
@@ -52,7 +59,9 @@ type ClusterRegistrationReconciler struct {
 	Scheme *runtime.Scheme
 	Log    logr.Logger
 
-	cfg *config.OperatorConfig
+	cfg                *config.OperatorConfig
+	marketplaceBackend marketplace.MarketplaceBackendProvider
+	recorder           record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a ClusterRegistration object and makes changes based on the state read
@@ -97,7 +106,12 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 	}
 
 	//Get Account Id from Pull Secret Token
-	rhmAccountId, err := marketplace.GetAccountIdFromJWTToken(string(rhmPullSecret.Data[utils.RHMPullSecretKey]))
+	var rhmAccountId string
+	claims, err := marketplace.GetJWTTokenClaims(string(rhmPullSecret.Data[utils.RHMPullSecretKey]))
+	if err == nil {
+		rhmAccountId = claims.AccountID
+	}
+
 	if rhmAccountId == "" || err != nil {
 		reqLogger.Error(err, "Token is missing account id")
 		annotations[utils.RHMPullSecretStatus] = "error"
@@ -119,17 +133,6 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 		return reconcile.Result{}, err
 	}
 
-	mclient, err := marketplace.NewMarketplaceClient(&marketplace.MarketplaceClientConfig{
-		Url:      r.cfg.Marketplace.URL, // parameterize this for dev
-		Token:    string(pullSecret),
-		Insecure: r.cfg.Marketplace.InsecureClient,
-	})
-
-	if err != nil {
-		reqLogger.Error(err, "failed to build marketplaceclient")
-		return reconcile.Result{}, nil
-	}
-
 	newMarketplaceConfig := &marketplacev1alpha1.MarketplaceConfig{}
 	err = r.Client.Get(context.TODO(), types.NamespacedName{
 		Namespace: request.Namespace,
@@ -140,12 +143,19 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 		newMarketplaceConfig = nil
 	}
 
+	mclient, err := newMarketplaceBackend(r.Client, r.marketplaceBackend, newMarketplaceConfig, string(pullSecret))
+
+	if err != nil {
+		reqLogger.Error(err, "failed to build marketplaceclient")
+		return reconcile.Result{}, err
+	}
+
 	if newMarketplaceConfig != nil {
 		reqLogger.Info("MarketPlace config object found, check status if its installed or not")
 		//Setting MarketplaceClientAccount
 
 		marketplaceClientAccount := &marketplace.MarketplaceClientAccount{
-			AccountId:   newMarketplaceConfig.Spec.RhmAccountID,
+			AccountId:   rhmAccountId,
 			ClusterUuid: newMarketplaceConfig.Spec.ClusterUUID,
 		}
 
@@ -252,14 +262,8 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 		clusterVersion = nil
 	}
 
-	var clusterID string
-	if clusterVersion != nil {
-		clusterID = string(clusterVersion.Spec.ClusterID)
-		reqLogger.Info("Clusterversion object found with clusterID", "clusterID", clusterID)
-	} else {
-		clusterID = uuid.New().String()
-		reqLogger.Info("Clusterversion object not found, generating clusterID", "clusterID", clusterID)
-	}
+	clusterID := registrationClusterID(clusterVersion, newMarketplaceConfig)
+	reqLogger.Info("using clusterID", "clusterID", clusterID, "clusterVersionFound", clusterVersion != nil)
 
 	newMarketplaceConfig = &marketplacev1alpha1.MarketplaceConfig{}
 	err = r.Client.Get(context.TODO(), types.NamespacedName{
@@ -289,6 +293,7 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 	}
 
 	owners := newMarketplaceConfig.GetOwnerReferences()
+	previousAccountId := newMarketplaceConfig.Spec.RhmAccountID
 
 	if newMarketplaceConfig.Spec.ClusterUUID != string(clusterID) ||
 		newMarketplaceConfig.Spec.RhmAccountID != rhmAccountId ||
@@ -304,6 +309,46 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 		}
 	}
 
+	now := time.Now()
+	statusUpdated := false
+
+	// A token for a different account invalidates the registration of the old
+	// account, the marketplaceconfig controller registers the new account.
+	if previousAccountId != "" && previousAccountId != rhmAccountId {
+		reqLogger.Info("account changed, re-registering", "previous", previousAccountId, "account", rhmAccountId)
+		message := fmt.Sprintf("Account changed from %s to %s, waiting for registration", previousAccountId, rhmAccountId)
+		newMarketplaceConfig.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ConditionRegistered,
+			Status:  v1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ReasonAccountChanged,
+			Message: message,
+		})
+		r.recorder.Event(newMarketplaceConfig, v1.EventTypeNormal, marketplacev1alpha1.EventReasonAccountChanged, message)
+		statusUpdated = true
+	}
+
+	expiryCondition, expiresAt := pullSecretExpiryCondition(claims, now, r.cfg.Marketplace.PullSecretExpiryWarning)
+
+	if newMarketplaceConfig.Status.Conditions.SetCondition(expiryCondition) {
+		if expiryCondition.IsTrue() {
+			r.recorder.Event(newMarketplaceConfig, v1.EventTypeWarning, marketplacev1alpha1.EventReasonPullSecretExpiring, expiryCondition.Message)
+		}
+		statusUpdated = true
+	}
+
+	if !reflect.DeepEqual(newMarketplaceConfig.Status.PullSecretExpiration, expiresAt) {
+		newMarketplaceConfig.Status.PullSecretExpiration = expiresAt
+		statusUpdated = true
+	}
+
+	if statusUpdated {
+		err = r.Client.Status().Update(context.TODO(), newMarketplaceConfig)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update Marketplace Config status")
+			return reconcile.Result{}, err
+		}
+	}
+
 	ownerFound := false
 	for _, owner := range rhmPullSecret.ObjectMeta.OwnerReferences {
 		if owner.Name == rhmPullSecret.Name &&
@@ -324,8 +369,152 @@ func (r *ClusterRegistrationReconciler) Reconcile(request reconcile.Request) (re
 		}
 	}
 
-	reqLogger.Info("reconcile finished. Marketplace Config Created")
-	return reconcile.Result{}, nil
+	requeueAfter := pullSecretRequeueAfter(
+		expiresAt, now,
+		r.cfg.Marketplace.PullSecretExpiryWarning,
+		r.cfg.Marketplace.OperatorSecretRefreshInterval)
+
+	reqLogger.Info("reconcile finished. Marketplace Config Created", "requeueAfter", requeueAfter)
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// registrationClusterID returns the id the cluster registers with. Clusters
+// without a ClusterVersion keep the id generated on their first registration,
+// a new one would register the cluster again on every refresh.
+func registrationClusterID(
+	clusterVersion *openshiftconfigv1.ClusterVersion,
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+) string {
+	if clusterVersion != nil {
+		return string(clusterVersion.Spec.ClusterID)
+	}
+
+	if marketplaceConfig != nil && marketplaceConfig.Spec.ClusterUUID != "" {
+		return marketplaceConfig.Spec.ClusterUUID
+	}
+
+	return uuid.New().String()
+}
+
+// newMarketplaceBackend returns the backend for the marketplace config. Disconnected
+// clusters use their offline activation file instead of the marketplace.
+func newMarketplaceBackend(
+	c client.Client,
+	provider marketplace.MarketplaceBackendProvider,
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+	token string,
+) (marketplace.MarketplaceBackend, error) {
+	if marketplaceConfig == nil || marketplaceConfig.Spec.Disconnected == nil {
+		return provider.NewMarketplaceBackend(token)
+	}
+
+	disconnected := marketplaceConfig.Spec.Disconnected
+
+	activationFile, err := secretKeyData(c, marketplaceConfig.Namespace, disconnected.ActivationSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyData, err := secretKeyData(c, marketplaceConfig.Namespace, disconnected.MarketplacePublicKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := marketplace.ParsePublicKey(publicKeyData)
+	if err != nil {
+		return nil, err
+	}
+
+	return marketplace.NewOfflineMarketplaceClient(activationFile, publicKey)
+}
+
+func secretKeyData(c client.Client, namespace string, selector v1.SecretKeySelector) ([]byte, error) {
+	secret := &v1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      selector.Name,
+		Namespace: namespace,
+	}, secret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := secret.Data[selector.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s is missing key %s", selector.Name, selector.Key)
+	}
+
+	return data, nil
+}
+
+// pullSecretExpiryCondition returns the expiry condition for the pull secret token
+// and its expiry time.
+func pullSecretExpiryCondition(
+	claims *marketplace.MarketplaceClaims,
+	now time.Time,
+	warning time.Duration,
+) (status.Condition, *metav1.Time) {
+	expiresAt := claims.ExpiresAt()
+
+	if expiresAt == nil {
+		return status.Condition{
+			Type:    marketplacev1alpha1.ConditionPullSecretExpiring,
+			Status:  v1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ReasonPullSecretNoExpiration,
+			Message: "Pull secret token does not expire",
+		}, nil
+	}
+
+	expiration := metav1.NewTime(*expiresAt)
+
+	switch {
+	case !now.Before(*expiresAt):
+		return status.Condition{
+			Type:    marketplacev1alpha1.ConditionPullSecretExpiring,
+			Status:  v1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonPullSecretExpired,
+			Message: fmt.Sprintf("Pull secret token expired at %s, please generate a new token from Red Hat Marketplace", expiresAt.Format(time.RFC3339)),
+		}, &expiration
+	case now.Add(warning).After(*expiresAt):
+		return status.Condition{
+			Type:    marketplacev1alpha1.ConditionPullSecretExpiring,
+			Status:  v1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonPullSecretExpiringSoon,
+			Message: fmt.Sprintf("Pull secret token expires at %s, please generate a new token from Red Hat Marketplace", expiresAt.Format(time.RFC3339)),
+		}, &expiration
+	default:
+		return status.Condition{
+			Type:    marketplacev1alpha1.ConditionPullSecretExpiring,
+			Status:  v1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ReasonPullSecretValid,
+			Message: fmt.Sprintf("Pull secret token expires at %s", expiresAt.Format(time.RFC3339)),
+		}, &expiration
+	}
+}
+
+// pullSecretRequeueAfter returns when to reconcile the pull secret again. It is the
+// refresh interval of the rhm-operator-secret, or sooner if the token starts expiring
+// or expires before then.
+func pullSecretRequeueAfter(
+	expiresAt *metav1.Time,
+	now time.Time,
+	warning time.Duration,
+	interval time.Duration,
+) time.Duration {
+	if expiresAt == nil {
+		return interval
+	}
+
+	for _, next := range []time.Time{expiresAt.Add(-warning), expiresAt.Time} {
+		if next.After(now) {
+			if wait := next.Sub(now); interval <= 0 || wait < interval {
+				return wait
+			}
+			break
+		}
+	}
+
+	return interval
 }
 
 func (r *ClusterRegistrationReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
@@ -338,6 +527,16 @@ func (m *ClusterRegistrationReconciler) InjectOperatorConfig(cfg *config.Operato
 	return nil
 }
 
+func (m *ClusterRegistrationReconciler) InjectEventRecorder(rec record.EventRecorder) error {
+	m.recorder = rec
+	return nil
+}
+
+func (m *ClusterRegistrationReconciler) InjectMarketplaceBackend(provider marketplace.MarketplaceBackendProvider) error {
+	m.marketplaceBackend = provider
+	return nil
+}
+
 func (r *ClusterRegistrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1.Secret{}, builder.WithPredicates(
@@ -379,5 +578,41 @@ func (r *ClusterRegistrationReconciler) SetupWithManager(mgr ctrl.Manager) error
 				},
 			},
 		)).
+		Watches(
+			&source.Kind{Type: &v1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.disconnectedSecretToPullSecret),
+			}).
 		Complete(r)
 }
+
+// disconnectedSecretToPullSecret maps the activation and marketplace public key
+// secrets of a disconnected marketplace config to the pull secret, so registration
+// is retried when they change.
+func (r *ClusterRegistrationReconciler) disconnectedSecretToPullSecret(a handler.MapObject) []reconcile.Request {
+	configs := &marketplacev1alpha1.MarketplaceConfigList{}
+
+	if err := r.Client.List(context.TODO(), configs, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list marketplace configs")
+		return nil
+	}
+
+	for _, marketplaceConfig := range configs.Items {
+		disconnected := marketplaceConfig.Spec.Disconnected
+		if disconnected == nil {
+			continue
+		}
+
+		if disconnected.ActivationSecret.Name == a.Meta.GetName() ||
+			disconnected.MarketplacePublicKeySecret.Name == a.Meta.GetName() {
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{
+					Name:      utils.RHMPullSecretName,
+					Namespace: a.Meta.GetNamespace(),
+				}},
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type fakeMarketplaceBackend struct{}
+
+func (fakeMarketplaceBackend) NewMarketplaceBackend(token string) (marketplace.MarketplaceBackend, error) {
+	return fakeMarketplaceBackend{}, nil
+}
+
+func (fakeMarketplaceBackend) RegistrationStatus(account *marketplace.MarketplaceClientAccount) (marketplace.RegistrationStatusOutput, error) {
+	return marketplace.RegistrationStatusOutput{RegistrationStatus: marketplace.RegistrationStatusInstalled}, nil
+}
+
+func (fakeMarketplaceBackend) GetMarketplaceSecret() (*corev1.Secret, error) {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RHMOperatorSecretName},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}, nil
+}
+
+var _ = Describe("ClusterRegistrationController", func() {
+	Describe("check pull secret expiry", func() {
+		var (
+			now      time.Time
+			warning  = 7 * 24 * time.Hour
+			interval = 24 * time.Hour
+		)
+
+		claimsExpiringIn := func(d time.Duration) *marketplace.MarketplaceClaims {
+			return &marketplace.MarketplaceClaims{
+				AccountID: "account",
+				StandardClaims: jwt.StandardClaims{
+					ExpiresAt: now.Add(d).Unix(),
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			now = time.Now().Truncate(time.Second)
+		})
+
+		It("should report tokens without expiry", func() {
+			cond, expiresAt := pullSecretExpiryCondition(&marketplace.MarketplaceClaims{}, now, warning)
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ReasonPullSecretNoExpiration))
+			Expect(expiresAt).To(BeNil())
+			Expect(pullSecretRequeueAfter(expiresAt, now, warning, interval)).To(Equal(interval))
+		})
+
+		It("should report valid tokens", func() {
+			cond, expiresAt := pullSecretExpiryCondition(claimsExpiringIn(30*24*time.Hour), now, warning)
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ReasonPullSecretValid))
+			Expect(expiresAt.Time).To(BeTemporally("==", now.Add(30*24*time.Hour)))
+			Expect(pullSecretRequeueAfter(expiresAt, now, warning, interval)).To(Equal(interval))
+		})
+
+		It("should requeue when the token starts expiring", func() {
+			_, expiresAt := pullSecretExpiryCondition(claimsExpiringIn(warning+time.Hour), now, warning)
+			Expect(pullSecretRequeueAfter(expiresAt, now, warning, interval)).To(Equal(time.Hour))
+		})
+
+		It("should report expiring tokens", func() {
+			cond, expiresAt := pullSecretExpiryCondition(claimsExpiringIn(2*time.Hour), now, warning)
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ReasonPullSecretExpiringSoon))
+			Expect(pullSecretRequeueAfter(expiresAt, now, warning, interval)).To(Equal(2 * time.Hour))
+		})
+
+		It("should report expired tokens", func() {
+			cond, expiresAt := pullSecretExpiryCondition(claimsExpiringIn(-time.Hour), now, warning)
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ReasonPullSecretExpired))
+			Expect(expiresAt).To(Equal(&metav1.Time{Time: now.Add(-time.Hour).UTC()}))
+			Expect(pullSecretRequeueAfter(expiresAt, now, warning, interval)).To(Equal(interval))
+		})
+	})
+
+	Describe("reconcile without a ClusterVersion", func() {
+		var (
+			namespace  = "redhat-marketplace-operator"
+			reconciler *ClusterRegistrationReconciler
+			req        = reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      utils.RHMPullSecretName,
+					Namespace: namespace,
+				},
+			}
+		)
+
+		BeforeEach(func() {
+			s := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+			Expect(openshiftconfigv1.AddToScheme(s)).To(Succeed())
+			Expect(marketplacev1alpha1.AddToScheme(s)).To(Succeed())
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &marketplace.MarketplaceClaims{
+				AccountID: "account",
+			}).SignedString([]byte("key"))
+			Expect(err).To(Succeed())
+
+			pullSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      utils.RHMPullSecretName,
+					Namespace: namespace,
+				},
+				Data: map[string][]byte{
+					utils.RHMPullSecretKey: []byte(token),
+				},
+			}
+
+			cfg := &config.OperatorConfig{}
+			cfg.Marketplace.PullSecretExpiryWarning = 7 * 24 * time.Hour
+			cfg.Marketplace.OperatorSecretRefreshInterval = 24 * time.Hour
+
+			reconciler = &ClusterRegistrationReconciler{
+				Client:             fake.NewFakeClientWithScheme(s, pullSecret),
+				Scheme:             s,
+				Log:                logf.Log.WithName("clusterregistration"),
+				cfg:                cfg,
+				marketplaceBackend: fakeMarketplaceBackend{},
+				recorder:           record.NewFakeRecorder(10),
+			}
+		})
+
+		It("should keep the generated cluster id", func() {
+			marketplaceConfig := &marketplacev1alpha1.MarketplaceConfig{}
+			key := types.NamespacedName{Name: utils.MARKETPLACECONFIG_NAME, Namespace: namespace}
+
+			_, err := reconciler.Reconcile(req)
+			Expect(err).To(Succeed())
+			Expect(reconciler.Client.Get(context.TODO(), key, marketplaceConfig)).To(Succeed())
+
+			clusterUUID := marketplaceConfig.Spec.ClusterUUID
+			Expect(clusterUUID).ToNot(BeEmpty())
+
+			_, err = reconciler.Reconcile(req)
+			Expect(err).To(Succeed())
+			Expect(reconciler.Client.Get(context.TODO(), key, marketplaceConfig)).To(Succeed())
+			Expect(marketplaceConfig.Spec.ClusterUUID).To(Equal(clusterUUID))
+		})
+
+		Context("disconnected", func() {
+			BeforeEach(func() {
+				marketplaceConfig := &marketplacev1alpha1.MarketplaceConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      utils.MARKETPLACECONFIG_NAME,
+						Namespace: namespace,
+					},
+					Spec: marketplacev1alpha1.MarketplaceConfigSpec{
+						ClusterUUID: "cluster",
+						Disconnected: &marketplacev1alpha1.DisconnectedSpec{
+							ActivationSecret: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "activation"},
+								Key:                  "activation.json",
+							},
+							MarketplacePublicKeySecret: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "marketplace-key"},
+								Key:                  "key.pem",
+							},
+						},
+					},
+				}
+				Expect(reconciler.Client.Create(context.TODO(), marketplaceConfig)).To(Succeed())
+			})
+
+			It("should retry when the activation secret is missing", func() {
+				_, err := reconciler.Reconcile(req)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should reconcile the pull secret when the activation secrets change", func() {
+				activation := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "activation", Namespace: namespace}}
+				Expect(reconciler.disconnectedSecretToPullSecret(handler.MapObject{Meta: activation, Object: activation})).
+					To(ConsistOf(req))
+
+				key := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "marketplace-key", Namespace: namespace}}
+				Expect(reconciler.disconnectedSecretToPullSecret(handler.MapObject{Meta: key, Object: key})).
+					To(ConsistOf(req))
+
+				other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace}}
+				Expect(reconciler.disconnectedSecretToPullSecret(handler.MapObject{Meta: other, Object: other})).
+					To(BeEmpty())
+			})
+		})
+	})
+})
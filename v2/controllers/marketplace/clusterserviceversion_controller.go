@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -34,7 +35,9 @@ import (
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
 	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
 	utils "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -59,6 +62,8 @@ const (
 	ignoreTagValue  = "2"
 	meterDefStatus  = "marketplace.redhat.com/meterDefinitionStatus"
 	meterDefError   = "marketplace.redhat.com/meterDefinitionError"
+
+	meterDefAnnotationErrorReason = "MeterDefinitionAnnotationError"
 )
 
 // blank assignment to verify that ReconcileClusterServiceVersion implements reconcile.Reconciler
@@ -71,6 +76,18 @@ type ClusterServiceVersionReconciler struct {
 	Client client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+
+	recorder record.EventRecorder
+}
+
+func (r *ClusterServiceVersionReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *ClusterServiceVersionReconciler) InjectEventRecorder(rec record.EventRecorder) error {
+	r.recorder = rec
+	return nil
 }
 
 // Reconcile reads that state of the cluster for a ClusterServiceVersion object and makes changes based on the state read
@@ -347,6 +364,8 @@ func (r *ClusterServiceVersionReconciler) reconcileMeterDefAnnotation(CSV *olmv1
 
 	if err != nil {
 		reqLogger.Error(err, "Could not build a local copy of the MeterDefinition")
+		r.recorder.Eventf(CSV, corev1.EventTypeWarning, meterDefAnnotationErrorReason,
+			"Failed to parse %s annotation: %s", utils.CSV_METERDEFINITION_ANNOTATION, err.Error())
 		reqLogger.Info("Adding failiure annotation in csv file ")
 		annotations[meterDefStatus] = "error"
 		annotations[meterDefError] = err.Error()
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -97,7 +98,7 @@ var _ = Describe("Testing with Ginkgo", func() {
 			setup = func(r *ReconcilerTest) error {
 				var log = logf.Log.WithName("clusterserviceversion_controller")
 				r.Client = fake.NewFakeClient(r.GetGetObjects()...)
-				r.Reconciler = &ClusterServiceVersionReconciler{Client: r.Client, Scheme: scheme.Scheme, Log: log}
+				r.Reconciler = &ClusterServiceVersionReconciler{Client: r.Client, Scheme: scheme.Scheme, Log: log, recorder: record.NewFakeRecorder(10)}
 				return nil
 			}
 
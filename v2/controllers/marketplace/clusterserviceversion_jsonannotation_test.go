@@ -27,6 +27,7 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/tests/mock/mock_client"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/utils/pointer"
 	k8client "sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,7 +41,8 @@ var _ = Describe("JsonMeterDefValidation", func() {
 		//statusWriter *mock_client.MockStatusWriter
 		ctx context.Context
 		//patcher      *mock_patch.MockPatchMaker
-		sut *ClusterServiceVersionReconciler
+		sut      *ClusterServiceVersionReconciler
+		recorder *record.FakeRecorder
 		//cc  reconcileutils.ClientCommandRunner
 		meterDefStatus = "marketplace.redhat.com/meterDefinitionStatus"
 		meterDefError  = "marketplace.redhat.com/meterDefinitionError"
@@ -58,7 +60,8 @@ var _ = Describe("JsonMeterDefValidation", func() {
 		//cc = reconcileutils.NewClientCommand(client, scheme.Scheme, logger)
 		ctx = context.TODO()
 
-		sut = &ClusterServiceVersionReconciler{Client: client, Scheme: scheme.Scheme, Log: logger}
+		recorder = record.NewFakeRecorder(10)
+		sut = &ClusterServiceVersionReconciler{Client: client, Scheme: scheme.Scheme, Log: logger, recorder: recorder}
 
 		CSV = &olmv1alpha1.ClusterServiceVersion{
 			ObjectMeta: metav1.ObjectMeta{
@@ -180,6 +183,7 @@ var _ = Describe("JsonMeterDefValidation", func() {
 			sut.reconcileMeterDefAnnotation(CSV, annBad)
 			Expect(CSV.GetAnnotations()[meterDefStatus]).To(Equal("error"))
 			Expect(CSV.GetAnnotations()[meterDefError]).Should(ContainSubstring("invalid character"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(meterDefAnnotationErrorReason)))
 
 			//client.EXPECT().Update(ctx, CSV).Return(nil).Times(1)
 			By("testing for success")
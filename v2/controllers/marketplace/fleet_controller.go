@@ -0,0 +1,356 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// blank assignment to verify that FleetReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &FleetReconciler{}
+
+// FleetReconciler pushes the status summary of this cluster to the hub
+// cluster configured on the MarketplaceConfig.
+type FleetReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	cfg *config.OperatorConfig
+
+	// newHubClient builds the client of the hub cluster from a kubeconfig
+	newHubClient func(kubeconfig []byte, scheme *runtime.Scheme, base *http.Transport) (client.Client, error)
+
+	hubClientsMutex sync.Mutex
+	hubClients      map[types.NamespacedName]hubClient
+}
+
+// hubClient is the client built for the hub kubeconfig with the hash on
+// the shared transport.
+type hubClient struct {
+	kubeconfigHash [sha256.Size]byte
+	transport      *http.Transport
+	client.Client
+}
+
+// Reconcile builds the summary of this cluster and writes it to the
+// ManagedClusterStatus on the hub cluster.
+func (r *FleetReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling Fleet")
+
+	marketplaceConfig := &marketplacev1alpha1.MarketplaceConfig{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, marketplaceConfig)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	fleet := marketplaceConfig.Spec.Fleet
+	if fleet == nil {
+		r.hubClientsMutex.Lock()
+		delete(r.hubClients, request.NamespacedName)
+		r.hubClientsMutex.Unlock()
+		return reconcile.Result{}, nil
+	}
+
+	// the ManagedClusterStatus on the hub is named after the cluster id
+	if marketplaceConfig.Spec.ClusterUUID == "" {
+		err := r.setFleetCondition(marketplaceConfig, status.Condition{
+			Type:    marketplacev1alpha1.ConditionFleetStatusError,
+			Status:  corev1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonFleetClusterUUIDMissing,
+			Message: "the cluster has no clusterUUID to name its status on the hub",
+		})
+		return reconcile.Result{}, err
+	}
+
+	kubeconfig := &corev1.Secret{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      fleet.HubKubeconfigSecret.Name,
+		Namespace: marketplaceConfig.Namespace,
+	}, kubeconfig)
+	if err != nil {
+		reqLogger.Error(err, "failed to get hub kubeconfig")
+		return reconcile.Result{}, err
+	}
+
+	data, ok := kubeconfig.Data[fleet.HubKubeconfigSecret.Key]
+	if !ok {
+		err := fmt.Errorf("hub kubeconfig secret %s is missing key %s", kubeconfig.Name, fleet.HubKubeconfigSecret.Key)
+		reqLogger.Error(err, "failed to get hub kubeconfig")
+
+		if condErr := r.setFleetCondition(marketplaceConfig, status.Condition{
+			Type:    marketplacev1alpha1.ConditionFleetStatusError,
+			Status:  corev1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonFleetKubeconfigMissing,
+			Message: err.Error(),
+		}); condErr != nil {
+			return reconcile.Result{}, condErr
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	hubClient, err := r.getHubClient(request.NamespacedName, data)
+	if err != nil {
+		reqLogger.Error(err, "failed to build hub client")
+		return reconcile.Result{}, err
+	}
+
+	reports := &marketplacev1alpha1.MeterReportList{}
+	err = r.Client.List(context.TODO(), reports, client.InNamespace(marketplaceConfig.Namespace))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	meterdefs := &marketplacev1beta1.MeterDefinitionList{}
+	err = r.Client.List(context.TODO(), meterdefs)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	summary := clusterStatusSummary(marketplaceConfig, reports.Items, len(meterdefs.Items), time.Now())
+
+	managedCluster := &marketplacev1alpha1.ManagedClusterStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      marketplaceConfig.Spec.ClusterUUID,
+			Namespace: fleet.HubNamespace,
+		},
+	}
+
+	result, err := controllerutil.CreateOrUpdate(context.TODO(), hubClient, managedCluster, func() error {
+		managedCluster.Spec = summary
+		return nil
+	})
+	if err != nil {
+		reqLogger.Error(err, "failed to push status to the hub")
+		return reconcile.Result{}, err
+	}
+
+	reqLogger.Info("pushed status to the hub", "result", result)
+
+	err = r.setFleetCondition(marketplaceConfig, status.Condition{
+		Type:    marketplacev1alpha1.ConditionFleetStatusError,
+		Status:  corev1.ConditionFalse,
+		Reason:  marketplacev1alpha1.ReasonFleetStatusPushed,
+		Message: "the cluster status is pushed to the hub",
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: r.cfg.Fleet.StatusInterval}, nil
+}
+
+// setFleetCondition updates the fleet condition of the MarketplaceConfig when it changes.
+func (r *FleetReconciler) setFleetCondition(
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+	cond status.Condition,
+) error {
+	if !marketplaceConfig.Status.Conditions.SetCondition(cond) {
+		return nil
+	}
+
+	return r.Client.Status().Update(context.TODO(), marketplaceConfig)
+}
+
+// hubKubeconfigToMarketplaceConfig maps a hub kubeconfig secret to the
+// MarketplaceConfigs that use it.
+func (r *FleetReconciler) hubKubeconfigToMarketplaceConfig(a handler.MapObject) []reconcile.Request {
+	configs := &marketplacev1alpha1.MarketplaceConfigList{}
+
+	if err := r.Client.List(context.TODO(), configs, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list marketplace configs")
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for _, marketplaceConfig := range configs.Items {
+		if fleet := marketplaceConfig.Spec.Fleet; fleet != nil && fleet.HubKubeconfigSecret.Name == a.Meta.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      marketplaceConfig.Name,
+					Namespace: marketplaceConfig.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
+}
+
+// getHubClient returns the hub client of the MarketplaceConfig. The client is
+// built again only when the kubeconfig or the shared transport changes.
+func (r *FleetReconciler) getHubClient(key types.NamespacedName, kubeconfig []byte) (client.Client, error) {
+	hash := sha256.Sum256(kubeconfig)
+
+	r.hubClientsMutex.Lock()
+	defer r.hubClientsMutex.Unlock()
+
+	httpTransport, err := r.cfg.Infrastructure.Transport()
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := r.hubClients[key]; ok && cached.kubeconfigHash == hash && cached.transport == httpTransport {
+		return cached.Client, nil
+	}
+
+	c, err := r.newHubClient(kubeconfig, r.Scheme, httpTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.hubClients == nil {
+		r.hubClients = map[types.NamespacedName]hubClient{}
+	}
+	r.hubClients[key] = hubClient{kubeconfigHash: hash, transport: httpTransport, Client: c}
+
+	return c, nil
+}
+
+// clusterStatusSummary returns the summary of the cluster pushed to the hub.
+func clusterStatusSummary(
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+	reports []marketplacev1alpha1.MeterReport,
+	meterDefinitionCount int,
+	now time.Time,
+) marketplacev1alpha1.ManagedClusterStatusSpec {
+	summary := marketplacev1alpha1.ManagedClusterStatusSpec{
+		ClusterUUID:          marketplaceConfig.Spec.ClusterUUID,
+		ClusterName:          marketplaceConfig.Spec.ClusterName,
+		RhmAccountID:         marketplaceConfig.Spec.RhmAccountID,
+		Registered:           marketplaceConfig.Status.Conditions.IsTrueFor(marketplacev1alpha1.ConditionRegistered),
+		MeterDefinitionCount: meterDefinitionCount,
+		LastHeartbeatTime:    &metav1.Time{Time: now},
+	}
+
+	if cond := marketplaceConfig.Status.Conditions.GetCondition(marketplacev1alpha1.ConditionRegistrationError); cond != nil && cond.IsTrue() {
+		summary.Errors = append(summary.Errors, fmt.Sprintf("registration: %s", cond.Message))
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Spec.EndTime.Before(&reports[j].Spec.EndTime)
+	})
+
+	// only report errors since the last successful report
+	reportErrors := []string{}
+
+	for i := range reports {
+		report := &reports[i]
+		cond := report.Status.Conditions.GetCondition(marketplacev1alpha1.ReportConditionTypeJobRunning)
+
+		if cond == nil {
+			continue
+		}
+
+		switch {
+		case cond.IsTrue() && cond.Reason == marketplacev1alpha1.ReportConditionReasonJobFinished:
+			summary.LastReportName = report.Name
+			summary.LastReportTime = report.Spec.EndTime.DeepCopy()
+			reportErrors = []string{}
+		case cond.Reason == marketplacev1alpha1.ReportConditionReasonJobErrored:
+			reportErrors = append(reportErrors, fmt.Sprintf("report %s: %s", report.Name, cond.Message))
+		}
+	}
+
+	summary.Errors = append(summary.Errors, reportErrors...)
+
+	return summary
+}
+
+// newHubClient builds the client of the hub cluster on a copy of the shared
+// outbound transport that uses the tls settings of the kubeconfig.
+func newHubClient(kubeconfig []byte, scheme *runtime.Scheme, base *http.Transport) (client.Client, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	httpTransport := base.Clone()
+	if tlsConfig != nil {
+		httpTransport.TLSClientConfig = tlsConfig
+	}
+
+	// client-go rejects a transport along with tls settings, it still adds
+	// the authentication of the kubeconfig
+	restConfig.Transport = httpTransport
+	restConfig.TLSClientConfig = rest.TLSClientConfig{}
+
+	return client.New(restConfig, client.Options{Scheme: scheme})
+}
+
+func (r *FleetReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *FleetReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
+}
+
+func (r *FleetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.newHubClient == nil {
+		r.newHubClient = newHubClient
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("fleet").
+		For(&marketplacev1alpha1.MarketplaceConfig{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.hubKubeconfigToMarketplaceConfig),
+			}).
+		Complete(r)
+}
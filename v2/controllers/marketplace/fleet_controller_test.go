@@ -0,0 +1,289 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("FleetController", func() {
+	var (
+		now              time.Time
+		heartbeatTimeout = time.Hour
+		reportTimeout    = 48 * time.Hour
+	)
+
+	report := func(name string, end time.Time, cond status.Condition) marketplacev1alpha1.MeterReport {
+		return marketplacev1alpha1.MeterReport{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: marketplacev1alpha1.MeterReportSpec{
+				EndTime: metav1.Time{Time: end},
+			},
+			Status: marketplacev1alpha1.MeterReportStatus{
+				Conditions: status.Conditions{cond},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Now().Truncate(time.Second)
+	})
+
+	Describe("build cluster summary", func() {
+		var marketplaceConfig *marketplacev1alpha1.MarketplaceConfig
+
+		BeforeEach(func() {
+			marketplaceConfig = &marketplacev1alpha1.MarketplaceConfig{
+				Spec: marketplacev1alpha1.MarketplaceConfigSpec{
+					ClusterUUID:  "cluster",
+					ClusterName:  "name",
+					RhmAccountID: "account",
+				},
+				Status: marketplacev1alpha1.MarketplaceConfigStatus{
+					Conditions: status.Conditions{
+						{
+							Type:   marketplacev1alpha1.ConditionRegistered,
+							Status: corev1.ConditionTrue,
+							Reason: marketplacev1alpha1.ReasonRegistrationSuccess,
+						},
+					},
+				},
+			}
+		})
+
+		It("should summarize the last successful report", func() {
+			summary := clusterStatusSummary(marketplaceConfig, []marketplacev1alpha1.MeterReport{
+				report("day-2", now.Add(-24*time.Hour), marketplacev1alpha1.ReportConditionJobFinished),
+				report("day-1", now.Add(-48*time.Hour), marketplacev1alpha1.ReportConditionJobFinished),
+				report("today", now, marketplacev1alpha1.ReportConditionJobSubmitted),
+			}, 3, now)
+
+			Expect(summary.ClusterUUID).To(Equal("cluster"))
+			Expect(summary.Registered).To(BeTrue())
+			Expect(summary.MeterDefinitionCount).To(Equal(3))
+			Expect(summary.LastReportName).To(Equal("day-2"))
+			Expect(summary.LastReportTime.Time).To(BeTemporally("==", now.Add(-24*time.Hour)))
+			Expect(summary.LastHeartbeatTime.Time).To(BeTemporally("==", now))
+			Expect(summary.Errors).To(BeEmpty())
+		})
+
+		It("should summarize errors since the last successful report", func() {
+			marketplaceConfig.Status.Conditions = status.Conditions{
+				{
+					Type:    marketplacev1alpha1.ConditionRegistrationError,
+					Status:  corev1.ConditionTrue,
+					Reason:  marketplacev1alpha1.ReasonRegistrationError,
+					Message: "failed",
+				},
+			}
+
+			summary := clusterStatusSummary(marketplaceConfig, []marketplacev1alpha1.MeterReport{
+				report("day-1", now.Add(-72*time.Hour), marketplacev1alpha1.ReportConditionJobErrored),
+				report("day-2", now.Add(-48*time.Hour), marketplacev1alpha1.ReportConditionJobFinished),
+				report("day-3", now.Add(-24*time.Hour), marketplacev1alpha1.ReportConditionJobErrored),
+			}, 0, now)
+
+			Expect(summary.Registered).To(BeFalse())
+			Expect(summary.LastReportName).To(Equal("day-2"))
+			Expect(summary.Errors).To(HaveLen(2))
+			Expect(summary.Errors[0]).To(HavePrefix("registration:"))
+			Expect(summary.Errors[1]).To(HavePrefix("report day-3:"))
+		})
+	})
+
+	Describe("check managed cluster status", func() {
+		var spec *marketplacev1alpha1.ManagedClusterStatusSpec
+
+		BeforeEach(func() {
+			spec = &marketplacev1alpha1.ManagedClusterStatusSpec{
+				Registered:        true,
+				LastHeartbeatTime: &metav1.Time{Time: now.Add(-10 * time.Minute)},
+				LastReportTime:    &metav1.Time{Time: now.Add(-24 * time.Hour)},
+			}
+		})
+
+		It("should report healthy clusters", func() {
+			cond := managedClusterReportingCondition(spec, now, heartbeatTimeout, reportTimeout)
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ManagedClusterReasonReporting))
+			Expect(managedClusterRequeueAfter(spec, now, heartbeatTimeout)).To(Equal(50*time.Minute + time.Second))
+		})
+
+		It("should report missed heartbeats", func() {
+			spec.LastHeartbeatTime = &metav1.Time{Time: now.Add(-2 * time.Hour)}
+			cond := managedClusterReportingCondition(spec, now, heartbeatTimeout, reportTimeout)
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ManagedClusterReasonHeartbeatMissed))
+			Expect(managedClusterRequeueAfter(spec, now, heartbeatTimeout)).To(Equal(heartbeatTimeout))
+		})
+
+		It("should report unregistered clusters", func() {
+			spec.Registered = false
+			cond := managedClusterReportingCondition(spec, now, heartbeatTimeout, reportTimeout)
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ManagedClusterReasonNotRegistered))
+		})
+
+		It("should report overdue reports", func() {
+			spec.LastReportTime = &metav1.Time{Time: now.Add(-72 * time.Hour)}
+			cond := managedClusterReportingCondition(spec, now, heartbeatTimeout, reportTimeout)
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(marketplacev1alpha1.ManagedClusterReasonReportOverdue))
+		})
+	})
+
+	Describe("hub client", func() {
+		It("should build the hub client once per kubeconfig", func() {
+			built := 0
+			r := &FleetReconciler{
+				cfg: &config.OperatorConfig{Infrastructure: &config.Infrastructure{}},
+				newHubClient: func(kubeconfig []byte, scheme *runtime.Scheme, base *http.Transport) (client.Client, error) {
+					Expect(base).ToNot(BeNil())
+					built++
+					return fake.NewFakeClient(), nil
+				},
+			}
+			key := types.NamespacedName{Name: "marketplaceconfig", Namespace: "openshift-redhat-marketplace"}
+
+			first, err := r.getHubClient(key, []byte("kubeconfig-1"))
+			Expect(err).To(Succeed())
+			second, err := r.getHubClient(key, []byte("kubeconfig-1"))
+			Expect(err).To(Succeed())
+			Expect(second).To(BeIdenticalTo(first))
+			Expect(built).To(Equal(1))
+
+			_, err = r.getHubClient(key, []byte("kubeconfig-2"))
+			Expect(err).To(Succeed())
+			Expect(built).To(Equal(2))
+		})
+	})
+
+	Describe("reconcile", func() {
+		var (
+			r                 *FleetReconciler
+			marketplaceConfig *marketplacev1alpha1.MarketplaceConfig
+			kubeconfig        *corev1.Secret
+			req               reconcile.Request
+		)
+
+		BeforeEach(func() {
+			marketplaceConfig = &marketplacev1alpha1.MarketplaceConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "marketplaceconfig", Namespace: "openshift-redhat-marketplace"},
+				Spec: marketplacev1alpha1.MarketplaceConfigSpec{
+					ClusterUUID: "cluster",
+					Fleet: &marketplacev1alpha1.FleetSpec{
+						HubKubeconfigSecret: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "hub"},
+							Key:                  "kubeconfig",
+						},
+						HubNamespace: "fleet",
+					},
+				},
+			}
+			kubeconfig = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "hub", Namespace: "openshift-redhat-marketplace"},
+				Data:       map[string][]byte{"kubeconfig": []byte("kubeconfig")},
+			}
+			req = reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      marketplaceConfig.Name,
+				Namespace: marketplaceConfig.Namespace,
+			}}
+		})
+
+		JustBeforeEach(func() {
+			s := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+			Expect(marketplacev1alpha1.AddToScheme(s)).To(Succeed())
+			Expect(marketplacev1beta1.AddToScheme(s)).To(Succeed())
+
+			hub := fake.NewFakeClientWithScheme(s)
+			r = &FleetReconciler{
+				Client: fake.NewFakeClientWithScheme(s, marketplaceConfig, kubeconfig),
+				Scheme: s,
+				Log:    logf.Log.WithName("fleet"),
+				cfg:    &config.OperatorConfig{},
+				newHubClient: func(kubeconfig []byte, scheme *runtime.Scheme, base *http.Transport) (client.Client, error) {
+					return hub, nil
+				},
+			}
+		})
+
+		getCondition := func() *status.Condition {
+			updated := &marketplacev1alpha1.MarketplaceConfig{}
+			Expect(r.Client.Get(context.TODO(), req.NamespacedName, updated)).To(Succeed())
+			return updated.Status.Conditions.GetCondition(marketplacev1alpha1.ConditionFleetStatusError)
+		}
+
+		It("should push the status to the hub", func() {
+			_, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			Expect(getCondition().Reason).To(Equal(marketplacev1alpha1.ReasonFleetStatusPushed))
+		})
+
+		Context("without a cluster id", func() {
+			BeforeEach(func() {
+				marketplaceConfig.Spec.ClusterUUID = ""
+			})
+
+			It("should report the missing cluster id", func() {
+				_, err := r.Reconcile(req)
+				Expect(err).To(Succeed())
+				Expect(getCondition().IsTrue()).To(BeTrue())
+				Expect(getCondition().Reason).To(Equal(marketplacev1alpha1.ReasonFleetClusterUUIDMissing))
+			})
+		})
+
+		Context("with a kubeconfig secret missing the key", func() {
+			BeforeEach(func() {
+				kubeconfig.Data = map[string][]byte{}
+			})
+
+			It("should retry and report the missing key", func() {
+				_, err := r.Reconcile(req)
+				Expect(err).To(HaveOccurred())
+				Expect(getCondition().IsTrue()).To(BeTrue())
+				Expect(getCondition().Reason).To(Equal(marketplacev1alpha1.ReasonFleetKubeconfigMissing))
+			})
+		})
+
+		It("should reconcile the marketplace config when the kubeconfig secret changes", func() {
+			Expect(r.hubKubeconfigToMarketplaceConfig(handler.MapObject{Meta: kubeconfig, Object: kubeconfig})).
+				To(ConsistOf(req))
+
+			other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: kubeconfig.Namespace}}
+			Expect(r.hubKubeconfigToMarketplaceConfig(handler.MapObject{Meta: other, Object: other})).
+				To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,148 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// blank assignment to verify that ManagedClusterStatusReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ManagedClusterStatusReconciler{}
+
+// ManagedClusterStatusReconciler runs on the hub cluster and marks the
+// managed clusters that stopped reporting.
+type ManagedClusterStatusReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	cfg *config.OperatorConfig
+}
+
+// Reconcile evaluates the summary pushed by a managed cluster and sets the
+// reporting condition.
+func (r *ManagedClusterStatusReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ManagedClusterStatus")
+
+	instance := &marketplacev1alpha1.ManagedClusterStatus{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	cond := managedClusterReportingCondition(&instance.Spec, now, r.cfg.Fleet.HeartbeatTimeout, r.cfg.Fleet.ReportTimeout)
+
+	if instance.Status.Conditions.SetCondition(cond) {
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "failed to update status")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{
+		RequeueAfter: managedClusterRequeueAfter(&instance.Spec, now, r.cfg.Fleet.HeartbeatTimeout),
+	}, nil
+}
+
+// managedClusterReportingCondition returns the reporting condition of a
+// managed cluster from its last summary.
+func managedClusterReportingCondition(
+	spec *marketplacev1alpha1.ManagedClusterStatusSpec,
+	now time.Time,
+	heartbeatTimeout, reportTimeout time.Duration,
+) status.Condition {
+	cond := status.Condition{
+		Type:    marketplacev1alpha1.ManagedClusterConditionReporting,
+		Status:  corev1.ConditionFalse,
+		Reason:  marketplacev1alpha1.ManagedClusterReasonReporting,
+		Message: "Cluster is reporting.",
+	}
+
+	switch {
+	case spec.LastHeartbeatTime == nil || now.Sub(spec.LastHeartbeatTime.Time) > heartbeatTimeout:
+		cond.Reason = marketplacev1alpha1.ManagedClusterReasonHeartbeatMissed
+		cond.Message = fmt.Sprintf("Cluster has not pushed its status for more than %s.", heartbeatTimeout)
+	case !spec.Registered:
+		cond.Reason = marketplacev1alpha1.ManagedClusterReasonNotRegistered
+		cond.Message = "Cluster is not registered."
+	case spec.LastReportTime == nil || now.Sub(spec.LastReportTime.Time) > reportTimeout:
+		cond.Reason = marketplacev1alpha1.ManagedClusterReasonReportOverdue
+		cond.Message = fmt.Sprintf("Cluster has no successful report for more than %s.", reportTimeout)
+	default:
+		cond.Status = corev1.ConditionTrue
+	}
+
+	return cond
+}
+
+// managedClusterRequeueAfter returns when the heartbeat of a managed cluster
+// times out so a silent cluster is marked without waiting for an update.
+func managedClusterRequeueAfter(
+	spec *marketplacev1alpha1.ManagedClusterStatusSpec,
+	now time.Time,
+	heartbeatTimeout time.Duration,
+) time.Duration {
+	if spec.LastHeartbeatTime == nil {
+		return heartbeatTimeout
+	}
+
+	if until := spec.LastHeartbeatTime.Add(heartbeatTimeout).Sub(now); until > 0 {
+		return until + time.Second
+	}
+
+	return heartbeatTimeout
+}
+
+func (r *ManagedClusterStatusReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *ManagedClusterStatusReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
+}
+
+func (r *ManagedClusterStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// only the hub cluster collects the status of managed clusters
+	if !r.cfg.Fleet.Hub {
+		return nil
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&marketplacev1alpha1.ManagedClusterStatus{}).
+		Complete(r)
+}
@@ -26,6 +26,7 @@ import (
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
@@ -38,6 +39,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -69,6 +71,10 @@ type MarketplaceConfigReconciler struct {
 	Log    logr.Logger
 	cc     ClientCommandRunner
 	cfg    *config.OperatorConfig
+
+	factory            *manifests.Factory
+	recorder           record.EventRecorder
+	marketplaceBackend marketplace.MarketplaceBackendProvider
 }
 
 // Reconcile reads that state of the cluster for a MarketplaceConfig object and makes changes based on the state read
@@ -208,73 +214,16 @@ func (r *MarketplaceConfigReconciler) Reconcile(request reconcile.Request) (reco
 
 	var foundRazee *marketplacev1alpha1.RazeeDeployment
 
-	//Check if RazeeDeployment exists, if not create one
-	foundRazee = &marketplacev1alpha1.RazeeDeployment{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: utils.RAZEE_NAME, Namespace: marketplaceConfig.Namespace}, foundRazee)
-	if err != nil && errors.IsNotFound(err) {
-		newRazeeCrd := utils.BuildRazeeCr(marketplaceConfig.Namespace, marketplaceConfig.Spec.ClusterUUID, marketplaceConfig.Spec.DeploySecretName, marketplaceConfig.Spec.Features)
-
-		// Sets the owner for foundRazee
-		if err = controllerutil.SetControllerReference(marketplaceConfig, newRazeeCrd, r.Scheme); err != nil {
-			reqLogger.Error(err, "Failed to create a new RazeeDeployment CR.")
-			return reconcile.Result{}, err
-		}
-
-		reqLogger.Info("creating razee cr")
-		err = r.Client.Create(context.TODO(), newRazeeCrd)
-
-		if err != nil {
-			reqLogger.Error(err, "Failed to create a new RazeeDeployment CR.")
-			return reconcile.Result{}, err
-		}
-
-		ok := marketplaceConfig.Status.Conditions.SetCondition(status.Condition{
-			Type:    marketplacev1alpha1.ConditionInstalling,
-			Status:  corev1.ConditionTrue,
-			Reason:  marketplacev1alpha1.ReasonRazeeInstalled,
-			Message: "RazeeDeployment installed.",
-		})
-
-		if ok {
-			err = r.Client.Status().Update(context.TODO(), marketplaceConfig)
-
-			if err != nil {
-				reqLogger.Error(err, "failed to update status")
-				return reconcile.Result{}, err
-			}
-		}
-
-		return reconcile.Result{Requeue: true}, nil
-	} else if err != nil {
-		reqLogger.Error(err, "Failed to get RazeeDeployment CR")
-		return reconcile.Result{}, err
-	}
-
-	updatedRazee := foundRazee.DeepCopy()
-	updatedRazee.Spec.ClusterUUID = marketplaceConfig.Spec.ClusterUUID
-	updatedRazee.Spec.DeploySecretName = marketplaceConfig.Spec.DeploySecretName
-	updatedRazee.Spec.Features = marketplaceConfig.Spec.Features.DeepCopy()
-
-	if !reflect.DeepEqual(foundRazee, updatedRazee) {
-		reqLogger.Info("updating razee cr")
-		err = r.Client.Update(context.TODO(), updatedRazee)
-
-		if err != nil {
-			reqLogger.Error(err, "Failed to create a new RazeeDeployment CR.")
-			return reconcile.Result{}, err
+	// Disconnected clusters can't reach the razee backend
+	if marketplaceConfig.Spec.Disconnected != nil {
+		if result, err := r.removeRazeeDeployment(reqLogger, marketplaceConfig); err != nil || result.Requeue {
+			return result, err
 		}
-
-		ok := marketplaceConfig.Status.Conditions.SetCondition(status.Condition{
-			Type:    marketplacev1alpha1.ConditionInstalling,
-			Status:  corev1.ConditionTrue,
-			Reason:  marketplacev1alpha1.ReasonRazeeInstalled,
-			Message: "RazeeDeployment updated.",
-		})
-
-		if ok {
-			_ = r.Client.Status().Update(context.TODO(), marketplaceConfig)
+	} else {
+		foundRazee = &marketplacev1alpha1.RazeeDeployment{}
+		if result, err := r.reconcileRazeeDeployment(reqLogger, marketplaceConfig, foundRazee); err != nil || result.Requeue {
+			return result, err
 		}
-		return reconcile.Result{Requeue: true}, nil
 	}
 
 	foundMeterBase := &marketplacev1alpha1.MeterBase{}
@@ -333,6 +282,46 @@ func (r *MarketplaceConfigReconciler) Reconcile(request reconcile.Request) (reco
 
 	reqLogger.Info("found meterbase")
 
+	// Outbound clients trust the CA bundle injected by OpenShift
+	result, _ = cc.Do(
+		context.TODO(),
+		manifests.CreateIfNotExistsFactoryItem(
+			&corev1.ConfigMap{},
+			func() (runtime.Object, error) {
+				return r.factory.TrustedCABundleConfigMap(), nil
+			},
+			CreateWithAddController(marketplaceConfig),
+		),
+	)
+
+	if !result.Is(Continue) {
+		if result.Is(Error) {
+			reqLogger.Error(result.GetError(), "Failed to create trusted ca bundle.")
+		}
+		return result.Return()
+	}
+
+	// Disconnected clusters keep their reports in a local archive
+	if marketplaceConfig.Spec.Disconnected != nil {
+		result, _ := cc.Do(
+			context.TODO(),
+			manifests.CreateIfNotExistsFactoryItem(
+				&corev1.PersistentVolumeClaim{},
+				func() (runtime.Object, error) {
+					return r.factory.ReportArchivePVC(marketplaceConfig)
+				},
+				CreateWithAddController(marketplaceConfig),
+			),
+		)
+
+		if !result.Is(Continue) {
+			if result.Is(Error) {
+				reqLogger.Error(result.GetError(), "Failed to create report archive.")
+			}
+			return result.Return()
+		}
+	}
+
 	// Check if operator source exists, or create a new one
 	foundOpSrc := &unstructured.Unstructured{}
 	foundOpSrc.SetGroupVersionKind(schema.GroupVersionKind{
@@ -450,11 +439,11 @@ func (r *MarketplaceConfigReconciler) Reconcile(request reconcile.Request) (reco
 
 	if ok {
 		reqLogger.Info("attempting to update registration")
-		marketplaceClient, err := marketplace.NewMarketplaceClient(&marketplace.MarketplaceClientConfig{
-			Url:      r.cfg.Marketplace.URL,
-			Token:    string(pullSecret),
-			Insecure: r.cfg.Marketplace.InsecureClient,
-		})
+		marketplaceClient, err := newMarketplaceBackend(r.Client, r.marketplaceBackend, marketplaceConfig, string(pullSecret))
+		if err != nil {
+			reqLogger.Error(err, "failed to build marketplace client")
+			return reconcile.Result{}, err
+		}
 
 		marketplaceClientAccount := &marketplace.MarketplaceClientAccount{
 			AccountId:   marketplaceConfig.Spec.RhmAccountID,
@@ -473,7 +462,20 @@ func (r *MarketplaceConfigReconciler) Reconcile(request reconcile.Request) (reco
 		statusConditions := registrationStatusOutput.TransformConfigStatus()
 
 		for _, cond := range statusConditions {
-			updated = updated || marketplaceConfig.Status.Conditions.SetCondition(cond)
+			if !marketplaceConfig.Status.Conditions.SetCondition(cond) {
+				continue
+			}
+
+			updated = true
+
+			if cond.Type == marketplacev1alpha1.ConditionRegistered {
+				eventType := corev1.EventTypeNormal
+				if cond.Status != corev1.ConditionTrue {
+					eventType = corev1.EventTypeWarning
+				}
+
+				r.recorder.Event(marketplaceConfig, eventType, marketplacev1alpha1.EventReasonRegistrationStateChanged, cond.Message)
+			}
 		}
 	}
 
@@ -497,6 +499,114 @@ func labelsForMarketplaceConfig(name string) map[string]string {
 	return map[string]string{"app": "marketplaceconfig", "marketplaceconfig_cr": name}
 }
 
+// reconcileRazeeDeployment creates the RazeeDeployment of the marketplace
+// config into foundRazee and keeps its spec up to date.
+func (r *MarketplaceConfigReconciler) reconcileRazeeDeployment(
+	reqLogger logr.Logger,
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+	foundRazee *marketplacev1alpha1.RazeeDeployment,
+) (reconcile.Result, error) {
+	//Check if RazeeDeployment exists, if not create one
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: utils.RAZEE_NAME, Namespace: marketplaceConfig.Namespace}, foundRazee)
+	if err != nil && errors.IsNotFound(err) {
+		newRazeeCrd := utils.BuildRazeeCr(marketplaceConfig.Namespace, marketplaceConfig.Spec.ClusterUUID, marketplaceConfig.Spec.DeploySecretName, marketplaceConfig.Spec.Features)
+
+		// Sets the owner for foundRazee
+		if err = controllerutil.SetControllerReference(marketplaceConfig, newRazeeCrd, r.Scheme); err != nil {
+			reqLogger.Error(err, "Failed to create a new RazeeDeployment CR.")
+			return reconcile.Result{}, err
+		}
+
+		reqLogger.Info("creating razee cr")
+		err = r.Client.Create(context.TODO(), newRazeeCrd)
+
+		if err != nil {
+			reqLogger.Error(err, "Failed to create a new RazeeDeployment CR.")
+			return reconcile.Result{}, err
+		}
+
+		ok := marketplaceConfig.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ConditionInstalling,
+			Status:  corev1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonRazeeInstalled,
+			Message: "RazeeDeployment installed.",
+		})
+
+		if ok {
+			err = r.Client.Status().Update(context.TODO(), marketplaceConfig)
+
+			if err != nil {
+				reqLogger.Error(err, "failed to update status")
+				return reconcile.Result{}, err
+			}
+		}
+
+		return reconcile.Result{Requeue: true}, nil
+	} else if err != nil {
+		reqLogger.Error(err, "Failed to get RazeeDeployment CR")
+		return reconcile.Result{}, err
+	}
+
+	updatedRazee := foundRazee.DeepCopy()
+	updatedRazee.Spec.ClusterUUID = marketplaceConfig.Spec.ClusterUUID
+	updatedRazee.Spec.DeploySecretName = marketplaceConfig.Spec.DeploySecretName
+	updatedRazee.Spec.Features = marketplaceConfig.Spec.Features.DeepCopy()
+
+	if !reflect.DeepEqual(foundRazee, updatedRazee) {
+		reqLogger.Info("updating razee cr")
+		err = r.Client.Update(context.TODO(), updatedRazee)
+
+		if err != nil {
+			reqLogger.Error(err, "Failed to create a new RazeeDeployment CR.")
+			return reconcile.Result{}, err
+		}
+
+		ok := marketplaceConfig.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ConditionInstalling,
+			Status:  corev1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonRazeeInstalled,
+			Message: "RazeeDeployment updated.",
+		})
+
+		if ok {
+			_ = r.Client.Status().Update(context.TODO(), marketplaceConfig)
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// removeRazeeDeployment deletes the RazeeDeployment of the marketplace config,
+// its finalizer uninstalls razee.
+func (r *MarketplaceConfigReconciler) removeRazeeDeployment(
+	reqLogger logr.Logger,
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+) (reconcile.Result, error) {
+	razee := &marketplacev1alpha1.RazeeDeployment{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: utils.RAZEE_NAME, Namespace: marketplaceConfig.Namespace}, razee)
+	if errors.IsNotFound(err) {
+		return reconcile.Result{}, nil
+	}
+
+	if err != nil {
+		reqLogger.Error(err, "Failed to get RazeeDeployment CR")
+		return reconcile.Result{}, err
+	}
+
+	if razee.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	reqLogger.Info("deleting razee cr of a disconnected cluster")
+	if err := r.Client.Delete(context.TODO(), razee); err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Failed to delete RazeeDeployment CR")
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{Requeue: true}, nil
+}
+
 // Begin installation or deletion of Catalog Source
 func (r *MarketplaceConfigReconciler) createCatalogSource(request reconcile.Request, marketplaceConfig *marketplacev1alpha1.MarketplaceConfig, catalogName string) (bool, error) {
 	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name, "CatalogSource.Name", catalogName)
@@ -624,11 +734,26 @@ func (r *MarketplaceConfigReconciler) InjectCommandRunner(ccp ClientCommandRunne
 	return nil
 }
 
+func (r *MarketplaceConfigReconciler) InjectEventRecorder(rec record.EventRecorder) error {
+	r.recorder = rec
+	return nil
+}
+
 func (m *MarketplaceConfigReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
 	m.cfg = cfg
 	return nil
 }
 
+func (m *MarketplaceConfigReconciler) InjectFactory(f *manifests.Factory) error {
+	m.factory = f
+	return nil
+}
+
+func (m *MarketplaceConfigReconciler) InjectMarketplaceBackend(provider marketplace.MarketplaceBackendProvider) error {
+	m.marketplaceBackend = provider
+	return nil
+}
+
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func (r *MarketplaceConfigReconciler) SetupWithManager(mgr manager.Manager) error {
 	// Create a new controller
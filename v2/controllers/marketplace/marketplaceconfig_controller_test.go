@@ -15,10 +15,13 @@
 package marketplace
 
 import (
+	"context"
+
 	"github.com/gotidy/ptr"
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/tests/rectest"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 	opsrcApi "github.com/operator-framework/api/pkg/operators/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
@@ -28,11 +31,13 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -84,10 +89,11 @@ var _ = Describe("Testing with Ginkgo", func() {
 
 				r.Client = fake.NewFakeClient(r.GetGetObjects()...)
 				r.Reconciler = &MarketplaceConfigReconciler{
-					Client: r.Client,
-					Scheme: s,
-					Log:    log,
-					cc:     reconcileutils.NewLoglessClientCommand(r.Client, s),
+					Client:   r.Client,
+					Scheme:   s,
+					Log:      log,
+					cc:       reconcileutils.NewLoglessClientCommand(r.Client, s),
+					recorder: record.NewFakeRecorder(10),
 					cfg: &config.OperatorConfig{
 						DeployedNamespace: namespace,
 					},
@@ -134,4 +140,27 @@ var _ = Describe("Testing with Ginkgo", func() {
 		viper.Set("IBMCatalogSource", true)
 		testCleanInstall(GinkgoT())
 	})
+
+	It("should remove razee from disconnected clusters", func() {
+		namespace := "redhat-marketplace-operator"
+		marketplaceconfig := utils.BuildMarketplaceConfigCR(namespace, "example-userid")
+		marketplaceconfig.Spec.Disconnected = &marketplacev1alpha1.DisconnectedSpec{}
+		razeedeployment := utils.BuildRazeeCr(namespace, marketplaceconfig.Spec.ClusterUUID, marketplaceconfig.Spec.DeploySecretName, nil)
+
+		s := scheme.Scheme
+		s.AddKnownTypes(marketplacev1alpha1.SchemeGroupVersion, marketplaceconfig, razeedeployment)
+		client := fake.NewFakeClientWithScheme(s, marketplaceconfig, razeedeployment)
+		r := &MarketplaceConfigReconciler{Client: client, Scheme: s, Log: logf.Log}
+
+		result, err := r.removeRazeeDeployment(logf.Log, marketplaceconfig)
+		Expect(err).To(Succeed())
+		Expect(result.Requeue).To(BeTrue())
+
+		err = client.Get(context.TODO(), types.NamespacedName{Name: utils.RAZEE_NAME, Namespace: namespace}, &marketplacev1alpha1.RazeeDeployment{})
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+		result, err = r.removeRazeeDeployment(logf.Log, marketplaceconfig)
+		Expect(err).To(Succeed())
+		Expect(result.Requeue).To(BeFalse())
+	})
 })
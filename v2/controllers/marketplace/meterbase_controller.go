@@ -18,15 +18,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/gotidy/ptr"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/authcheck"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/certificates"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/runnables"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/operrors"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/patch"
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
@@ -37,19 +44,26 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
 	prom "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/prometheus"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
 	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -64,6 +78,14 @@ const (
 // blank assignment to verify that ReconcileMeterBase implements reconcile.Reconciler
 var _ reconcile.Reconciler = &MeterBaseReconciler{}
 
+func init() {
+	runnables.RegisterRemediationPolicies(
+		runnables.AuthCheckRemediation("metric-state", map[string]string{"app.kubernetes.io/name": "rhm-metric-state"}),
+		runnables.AuthCheckRemediation("prometheus-operator", map[string]string{"app.kubernetes.io/name": "prometheus-operator"}),
+		runnables.AuthCheckRemediation("prometheus", map[string]string{"app": "prometheus"}),
+	)
+}
+
 // MeterBaseReconciler reconciles a MeterBase object
 type MeterBaseReconciler struct {
 	// This Client, initialized using mgr.Client() above, is a split Client
@@ -73,8 +95,13 @@ type MeterBaseReconciler struct {
 	Log    logr.Logger
 	CC     ClientCommandRunner
 
-	factory *manifests.Factory
-	patcher patch.Patcher
+	cfg      *config.OperatorConfig
+	factory  *manifests.Factory
+	patcher  patch.Patcher
+	recorder record.EventRecorder
+
+	seriesBudgetMutex   sync.Mutex
+	seriesBudgetChecked map[types.NamespacedName]time.Time
 }
 
 func (r *MeterBaseReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
@@ -88,6 +115,11 @@ func (r *MeterBaseReconciler) InjectCommandRunner(ccp ClientCommandRunner) error
 	return nil
 }
 
+func (r *MeterBaseReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
+}
+
 func (r *MeterBaseReconciler) InjectPatch(p patch.Patcher) error {
 	r.patcher = p
 	return nil
@@ -98,15 +130,17 @@ func (r *MeterBaseReconciler) InjectFactory(f *manifests.Factory) error {
 	return nil
 }
 
+func (r *MeterBaseReconciler) InjectEventRecorder(rec record.EventRecorder) error {
+	r.recorder = rec
+	return nil
+}
+
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func (r *MeterBaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	mapFn := handler.ToRequestsFunc(
 		func(a handler.MapObject) []reconcile.Request {
 			return []reconcile.Request{
-				{NamespacedName: types.NamespacedName{
-					Name:      "rhm-marketplaceconfig-meterbase",
-					Namespace: "openshift-redhat-marketplace",
-				}},
+				{NamespacedName: r.meterBaseKey()},
 			}
 		})
 
@@ -146,7 +180,79 @@ func (r *MeterBaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&source.Kind{Type: &corev1.Namespace{}},
 			&handler.EnqueueRequestsFromMapFunc{
 				ToRequests: mapFn,
-			}).Complete(r)
+			}).
+		Watches(
+			&source.Kind{Type: &marketplacev1beta1.MeterDefinition{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: mapFn,
+			},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &monitoringv1.ServiceMonitor{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: mapFn,
+			},
+			builder.WithPredicates(r.scrapeSourcePredicate(
+				func(spec *marketplacev1alpha1.ScrapeSourcesSpec) *metav1.LabelSelector {
+					return spec.ServiceMonitorSelector
+				}))).
+		Watches(
+			&source.Kind{Type: &monitoringv1.PodMonitor{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: mapFn,
+			},
+			builder.WithPredicates(r.scrapeSourcePredicate(
+				func(spec *marketplacev1alpha1.ScrapeSourcesSpec) *metav1.LabelSelector {
+					return spec.PodMonitorSelector
+				}))).
+		Complete(r)
+}
+
+// meterBaseKey is the key of the MeterBase in the operator namespace.
+func (r *MeterBaseReconciler) meterBaseKey() types.NamespacedName {
+	return types.NamespacedName{
+		Name:      utils.METERBASE_NAME,
+		Namespace: r.cfg.DeployedNamespace,
+	}
+}
+
+// scrapeSourcePredicate passes the monitors selected by the scrape sources of
+// the MeterBase, or that were selected before an update.
+func (r *MeterBaseReconciler) scrapeSourcePredicate(
+	selectorFor func(*marketplacev1alpha1.ScrapeSourcesSpec) *metav1.LabelSelector,
+) predicate.Funcs {
+	selected := func(obj metav1.Object) bool {
+		meterbase := &marketplacev1alpha1.MeterBase{}
+		if err := r.Client.Get(context.TODO(), r.meterBaseKey(), meterbase); err != nil {
+			return false
+		}
+
+		if meterbase.Spec.ScrapeSources == nil || selectorFor(meterbase.Spec.ScrapeSources) == nil {
+			return false
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(selectorFor(meterbase.Spec.ScrapeSources))
+		if err != nil {
+			return false
+		}
+
+		return selector.Matches(labels.Set(obj.GetLabels()))
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return selected(e.Meta)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return selected(e.MetaOld) || selected(e.MetaNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return selected(e.Meta)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
 }
 
 // Reconcile reads that state of the cluster for a MeterBase object and makes changes based on the state read
@@ -240,14 +346,28 @@ func (r *MeterBaseReconciler) Reconcile(request reconcile.Request) (reconcile.Re
 
 	cfg := &corev1.Secret{}
 	prometheus := &monitoringv1.Prometheus{}
-	if result, _ := cc.Do(context.TODO(),
-		Do(r.reconcilePrometheusOperator(instance, factory)...),
+	installActions := []ClientAction{
+		Do(r.reconcileServingCertificates(instance, factory)...),
 		Do(r.installMetricStateDeployment(instance, factory)...),
-		Do(r.reconcileAdditionalConfigSecret(cc, instance, prometheus, factory, cfg)...),
-		Do(r.reconcilePrometheus(instance, prometheus, factory, cfg)...),
-		Do(r.verifyPVCSize(reqLogger, instance, factory, prometheus)...),
-		Do(r.recyclePrometheusPods(reqLogger, instance, factory, prometheus)...),
-	); !result.Is(Continue) {
+		Do(r.uninstallInternalPrometheus(instance, factory)...),
+	}
+
+	// An external prometheus scrapes the metric state service monitor, so
+	// there is nothing else to install and a prometheus installed before is
+	// removed
+	if instance.Spec.ExternalPrometheus == nil {
+		installActions = []ClientAction{
+			Do(r.reconcileServingCertificates(instance, factory)...),
+			Do(r.reconcilePrometheusOperator(instance, factory)...),
+			Do(r.installMetricStateDeployment(instance, factory)...),
+			Do(r.reconcileAdditionalConfigSecret(cc, instance, prometheus, factory, cfg)...),
+			Do(r.reconcilePrometheus(instance, prometheus, factory, cfg)...),
+			Do(r.verifyPVCSize(reqLogger, instance, factory, prometheus)...),
+			Do(r.recyclePrometheusPods(reqLogger, instance, factory, prometheus)...),
+		}
+	}
+
+	if result, _ := cc.Do(context.TODO(), installActions...); !result.Is(Continue) {
 		if result.Is(Error) {
 			reqLogger.Error(result, "error in reconcile")
 			return result.ReturnWithError(merrors.Wrap(result, "error creating prometheus"))
@@ -270,42 +390,13 @@ func (r *MeterBaseReconciler) Reconcile(request reconcile.Request) (reconcile.Re
 			Namespace: instance.Namespace,
 			Name:      instance.Name,
 		}, instance),
-		HandleResult(
-			GetAction(types.NamespacedName{
-				Namespace: prometheus.Namespace,
-				Name:      fmt.Sprintf("prometheus-%s", prometheus.Name),
-			}, prometheusStatefulset),
-			OnContinue(Call(func() (ClientAction, error) {
-				updatedInstance := instance.DeepCopy()
-				updatedInstance.Status.Replicas = &prometheusStatefulset.Status.Replicas
-				updatedInstance.Status.UpdatedReplicas = &prometheusStatefulset.Status.UpdatedReplicas
-				updatedInstance.Status.AvailableReplicas = &prometheusStatefulset.Status.ReadyReplicas
-				updatedInstance.Status.UnavailableReplicas = ptr.Int32(
-					prometheusStatefulset.Status.CurrentReplicas - prometheusStatefulset.Status.ReadyReplicas)
-
-				var action ClientAction = nil
-
-				reqLogger.Info("statefulset status", "status", updatedInstance.Status)
-
-				if prometheusStatefulset.Status.Replicas != prometheusStatefulset.Status.ReadyReplicas {
-					reqLogger.Info("prometheus statefulset has not finished roll out",
-						"replicas", prometheusStatefulset.Status.Replicas,
-						"ready", prometheusStatefulset.Status.ReadyReplicas)
-					action = RequeueAfterResponse(5 * time.Second)
-				}
-
-				if !reflect.DeepEqual(updatedInstance.Status, instance.Status) {
-					reqLogger.Info("prometheus statefulset status is up to date")
-					return HandleResult(UpdateAction(updatedInstance, UpdateStatusOnly(true)), OnContinue(action)), nil
-				}
+		Call(func() (ClientAction, error) {
+			if instance.Spec.ExternalPrometheus != nil {
+				return nil, nil
+			}
 
-				return action, nil
-			})),
-			OnNotFound(Call(func() (ClientAction, error) {
-				reqLogger.Info("can't find prometheus statefulset, requeuing")
-				return RequeueAfterResponse(30 * time.Second), nil
-			})),
-		),
+			return r.prometheusStatus(reqLogger, instance, prometheus, prometheusStatefulset), nil
+		}),
 	); result.Is(Error) || result.Is(Requeue) {
 		if err != nil {
 			return result.ReturnWithError(merrors.Wrap(err, "error creating service monitor"))
@@ -330,6 +421,20 @@ func (r *MeterBaseReconciler) Reconcile(request reconcile.Request) (reconcile.Re
 		return result.Return()
 	}
 
+	if instance.Spec.ExternalPrometheus == nil {
+		if err := r.reconcileSeriesBudget(reqLogger, instance, factory); err != nil {
+			reqLogger.Error(err, "failed to update the meter definition series counts")
+		}
+
+		if err := r.reconcileStorageGrowth(reqLogger, instance, factory); err != nil {
+			reqLogger.Error(err, "failed to check the prometheus storage")
+		}
+	}
+
+	if err := r.reconcilePermissions(reqLogger, instance); err != nil {
+		reqLogger.Error(err, "failed to check the workload permissions")
+	}
+
 	meterReportList := &marketplacev1alpha1.MeterReportList{}
 	if result, err := cc.Do(
 		context.TODO(),
@@ -337,7 +442,7 @@ func (r *MeterBaseReconciler) Reconcile(request reconcile.Request) (reconcile.Re
 			ListAction(meterReportList, client.InNamespace(request.Namespace)),
 			OnContinue(Call(func() (ClientAction, error) {
 				loc := time.UTC
-				dateRangeInDays := -30
+				dateRangeInDays := -manifests.MeterReportRetentionDays
 
 				meterReportNames := r.sortMeterReports(meterReportList)
 
@@ -368,6 +473,12 @@ func (r *MeterBaseReconciler) Reconcile(request reconcile.Request) (reconcile.Re
 					return nil, err
 				}
 
+				err = r.migratePendingReports(reqLogger, meterReportList.Items, meterReportNames, instance)
+
+				if err != nil {
+					return nil, err
+				}
+
 				return nil, nil
 			})),
 			OnNotFound(Call(func() (ClientAction, error) {
@@ -383,12 +494,128 @@ func (r *MeterBaseReconciler) Reconcile(request reconcile.Request) (reconcile.Re
 		return result.Return()
 	}
 
+	// the requeue is the timer of the series budget checks
+	requeueAfter := time.Hour * 1
+	if r.cfg != nil && r.cfg.Metering.SeriesBudgetInterval > 0 && r.cfg.Metering.SeriesBudgetInterval < requeueAfter {
+		requeueAfter = r.cfg.Metering.SeriesBudgetInterval
+	}
+
 	reqLogger.Info("finished reconciling")
-	return reconcile.Result{RequeueAfter: time.Hour * 1}, nil
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
 }
 
 const promServiceName = "rhm-prometheus-meterbase"
 
+// prometheusStatus copies the status of the prometheus statefulset to the meterbase.
+func (r *MeterBaseReconciler) prometheusStatus(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterBase,
+	prometheus *monitoringv1.Prometheus,
+	prometheusStatefulset *appsv1.StatefulSet,
+) ClientAction {
+	pods := &corev1.PodList{}
+
+	return HandleResult(
+		GetAction(types.NamespacedName{
+			Namespace: prometheus.Namespace,
+			Name:      fmt.Sprintf("prometheus-%s", prometheus.Name),
+		}, prometheusStatefulset),
+		OnContinue(Do(
+			ListAction(pods, client.InNamespace(prometheus.Namespace), prometheusPodLabels(prometheus.Name)),
+			Call(func() (ClientAction, error) {
+				updatedInstance := instance.DeepCopy()
+				updatedInstance.Status.PrometheusReplicas = prometheusReplicaStatuses(pods.Items)
+				updatedInstance.Status.Replicas = &prometheusStatefulset.Status.Replicas
+				updatedInstance.Status.UpdatedReplicas = &prometheusStatefulset.Status.UpdatedReplicas
+				updatedInstance.Status.AvailableReplicas = &prometheusStatefulset.Status.ReadyReplicas
+				updatedInstance.Status.UnavailableReplicas = ptr.Int32(
+					prometheusStatefulset.Status.CurrentReplicas - prometheusStatefulset.Status.ReadyReplicas)
+
+				var action ClientAction = nil
+
+				reqLogger.Info("statefulset status", "status", updatedInstance.Status)
+
+				if prometheusStatefulset.Status.Replicas != prometheusStatefulset.Status.ReadyReplicas {
+					reqLogger.Info("prometheus statefulset has not finished roll out",
+						"replicas", prometheusStatefulset.Status.Replicas,
+						"ready", prometheusStatefulset.Status.ReadyReplicas)
+					action = RequeueAfterResponse(5 * time.Second)
+				}
+
+				if !reflect.DeepEqual(updatedInstance.Status, instance.Status) {
+					reqLogger.Info("prometheus statefulset status is up to date")
+					return HandleResult(UpdateAction(updatedInstance, UpdateStatusOnly(true)), OnContinue(action)), nil
+				}
+
+				return action, nil
+			}),
+		)),
+		OnNotFound(Call(func() (ClientAction, error) {
+			reqLogger.Info("can't find prometheus statefulset, requeuing")
+			return RequeueAfterResponse(30 * time.Second), nil
+		})),
+	)
+}
+
+// prometheusPodLabels selects the pods of the Prometheus deployment
+func prometheusPodLabels(name string) client.MatchingLabels {
+	return client.MatchingLabels{"app": "prometheus", "prometheus": name}
+}
+
+// prometheusPodClaim returns the claim holding the Prometheus data of the pod.
+func prometheusPodClaim(pod *corev1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+
+	return ""
+}
+
+// prometheusReplicaStatuses returns the health of the Prometheus pods, sorted by name.
+func prometheusReplicaStatuses(pods []corev1.Pod) []marketplacev1alpha1.PrometheusReplicaStatus {
+	statuses := []marketplacev1alpha1.PrometheusReplicaStatus{}
+
+	for i := range pods {
+		pod := &pods[i]
+		replica := marketplacev1alpha1.PrometheusReplicaStatus{
+			Name:                  pod.Name,
+			Phase:                 pod.Status.Phase,
+			Node:                  pod.Spec.NodeName,
+			PersistentVolumeClaim: prometheusPodClaim(pod),
+		}
+
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady {
+				replica.Ready = cond.Status == corev1.ConditionTrue
+				if !replica.Ready {
+					replica.Message = cond.Message
+				}
+			}
+		}
+
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.Name != "prometheus" {
+				continue
+			}
+
+			replica.Restarts = container.RestartCount
+			if !replica.Ready && container.State.Waiting != nil {
+				replica.Message = container.State.Waiting.Reason
+			}
+		}
+
+		statuses = append(statuses, replica)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
 func (r *MeterBaseReconciler) createReportIfNotFound(expectedCreatedDates []string, foundCreatedDates []string, request reconcile.Request, instance *marketplacev1alpha1.MeterBase) error {
 	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 
@@ -410,6 +637,52 @@ func (r *MeterBaseReconciler) createReportIfNotFound(expectedCreatedDates []stri
 	return nil
 }
 
+// migratePendingReports points the reports that haven't finished at the
+// Prometheus the meterbase uses now, so they don't query a Prometheus that
+// was removed. The next reporter job of the report queries the new one.
+// Only the reports named in meterReportNames, the ones not pruned, are migrated.
+func (r *MeterBaseReconciler) migratePendingReports(
+	reqLogger logr.Logger,
+	reports []marketplacev1alpha1.MeterReport,
+	meterReportNames []string,
+	instance *marketplacev1alpha1.MeterBase,
+) error {
+	for i := range reports {
+		report := &reports[i]
+
+		if !utils.Contains(meterReportNames, report.Name) || reportFinished(report) {
+			continue
+		}
+
+		expected := r.newMeterReport(report.Namespace, report.Spec.StartTime.Time, report.Spec.EndTime.Time, report.Name, instance, promServiceName)
+
+		if reflect.DeepEqual(report.Spec.PrometheusService, expected.Spec.PrometheusService) &&
+			reflect.DeepEqual(report.Spec.ExternalPrometheus, expected.Spec.ExternalPrometheus) {
+			continue
+		}
+
+		report.Spec.PrometheusService = expected.Spec.PrometheusService
+		report.Spec.ExternalPrometheus = expected.Spec.ExternalPrometheus
+
+		if err := r.Client.Update(context.TODO(), report); err != nil {
+			return err
+		}
+
+		reqLogger.Info("Migrated Report Prometheus", "Resource", report.Name, "external", report.Spec.ExternalPrometheus != nil)
+		r.recorder.Event(report, corev1.EventTypeNormal, marketplacev1alpha1.ReportEventReasonPrometheusChanged,
+			"Report queries the Prometheus of the meterbase")
+	}
+
+	return nil
+}
+
+// reportFinished returns true if the reporter job of the report succeeded.
+func reportFinished(report *marketplacev1alpha1.MeterReport) bool {
+	cond := report.Status.Conditions.GetCondition(marketplacev1alpha1.ReportConditionTypeJobRunning)
+	return (cond != nil && cond.Reason == marketplacev1alpha1.ReportConditionReasonJobFinished) ||
+		(report.Status.AssociatedJob != nil && report.Status.AssociatedJob.IsSuccessful())
+}
+
 func (r *MeterBaseReconciler) removeOldReports(meterReportNames []string, loc *time.Location, dateRange int, request reconcile.Request) ([]string, error) {
 	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	limit := utils.TruncateTime(time.Now(), loc).AddDate(0, 0, dateRange)
@@ -509,7 +782,7 @@ func (r *MeterBaseReconciler) generateExpectedDates(endTime time.Time, loc *time
 }
 
 func (r *MeterBaseReconciler) newMeterReport(namespace string, startTime time.Time, endTime time.Time, meterReportName string, instance *marketplacev1alpha1.MeterBase, prometheusServiceName string) *marketplacev1alpha1.MeterReport {
-	return &marketplacev1alpha1.MeterReport{
+	report := &marketplacev1alpha1.MeterReport{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      meterReportName,
 			Namespace: namespace,
@@ -517,13 +790,21 @@ func (r *MeterBaseReconciler) newMeterReport(namespace string, startTime time.Ti
 		Spec: marketplacev1alpha1.MeterReportSpec{
 			StartTime: metav1.NewTime(startTime),
 			EndTime:   metav1.NewTime(endTime),
-			PrometheusService: &common.ServiceReference{
-				Name:       prometheusServiceName,
-				Namespace:  instance.Namespace,
-				TargetPort: intstr.FromString("rbac"),
-			},
 		},
 	}
+
+	if instance.Spec.ExternalPrometheus != nil {
+		report.Spec.ExternalPrometheus = instance.Spec.ExternalPrometheus.DeepCopy()
+		return report
+	}
+
+	report.Spec.PrometheusService = &common.ServiceReference{
+		Name:       prometheusServiceName,
+		Namespace:  instance.Namespace,
+		TargetPort: intstr.FromString("rbac"),
+	}
+
+	return report
 }
 
 func (r *MeterBaseReconciler) reconcilePrometheusSubscription(
@@ -653,6 +934,163 @@ func (r *MeterBaseReconciler) installMetricStateDeployment(
 	}
 }
 
+// servingCertificates are the TLS secrets of the metering services, issued
+// by the openshift service CA on openshift
+var servingCertificates = []struct {
+	secret  string
+	service string
+}{
+	{secret: "prometheus-operator-tls", service: "prometheus-operator"},
+	{secret: "rhm-prometheus-meterbase-tls", service: "rhm-prometheus-meterbase"},
+	{secret: "rhm-metric-state-tls", service: "rhm-metric-state-service"},
+}
+
+const servingCertificatesCAName = "rhm-meterbase-serving-ca"
+
+// reconcileServingCertificates issues the serving certificates of the
+// metering services and writes their CAs to the CA bundle config maps in
+// place of the openshift service CA.
+func (r *MeterBaseReconciler) reconcileServingCertificates(
+	instance *marketplacev1alpha1.MeterBase,
+	factory *manifests.Factory,
+) []ClientAction {
+	if r.hasOpenshiftMonitoring() {
+		return []ClientAction{}
+	}
+
+	args := manifests.CreateOrUpdateFactoryItemArgs{
+		Owner:   instance,
+		Patcher: r.patcher,
+	}
+
+	now := time.Now()
+	bundle := []byte{}
+	actions := []ClientAction{}
+
+	for _, cert := range servingCertificates {
+		secret := &corev1.Secret{}
+		name := types.NamespacedName{Namespace: instance.Namespace, Name: cert.secret}
+		dnsNames := []string{
+			fmt.Sprintf("%s.%s.svc", cert.service, instance.Namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", cert.service, instance.Namespace),
+			cert.service,
+		}
+
+		rotate := func() (bool, error) {
+			data, changed, err := rotateServingCertificate(secret.Data, dnsNames, now)
+			if err != nil {
+				return false, err
+			}
+
+			secret.Data = data
+			bundle = append(bundle, certificates.Bundle(now,
+				data[certificates.SecretCACertKey],
+				data[certificates.SecretPreviousCACertKey])...)
+			return changed, nil
+		}
+
+		actions = append(actions, HandleResult(
+			GetAction(name, secret),
+			OnNotFound(Call(func() (ClientAction, error) {
+				secret.ObjectMeta = metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace}
+				secret.Type = corev1.SecretTypeTLS
+
+				if _, err := rotate(); err != nil {
+					return nil, err
+				}
+
+				return CreateAction(secret, CreateWithAddController(instance)), nil
+			})),
+			OnContinue(Call(func() (ClientAction, error) {
+				changed, err := rotate()
+				if err != nil || !changed {
+					return nil, err
+				}
+
+				return UpdateAction(secret), nil
+			}))))
+	}
+
+	return append(actions,
+		manifests.CreateOrUpdateFactoryItemAction(
+			&corev1.ConfigMap{},
+			func() (runtime.Object, error) {
+				cm, err := factory.PrometheusServingCertsCABundle()
+				if err != nil {
+					return nil, err
+				}
+
+				cm.Data = map[string]string{"service-ca.crt": string(bundle)}
+				return cm, nil
+			},
+			args,
+		),
+		manifests.CreateOrUpdateFactoryItemAction(
+			&corev1.ConfigMap{},
+			func() (runtime.Object, error) {
+				cm, err := factory.NewPrometheusOperatorCertsCABundle()
+				if err != nil {
+					return nil, err
+				}
+
+				cm.Data = map[string]string{"service-ca.crt": string(bundle)}
+				return cm, nil
+			},
+			args,
+		),
+	)
+}
+
+// rotateServingCertificate returns the secret data with a CA and a serving
+// certificate for the DNS names that are valid past their renewal windows.
+// A replaced CA is kept as the previous CA in the bundle.
+func rotateServingCertificate(
+	current map[string][]byte,
+	dnsNames []string,
+	now time.Time,
+) (map[string][]byte, bool, error) {
+	data := map[string][]byte{}
+	for key, value := range current {
+		data[key] = value
+	}
+
+	changed := false
+
+	if len(data[certificates.SecretCAKeyKey]) == 0 ||
+		certificates.NeedsRenewal(data[certificates.SecretCACertKey], now, certificates.CARenewBefore) {
+		ca, err := certificates.GenerateCA(servingCertificatesCAName, now)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if len(data[certificates.SecretCACertKey]) != 0 {
+			data[certificates.SecretPreviousCACertKey] = certificates.Bundle(now, data[certificates.SecretCACertKey])
+		}
+
+		data[certificates.SecretCACertKey] = ca.Cert
+		data[certificates.SecretCAKeyKey] = ca.Key
+		changed = true
+	}
+
+	if changed ||
+		certificates.NeedsRenewal(data[corev1.TLSCertKey], now, certificates.ServingRenewBefore) ||
+		certificates.Verify(data[certificates.SecretCACertKey], data[corev1.TLSCertKey], dnsNames[0], now) != nil {
+		serving, err := certificates.GenerateServingCert(&certificates.KeyPair{
+			Cert: data[certificates.SecretCACertKey],
+			Key:  data[certificates.SecretCAKeyKey],
+		}, dnsNames, now)
+		if err != nil {
+			return nil, false, err
+		}
+
+		data[corev1.TLSCertKey] = serving.Cert
+		data[corev1.TLSPrivateKeyKey] = serving.Key
+		changed = true
+	}
+
+	return data, changed, nil
+}
+
 func (r *MeterBaseReconciler) uninstallPrometheusOperator(
 	instance *marketplacev1alpha1.MeterBase,
 	factory *manifests.Factory,
@@ -695,10 +1133,11 @@ func (r *MeterBaseReconciler) reconcileAdditionalConfigSecret(
 	additionalConfigSecret *corev1.Secret,
 ) []ClientAction {
 	reqLogger := r.Log.WithValues("func", "reconcileAdditionalConfigSecret", "Request.Namespace", instance.Namespace, "Request.Name", instance.Name)
-	openshiftKubeletMonitor := &monitoringv1.ServiceMonitor{}
-	openshiftKubeStateMonitor := &monitoringv1.ServiceMonitor{}
+	kubeletMonitor := &monitoringv1.ServiceMonitor{}
+	kubeStateMonitor := &monitoringv1.ServiceMonitor{}
 	metricStateMonitor := &monitoringv1.ServiceMonitor{}
 	secretsInNamespace := &corev1.SecretList{}
+	sources := &scrapeSourceMonitors{}
 
 	sm, err := factory.MetricStateServiceMonitor()
 
@@ -706,18 +1145,30 @@ func (r *MeterBaseReconciler) reconcileAdditionalConfigSecret(
 		reqLogger.Error(err, "error getting metric state")
 	}
 
-	return []ClientAction{
+	var serviceMonitorAction ClientAction = HandleResult(
 		Do(
-			HandleResult(
-				Do(
-					GetAction(types.NamespacedName{
-						Namespace: "openshift-monitoring",
-						Name:      "kubelet",
-					}, openshiftKubeletMonitor),
-					GetAction(types.NamespacedName{
-						Namespace: "openshift-monitoring",
-						Name:      "kube-state-metrics",
-					}, openshiftKubeStateMonitor),
+			GetAction(types.NamespacedName{
+				Namespace: "openshift-monitoring",
+				Name:      "kubelet",
+			}, kubeletMonitor),
+			GetAction(types.NamespacedName{
+				Namespace: "openshift-monitoring",
+				Name:      "kube-state-metrics",
+			}, kubeStateMonitor)),
+		OnNotFound(ReturnWithError(errors.New("required serviceMonitor not found"))),
+		OnError(ReturnWithError(errors.New("required serviceMonitor errored"))))
+
+	// without openshift-monitoring the service monitors are built from the
+	// kubelet and kube-state-metrics services
+	if !r.hasOpenshiftMonitoring() {
+		serviceMonitorAction = Do(r.kubernetesServiceMonitors(instance, factory, kubeletMonitor, kubeStateMonitor)...)
+	}
+
+	return []ClientAction{
+		serviceMonitorAction,
+		Do(
+			HandleResult(
+				Do(
 					GetAction(types.NamespacedName{
 						Namespace: sm.ObjectMeta.Namespace,
 						Name:      sm.ObjectMeta.Name,
@@ -726,10 +1177,11 @@ func (r *MeterBaseReconciler) reconcileAdditionalConfigSecret(
 				OnNotFound(ReturnWithError(errors.New("required serviceMonitor not found"))),
 				OnError(ReturnWithError(errors.New("required serviceMonitor errored")))),
 		),
+		Do(r.listScrapeSources(instance, sources)...),
 		Call(func() (ClientAction, error) {
 			newEndpoints := []monitoringv1.Endpoint{}
 
-			for _, ep := range openshiftKubeStateMonitor.Spec.Endpoints {
+			for _, ep := range kubeStateMonitor.Spec.Endpoints {
 				newEp := ep.DeepCopy()
 				configs := []*monitoringv1.RelabelConfig{
 					{
@@ -742,59 +1194,1089 @@ func (r *MeterBaseReconciler) reconcileAdditionalConfigSecret(
 				newEndpoints = append(newEndpoints, *newEp)
 			}
 
-			openshiftKubeStateMonitor.Spec.Endpoints = newEndpoints
+			kubeStateMonitor.Spec.Endpoints = newEndpoints
+
+			sMons := map[string]*monitoringv1.ServiceMonitor{
+				"kube-state": kubeStateMonitor,
+				"kubelet":    kubeletMonitor,
+			}
+			sMons[metricStateMonitor.Name] = metricStateMonitor
+
+			pMons, sourceStatuses := selectScrapeSources(sources, sMons)
+
+			cfgGen := prom.NewConfigGenerator(reqLogger).
+				WithScrapeLimits(scrapeLimits(instance.Spec.ScrapeLimits, metricStateMonitor.Name, sMons, pMons))
+
+			basicAuthSecrets, err := prom.LoadBasicAuthSecrets(r.Client, sMons, prometheus.Spec.RemoteRead, prometheus.Spec.RemoteWrite, prometheus.Spec.APIServerConfig, secretsInNamespace)
+			if err != nil {
+				return nil, err
+			}
+
+			bearerTokens, err := prom.LoadBearerTokensFromSecrets(r.Client, sMons)
+			if err != nil {
+				return nil, err
+			}
+
+			podBasicAuthSecrets, err := prom.LoadPodMonitorBasicAuthSecrets(r.Client, pMons)
+			if err != nil {
+				return nil, err
+			}
+
+			podBearerTokens, err := prom.LoadPodMonitorBearerTokensFromSecrets(r.Client, pMons)
+			if err != nil {
+				return nil, err
+			}
+
+			for k, v := range podBasicAuthSecrets {
+				basicAuthSecrets[k] = v
+			}
+
+			for k, v := range podBearerTokens {
+				bearerTokens[k] = v
+			}
+
+			cfg, err := cfgGen.GenerateConfig(prometheus, sMons, pMons, basicAuthSecrets, bearerTokens, []string{})
+
+			if err != nil {
+				return nil, err
+			}
+
+			sec, err := factory.PrometheusAdditionalConfigSecret(cfg)
+
+			if err != nil {
+				return nil, err
+			}
+
+			key, err := client.ObjectKeyFromObject(sec)
+
+			if err != nil {
+				return nil, err
+			}
+
+			var statusAction ClientAction
+			if !reflect.DeepEqual(instance.Status.ScrapeSources, sourceStatuses) {
+				instance.Status.ScrapeSources = sourceStatuses
+				statusAction = UpdateAction(instance, UpdateStatusOnly(true))
+			}
+
+			return Do(
+				HandleResult(
+					GetAction(key, additionalConfigSecret),
+					OnNotFound(CreateAction(sec, CreateWithAddController(instance))),
+					OnContinue(Call(func() (ClientAction, error) {
+
+						if reflect.DeepEqual(additionalConfigSecret.Data, sec.Data) {
+							return nil, nil
+						}
+
+						return UpdateAction(sec), nil
+					}))),
+				Call(func() (ClientAction, error) {
+					return statusAction, nil
+				})), nil
+		}),
+	}
+}
+
+// scrapeLimits returns the limits of spec.scrapeLimits by monitor identifier.
+func scrapeLimits(
+	spec *marketplacev1alpha1.ScrapeLimitsSpec,
+	metricStateName string,
+	sMons map[string]*monitoringv1.ServiceMonitor,
+	pMons map[string]*monitoringv1.PodMonitor,
+) map[string]prom.ScrapeLimit {
+	limits := map[string]prom.ScrapeLimit{}
+
+	if spec == nil {
+		return limits
+	}
+
+	set := func(identifier string, limit *marketplacev1alpha1.ScrapeLimit) {
+		if limit != nil {
+			limits[identifier] = prom.ScrapeLimit{
+				SampleLimit: limit.SampleLimit,
+				LabelLimit:  limit.LabelLimit,
+			}
+		}
+	}
+
+	set("kube-state", spec.KubeStateMetrics)
+	set("kubelet", spec.Kubelet)
+	set(metricStateName, spec.MetricState)
+
+	for identifier := range sMons {
+		if strings.HasPrefix(identifier, scrapeSourcePrefix) {
+			set(identifier, spec.ScrapeSources)
+		}
+	}
+
+	for identifier := range pMons {
+		set(identifier, spec.ScrapeSources)
+	}
+
+	return limits
+}
+
+// bytesPerSeries is the memory Prometheus needs for a head series, used to
+// derive the series budget from the memory of the Prometheus deployment.
+const bytesPerSeries = 8 * 1024
+
+// seriesBudget returns the number of head series the metering Prometheus can
+// hold, or 0 when there is no budget.
+func seriesBudget(instance *marketplacev1alpha1.MeterBase) int64 {
+	spec := instance.Spec.Prometheus
+	if spec == nil {
+		return 0
+	}
+
+	if spec.SeriesBudget != nil {
+		return *spec.SeriesBudget
+	}
+
+	memory, ok := spec.Limits[corev1.ResourceMemory]
+	if !ok {
+		memory, ok = spec.Requests[corev1.ResourceMemory]
+	}
+
+	if !ok {
+		return 0
+	}
+
+	return memory.Value() / bytesPerSeries
+}
+
+// seriesBudgetCondition returns the series budget warning of a meter
+// definition reading count of the total head series. Only the offenders of
+// a Prometheus near or over its budget get the warning.
+func seriesBudgetCondition(count, total, budget int64, warning float64, offender bool) status.Condition {
+	message := fmt.Sprintf("The meter definition reads %d series. Prometheus holds %d series of a budget of %d.", count, total, budget)
+
+	switch {
+	case !offender || count == 0 || float64(total) < float64(budget)*warning:
+		return status.Condition{
+			Type:    common.MeterDefConditionTypeSeriesBudgetWarning,
+			Status:  corev1.ConditionFalse,
+			Reason:  common.MeterDefConditionReasonWithinSeriesBudget,
+			Message: message,
+		}
+	case total >= budget:
+		return status.Condition{
+			Type:    common.MeterDefConditionTypeSeriesBudgetWarning,
+			Status:  corev1.ConditionTrue,
+			Reason:  common.MeterDefConditionReasonOverSeriesBudget,
+			Message: message,
+		}
+	default:
+		return status.Condition{
+			Type:    common.MeterDefConditionTypeSeriesBudgetWarning,
+			Status:  corev1.ConditionTrue,
+			Reason:  common.MeterDefConditionReasonNearSeriesBudget,
+			Message: message,
+		}
+	}
+}
+
+// seriesBudgetOffenders returns the meter definitions reading the most
+// series that together hold the series over the warning threshold of the
+// budget. None are returned when the meter definitions can't bring
+// Prometheus back under the threshold.
+func seriesBudgetOffenders(counts map[string]int64, total, budget int64, warning float64) map[string]bool {
+	offenders := map[string]bool{}
+	excess := float64(total) - float64(budget)*warning
+
+	if budget <= 0 || excess < 0 {
+		return offenders
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var sum int64
+	for _, key := range keys {
+		if counts[key] == 0 {
+			break
+		}
+
+		offenders[key] = true
+		sum += counts[key]
+
+		if float64(sum) >= excess {
+			return offenders
+		}
+	}
+
+	return map[string]bool{}
+}
+
+// meterDefinitionSelectors returns the series selectors of the meter queries.
+func meterDefinitionSelectors(mdef *marketplacev1beta1.MeterDefinition) []string {
+	found := map[string]bool{}
+	selectors := []string{}
+
+	for _, meter := range mdef.Spec.Meters {
+		// invalid queries fail the reports, they have no series to count
+		querySelectors, err := prom.QuerySelectors(meter.Query)
+		if err != nil {
+			continue
+		}
+
+		for _, selector := range querySelectors {
+			if !found[selector] {
+				found[selector] = true
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+
+	sort.Strings(selectors)
+	return selectors
+}
+
+// seriesBudgetDue returns if the series budget of the meterbase wasn't
+// checked within the interval.
+func (r *MeterBaseReconciler) seriesBudgetDue(instance *marketplacev1alpha1.MeterBase, now time.Time) bool {
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+
+	r.seriesBudgetMutex.Lock()
+	defer r.seriesBudgetMutex.Unlock()
+
+	last, ok := r.seriesBudgetChecked[key]
+	return !ok || now.Sub(last) >= r.cfg.Metering.SeriesBudgetInterval
+}
+
+// setSeriesBudgetChecked records when the series of the meterbase were fetched,
+// so a failed fetch is retried on the next reconcile.
+func (r *MeterBaseReconciler) setSeriesBudgetChecked(instance *marketplacev1alpha1.MeterBase, now time.Time) {
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+
+	r.seriesBudgetMutex.Lock()
+	defer r.seriesBudgetMutex.Unlock()
+
+	if r.seriesBudgetChecked == nil {
+		r.seriesBudgetChecked = map[types.NamespacedName]time.Time{}
+	}
+
+	r.seriesBudgetChecked[key] = now
+}
+
+// reconcileSeriesBudget sets the series count and the series budget warning
+// of the meter definitions from the head series of the metering Prometheus,
+// at most once per interval.
+func (r *MeterBaseReconciler) reconcileSeriesBudget(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterBase,
+	factory *manifests.Factory,
+) error {
+	now := time.Now()
+	if r.cfg == nil || !r.seriesBudgetDue(instance, now) {
+		return nil
+	}
+
+	apiClient, err := r.prometheusAPIClient(reqLogger, instance, factory)
+	if err != nil || apiClient == nil {
+		return err
+	}
+
+	tsdb, err := apiClient.TSDBStatus(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	mdefs := &marketplacev1beta1.MeterDefinitionList{}
+	if err := r.Client.List(context.TODO(), mdefs); err != nil {
+		return err
+	}
+
+	counts := map[string]int64{}
+	for i := range mdefs.Items {
+		mdef := &mdefs.Items[i]
+
+		count, err := apiClient.SeriesCount(context.TODO(), meterDefinitionSelectors(mdef))
+		if err != nil {
+			return err
+		}
+
+		counts[types.NamespacedName{Namespace: mdef.Namespace, Name: mdef.Name}.String()] = int64(count)
+	}
+
+	r.setSeriesBudgetChecked(instance, now)
+
+	budget := seriesBudget(instance)
+	total := int64(tsdb.HeadStats.NumSeries)
+	warning := r.cfg.Metering.SeriesBudgetWarning
+	offenders := seriesBudgetOffenders(counts, total, budget, warning)
+
+	if budget > 0 && float64(total) >= float64(budget)*warning {
+		r.recorder.Eventf(instance, corev1.EventTypeWarning, marketplacev1alpha1.MeterBaseEventReasonSeriesBudget,
+			"Prometheus holds %d series of a budget of %d, %d meter definitions read the most series", total, budget, len(offenders))
+	}
+
+	for i := range mdefs.Items {
+		mdef := &mdefs.Items[i]
+		key := types.NamespacedName{Namespace: mdef.Namespace, Name: mdef.Name}.String()
+		count := counts[key]
+
+		changed := mdef.Status.SeriesCount == nil || *mdef.Status.SeriesCount != count
+		mdef.Status.SeriesCount = &count
+
+		if budget > 0 {
+			if mdef.Status.Conditions.SetCondition(seriesBudgetCondition(count, total, budget, warning, offenders[key])) {
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := r.Client.Status().Update(context.TODO(), mdef); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prometheusAPIClient returns a client of the metering Prometheus, or nil when
+// the operator has no token for it.
+func (r *MeterBaseReconciler) prometheusAPIClient(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterBase,
+	factory *manifests.Factory,
+) (*prom.APIClient, error) {
+	if r.cfg == nil {
+		return nil, nil
+	}
+
+	token, err := ioutil.ReadFile(r.cfg.Metering.PrometheusTokenFile)
+	if err != nil {
+		reqLogger.Info("skipping the prometheus api, no prometheus token", "file", r.cfg.Metering.PrometheusTokenFile)
+		return nil, nil
+	}
+
+	caBundle, err := factory.PrometheusServingCertsCABundle()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      caBundle.Name,
+	}, caBundle); err != nil {
+		return nil, err
+	}
+
+	return prom.NewAPIClient(
+		fmt.Sprintf("https://%s.%s.svc:9092", promServiceName, instance.Namespace),
+		strings.TrimSpace(string(token)),
+		[]byte(caBundle.Data["service-ca.crt"]))
+}
+
+// storageUsagePercent is the percent of capacity used, rounded down.
+func storageUsagePercent(used int64, capacity resource.Quantity) int32 {
+	if capacity.Value() <= 0 {
+		return 0
+	}
+
+	return int32(used * 100 / capacity.Value())
+}
+
+// nextStorageSize grows size by the growth percent, rounded up to the next
+// Gi and capped at the max size. It returns false when size is already at
+// the max size.
+func nextStorageSize(size resource.Quantity, autoGrow *marketplacev1alpha1.StorageAutoGrowSpec) (resource.Quantity, bool) {
+	const gibibyte = 1024 * 1024 * 1024
+
+	if autoGrow != nil && autoGrow.MaxSize != nil && size.Cmp(*autoGrow.MaxSize) >= 0 {
+		return size, false
+	}
+
+	grown := size.Value() + size.Value()*int64(autoGrow.GetGrowthPercent())/100
+	grown = (grown + gibibyte - 1) / gibibyte * gibibyte
+	next := *resource.NewQuantity(grown, resource.BinarySI)
+
+	if autoGrow != nil && autoGrow.MaxSize != nil && next.Cmp(*autoGrow.MaxSize) > 0 {
+		next = autoGrow.MaxSize.DeepCopy()
+	}
+
+	return next, true
+}
+
+// reconcileStorageGrowth records the disk usage of the Prometheus volumes and
+// grows them when the usage crosses the threshold. The grown size is kept in
+// the status, from where the volume claim template and verifyPVCSize pick it
+// up. The status is only written when the usage percent changes so the
+// growing volume does not requeue the meterbase on every reconcile.
+func (r *MeterBaseReconciler) reconcileStorageGrowth(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterBase,
+	factory *manifests.Factory,
+) error {
+	if instance.Spec.Prometheus == nil || instance.Spec.Prometheus.Storage.EmptyDir != nil {
+		return nil
+	}
+
+	autoGrow := instance.Spec.Prometheus.Storage.AutoGrow
+
+	apiClient, err := r.prometheusAPIClient(reqLogger, instance, factory)
+	if err != nil || apiClient == nil {
+		return err
+	}
+
+	used, err := apiClient.StorageUsage(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(context.TODO(), pvcs,
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{"prometheus": instance.Name}); err != nil {
+		return err
+	}
+
+	if len(pvcs.Items) == 0 {
+		return nil
+	}
+
+	var capacity *resource.Quantity
+	var storageClassName *string
+	for _, item := range pvcs.Items {
+		itemCapacity, ok := item.Status.Capacity[corev1.ResourceStorage]
+		if !ok {
+			itemCapacity = item.Spec.Resources.Requests[corev1.ResourceStorage]
+		}
+
+		if capacity == nil || itemCapacity.Cmp(*capacity) < 0 {
+			capacity = &itemCapacity
+		}
+
+		if item.Spec.StorageClassName != nil {
+			storageClassName = item.Spec.StorageClassName
+		}
+	}
+
+	storage := &marketplacev1alpha1.PrometheusStorageStatus{}
+	if instance.Status.Storage != nil {
+		storage = instance.Status.Storage.DeepCopy()
+	}
+
+	percent := storageUsagePercent(used, *capacity)
+	changed := instance.Status.Storage == nil ||
+		storage.UsagePercent != percent ||
+		storage.Capacity == nil || storage.Capacity.Cmp(*capacity) != 0
+
+	storage.Capacity = capacity
+	storage.Used = resource.NewQuantity(used, resource.BinarySI)
+	storage.UsagePercent = percent
+
+	threshold := autoGrow.GetUsageThreshold()
+	requested := instance.PrometheusStorageSize()
+
+	// grow from the capacity when the volume was provisioned larger than requested
+	size := requested.DeepCopy()
+	if capacity.Cmp(size) > 0 {
+		size = capacity.DeepCopy()
+	}
+
+	condition := status.Condition{
+		Type:    marketplacev1alpha1.ConditionStorageGrowth,
+		Status:  corev1.ConditionTrue,
+		Reason:  marketplacev1alpha1.ReasonStorageWithinThreshold,
+		Message: fmt.Sprintf("Prometheus storage is %d%% used, below the %d%% threshold.", percent, threshold),
+	}
+
+	switch {
+	case autoGrow != nil && autoGrow.Disabled:
+		reqLogger.Info("prometheus storage growth is disabled", "usagePercent", percent)
+	case percent < threshold:
+	case requested.Cmp(*capacity) > 0:
+		reqLogger.Info("prometheus storage resize is pending", "size", requested.String(), "capacity", capacity.String())
+		condition = status.Condition{}
+	default:
+		next, ok := nextStorageSize(size, autoGrow)
+		if !ok {
+			condition = status.Condition{
+				Type:    marketplacev1alpha1.ConditionStorageGrowth,
+				Status:  corev1.ConditionFalse,
+				Reason:  marketplacev1alpha1.ReasonStorageAtMaxSize,
+				Message: fmt.Sprintf("Prometheus storage is %d%% used and is at the max size %s.", percent, size.String()),
+			}
+			break
+		}
+
+		expandable, err := r.storageClassAllowsExpansion(storageClassName)
+		if err != nil {
+			return err
+		}
+
+		if !expandable {
+			condition = status.Condition{
+				Type:    marketplacev1alpha1.ConditionStorageGrowth,
+				Status:  corev1.ConditionFalse,
+				Reason:  marketplacev1alpha1.ReasonStorageClassNotExpandable,
+				Message: fmt.Sprintf("Prometheus storage is %d%% used and the storage class does not allow volume expansion.", percent),
+			}
+			break
+		}
+
+		storage.Size = &next
+		storage.LastGrowthTime = &metav1.Time{Time: time.Now()}
+		changed = true
+		condition = status.Condition{
+			Type:    marketplacev1alpha1.ConditionStorageGrowth,
+			Status:  corev1.ConditionTrue,
+			Reason:  marketplacev1alpha1.ReasonStorageGrown,
+			Message: fmt.Sprintf("Prometheus storage was %d%% used and was grown from %s to %s.", percent, size.String(), next.String()),
+		}
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, marketplacev1alpha1.MeterBaseEventReasonStorageGrown,
+			"Growing Prometheus storage from %s to %s, %d%% is used", size.String(), next.String(), percent)
+	}
+
+	if condition.Type != "" {
+		if condition.Status == corev1.ConditionFalse {
+			r.recorder.Eventf(instance, corev1.EventTypeWarning, marketplacev1alpha1.MeterBaseEventReasonStorageFull, "%s", condition.Message)
+		}
+
+		if instance.Status.Conditions.SetCondition(condition) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	instance.Status.Storage = storage
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// storageClassAllowsExpansion returns true if the storage class, or the
+// default storage class when name is nil, allows volume expansion.
+func (r *MeterBaseReconciler) storageClassAllowsExpansion(name *string) (bool, error) {
+	if name == nil {
+		defaultClass, err := utils.GetDefaultStorageClass(r.Client)
+		if err != nil {
+			return false, err
+		}
+		name = &defaultClass
+	}
+
+	storageClass := &storagev1.StorageClass{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: *name}, storageClass); err != nil {
+		return false, err
+	}
+
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
+// permissionWorkloads are the pod labels of the metering workloads that run
+// the authcheck sidecar, keyed by workload name.
+func permissionWorkloads(instance *marketplacev1alpha1.MeterBase) map[string]client.MatchingLabels {
+	workloads := map[string]client.MatchingLabels{
+		"rhm-metric-state": {"app.kubernetes.io/name": "rhm-metric-state"},
+	}
+
+	if instance.Spec.ExternalPrometheus == nil {
+		workloads["prometheus-operator"] = client.MatchingLabels{"app.kubernetes.io/name": "prometheus-operator"}
+		workloads["prometheus"] = prometheusPodLabels(instance.Name)
+	}
+
+	return workloads
+}
+
+// permissionsCondition reports the missing permissions of each workload.
+func permissionsCondition(missing map[string][]authcheck.PermissionResult) status.Condition {
+	workloads := []string{}
+	for workload, results := range missing {
+		if len(results) != 0 {
+			workloads = append(workloads, workload)
+		}
+	}
+
+	if len(workloads) == 0 {
+		return status.Condition{
+			Type:    marketplacev1alpha1.ConditionMissingPermissions,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ReasonPermissionsGranted,
+			Message: "Metering workloads have the permissions they need.",
+		}
+	}
+
+	sort.Strings(workloads)
+
+	messages := []string{}
+	for _, workload := range workloads {
+		permissions := []string{}
+		seen := map[string]bool{}
+
+		for _, result := range missing[workload] {
+			permission := result.Permission.String()
+			if seen[permission] {
+				continue
+			}
+
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+
+		messages = append(messages, fmt.Sprintf("%s cannot %s", workload, strings.Join(permissions, ", ")))
+	}
+
+	return status.Condition{
+		Type:    marketplacev1alpha1.ConditionMissingPermissions,
+		Status:  corev1.ConditionTrue,
+		Reason:  marketplacev1alpha1.ReasonPermissionsMissing,
+		Message: fmt.Sprintf("Metering workloads are missing permissions: %s.", strings.Join(messages, "; ")),
+	}
+}
+
+// reconcilePermissions reads the permission self-test of the authcheck
+// sidecars and sets the missing permissions condition. Pods that can't be
+// reached are skipped, the condition is left as is when none can.
+func (r *MeterBaseReconciler) reconcilePermissions(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterBase,
+) error {
+	httpTransport, err := r.cfg.Infrastructure.Transport()
+	if err != nil {
+		return err
+	}
 
-			sMons := map[string]*monitoringv1.ServiceMonitor{
-				"kube-state": openshiftKubeStateMonitor,
-				"kubelet":    openshiftKubeletMonitor,
-			}
-			sMons[metricStateMonitor.Name] = metricStateMonitor
+	httpClient := &http.Client{Transport: httpTransport, Timeout: 5 * time.Second}
+	missing := map[string][]authcheck.PermissionResult{}
+	checked := 0
 
-			cfgGen := prom.NewConfigGenerator(reqLogger)
+	for workload, labels := range permissionWorkloads(instance) {
+		pods := &corev1.PodList{}
+		if err := r.Client.List(context.TODO(), pods, client.InNamespace(instance.Namespace), labels); err != nil {
+			return err
+		}
 
-			basicAuthSecrets, err := prom.LoadBasicAuthSecrets(r.Client, sMons, prometheus.Spec.RemoteRead, prometheus.Spec.RemoteWrite, prometheus.Spec.APIServerConfig, secretsInNamespace)
-			if err != nil {
-				return nil, err
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+
+			if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || !authcheck.HasAuthCheck(pod) {
+				continue
 			}
 
-			bearerTokens, err := prom.LoadBearerTokensFromSecrets(r.Client, sMons)
+			permissions, err := authcheck.GetStatus(context.TODO(), httpClient, pod)
 			if err != nil {
-				return nil, err
+				reqLogger.V(2).Info("failed to get the permission status", "pod", pod.Name, "error", err.Error())
+				continue
 			}
 
-			cfg, err := cfgGen.GenerateConfig(prometheus, sMons, basicAuthSecrets, bearerTokens, []string{})
+			checked++
+			missing[workload] = append(missing[workload], permissions.Missing()...)
+		}
+	}
+
+	if checked == 0 {
+		return nil
+	}
+
+	if !instance.Status.Conditions.SetCondition(permissionsCondition(missing)) {
+		return nil
+	}
+
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// scrapeSourceMonitors are the monitors selected by spec.scrapeSources
+type scrapeSourceMonitors struct {
+	namespaces      map[string]bool
+	serviceMonitors monitoringv1.ServiceMonitorList
+	podMonitors     monitoringv1.PodMonitorList
+}
+
+// listScrapeSources lists the namespaces and monitors selected by spec.scrapeSources.
+func (r *MeterBaseReconciler) listScrapeSources(
+	instance *marketplacev1alpha1.MeterBase,
+	sources *scrapeSourceMonitors,
+) []ClientAction {
+	spec := instance.Spec.ScrapeSources
+	sources.namespaces = map[string]bool{instance.Namespace: true}
+
+	if spec == nil {
+		return nil
+	}
+
+	actions := []ClientAction{}
 
+	if spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+		if err != nil {
+			return []ClientAction{ReturnWithError(merrors.Wrap(err, "invalid scrapeSources.namespaceSelector"))}
+		}
+
+		namespaces := &corev1.NamespaceList{}
+		actions = append(actions, HandleResult(
+			ListAction(namespaces, client.MatchingLabelsSelector{Selector: selector}),
+			OnContinue(Call(func() (ClientAction, error) {
+				sources.namespaces = map[string]bool{}
+				for _, ns := range namespaces.Items {
+					sources.namespaces[ns.Name] = true
+				}
+				return nil, nil
+			}))))
+	}
+
+	if spec.ServiceMonitorSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.ServiceMonitorSelector)
+		if err != nil {
+			return []ClientAction{ReturnWithError(merrors.Wrap(err, "invalid scrapeSources.serviceMonitorSelector"))}
+		}
+
+		actions = append(actions, ListAction(&sources.serviceMonitors, client.MatchingLabelsSelector{Selector: selector}))
+	}
+
+	if spec.PodMonitorSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.PodMonitorSelector)
+		if err != nil {
+			return []ClientAction{ReturnWithError(merrors.Wrap(err, "invalid scrapeSources.podMonitorSelector"))}
+		}
+
+		actions = append(actions, ListAction(&sources.podMonitors, client.MatchingLabelsSelector{Selector: selector}))
+	}
+
+	return actions
+}
+
+// scrapeSourcePrefix prefixes the identifiers of the scrape source monitors
+const scrapeSourcePrefix = "scrapeSource/"
+
+// reservedMetricsRegex matches the series the meter definition queries rely
+// on. Scrape sources cannot add series with these names.
+var reservedMetricsRegex = "(kube_.*|kubelet_.*|container_.*|machine_.*|meterdef_.*)"
+
+// selectScrapeSources validates the monitors selected by spec.scrapeSources,
+// adds the valid service monitors to sMons and returns the valid pod monitors
+// with the status of every selected monitor.
+func selectScrapeSources(
+	sources *scrapeSourceMonitors,
+	sMons map[string]*monitoringv1.ServiceMonitor,
+) (map[string]*monitoringv1.PodMonitor, []marketplacev1alpha1.ScrapeSourceStatus) {
+	pMons := map[string]*monitoringv1.PodMonitor{}
+	var statuses []marketplacev1alpha1.ScrapeSourceStatus
+
+	jobs := map[string]bool{}
+	for _, mon := range sMons {
+		jobs[mon.Namespace+"/"+mon.Name] = true
+	}
+
+	addStatus := func(kind string, meta metav1.ObjectMeta, err error) {
+		sourceStatus := marketplacev1alpha1.ScrapeSourceStatus{
+			Kind:      kind,
+			Namespace: meta.Namespace,
+			Name:      meta.Name,
+			Accepted:  err == nil,
+		}
+		if err != nil {
+			sourceStatus.Message = err.Error()
+		}
+		statuses = append(statuses, sourceStatus)
+	}
+
+	for _, mon := range sources.serviceMonitors.Items {
+		if !sources.namespaces[mon.Namespace] {
+			continue
+		}
+
+		err := validateScrapeSourceNamespace(mon.Namespace, mon.Spec.NamespaceSelector)
+		if err == nil && jobs[mon.Namespace+"/"+mon.Name] {
+			err = errors.New("monitor is already scraped by metering")
+		}
+		for _, ep := range mon.Spec.Endpoints {
 			if err != nil {
-				return nil, err
+				break
 			}
+			err = validateScrapeSourceEndpoint(ep.BearerTokenFile, ep.TLSConfig)
+		}
 
-			sec, err := factory.PrometheusAdditionalConfigSecret(cfg)
+		addStatus(monitoringv1.ServiceMonitorsKind, mon.ObjectMeta, err)
+
+		if err != nil {
+			continue
+		}
+
+		newMon := mon.DeepCopy()
+		for i := range newMon.Spec.Endpoints {
+			ep := &newMon.Spec.Endpoints[i]
+			ep.HonorLabels = false
+			ep.RelabelConfigs = append(ep.RelabelConfigs, scrapeSourceRelabelConfigs(mon.Namespace)...)
+			ep.MetricRelabelConfigs = append(ep.MetricRelabelConfigs, scrapeSourceMetricRelabelConfigs(mon.Namespace)...)
+		}
+		sMons[scrapeSourcePrefix+"serviceMonitor/"+mon.Namespace+"/"+mon.Name] = newMon
+	}
+
+	for _, mon := range sources.podMonitors.Items {
+		if !sources.namespaces[mon.Namespace] {
+			continue
+		}
 
+		err := validateScrapeSourceNamespace(mon.Namespace, mon.Spec.NamespaceSelector)
+		if err == nil && jobs[mon.Namespace+"/"+mon.Name] {
+			err = errors.New("monitor is already scraped by metering")
+		}
+		for _, ep := range mon.Spec.PodMetricsEndpoints {
 			if err != nil {
-				return nil, err
+				break
 			}
+			if ep.TLSConfig != nil {
+				err = validateScrapeSourceEndpoint("", &monitoringv1.TLSConfig{SafeTLSConfig: ep.TLSConfig.SafeTLSConfig})
+			}
+		}
 
-			key, err := client.ObjectKeyFromObject(sec)
+		addStatus(monitoringv1.PodMonitorsKind, mon.ObjectMeta, err)
 
-			if err != nil {
-				return nil, err
+		if err != nil {
+			continue
+		}
+
+		newMon := mon.DeepCopy()
+		for i := range newMon.Spec.PodMetricsEndpoints {
+			ep := &newMon.Spec.PodMetricsEndpoints[i]
+			ep.HonorLabels = false
+			ep.RelabelConfigs = append(ep.RelabelConfigs, scrapeSourceRelabelConfigs(mon.Namespace)...)
+			ep.MetricRelabelConfigs = append(ep.MetricRelabelConfigs, scrapeSourceMetricRelabelConfigs(mon.Namespace)...)
+		}
+		pMons[scrapeSourcePrefix+"podMonitor/"+mon.Namespace+"/"+mon.Name] = newMon
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return pMons, statuses
+}
+
+// validateScrapeSourceNamespace rejects monitors scraping targets outside of
+// their own namespace.
+func validateScrapeSourceNamespace(namespace string, selector monitoringv1.NamespaceSelector) error {
+	if selector.Any {
+		return errors.New("namespaceSelector.any is not allowed, a monitor may only scrape its own namespace")
+	}
+
+	for _, name := range selector.MatchNames {
+		if name != namespace {
+			return fmt.Errorf("namespace %s is not allowed, a monitor may only scrape its own namespace", name)
+		}
+	}
+
+	return nil
+}
+
+// validateScrapeSourceEndpoint rejects endpoints reading files or tls assets
+// from the Prometheus pod, the same way the prometheus operator does when
+// arbitrary file system access is denied.
+func validateScrapeSourceEndpoint(bearerTokenFile string, tlsConfig *monitoringv1.TLSConfig) error {
+	if bearerTokenFile != "" {
+		return errors.New("bearerTokenFile is not allowed, use bearerTokenSecret")
+	}
+
+	if tlsConfig == nil {
+		return nil
+	}
+
+	if tlsConfig.CAFile != "" || tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" {
+		return errors.New("tls files are not allowed")
+	}
+
+	if tlsConfig.CA.Secret != nil || tlsConfig.CA.ConfigMap != nil ||
+		tlsConfig.Cert.Secret != nil || tlsConfig.Cert.ConfigMap != nil ||
+		tlsConfig.KeySecret != nil {
+		return errors.New("tls certificates are not supported, only insecureSkipVerify and serverName may be set")
+	}
+
+	return nil
+}
+
+// scrapeSourceRelabelConfigs are applied after the relabelings of a scrape
+// source so its targets keep the namespace of the monitor.
+func scrapeSourceRelabelConfigs(namespace string) []*monitoringv1.RelabelConfig {
+	return []*monitoringv1.RelabelConfig{
+		{
+			TargetLabel: "namespace",
+			Replacement: namespace,
+		},
+	}
+}
+
+// scrapeSourceMetricRelabelConfigs are applied after the metric relabelings
+// of a scrape source. They drop the series used by metering and keep the
+// namespace of the monitor on every series.
+func scrapeSourceMetricRelabelConfigs(namespace string) []*monitoringv1.RelabelConfig {
+	return []*monitoringv1.RelabelConfig{
+		{
+			SourceLabels: []string{"__name__"},
+			Action:       "drop",
+			Regex:        reservedMetricsRegex,
+		},
+		{
+			TargetLabel: "namespace",
+			Replacement: namespace,
+		},
+	}
+}
+
+// hasOpenshiftMonitoring returns if the kubelet and kube-state-metrics service
+// monitors of openshift-monitoring are available.
+func (r *MeterBaseReconciler) hasOpenshiftMonitoring() bool {
+	return r.cfg == nil || r.cfg.Infrastructure == nil || r.cfg.Infrastructure.HasOpenshift()
+}
+
+// kubernetesServiceMonitors builds the kubelet and kube-state-metrics service
+// monitors from their services.
+func (r *MeterBaseReconciler) kubernetesServiceMonitors(
+	instance *marketplacev1alpha1.MeterBase,
+	factory *manifests.Factory,
+	kubeletMonitor *monitoringv1.ServiceMonitor,
+	kubeStateMonitor *monitoringv1.ServiceMonitor,
+) []ClientAction {
+	kubelet, kubeStateMetrics := kubernetesMonitoringTargets(instance.Spec.KubernetesMonitoring, r.defaultKubeletService())
+	kubeletService := &corev1.Service{}
+	kubeStateService := &corev1.Service{}
+	kubeStateServices := &corev1.ServiceList{}
+
+	kubeStateAction := HandleResult(
+		ListAction(kubeStateServices, client.MatchingLabels{kubeStateMetricsNameLabel: "kube-state-metrics"}),
+		OnContinue(Call(func() (ClientAction, error) {
+			if len(kubeStateServices.Items) == 0 {
+				return nil, errors.New("kube-state-metrics service not found, set spec.kubernetesMonitoring.kubeStateMetrics")
 			}
 
-			return HandleResult(
-				GetAction(key, additionalConfigSecret),
-				OnNotFound(CreateAction(sec, CreateWithAddController(instance))),
-				OnContinue(Call(func() (ClientAction, error) {
+			sort.Slice(kubeStateServices.Items, func(i, j int) bool {
+				a, b := kubeStateServices.Items[i], kubeStateServices.Items[j]
+				if a.Namespace != b.Namespace {
+					return a.Namespace < b.Namespace
+				}
+				return a.Name < b.Name
+			})
 
-					if reflect.DeepEqual(additionalConfigSecret.Data, sec.Data) {
-						return nil, nil
-					}
+			kubeStateServices.Items[0].DeepCopyInto(kubeStateService)
+			return nil, nil
+		})))
+
+	if kubeStateMetrics.Name != "" {
+		kubeStateAction = HandleResult(
+			GetAction(types.NamespacedName{
+				Namespace: kubeStateMetrics.Namespace,
+				Name:      kubeStateMetrics.Name,
+			}, kubeStateService),
+			OnNotFound(ReturnWithError(
+				fmt.Errorf("kube-state-metrics service %s/%s not found", kubeStateMetrics.Namespace, kubeStateMetrics.Name))))
+	}
+
+	return []ClientAction{
+		HandleResult(
+			GetAction(types.NamespacedName{
+				Namespace: kubelet.Namespace,
+				Name:      kubelet.Name,
+			}, kubeletService),
+			OnNotFound(ReturnWithError(
+				fmt.Errorf("kubelet service %s/%s not found", kubelet.Namespace, kubelet.Name)))),
+		kubeStateAction,
+		Call(func() (ClientAction, error) {
+			if kubeStateMetrics.Port == "" {
+				kubeStateMetrics.Port = metricsPortName(kubeStateService)
+			}
 
-					return UpdateAction(sec), nil
-				}))), nil
+			*kubeletMonitor = *factory.KubernetesServiceMonitor(
+				"kubelet", kubeletService, kubelet, "/metrics", "/metrics/cadvisor")
+			*kubeStateMonitor = *factory.KubernetesServiceMonitor(
+				"kube-state-metrics", kubeStateService, kubeStateMetrics, "/metrics")
+			return nil, nil
 		}),
 	}
 }
 
+const kubeStateMetricsNameLabel = "app.kubernetes.io/name"
+
+// defaultKubeletService is the kubelet service of the operator config,
+// kube-system/kubelet when it isn't set.
+func (r *MeterBaseReconciler) defaultKubeletService() types.NamespacedName {
+	service := types.NamespacedName{Namespace: "kube-system", Name: "kubelet"}
+
+	if r.cfg == nil || r.cfg.Metering.KubeletService == "" {
+		return service
+	}
+
+	if parts := strings.SplitN(r.cfg.Metering.KubeletService, "/", 2); len(parts) == 2 {
+		service.Namespace, service.Name = parts[0], parts[1]
+	} else {
+		service.Name = parts[0]
+	}
+
+	return service
+}
+
+// kubernetesMonitoringTargets returns the kubelet and kube-state-metrics
+// targets with their defaults. An empty kube-state-metrics name means the
+// service is discovered by label.
+func kubernetesMonitoringTargets(
+	spec *marketplacev1alpha1.KubernetesMonitoringSpec,
+	defaultKubelet types.NamespacedName,
+) (kubelet marketplacev1alpha1.MonitoredService, kubeStateMetrics marketplacev1alpha1.MonitoredService) {
+	kubelet = marketplacev1alpha1.MonitoredService{
+		Name:               defaultKubelet.Name,
+		Namespace:          defaultKubelet.Namespace,
+		InsecureSkipVerify: true,
+	}
+
+	if spec != nil && spec.Kubelet != nil {
+		kubelet = *spec.Kubelet
+	}
+
+	if kubelet.Port == "" {
+		kubelet.Port = "https-metrics"
+	}
+
+	if kubelet.Scheme == "" {
+		kubelet.Scheme = "https"
+	}
+
+	if spec != nil && spec.KubeStateMetrics != nil {
+		kubeStateMetrics = *spec.KubeStateMetrics
+	}
+
+	if kubeStateMetrics.Scheme == "" {
+		kubeStateMetrics.Scheme = "http"
+	}
+
+	return kubelet, kubeStateMetrics
+}
+
+// metricsPortName returns the http-metrics port of the service, or its first port.
+func metricsPortName(service *corev1.Service) string {
+	for _, port := range service.Spec.Ports {
+		if port.Name == "http-metrics" {
+			return port.Name
+		}
+	}
+
+	if len(service.Spec.Ports) == 0 {
+		return ""
+	}
+
+	return service.Spec.Ports[0].Name
+}
+
 func (r *MeterBaseReconciler) reconcilePrometheus(
 	instance *marketplacev1alpha1.MeterBase,
 	prometheus *monitoringv1.Prometheus,
@@ -808,6 +2290,7 @@ func (r *MeterBaseReconciler) reconcilePrometheus(
 
 	dataSecret := &corev1.Secret{}
 	kubeletCertsCM := &corev1.ConfigMap{}
+	meterdefs := &marketplacev1beta1.MeterDefinitionList{}
 
 	return []ClientAction{
 		manifests.CreateIfNotExistsFactoryItem(
@@ -867,15 +2350,19 @@ func (r *MeterBaseReconciler) reconcilePrometheus(
 				return factory.PrometheusService(instance.Name)
 			},
 			args),
+		ListAction(meterdefs),
+		Call(func() (ClientAction, error) {
+			return r.reportInvalidMeterQueries(instance, meterdefs.Items), nil
+		}),
 		HandleResult(
 			GetAction(
 				types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace},
 				prometheus,
 			),
-			OnNotFound(Call(r.createPrometheus(instance, factory, configSecret))),
+			OnNotFound(Call(r.createPrometheus(instance, factory, configSecret, meterdefs))),
 			OnContinue(
 				Call(func() (ClientAction, error) {
-					expectedPrometheus, err := r.newPrometheusOperator(instance, factory, configSecret)
+					expectedPrometheus, err := r.newPrometheusOperator(instance, factory, configSecret, meterdefs.Items)
 
 					if orig, _ := r.patcher.GetOriginalConfiguration(prometheus); orig == nil {
 						data, _ := r.patcher.GetModifiedConfiguration(prometheus, false)
@@ -933,6 +2420,71 @@ func (r *MeterBaseReconciler) reconcilePrometheus(
 	}
 }
 
+// reportInvalidMeterQueries sets the MeterQueriesInvalid condition of the meterbase
+// and records an event on the meter definitions with queries that can't be parsed.
+// The metrics of those queries can't be kept by the remote write filter.
+func (r *MeterBaseReconciler) reportInvalidMeterQueries(
+	instance *marketplacev1alpha1.MeterBase,
+	meterdefs []marketplacev1beta1.MeterDefinition,
+) ClientAction {
+	invalid := invalidMeterQueries(meterdefs)
+
+	condition := status.Condition{
+		Type:    marketplacev1alpha1.ConditionMeterQueriesInvalid,
+		Status:  corev1.ConditionFalse,
+		Reason:  marketplacev1alpha1.ReasonMeterQueriesParsed,
+		Message: "all meter definition queries are parsed",
+	}
+
+	if len(invalid) != 0 {
+		messages := []string{}
+		for _, query := range invalid {
+			messages = append(messages, fmt.Sprintf("%s/%s: %s", query.meterdef.Namespace, query.meterdef.Name, query.err))
+		}
+
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = marketplacev1alpha1.ReasonMeterQueriesNotParsed
+		condition.Message = strings.Join(messages, "; ")
+	}
+
+	if existing := instance.Status.Conditions.GetCondition(condition.Type); existing == nil || existing.Message != condition.Message {
+		for _, query := range invalid {
+			r.recorder.Eventf(query.meterdef, corev1.EventTypeWarning, marketplacev1alpha1.MeterBaseEventReasonInvalidQuery,
+				"query %q can't be parsed, its metrics are not remote written: %s", query.query, query.err)
+		}
+	}
+
+	return HandleResult(
+		UpdateStatusCondition(instance, &instance.Status.Conditions, condition),
+		OnRequeue(ContinueResponse()),
+	)
+}
+
+// invalidMeterQuery is a meter definition query that can't be parsed.
+type invalidMeterQuery struct {
+	meterdef *marketplacev1beta1.MeterDefinition
+	query    string
+	err      error
+}
+
+func invalidMeterQueries(meterdefs []marketplacev1beta1.MeterDefinition) []invalidMeterQuery {
+	invalid := []invalidMeterQuery{}
+
+	for i := range meterdefs {
+		for _, meter := range meterdefs[i].Spec.Meters {
+			if _, err := prom.QueryMetricNames(meter.Query); err != nil {
+				invalid = append(invalid, invalidMeterQuery{
+					meterdef: &meterdefs[i],
+					query:    meter.Query,
+					err:      err,
+				})
+			}
+		}
+	}
+
+	return invalid
+}
+
 func (r *MeterBaseReconciler) recyclePrometheusPods(
 	log logr.Logger,
 	instance *marketplacev1alpha1.MeterBase,
@@ -940,41 +2492,79 @@ func (r *MeterBaseReconciler) recyclePrometheusPods(
 	prometheusDeployment *monitoringv1.Prometheus,
 ) []ClientAction {
 	pvcs := &corev1.PersistentVolumeClaimList{}
+	pods := &corev1.PodList{}
 
 	return []ClientAction{
 		Call(func() (ClientAction, error) {
 			return ListAction(pvcs, client.MatchingLabels{"prometheus": prometheusDeployment.Name}), nil
 		}),
+		Call(func() (ClientAction, error) {
+			return ListAction(pods, client.InNamespace(instance.Namespace), prometheusPodLabels(prometheusDeployment.Name)), nil
+		}),
 		Call(func() (ClientAction, error) {
 			if len(pvcs.Items) == 0 {
 				log.Info("no pvcs found")
 				return nil, nil
 			}
 
-			for _, item := range pvcs.Items {
-				for _, cond := range item.Status.Conditions {
-					if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
-						name := strings.Replace(item.Name, "prometheus-rhm-marketplaceconfig-meterbase-db-", "", -1)
-						log.Info("volume pending resize, restarting pod", "name", name)
-						pod := &corev1.Pod{}
-
-						return HandleResult(
-							GetAction(types.NamespacedName{Name: name, Namespace: instance.Namespace}, pod),
-							OnContinue(
-								HandleResult(
-									DeleteAction(pod),
-									OnAny(RequeueAfterResponse(10*time.Second))),
-							),
-						), nil
-					}
-				}
+			pod, waiting := prometheusPodToRecycle(pvcs.Items, pods.Items)
+			if waiting != "" {
+				log.Info("volume pending resize, waiting for the other replicas to be ready", "waitingFor", waiting)
+				return RequeueAfterResponse(30 * time.Second), nil
 			}
 
-			return nil, nil
+			if pod == nil {
+				return nil, nil
+			}
+
+			log.Info("volume pending resize, restarting pod", "name", pod.Name)
+			return HandleResult(
+				DeleteAction(pod),
+				OnAny(RequeueAfterResponse(10*time.Second))), nil
 		}),
 	}
 }
 
+// prometheusPodToRecycle returns the pod of a volume pending a file system
+// resize. Only one pod is restarted at a time, and only when every other
+// replica is ready, so a replica is always scraping. The name of the replica
+// that is not ready is returned when the restart has to wait.
+func prometheusPodToRecycle(
+	pvcs []corev1.PersistentVolumeClaim,
+	pods []corev1.Pod,
+) (*corev1.Pod, string) {
+	podsByClaim := map[string]*corev1.Pod{}
+	for i := range pods {
+		if claim := prometheusPodClaim(&pods[i]); claim != "" {
+			podsByClaim[claim] = &pods[i]
+		}
+	}
+
+	for _, item := range pvcs {
+		pending := false
+		for _, cond := range item.Status.Conditions {
+			if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+				pending = true
+			}
+		}
+
+		pod, ok := podsByClaim[item.Name]
+		if !pending || !ok {
+			continue
+		}
+
+		for _, replica := range prometheusReplicaStatuses(pods) {
+			if replica.Name != pod.Name && !replica.Ready {
+				return nil, replica.Name
+			}
+		}
+
+		return pod, ""
+	}
+
+	return nil, ""
+}
+
 func (r *MeterBaseReconciler) verifyPVCSize(
 	log logr.Logger,
 	instance *marketplacev1alpha1.MeterBase,
@@ -1016,20 +2606,30 @@ func (r *MeterBaseReconciler) verifyPVCSize(
 					return nil, nil
 				}
 
+				if prometheusDeployment.Spec.Storage == nil ||
+					prometheusDeployment.Spec.Storage.VolumeClaimTemplate.Spec.Resources.Requests.Storage() == nil {
+					log.Info("prometheusDeployment Storage not defined")
+					return nil, nil
+				}
+
+				promDefinedStorage := prometheusDeployment.Spec.Storage.VolumeClaimTemplate.Spec.Resources.Requests.Storage()
+
 				if storageClass.AllowVolumeExpansion == nil ||
 					(storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion != true) {
 					log.Info("storage class does not allow for expansion", "storageClass", storageClass.String())
-					return nil, nil
-				}
 
-				if prometheusDeployment.Spec.Storage == nil ||
-					prometheusDeployment.Spec.Storage.VolumeClaimTemplate.Spec.Resources.Requests.Storage() == nil {
-					log.Info("prometheusDeployment Storage not defined")
+					for _, item := range pvcs.Items {
+						if item.Spec.Resources.Requests.Storage().Cmp(*promDefinedStorage) < 0 {
+							r.recorder.Eventf(instance, corev1.EventTypeWarning, marketplacev1alpha1.MeterBaseEventReasonPVCResizeNeeded,
+								"PVC %s is %s but %s is requested and storage class %s does not allow volume expansion",
+								item.Name, item.Spec.Resources.Requests.Storage().String(), promDefinedStorage.String(), storageClass.Name)
+						}
+					}
+
 					return nil, nil
 				}
 
 				actions := []ClientAction{}
-				promDefinedStorage := prometheusDeployment.Spec.Storage.VolumeClaimTemplate.Spec.Resources.Requests.Storage()
 
 				for _, item := range pvcs.Items {
 					switch item.Spec.Resources.Requests.Storage().Cmp(*promDefinedStorage) {
@@ -1043,6 +2643,9 @@ func (r *MeterBaseReconciler) verifyPVCSize(
 
 					localItem := item.DeepCopy()
 					log.Info("pvc size is different", "oldSize", localItem.Spec.Resources.Requests.Storage().String(), "newSize", promDefinedStorage.String())
+					r.recorder.Eventf(instance, corev1.EventTypeNormal, marketplacev1alpha1.MeterBaseEventReasonPVCResize,
+						"Resizing PVC %s from %s to %s",
+						localItem.Name, localItem.Spec.Resources.Requests.Storage().String(), promDefinedStorage.String())
 					localItem.Spec.Resources.Requests = corev1.ResourceList{
 						corev1.ResourceStorage: *promDefinedStorage,
 					}
@@ -1086,7 +2689,8 @@ func (r *MeterBaseReconciler) uninstallPrometheus(
 	secret2, _ := factory.PrometheusHtpasswdSecret("foo")
 	secret3, _ := factory.PrometheusRBACProxySecret()
 	secrets := []*corev1.Secret{secret0, secret1, secret2, secret3}
-	prom, _ := r.newPrometheusOperator(instance, factory, nil)
+	prom, _ := factory.NewPrometheus(manifests.MustAssetReader(manifests.PrometheusDeployment))
+	prom.Name = instance.Name
 	service, _ := factory.PrometheusService(instance.Name)
 	deployment, _ := factory.MetricStateDeployment()
 	service2, _ := factory.MetricStateService()
@@ -1125,6 +2729,45 @@ func (r *MeterBaseReconciler) uninstallPrometheus(
 	)
 }
 
+// uninstallInternalPrometheus removes the prometheus and prometheus operator
+// installed for the meterbase once it uses an external prometheus. The
+// metric state and the serving certificates are kept, so are the persistent
+// volume claims of prometheus.
+func (r *MeterBaseReconciler) uninstallInternalPrometheus(
+	instance *marketplacev1alpha1.MeterBase,
+	factory *manifests.Factory,
+) []ClientAction {
+	prom, _ := factory.NewPrometheus(manifests.MustAssetReader(manifests.PrometheusDeployment))
+	prom.Name = instance.Name
+	service, _ := factory.PrometheusService(instance.Name)
+	secret0, _ := factory.PrometheusDatasources()
+	secret1, _ := factory.PrometheusProxySecret()
+	secret2, _ := factory.PrometheusHtpasswdSecret("foo")
+	secret3, _ := factory.PrometheusRBACProxySecret()
+	operatorDeployment, _ := factory.NewPrometheusOperatorDeployment([]string{})
+	operatorService, _ := factory.NewPrometheusOperatorService()
+	operatorCM, _ := factory.NewPrometheusOperatorCertsCABundle()
+
+	objs := []interface {
+		runtime.Object
+		metav1.Object
+	}{
+		prom, service, secret0, secret1, secret2, secret3,
+		operatorDeployment, operatorService, operatorCM,
+	}
+
+	actions := []ClientAction{}
+	for _, obj := range objs {
+		actions = append(actions,
+			HandleResult(
+				GetAction(types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj),
+				OnContinue(DeleteAction(obj)),
+				OnNotFound(ContinueResponse())))
+	}
+
+	return actions
+}
+
 func (r *MeterBaseReconciler) reconcilePrometheusService(
 	instance *marketplacev1alpha1.MeterBase,
 	service *corev1.Service,
@@ -1144,9 +2787,10 @@ func (r *MeterBaseReconciler) createPrometheus(
 	instance *marketplacev1alpha1.MeterBase,
 	factory *manifests.Factory,
 	configSecret *corev1.Secret,
+	meterdefs *marketplacev1beta1.MeterDefinitionList,
 ) func() (ClientAction, error) {
 	return func() (ClientAction, error) {
-		newProm, err := r.newPrometheusOperator(instance, factory, configSecret)
+		newProm, err := r.newPrometheusOperator(instance, factory, configSecret, meterdefs.Items)
 		createResult := &ExecResult{}
 
 		if err != nil {
@@ -1193,8 +2837,9 @@ func (r *MeterBaseReconciler) newPrometheusOperator(
 	cr *marketplacev1alpha1.MeterBase,
 	factory *manifests.Factory,
 	cfg *corev1.Secret,
+	meterdefs []marketplacev1beta1.MeterDefinition,
 ) (*monitoringv1.Prometheus, error) {
-	prom, err := factory.NewPrometheusDeployment(cr, cfg)
+	prom, err := factory.NewPrometheusDeployment(cr, cfg, meterdefs)
 
 	factory.SetOwnerReference(prom, cr)
 
@@ -15,10 +15,36 @@
 package marketplace
 
 import (
+	"context"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus-operator/prometheus-operator/pkg/assets"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/authcheck"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/certificates"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	prom "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/prometheus"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/scheme"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var _ = Describe("MeterbaseController", func() {
@@ -45,4 +71,775 @@ var _ = Describe("MeterbaseController", func() {
 			Expect(exp).To(HaveLen(3))
 		})
 	})
+
+	Describe("check meter reports", func() {
+		var (
+			ctrl      *MeterBaseReconciler
+			meterbase *marketplacev1alpha1.MeterBase
+		)
+
+		BeforeEach(func() {
+			ctrl = &MeterBaseReconciler{}
+			meterbase = &marketplacev1alpha1.MeterBase{}
+			meterbase.Namespace = "ns"
+		})
+
+		It("should use the prometheus service", func() {
+			start := time.Now().UTC()
+			report := ctrl.newMeterReport("ns", start, start.AddDate(0, 0, 1), "report", meterbase, promServiceName)
+			Expect(report.Spec.ExternalPrometheus).To(BeNil())
+			Expect(report.Spec.PrometheusService).ToNot(BeNil())
+			Expect(report.Spec.PrometheusService.Name).To(Equal(promServiceName))
+		})
+
+		It("should use the external prometheus", func() {
+			meterbase.Spec.ExternalPrometheus = &common.ExternalPrometheus{
+				URL: "https://thanos-querier.openshift-monitoring.svc:9091",
+			}
+
+			start := time.Now().UTC()
+			report := ctrl.newMeterReport("ns", start, start.AddDate(0, 0, 1), "report", meterbase, promServiceName)
+			Expect(report.Spec.PrometheusService).To(BeNil())
+			Expect(report.Spec.ExternalPrometheus).To(Equal(meterbase.Spec.ExternalPrometheus))
+		})
+	})
+
+	Describe("check external prometheus", func() {
+		It("should remove the prometheus installed before", func() {
+			cfg, err := config.GetConfig()
+			Expect(err).To(Succeed())
+
+			factory := manifests.NewFactory(cfg, scheme.Scheme)
+			meterbase := &marketplacev1alpha1.MeterBase{
+				ObjectMeta: metav1.ObjectMeta{Name: "rhm-marketplaceconfig-meterbase", Namespace: "ns"},
+				Spec: marketplacev1alpha1.MeterBaseSpec{
+					Enabled: true,
+					ExternalPrometheus: &common.ExternalPrometheus{
+						URL: "https://thanos-querier.openshift-monitoring.svc:9091",
+					},
+				},
+			}
+
+			ctrl := &MeterBaseReconciler{}
+			prometheus, err := factory.NewPrometheus(manifests.MustAssetReader(manifests.PrometheusDeployment))
+			Expect(err).To(Succeed())
+			prometheus.Name = meterbase.Name
+			prometheusService, err := factory.PrometheusService(meterbase.Name)
+			Expect(err).To(Succeed())
+			prometheusOperator, err := factory.NewPrometheusOperatorDeployment([]string{})
+			Expect(err).To(Succeed())
+			metricState, err := factory.MetricStateDeployment()
+			Expect(err).To(Succeed())
+
+			testScheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(testScheme)).To(Succeed())
+			Expect(monitoringv1.AddToScheme(testScheme)).To(Succeed())
+
+			client := fake.NewFakeClientWithScheme(testScheme,
+				prometheus, prometheusService, prometheusOperator, metricState)
+			log := logf.Log.WithName("meterbase_controller")
+			ctrl.CC = reconcileutils.NewClientCommand(client, testScheme, log)
+
+			result, _ := ctrl.CC.Do(context.TODO(), ctrl.uninstallInternalPrometheus(meterbase, factory)...)
+			Expect(result.Is(reconcileutils.Continue)).To(BeTrue())
+
+			result, _ = ctrl.CC.Do(context.TODO(), ctrl.uninstallInternalPrometheus(meterbase, factory)...)
+			Expect(result.Is(reconcileutils.Continue)).To(BeTrue())
+
+			for _, obj := range []runtime.Object{prometheus, prometheusService, prometheusOperator} {
+				key, err := k8sclient.ObjectKeyFromObject(obj)
+				Expect(err).To(Succeed())
+				Expect(kerrors.IsNotFound(client.Get(context.TODO(), key, obj))).To(BeTrue())
+			}
+
+			key, err := k8sclient.ObjectKeyFromObject(metricState)
+			Expect(err).To(Succeed())
+			Expect(client.Get(context.TODO(), key, metricState)).To(Succeed())
+		})
+
+		It("should point the pending reports at the external prometheus", func() {
+			meterbase := &marketplacev1alpha1.MeterBase{
+				ObjectMeta: metav1.ObjectMeta{Name: "rhm-marketplaceconfig-meterbase", Namespace: "ns"},
+				Spec: marketplacev1alpha1.MeterBaseSpec{
+					Enabled: true,
+					ExternalPrometheus: &common.ExternalPrometheus{
+						URL: "https://thanos-querier.openshift-monitoring.svc:9091",
+					},
+				},
+			}
+
+			ctrl := &MeterBaseReconciler{recorder: record.NewFakeRecorder(10)}
+			start := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+			internal := &marketplacev1alpha1.MeterBase{ObjectMeta: meterbase.ObjectMeta}
+
+			pending := ctrl.newMeterReport("ns", start, start.AddDate(0, 0, 1), "meter-report-2021-03-01", internal, promServiceName)
+			finished := ctrl.newMeterReport("ns", start.AddDate(0, 0, 1), start.AddDate(0, 0, 2), "meter-report-2021-03-02", internal, promServiceName)
+			finished.Status.Conditions = status.NewConditions(marketplacev1alpha1.ReportConditionJobFinished)
+
+			testScheme := runtime.NewScheme()
+			Expect(marketplacev1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+			client := fake.NewFakeClientWithScheme(testScheme, pending, finished)
+			ctrl.Client = client
+
+			reports := &marketplacev1alpha1.MeterReportList{}
+			Expect(client.List(context.TODO(), reports)).To(Succeed())
+			Expect(ctrl.migratePendingReports(logf.Log.WithName("meterbase_controller"), reports.Items,
+				[]string{pending.Name, finished.Name}, meterbase)).To(Succeed())
+
+			migrated := &marketplacev1alpha1.MeterReport{}
+			Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: pending.Name}, migrated)).To(Succeed())
+			Expect(migrated.Spec.PrometheusService).To(BeNil())
+			Expect(migrated.Spec.ExternalPrometheus).To(Equal(meterbase.Spec.ExternalPrometheus))
+
+			unchanged := &marketplacev1alpha1.MeterReport{}
+			Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: finished.Name}, unchanged)).To(Succeed())
+			Expect(unchanged.Spec.PrometheusService).ToNot(BeNil())
+			Expect(unchanged.Spec.ExternalPrometheus).To(BeNil())
+		})
+	})
+
+	Describe("check prometheus remote storage", func() {
+		var (
+			factory   *manifests.Factory
+			meterbase *marketplacev1alpha1.MeterBase
+			meterdefs []marketplacev1beta1.MeterDefinition
+		)
+
+		BeforeEach(func() {
+			cfg, err := config.GetConfig()
+			Expect(err).To(Succeed())
+
+			factory = manifests.NewFactory(cfg, scheme.Scheme)
+			meterbase = &marketplacev1alpha1.MeterBase{
+				ObjectMeta: metav1.ObjectMeta{Name: "rhm-marketplaceconfig-meterbase", Namespace: "ns"},
+				Spec: marketplacev1alpha1.MeterBaseSpec{
+					Enabled: true,
+					Prometheus: &marketplacev1alpha1.PrometheusSpec{
+						Storage: marketplacev1alpha1.StorageSpec{
+							Size: resource.MustParse("30Gi"),
+						},
+					},
+				},
+			}
+			meterdefs = []marketplacev1beta1.MeterDefinition{
+				{
+					Spec: marketplacev1beta1.MeterDefinitionSpec{
+						Meters: []marketplacev1beta1.MeterWorkload{
+							{Query: `sum by (pod) (rate(app_requests_total{job="app"}[5m]))`},
+							{Query: `min_over_time((kube_pod_info{created_by_kind="DaemonSet"} or on() vector(0))[60m:60m])`},
+						},
+					},
+				},
+			}
+		})
+
+		It("should not set remote storage by default", func() {
+			prom, err := factory.NewPrometheusDeployment(meterbase, nil, meterdefs)
+			Expect(err).To(Succeed())
+			Expect(prom.Spec.RemoteWrite).To(BeEmpty())
+			Expect(prom.Spec.RemoteRead).To(BeEmpty())
+		})
+
+		It("should only write metering series", func() {
+			meterbase.Spec.Prometheus.RemoteWrite = []marketplacev1alpha1.RemoteWriteSpec{
+				{
+					URL: "https://victoria-metrics:8428/api/v1/write",
+					BearerTokenSecret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "remote-write"},
+						Key:                  "token",
+					},
+				},
+			}
+			meterbase.Spec.Prometheus.RemoteRead = []marketplacev1alpha1.RemoteReadSpec{
+				{URL: "https://victoria-metrics:8428/api/v1/read"},
+			}
+
+			prom, err := factory.NewPrometheusDeployment(meterbase, nil, meterdefs)
+			Expect(err).To(Succeed())
+			Expect(prom.Spec.RemoteWrite).To(HaveLen(1))
+			Expect(prom.Spec.RemoteWrite[0].BearerTokenFile).To(Equal("/etc/prometheus/secrets/remote-write/token"))
+			Expect(prom.Spec.Secrets).To(ContainElement("remote-write"))
+			Expect(prom.Spec.RemoteWrite[0].WriteRelabelConfigs).To(ConsistOf(monitoringv1.RelabelConfig{
+				SourceLabels: []string{"__name__"},
+				Action:       "keep",
+				Regex:        "(meterdef_.*|app_requests_total|kube_pod_info)",
+			}))
+			Expect(prom.Spec.RemoteRead).To(HaveLen(1))
+			Expect(prom.Spec.RemoteRead[0].URL).To(Equal("https://victoria-metrics:8428/api/v1/read"))
+		})
+
+		It("should report queries that can't be parsed", func() {
+			meterdefs = append(meterdefs, marketplacev1beta1.MeterDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "ns"},
+				Spec: marketplacev1beta1.MeterDefinitionSpec{
+					Meters: []marketplacev1beta1.MeterWorkload{
+						{Query: `sum(rate(app_requests_total[5m]`},
+					},
+				},
+			})
+
+			testScheme := runtime.NewScheme()
+			Expect(marketplacev1alpha1.AddToScheme(testScheme)).To(Succeed())
+			Expect(marketplacev1beta1.AddToScheme(testScheme)).To(Succeed())
+
+			client := fake.NewFakeClientWithScheme(testScheme, meterbase)
+			recorder := record.NewFakeRecorder(10)
+			ctrl := &MeterBaseReconciler{recorder: recorder}
+			ctrl.CC = reconcileutils.NewClientCommand(client, testScheme, logf.Log.WithName("meterbase_controller"))
+
+			result, _ := ctrl.CC.Do(context.TODO(), ctrl.reportInvalidMeterQueries(meterbase, meterdefs))
+			Expect(result.Is(reconcileutils.Continue)).To(BeTrue())
+
+			updated := &marketplacev1alpha1.MeterBase{}
+			key, err := k8sclient.ObjectKeyFromObject(meterbase)
+			Expect(err).To(Succeed())
+			Expect(client.Get(context.TODO(), key, updated)).To(Succeed())
+
+			cond := updated.Status.Conditions.GetCondition(marketplacev1alpha1.ConditionMeterQueriesInvalid)
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.IsTrue()).To(BeTrue())
+			Expect(cond.Message).To(ContainSubstring("ns/invalid"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(marketplacev1alpha1.MeterBaseEventReasonInvalidQuery)))
+
+			// reported once
+			result, _ = ctrl.CC.Do(context.TODO(), ctrl.reportInvalidMeterQueries(meterbase, meterdefs))
+			Expect(result.Is(reconcileutils.Continue)).To(BeTrue())
+			Expect(recorder.Events).ToNot(Receive())
+		})
+	})
+
+	Describe("check kubernetes monitoring", func() {
+		It("should default the kubelet and kube-state-metrics targets", func() {
+			defaultKubelet := types.NamespacedName{Namespace: "kube-system", Name: "kubelet"}
+			kubelet, kubeStateMetrics := kubernetesMonitoringTargets(nil, defaultKubelet)
+			Expect(kubelet).To(Equal(marketplacev1alpha1.MonitoredService{
+				Name:               "kubelet",
+				Namespace:          "kube-system",
+				Port:               "https-metrics",
+				Scheme:             "https",
+				InsecureSkipVerify: true,
+			}))
+			Expect(kubeStateMetrics).To(Equal(marketplacev1alpha1.MonitoredService{Scheme: "http"}))
+
+			kubelet, kubeStateMetrics = kubernetesMonitoringTargets(&marketplacev1alpha1.KubernetesMonitoringSpec{
+				KubeStateMetrics: &marketplacev1alpha1.MonitoredService{Name: "ksm", Namespace: "monitoring", Scheme: "https"},
+				Kubelet:          &marketplacev1alpha1.MonitoredService{Name: "kubelet", Namespace: "monitoring"},
+			}, defaultKubelet)
+			Expect(kubelet.Namespace).To(Equal("monitoring"))
+			Expect(kubelet.InsecureSkipVerify).To(BeFalse())
+			Expect(kubelet.Port).To(Equal("https-metrics"))
+			Expect(kubeStateMetrics.Name).To(Equal("ksm"))
+			Expect(kubeStateMetrics.Scheme).To(Equal("https"))
+
+			kubelet, _ = kubernetesMonitoringTargets(nil, types.NamespacedName{Namespace: "monitoring", Name: "kubelet-metrics"})
+			Expect(kubelet.Name).To(Equal("kubelet-metrics"))
+			Expect(kubelet.Namespace).To(Equal("monitoring"))
+			Expect(kubelet.Port).To(Equal("https-metrics"))
+		})
+
+		It("should build a service monitor for the service", func() {
+			cfg, err := config.GetConfig()
+			Expect(err).To(Succeed())
+			factory := manifests.NewFactory(cfg, scheme.Scheme)
+
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kube-state-metrics",
+					Namespace: "monitoring",
+					Labels:    map[string]string{"app.kubernetes.io/name": "kube-state-metrics"},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "telemetry"}, {Name: "http-metrics"}},
+				},
+			}
+			Expect(metricsPortName(service)).To(Equal("http-metrics"))
+
+			sm := factory.KubernetesServiceMonitor("kube-state-metrics", service,
+				marketplacev1alpha1.MonitoredService{Port: "http-metrics", Scheme: "http"}, "/metrics")
+			Expect(sm.Namespace).To(Equal("monitoring"))
+			Expect(sm.Spec.NamespaceSelector.MatchNames).To(Equal([]string{"monitoring"}))
+			Expect(sm.Spec.Selector.MatchLabels).To(Equal(service.Labels))
+			Expect(sm.Spec.Endpoints).To(HaveLen(1))
+			Expect(sm.Spec.Endpoints[0].Port).To(Equal("http-metrics"))
+			Expect(sm.Spec.Endpoints[0].TLSConfig).To(BeNil())
+
+			kubelet, _ := kubernetesMonitoringTargets(nil, types.NamespacedName{Namespace: "kube-system", Name: "kubelet"})
+			sm = factory.KubernetesServiceMonitor("kubelet", service, kubelet, "/metrics", "/metrics/cadvisor")
+			Expect(sm.Spec.Endpoints).To(HaveLen(2))
+			Expect(sm.Spec.Endpoints[1].Path).To(Equal("/metrics/cadvisor"))
+			Expect(sm.Spec.Endpoints[1].Scheme).To(Equal("https"))
+			Expect(sm.Spec.Endpoints[1].TLSConfig.InsecureSkipVerify).To(BeTrue())
+		})
+	})
+
+	Describe("check kubernetes without openshift", func() {
+		var factory *manifests.Factory
+
+		BeforeEach(func() {
+			cfg, err := config.GetConfig()
+			Expect(err).To(Succeed())
+
+			kubernetesCfg := *cfg
+			kubernetesCfg.Infrastructure = &config.Infrastructure{}
+			kubernetesCfg.Metering.KubeletService = "monitoring/kubelet-metrics"
+			factory = manifests.NewFactory(&kubernetesCfg, scheme.Scheme)
+
+			ctrl := &MeterBaseReconciler{cfg: &kubernetesCfg}
+			Expect(ctrl.hasOpenshiftMonitoring()).To(BeFalse())
+			Expect(ctrl.defaultKubeletService()).To(Equal(types.NamespacedName{Namespace: "monitoring", Name: "kubelet-metrics"}))
+		})
+
+		It("should not use the openshift service ca", func() {
+			service, err := factory.PrometheusService("rhm-marketplaceconfig-meterbase")
+			Expect(err).To(Succeed())
+			Expect(service.Annotations).ToNot(HaveKey("service.beta.openshift.io/serving-cert-secret-name"))
+			Expect(service.Spec.Ports).To(HaveLen(1))
+			Expect(service.Spec.Ports[0].Name).To(Equal("rbac"))
+
+			service, err = factory.MetricStateService()
+			Expect(err).To(Succeed())
+			Expect(service.Annotations).ToNot(HaveKey("service.beta.openshift.io/serving-cert-secret-name"))
+
+			cm, err := factory.PrometheusServingCertsCABundle()
+			Expect(err).To(Succeed())
+			Expect(cm.Annotations).ToNot(HaveKey("service.beta.openshift.io/inject-cabundle"))
+		})
+
+		It("should not deploy the oauth proxy", func() {
+			prom, err := factory.NewPrometheusDeployment(&marketplacev1alpha1.MeterBase{
+				ObjectMeta: metav1.ObjectMeta{Name: "rhm-marketplaceconfig-meterbase", Namespace: "ns"},
+				Spec: marketplacev1alpha1.MeterBaseSpec{
+					Enabled:    true,
+					Prometheus: &marketplacev1alpha1.PrometheusSpec{},
+				},
+			}, nil, nil)
+			Expect(err).To(Succeed())
+
+			names := []string{}
+			for _, container := range prom.Spec.Containers {
+				names = append(names, container.Name)
+			}
+			Expect(names).ToNot(ContainElement("prometheus-proxy"))
+			Expect(names).To(ContainElement("kube-rbac-proxy-1"))
+		})
+
+		It("should issue the serving certificates with the meterbase ca", func() {
+			now := time.Now()
+			dnsNames := []string{"rhm-metric-state-service.ns.svc", "rhm-metric-state-service"}
+
+			data, changed, err := rotateServingCertificate(nil, dnsNames, now)
+			Expect(err).To(Succeed())
+			Expect(changed).To(BeTrue())
+
+			ca, err := certificates.ParseCertificate(data[certificates.SecretCACertKey])
+			Expect(err).To(Succeed())
+			Expect(ca.Subject.CommonName).To(Equal(servingCertificatesCAName))
+			Expect(certificates.Verify(data[certificates.SecretCACertKey], data[corev1.TLSCertKey], dnsNames[0], now)).To(Succeed())
+
+			_, changed, err = rotateServingCertificate(data, dnsNames, now)
+			Expect(err).To(Succeed())
+			Expect(changed).To(BeFalse())
+		})
+	})
+
+	Describe("check scrape sources", func() {
+		var (
+			sources *scrapeSourceMonitors
+			sMons   map[string]*monitoringv1.ServiceMonitor
+		)
+
+		BeforeEach(func() {
+			sources = &scrapeSourceMonitors{
+				namespaces: map[string]bool{"vendor": true},
+				serviceMonitors: monitoringv1.ServiceMonitorList{
+					Items: []*monitoringv1.ServiceMonitor{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "vendor"},
+							Spec: monitoringv1.ServiceMonitorSpec{
+								Endpoints: []monitoringv1.Endpoint{{Port: "metrics", HonorLabels: true}},
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "all", Namespace: "vendor"},
+							Spec: monitoringv1.ServiceMonitorSpec{
+								NamespaceSelector: monitoringv1.NamespaceSelector{Any: true},
+								Endpoints:         []monitoringv1.Endpoint{{Port: "metrics"}},
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "vendor"},
+							Spec: monitoringv1.ServiceMonitorSpec{
+								Endpoints: []monitoringv1.Endpoint{{Port: "metrics", BearerTokenFile: "/var/run/secrets/token"}},
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "other"},
+						},
+					},
+				},
+				podMonitors: monitoringv1.PodMonitorList{
+					Items: []*monitoringv1.PodMonitor{
+						{
+							ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "vendor"},
+							Spec: monitoringv1.PodMonitorSpec{
+								Selector:            metav1.LabelSelector{MatchLabels: map[string]string{"app": "exporter"}},
+								PodMetricsEndpoints: []monitoringv1.PodMetricsEndpoint{{Port: "metrics"}},
+							},
+						},
+					},
+				},
+			}
+			sMons = map[string]*monitoringv1.ServiceMonitor{
+				"kubelet": {ObjectMeta: metav1.ObjectMeta{Name: "kubelet", Namespace: "openshift-monitoring"}},
+			}
+		})
+
+		It("should only add valid monitors of the selected namespaces", func() {
+			pMons, statuses := selectScrapeSources(sources, sMons)
+
+			Expect(statuses).To(HaveLen(4))
+			Expect(statuses[0]).To(Equal(marketplacev1alpha1.ScrapeSourceStatus{
+				Kind: "ServiceMonitor", Namespace: "vendor", Name: "all", Accepted: false,
+				Message: "namespaceSelector.any is not allowed, a monitor may only scrape its own namespace",
+			}))
+			Expect(statuses[1].Name).To(Equal("exporter"))
+			Expect(statuses[1].Accepted).To(BeTrue())
+			Expect(statuses[2].Name).To(Equal("token"))
+			Expect(statuses[2].Accepted).To(BeFalse())
+			Expect(statuses[3].Kind).To(Equal("PodMonitor"))
+			Expect(statuses[3].Accepted).To(BeTrue())
+
+			Expect(sMons).To(HaveLen(2))
+			Expect(pMons).To(HaveLen(1))
+
+			ep := sMons["scrapeSource/serviceMonitor/vendor/exporter"].Spec.Endpoints[0]
+			Expect(ep.HonorLabels).To(BeFalse())
+			Expect(ep.RelabelConfigs).To(Equal(scrapeSourceRelabelConfigs("vendor")))
+			Expect(ep.MetricRelabelConfigs).To(Equal(scrapeSourceMetricRelabelConfigs("vendor")))
+			Expect(sources.serviceMonitors.Items[0].Spec.Endpoints[0].HonorLabels).To(BeTrue())
+		})
+
+		It("should generate the pod monitor scrape config", func() {
+			pMons, _ := selectScrapeSources(sources, sMons)
+
+			cfg, err := prom.NewConfigGenerator(logf.Log).GenerateConfig(
+				&monitoringv1.Prometheus{}, map[string]*monitoringv1.ServiceMonitor{}, pMons,
+				map[string]assets.BasicAuthCredentials{}, map[string]assets.BearerToken{}, []string{})
+			Expect(err).To(Succeed())
+
+			Expect(string(cfg)).To(ContainSubstring("job_name: vendor/pods/0"))
+			Expect(string(cfg)).To(ContainSubstring("role: pod"))
+			Expect(string(cfg)).To(ContainSubstring("__meta_kubernetes_pod_label_app"))
+			Expect(string(cfg)).To(ContainSubstring(reservedMetricsRegex))
+		})
+
+		It("should only enqueue the meterbase for selected monitors", func() {
+			testScheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(testScheme)).To(Succeed())
+			Expect(marketplacev1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+			meterbase := &marketplacev1alpha1.MeterBase{
+				ObjectMeta: metav1.ObjectMeta{Name: "rhm-marketplaceconfig-meterbase", Namespace: "ns"},
+				Spec: marketplacev1alpha1.MeterBaseSpec{
+					ScrapeSources: &marketplacev1alpha1.ScrapeSourcesSpec{
+						ServiceMonitorSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app": "vendor"},
+						},
+					},
+				},
+			}
+			ctrl := &MeterBaseReconciler{
+				Client: fake.NewFakeClientWithScheme(testScheme, meterbase),
+				cfg:    &config.OperatorConfig{DeployedNamespace: "ns"},
+			}
+			Expect(ctrl.meterBaseKey()).To(Equal(types.NamespacedName{Name: meterbase.Name, Namespace: "ns"}))
+
+			sMonPredicate := ctrl.scrapeSourcePredicate(func(spec *marketplacev1alpha1.ScrapeSourcesSpec) *metav1.LabelSelector {
+				return spec.ServiceMonitorSelector
+			})
+			pMonPredicate := ctrl.scrapeSourcePredicate(func(spec *marketplacev1alpha1.ScrapeSourcesSpec) *metav1.LabelSelector {
+				return spec.PodMonitorSelector
+			})
+
+			selected := &monitoringv1.ServiceMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "vendor", Labels: map[string]string{"app": "vendor"}},
+			}
+			other := &monitoringv1.ServiceMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "exporter", Namespace: "other"},
+			}
+
+			Expect(sMonPredicate.Create(event.CreateEvent{Meta: selected, Object: selected})).To(BeTrue())
+			Expect(sMonPredicate.Create(event.CreateEvent{Meta: other, Object: other})).To(BeFalse())
+			Expect(sMonPredicate.Update(event.UpdateEvent{
+				MetaOld: selected, ObjectOld: selected, MetaNew: other, ObjectNew: other,
+			})).To(BeTrue())
+			Expect(pMonPredicate.Create(event.CreateEvent{Meta: selected, Object: selected})).To(BeFalse())
+		})
+	})
+
+	Describe("check series budget", func() {
+		It("should map the scrape limits to the monitors", func() {
+			limits := scrapeLimits(&marketplacev1alpha1.ScrapeLimitsSpec{
+				Kubelet:       &marketplacev1alpha1.ScrapeLimit{SampleLimit: 100000},
+				MetricState:   &marketplacev1alpha1.ScrapeLimit{SampleLimit: 5000, LabelLimit: 30},
+				ScrapeSources: &marketplacev1alpha1.ScrapeLimit{SampleLimit: 1000},
+			}, "rhm-metric-state",
+				map[string]*monitoringv1.ServiceMonitor{
+					"kubelet":          {},
+					"rhm-metric-state": {},
+					scrapeSourcePrefix + "serviceMonitor/a/b": {},
+				},
+				map[string]*monitoringv1.PodMonitor{
+					scrapeSourcePrefix + "podMonitor/a/c": {},
+				})
+
+			Expect(limits).To(Equal(map[string]prom.ScrapeLimit{
+				"kubelet":          {SampleLimit: 100000},
+				"rhm-metric-state": {SampleLimit: 5000, LabelLimit: 30},
+				scrapeSourcePrefix + "serviceMonitor/a/b": {SampleLimit: 1000},
+				scrapeSourcePrefix + "podMonitor/a/c":     {SampleLimit: 1000},
+			}))
+			Expect(scrapeLimits(nil, "rhm-metric-state", nil, nil)).To(BeEmpty())
+		})
+
+		It("should derive the budget from the prometheus memory", func() {
+			instance := &marketplacev1alpha1.MeterBase{}
+			Expect(seriesBudget(instance)).To(BeZero())
+
+			instance.Spec.Prometheus = &marketplacev1alpha1.PrometheusSpec{
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			}
+			Expect(seriesBudget(instance)).To(Equal(int64(131072)))
+
+			instance.Spec.Prometheus.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}
+			Expect(seriesBudget(instance)).To(Equal(int64(262144)))
+
+			budget := int64(1000)
+			instance.Spec.Prometheus.SeriesBudget = &budget
+			Expect(seriesBudget(instance)).To(Equal(budget))
+		})
+
+		It("should warn when prometheus is close to the budget", func() {
+			Expect(seriesBudgetCondition(10, 700, 1000, 0.8, true).Reason).To(Equal(common.MeterDefConditionReasonWithinSeriesBudget))
+			Expect(seriesBudgetCondition(0, 900, 1000, 0.8, true).Reason).To(Equal(common.MeterDefConditionReasonWithinSeriesBudget))
+			Expect(seriesBudgetCondition(10, 900, 1000, 0.8, false).Reason).To(Equal(common.MeterDefConditionReasonWithinSeriesBudget))
+
+			cond := seriesBudgetCondition(10, 850, 1000, 0.8, true)
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(common.MeterDefConditionReasonNearSeriesBudget))
+			Expect(cond.Message).To(ContainSubstring("reads 10 series"))
+
+			Expect(seriesBudgetCondition(10, 1200, 1000, 0.8, true).Reason).To(Equal(common.MeterDefConditionReasonOverSeriesBudget))
+		})
+
+		It("should only warn the meter definitions holding the excess series", func() {
+			counts := map[string]int64{"ns/big": 300, "ns/medium": 60, "ns/small": 10, "ns/none": 0}
+
+			Expect(seriesBudgetOffenders(counts, 700, 1000, 0.8)).To(BeEmpty())
+			Expect(seriesBudgetOffenders(counts, 1000, 1000, 0.8)).To(Equal(map[string]bool{"ns/big": true}))
+			Expect(seriesBudgetOffenders(counts, 1150, 1000, 0.8)).To(Equal(map[string]bool{"ns/big": true, "ns/medium": true}))
+			Expect(seriesBudgetOffenders(counts, 2000, 1000, 0.8)).To(BeEmpty())
+		})
+
+		It("should check the series budget once per interval after a fetch", func() {
+			ctrl := &MeterBaseReconciler{cfg: &config.OperatorConfig{}}
+			ctrl.cfg.Metering.SeriesBudgetInterval = time.Hour
+			instance := &marketplacev1alpha1.MeterBase{ObjectMeta: metav1.ObjectMeta{Name: "meterbase", Namespace: "ns"}}
+			now := time.Now()
+
+			Expect(ctrl.seriesBudgetDue(instance, now)).To(BeTrue())
+			Expect(ctrl.seriesBudgetDue(instance, now.Add(time.Minute))).To(BeTrue())
+
+			ctrl.setSeriesBudgetChecked(instance, now)
+			Expect(ctrl.seriesBudgetDue(instance, now.Add(time.Minute))).To(BeFalse())
+			Expect(ctrl.seriesBudgetDue(instance, now.Add(time.Hour))).To(BeTrue())
+		})
+
+		It("should find the selectors of the meter queries", func() {
+			mdef := &marketplacev1beta1.MeterDefinition{
+				Spec: marketplacev1beta1.MeterDefinitionSpec{
+					Meters: []marketplacev1beta1.MeterWorkload{
+						{Query: `kube_pod_info{namespace="app"} * on(pod) group_left container_cpu_usage_seconds_total`},
+						{Query: `sum by (pod) (kube_pod_info{namespace="app"})`},
+					},
+				},
+			}
+			Expect(meterDefinitionSelectors(mdef)).To(Equal([]string{
+				`{__name__="container_cpu_usage_seconds_total"}`,
+				`{__name__="kube_pod_info",namespace="app"}`,
+			}))
+		})
+
+	})
+
+	Describe("check prometheus replicas", func() {
+		pod := func(name string, ready bool) corev1.Pod {
+			status := corev1.ConditionFalse
+			if ready {
+				status = corev1.ConditionTrue
+			}
+
+			return corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: corev1.PodSpec{
+					NodeName: "node-" + name,
+					Volumes: []corev1.Volume{
+						{Name: "config"},
+						{
+							Name: "prometheus-db",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "db-" + name},
+							},
+						},
+					},
+				},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status, Message: "containers not ready"}},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "prometheus", RestartCount: 2},
+					},
+				},
+			}
+		}
+
+		pvc := func(name string, pending bool) corev1.PersistentVolumeClaim {
+			claim := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			if pending {
+				claim.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+					{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+				}
+			}
+			return claim
+		}
+
+		It("should report the health of each replica", func() {
+			statuses := prometheusReplicaStatuses([]corev1.Pod{pod("prometheus-1", false), pod("prometheus-0", true)})
+
+			Expect(statuses).To(Equal([]marketplacev1alpha1.PrometheusReplicaStatus{
+				{Name: "prometheus-0", Ready: true, Node: "node-prometheus-0", PersistentVolumeClaim: "db-prometheus-0", Restarts: 2},
+				{Name: "prometheus-1", Ready: false, Node: "node-prometheus-1", PersistentVolumeClaim: "db-prometheus-1", Restarts: 2, Message: "containers not ready"},
+			}))
+		})
+
+		It("should restart one replica at a time", func() {
+			toRecycle, waiting := prometheusPodToRecycle(
+				[]corev1.PersistentVolumeClaim{pvc("db-prometheus-0", false), pvc("db-prometheus-1", true)},
+				[]corev1.Pod{pod("prometheus-0", true), pod("prometheus-1", true)})
+			Expect(waiting).To(BeEmpty())
+			Expect(toRecycle.Name).To(Equal("prometheus-1"))
+
+			toRecycle, waiting = prometheusPodToRecycle(
+				[]corev1.PersistentVolumeClaim{pvc("db-prometheus-0", true), pvc("db-prometheus-1", true)},
+				[]corev1.Pod{pod("prometheus-0", true), pod("prometheus-1", false)})
+			Expect(toRecycle).To(BeNil())
+			Expect(waiting).To(Equal("prometheus-1"))
+
+			toRecycle, waiting = prometheusPodToRecycle(
+				[]corev1.PersistentVolumeClaim{pvc("db-prometheus-0", false)},
+				[]corev1.Pod{pod("prometheus-0", true)})
+			Expect(toRecycle).To(BeNil())
+			Expect(waiting).To(BeEmpty())
+		})
+	})
+
+	Describe("check storage growth", func() {
+		It("should use the grown size when larger", func() {
+			size := func(instance *marketplacev1alpha1.MeterBase) string {
+				size := instance.PrometheusStorageSize()
+				return size.String()
+			}
+
+			instance := &marketplacev1alpha1.MeterBase{
+				Spec: marketplacev1alpha1.MeterBaseSpec{
+					Prometheus: &marketplacev1alpha1.PrometheusSpec{
+						Storage: marketplacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")},
+					},
+				},
+			}
+			Expect(size(instance)).To(Equal("20Gi"))
+
+			grown := resource.MustParse("25Gi")
+			instance.Status.Storage = &marketplacev1alpha1.PrometheusStorageStatus{Size: &grown}
+			Expect(size(instance)).To(Equal("25Gi"))
+
+			instance.Spec.Prometheus.Storage.Size = resource.MustParse("40Gi")
+			Expect(size(instance)).To(Equal("40Gi"))
+		})
+
+		It("should leave room for compaction", func() {
+			Expect(manifests.PrometheusRetentionSize(resource.MustParse("20Gi"))).To(Equal("18432MB"))
+			Expect(manifests.PrometheusRetentionSize(resource.MustParse("100Gi"))).To(Equal("92160MB"))
+			Expect(manifests.PrometheusRetentionSize(resource.MustParse("1Gi"))).To(BeEmpty())
+		})
+
+		It("should grow by the growth percent up to the max size", func() {
+			Expect(storageUsagePercent(17*1024*1024*1024, resource.MustParse("20Gi"))).To(Equal(int32(85)))
+			Expect(storageUsagePercent(1, resource.Quantity{})).To(BeZero())
+
+			next, ok := nextStorageSize(resource.MustParse("20Gi"), nil)
+			Expect(ok).To(BeTrue())
+			Expect(next.String()).To(Equal("25Gi"))
+
+			percent := int32(10)
+			maxSize := resource.MustParse("30Gi")
+			autoGrow := &marketplacev1alpha1.StorageAutoGrowSpec{GrowthPercent: &percent, MaxSize: &maxSize}
+
+			next, ok = nextStorageSize(resource.MustParse("25Gi"), autoGrow)
+			Expect(ok).To(BeTrue())
+			Expect(next.String()).To(Equal("28Gi"))
+
+			next, ok = nextStorageSize(resource.MustParse("28Gi"), autoGrow)
+			Expect(ok).To(BeTrue())
+			Expect(next.String()).To(Equal("30Gi"))
+
+			_, ok = nextStorageSize(resource.MustParse("30Gi"), autoGrow)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("check permissions", func() {
+		It("should pass the permission manifest to the authcheck sidecar", func() {
+			cfg, err := config.GetConfig()
+			Expect(err).To(Succeed())
+			factory := manifests.NewFactory(cfg, scheme.Scheme)
+
+			dep, err := factory.MetricStateDeployment()
+			Expect(err).To(Succeed())
+
+			var args []string
+			for _, container := range dep.Spec.Template.Spec.Containers {
+				if container.Name == authcheck.ContainerName {
+					args = container.Args
+				}
+			}
+
+			Expect(args).To(ContainElement("--permissions"))
+			Expect(args[len(args)-1]).To(Equal(factory.MetricStatePermissions().String()))
+		})
+
+		It("should report the missing permissions of each workload", func() {
+			getPods := authcheck.PermissionResult{Permission: authcheck.Permission{Resource: "pods", Verb: "get"}}
+			updateSets := authcheck.PermissionResult{Permission: authcheck.Permission{
+				Namespace: "openshift-redhat-marketplace", Group: "apps", Resource: "statefulsets", Verb: "update",
+			}}
+
+			condition := permissionsCondition(map[string][]authcheck.PermissionResult{
+				"rhm-metric-state":    {getPods, getPods},
+				"prometheus-operator": {updateSets},
+				"prometheus":          {},
+			})
+			Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(marketplacev1alpha1.ReasonPermissionsMissing))
+			Expect(condition.Message).To(Equal("Metering workloads are missing permissions: " +
+				"prometheus-operator cannot update statefulsets.apps in openshift-redhat-marketplace; " +
+				"rhm-metric-state cannot get pods."))
+
+			condition = permissionsCondition(map[string][]authcheck.PermissionResult{"prometheus": {}})
+			Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(marketplacev1alpha1.ReasonPermissionsGranted))
+		})
+	})
 })
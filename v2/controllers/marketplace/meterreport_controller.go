@@ -18,11 +18,14 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/gotidy/ptr"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
@@ -35,10 +38,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -54,10 +61,15 @@ type MeterReportReconciler struct {
 	Log    logr.Logger
 	Scheme *runtime.Scheme
 
-	CC      ClientCommandRunner
-	patcher patch.Patcher
-	cfg     *config.OperatorConfig
-	factory *manifests.Factory
+	CC       ClientCommandRunner
+	patcher  patch.Patcher
+	cfg      *config.OperatorConfig
+	factory  *manifests.Factory
+	recorder record.EventRecorder
+
+	// apiReader reads from the apiserver, it sees the reporter jobs the
+	// cache hasn't caught up with yet
+	apiReader client.Reader
 }
 
 func (r *MeterReportReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
@@ -81,6 +93,16 @@ func (r *MeterReportReconciler) InjectFactory(f *manifests.Factory) error {
 	return nil
 }
 
+func (r *MeterReportReconciler) InjectEventRecorder(rec record.EventRecorder) error {
+	r.recorder = rec
+	return nil
+}
+
+func (r *MeterReportReconciler) InjectAPIReader(reader client.Reader) error {
+	r.apiReader = reader
+	return nil
+}
+
 func (m *MeterReportReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
 	m.cfg = cfg
 	return nil
@@ -94,6 +116,12 @@ func (r *MeterReportReconciler) SetupWithManager(mgr manager.Manager) error {
 			IsController: true,
 			OwnerType:    &marketplacev1alpha1.MeterReport{},
 		}).
+		Watches(
+			&source.Kind{Type: &batchv1.Job{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.queuedReports),
+			},
+			builder.WithPredicates(reporterJobFinished)).
 		Watches(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForOwner{
 			IsController: true,
 			OwnerType:    &marketplacev1alpha1.MeterReport{},
@@ -175,6 +203,86 @@ func (r *MeterReportReconciler) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, nil
 	}
 
+	marketplaceConfig := &marketplacev1alpha1.MarketplaceConfig{}
+	if instance.Status.AssociatedJob == nil {
+		result, _ := cc.Do(context.TODO(),
+			HandleResult(
+				GetAction(types.NamespacedName{Name: utils.MARKETPLACECONFIG_NAME, Namespace: instance.Namespace}, marketplaceConfig),
+				OnNotFound(Call(func() (ClientAction, error) {
+					marketplaceConfig = nil
+					return nil, nil
+				})),
+			),
+		)
+
+		if result.Is(Error) {
+			reqLogger.Error(result.GetError(), "Failed to get marketplaceconfig.")
+			return result.Return()
+		}
+	}
+
+	// the jobs of disconnected clusters mount the ReadWriteOnce report
+	// archive, they run one at a time
+	maxConcurrentJobs := r.cfg.ReportController.MaxConcurrentJobs
+	if marketplaceConfig != nil && marketplaceConfig.Spec.Disconnected != nil {
+		maxConcurrentJobs = 1
+	}
+
+	// Wait for a free reporter job before submitting the job
+	if instance.Status.AssociatedJob == nil &&
+		result.Is(NotFound) &&
+		maxConcurrentJobs > 0 {
+		result, _ := cc.Do(context.TODO(), r.queueReport(reqLogger, instance, maxConcurrentJobs)...)
+
+		if !result.Is(Continue) {
+			if result.Is(Error) {
+				reqLogger.Error(result.GetError(), "Failed to queue report.")
+			}
+			return result.Return()
+		}
+	}
+
+	// Snapshot the meter definitions in effect before the job is submitted
+	if instance.Status.MeterDefinitionSnapshot == nil {
+		result, _ := cc.Do(context.TODO(), r.snapshotMeterDefinitions(reqLogger, instance)...)
+
+		if !result.Is(Continue) {
+			if result.Is(Error) {
+				reqLogger.Error(result.GetError(), "Failed to snapshot meterdefinitions.")
+			}
+			return result.Return()
+		}
+	}
+
+	// Record the cluster inventory before the job is submitted
+	if instance.Status.ClusterInventory == nil && instance.Status.AssociatedJob == nil {
+		inventory := &marketplacev1alpha1.ClusterInventory{}
+		result, _ := cc.Do(context.TODO(),
+			HandleResult(
+				GetAction(types.NamespacedName{
+					Name:      utils.CLUSTER_INVENTORY_NAME,
+					Namespace: instance.Namespace,
+				}, inventory),
+				OnNotFound(ContinueResponse()),
+				OnContinue(Call(func() (ClientAction, error) {
+					if inventory.Status.Inventory == nil {
+						return nil, nil
+					}
+
+					instance.Status.ClusterInventory = inventory.Status.Inventory.DeepCopy()
+					return UpdateAction(instance, UpdateStatusOnly(true)), nil
+				})),
+			),
+		)
+
+		if !result.Is(Continue) && !result.Is(NotFound) {
+			if result.Is(Error) {
+				reqLogger.Error(result.GetError(), "Failed to record cluster inventory.")
+			}
+			return result.Return()
+		}
+	}
+
 	// Create associated job
 	if instance.Status.AssociatedJob == nil {
 		result, _ := cc.Do(context.TODO(),
@@ -182,10 +290,26 @@ func (r *MeterReportReconciler) Reconcile(request reconcile.Request) (reconcile.
 				manifests.CreateIfNotExistsFactoryItem(
 					job,
 					func() (runtime.Object, error) {
-						return r.factory.ReporterJob(instance, r.cfg.ReportController.RetryLimit)
+						newJob, err := r.factory.ReporterJob(instance, r.cfg.ReportController.RetryLimit)
+
+						if err != nil {
+							return nil, err
+						}
+
+						// disconnected clusters archive the report for export
+						if marketplaceConfig != nil && marketplaceConfig.Spec.Disconnected != nil {
+							r.factory.SetReporterJobArchive(newJob)
+						}
+
+						return newJob, nil
 					}, CreateWithAddController(instance),
 				),
-				OnRequeue(UpdateStatusCondition(instance, &instance.Status.Conditions, marketplacev1alpha1.ReportConditionJobSubmitted)),
+				OnRequeue(Call(func() (ClientAction, error) {
+					r.recorder.Eventf(instance, corev1.EventTypeNormal, marketplacev1alpha1.ReportEventReasonJobSubmitted,
+						"Submitted reporter job %s", instance.Name)
+					instance.Status.QueuePosition = nil
+					return UpdateStatusCondition(instance, &instance.Status.Conditions, marketplacev1alpha1.ReportConditionJobSubmitted), nil
+				})),
 			),
 		)
 
@@ -250,6 +374,8 @@ func (r *MeterReportReconciler) Reconcile(request reconcile.Request) (reconcile.
 			reqLogger.Info("job failed, deleteing and requeuing", "retryTime",
 				r.cfg.ReportController.RetryTime,
 				"diff", completionTimeDiff)
+			r.recorder.Eventf(instance, corev1.EventTypeNormal, marketplacev1alpha1.ReportEventReasonJobRetried,
+				"Deleting failed reporter job %s to retry", job.Name)
 			instance.Status.AssociatedJob = nil
 			result, _ = cc.Do(context.TODO(),
 				HandleResult(
@@ -259,6 +385,10 @@ func (r *MeterReportReconciler) Reconcile(request reconcile.Request) (reconcile.
 			)
 		default:
 			reqLogger.Info("job failed, requeuing in an hour", "time", completionTimeDiff)
+			if instance.Status.AssociatedJob == nil || !instance.Status.AssociatedJob.IsFailed() {
+				r.recorder.Eventf(instance, corev1.EventTypeWarning, marketplacev1alpha1.ReportEventReasonJobFailed,
+					"Reporter job %s failed, retrying after %s", job.Name, r.cfg.ReportController.RetryTime)
+			}
 			instance.Status.AssociatedJob = jr
 			result, _ = cc.Do(context.TODO(),
 				UpdateStatusCondition(
@@ -271,6 +401,10 @@ func (r *MeterReportReconciler) Reconcile(request reconcile.Request) (reconcile.
 		}
 	case jr.IsSuccessful():
 		reqLogger.Info("job is complete")
+		if instance.Status.AssociatedJob == nil || !instance.Status.AssociatedJob.IsSuccessful() {
+			r.recorder.Eventf(instance, corev1.EventTypeNormal, marketplacev1alpha1.ReportEventReasonJobSucceeded,
+				"Reporter job %s succeeded", job.Name)
+		}
 		instance.Status.AssociatedJob = jr
 		result, _ = cc.Do(context.TODO(),
 			UpdateStatusCondition(instance, &instance.Status.Conditions, marketplacev1alpha1.ReportConditionJobFinished),
@@ -295,3 +429,291 @@ func (r *MeterReportReconciler) Reconcile(request reconcile.Request) (reconcile.
 	reqLogger.Info("reconcile finished")
 	return reconcile.Result{}, nil
 }
+
+// queueReport holds the report until fewer than maxConcurrentJobs reporter jobs
+// are running. Queued reports record their position and are checked again later.
+func (r *MeterReportReconciler) queueReport(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterReport,
+	maxConcurrentJobs int,
+) []ClientAction {
+	reports := &marketplacev1alpha1.MeterReportList{}
+	jobs := &batchv1.JobList{}
+
+	return []ClientAction{
+		ListAction(reports, client.InNamespace("")),
+		Call(func() (ClientAction, error) {
+			// the cached jobs can miss a reporter job that was just submitted,
+			// so the running jobs are counted from a live list
+			reader := r.apiReader
+			if reader == nil {
+				reader = r.Client
+			}
+
+			if err := reader.List(context.TODO(), jobs, client.InNamespace("")); err != nil {
+				return nil, err
+			}
+
+			position, ready := reportQueuePosition(
+				instance,
+				reports.Items,
+				jobs.Items,
+				maxConcurrentJobs,
+				time.Now(),
+			)
+
+			if ready {
+				return nil, nil
+			}
+
+			reqLogger.Info("report is queued", "position", position)
+
+			updated := instance.Status.Conditions.SetCondition(marketplacev1alpha1.ReportConditionJobQueued)
+
+			if instance.Status.QueuePosition == nil || *instance.Status.QueuePosition != position {
+				instance.Status.QueuePosition = ptr.Int32(position)
+				updated = true
+			}
+
+			if updated {
+				return UpdateAction(instance, UpdateStatusOnly(true)), nil
+			}
+
+			// queued reports are dequeued when a reporter job finishes, the requeue
+			// only covers reports that become ready by date
+			return RequeueAfterResponse(queuedReportRequeue), nil
+		}),
+	}
+}
+
+// queuedReportRequeue is how often a queued report is checked when no reporter
+// job finishes in the meantime.
+const queuedReportRequeue = 5 * time.Minute
+
+// reporterJobFinished passes reporter jobs that finish or are removed, which
+// frees a slot for the queued reports.
+var reporterJobFinished = predicate.Funcs{
+	CreateFunc: func(evt event.CreateEvent) bool {
+		return false
+	},
+	UpdateFunc: func(evt event.UpdateEvent) bool {
+		oldJob, ok := evt.ObjectOld.(*batchv1.Job)
+		if !ok || !isReporterJob(oldJob) {
+			return false
+		}
+		newJob, ok := evt.ObjectNew.(*batchv1.Job)
+		if !ok {
+			return false
+		}
+
+		return !jobDone(oldJob) && jobDone(newJob)
+	},
+	DeleteFunc: func(evt event.DeleteEvent) bool {
+		return isReporterJob(evt.Meta)
+	},
+	GenericFunc: func(evt event.GenericEvent) bool {
+		return false
+	},
+}
+
+func isReporterJob(obj metav1.Object) bool {
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "MeterReport"
+}
+
+func jobDone(job *batchv1.Job) bool {
+	ref := &common.JobReference{}
+	ref.SetFromJob(job)
+	return ref.IsDone()
+}
+
+// queuedReports maps a finished reporter job to the reports waiting in the queue.
+func (r *MeterReportReconciler) queuedReports(a handler.MapObject) []reconcile.Request {
+	reports := &marketplacev1alpha1.MeterReportList{}
+
+	if err := r.Client.List(context.TODO(), reports); err != nil {
+		r.Log.Error(err, "failed to list meter reports")
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+
+	for _, report := range reports.Items {
+		if report.Status.QueuePosition == nil {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      report.Name,
+				Namespace: report.Namespace,
+			},
+		})
+	}
+
+	return requests
+}
+
+// reportQueuePosition returns the position of the report in the queue of reports waiting
+// for a reporter job and whether it can be submitted. Reports are queued by start date.
+func reportQueuePosition(
+	instance *marketplacev1alpha1.MeterReport,
+	reports []marketplacev1alpha1.MeterReport,
+	jobs []batchv1.Job,
+	maxConcurrentJobs int,
+	now time.Time,
+) (int32, bool) {
+	running := 0
+	reportsWithJobs := map[types.NamespacedName]bool{}
+
+	for i := range jobs {
+		owner := metav1.GetControllerOf(&jobs[i])
+
+		if owner == nil || owner.Kind != "MeterReport" {
+			continue
+		}
+
+		reportsWithJobs[types.NamespacedName{Namespace: jobs[i].Namespace, Name: owner.Name}] = true
+
+		jr := &common.JobReference{}
+		jr.SetFromJob(&jobs[i])
+
+		if !jr.IsDone() {
+			running = running + 1
+		}
+	}
+
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}
+	queue := []*marketplacev1alpha1.MeterReport{instance}
+
+	for i := range reports {
+		report := &reports[i]
+		reportKey := types.NamespacedName{Namespace: report.Namespace, Name: report.Name}
+
+		if reportKey == key || reportsWithJobs[reportKey] {
+			continue
+		}
+
+		if report.Status.AssociatedJob != nil || now.UTC().Before(report.Spec.EndTime.UTC()) {
+			continue
+		}
+
+		if cond := report.Status.Conditions.GetCondition(marketplacev1alpha1.ReportConditionTypeJobRunning); cond != nil &&
+			cond.Reason == marketplacev1alpha1.ReportConditionReasonJobFinished {
+			continue
+		}
+
+		queue = append(queue, report)
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		if !queue[i].Spec.StartTime.Equal(&queue[j].Spec.StartTime) {
+			return queue[i].Spec.StartTime.Before(&queue[j].Spec.StartTime)
+		}
+		if queue[i].Namespace != queue[j].Namespace {
+			return queue[i].Namespace < queue[j].Namespace
+		}
+		return queue[i].Name < queue[j].Name
+	})
+
+	position := 0
+	for i, report := range queue {
+		if report == instance {
+			position = i
+			break
+		}
+	}
+
+	return int32(position + 1), position < maxConcurrentJobs-running
+}
+
+// snapshotMeterDefinitions stores an immutable copy of the meter definitions on the
+// cluster that were created before the end of the report period and records their
+// spec hashes on the report status. The reporter narrows the status down to the
+// meter definitions with metrics in the period. Once the configmaps
+// exist they are never rewritten, so the report keeps the definitions it started with.
+// Shards are created before the first configmap, so a snapshot is only read back once
+// it is complete; shards left behind by an interrupted attempt are replaced.
+func (r *MeterReportReconciler) snapshotMeterDefinitions(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.MeterReport,
+) []ClientAction {
+	meterdefs := &marketplacev1beta1.MeterDefinitionList{}
+	cm := &corev1.ConfigMap{}
+
+	return []ClientAction{
+		HandleResult(
+			GetAction(types.NamespacedName{
+				Name:      manifests.MeterDefinitionSnapshotName(instance),
+				Namespace: instance.Namespace,
+			}, cm),
+			OnNotFound(HandleResult(
+				ListAction(meterdefs, client.InNamespace("")),
+				OnContinue(Call(func() (ClientAction, error) {
+					periodMeterDefs := []marketplacev1beta1.MeterDefinition{}
+					for _, meterdef := range meterdefs.Items {
+						if meterdef.CreationTimestamp.Time.Before(instance.Spec.EndTime.Time) {
+							periodMeterDefs = append(periodMeterDefs, meterdef)
+						}
+					}
+
+					newCMs, err := r.factory.ReporterMeterDefinitionSnapshot(instance, periodMeterDefs)
+
+					if err != nil {
+						return nil, err
+					}
+
+					actions := []ClientAction{}
+
+					for _, shard := range newCMs[1:] {
+						existing := &corev1.ConfigMap{}
+						actions = append(actions,
+							HandleResult(
+								GetAction(types.NamespacedName{Name: shard.Name, Namespace: shard.Namespace}, existing),
+								OnContinue(DeleteAction(existing)),
+								OnNotFound(ContinueResponse()),
+							),
+							HandleResult(
+								CreateAction(shard, CreateWithAddController(instance)),
+								OnRequeue(ContinueResponse()),
+							),
+						)
+					}
+
+					reqLogger.Info("creating meterdefinition snapshot", "count", len(periodMeterDefs), "configmaps", len(newCMs))
+					actions = append(actions, CreateAction(newCMs[0], CreateWithAddController(instance)))
+					return Do(actions...), nil
+				})),
+			)),
+			OnContinue(Call(func() (ClientAction, error) {
+				cms := []*corev1.ConfigMap{cm}
+				actions := []ClientAction{}
+
+				for _, name := range manifests.MeterDefinitionSnapshotShards(cm) {
+					shard := &corev1.ConfigMap{}
+					cms = append(cms, shard)
+					actions = append(actions, HandleResult(
+						GetAction(types.NamespacedName{
+							Name:      name,
+							Namespace: instance.Namespace,
+						}, shard),
+						OnNotFound(ReturnWithError(errors.New("meterdefinition snapshot shard "+name+" is missing"))),
+					))
+				}
+
+				actions = append(actions, Call(func() (ClientAction, error) {
+					snapshot, err := manifests.MeterDefinitionSnapshotFromConfigMaps(cms...)
+
+					if err != nil {
+						return nil, err
+					}
+
+					instance.Status.MeterDefinitionSnapshot = snapshot
+					return UpdateAction(instance, UpdateStatusOnly(true)), nil
+				}))
+
+				return Do(actions...), nil
+			})),
+		),
+	}
+}
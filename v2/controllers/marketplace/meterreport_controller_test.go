@@ -0,0 +1,314 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotidy/ptr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("MeterReportController", func() {
+	Describe("check report queue", func() {
+		var (
+			now     time.Time
+			reports []marketplacev1alpha1.MeterReport
+		)
+
+		newReport := func(name string, daysAgo int) marketplacev1alpha1.MeterReport {
+			start := now.AddDate(0, 0, -daysAgo-1)
+			return marketplacev1alpha1.MeterReport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "ns",
+				},
+				Spec: marketplacev1alpha1.MeterReportSpec{
+					StartTime: metav1.NewTime(start),
+					EndTime:   metav1.NewTime(start.AddDate(0, 0, 1)),
+				},
+			}
+		}
+
+		newJob := func(reportName string, done bool) batchv1.Job {
+			job := batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      reportName,
+					Namespace: "ns",
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							Kind:       "MeterReport",
+							Name:       reportName,
+							Controller: &[]bool{true}[0],
+						},
+					},
+				},
+			}
+
+			if done {
+				job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+					Type:   batchv1.JobComplete,
+					Status: "True",
+				})
+			}
+
+			return job
+		}
+
+		BeforeEach(func() {
+			now = time.Now().UTC()
+			reports = []marketplacev1alpha1.MeterReport{
+				newReport("day-1", 1),
+				newReport("day-3", 3),
+				newReport("day-2", 2),
+			}
+		})
+
+		It("should queue reports by date", func() {
+			position, ready := reportQueuePosition(&reports[0], reports, nil, 1, now)
+			Expect(position).To(Equal(int32(3)))
+			Expect(ready).To(BeFalse())
+
+			position, ready = reportQueuePosition(&reports[1], reports, nil, 1, now)
+			Expect(position).To(Equal(int32(1)))
+			Expect(ready).To(BeTrue())
+		})
+
+		It("should count running jobs against the limit", func() {
+			running := newReport("day-4", 4)
+			jobs := []batchv1.Job{newJob(running.Name, false)}
+			reports = append(reports, running)
+
+			position, ready := reportQueuePosition(&reports[1], reports, jobs, 1, now)
+			Expect(position).To(Equal(int32(1)))
+			Expect(ready).To(BeFalse())
+
+			position, ready = reportQueuePosition(&reports[1], reports, jobs, 2, now)
+			Expect(position).To(Equal(int32(1)))
+			Expect(ready).To(BeTrue())
+
+			jobs = []batchv1.Job{newJob(running.Name, true)}
+			position, ready = reportQueuePosition(&reports[1], reports, jobs, 1, now)
+			Expect(position).To(Equal(int32(1)))
+			Expect(ready).To(BeTrue())
+		})
+
+		It("should count the running jobs the cache hasn't seen yet", func() {
+			running := newReport("day-4", 4)
+			job := newJob(running.Name, false)
+
+			testScheme := runtime.NewScheme()
+			Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+			Expect(marketplacev1alpha1.AddToScheme(testScheme)).To(Succeed())
+
+			cached := fake.NewFakeClientWithScheme(testScheme, &reports[1], &running)
+			r := &MeterReportReconciler{
+				Client:    cached,
+				Log:       logf.Log.WithName("meterreport"),
+				apiReader: fake.NewFakeClientWithScheme(testScheme, &job),
+			}
+			cc := reconcileutils.NewClientCommand(cached, testScheme, r.Log)
+
+			result, _ := cc.Do(context.TODO(), r.queueReport(r.Log, &reports[1], 1)...)
+			Expect(result.Is(reconcileutils.Error)).To(BeFalse())
+			Expect(result.Is(reconcileutils.Continue)).To(BeFalse())
+			Expect(reports[1].Status.QueuePosition).To(Equal(ptr.Int32(1)))
+			Expect(reports[1].Status.Conditions.GetCondition(marketplacev1alpha1.ReportConditionTypeJobRunning).Reason).To(
+				Equal(marketplacev1alpha1.ReportConditionReasonJobQueued))
+		})
+
+		It("should not queue reports that are not ready", func() {
+			future := newReport("future", -2)
+			reports = append(reports, future)
+
+			position, _ := reportQueuePosition(&reports[0], reports, nil, 1, now)
+			Expect(position).To(Equal(int32(3)))
+		})
+
+		It("should only pass reporter jobs that finish", func() {
+			running, done := newJob("day-1", false), newJob("day-1", true)
+			other := newJob("other", false)
+			other.OwnerReferences = nil
+
+			Expect(reporterJobFinished.Update(event.UpdateEvent{
+				MetaOld: &running, ObjectOld: &running,
+				MetaNew: &done, ObjectNew: &done,
+			})).To(BeTrue())
+			Expect(reporterJobFinished.Update(event.UpdateEvent{
+				MetaOld: &done, ObjectOld: &done,
+				MetaNew: &done, ObjectNew: &done,
+			})).To(BeFalse())
+			Expect(reporterJobFinished.Update(event.UpdateEvent{
+				MetaOld: &running, ObjectOld: &running,
+				MetaNew: &running, ObjectNew: &running,
+			})).To(BeFalse())
+			Expect(reporterJobFinished.Delete(event.DeleteEvent{Meta: &running, Object: &running})).To(BeTrue())
+			Expect(reporterJobFinished.Delete(event.DeleteEvent{Meta: &other, Object: &other})).To(BeFalse())
+			Expect(reporterJobFinished.Create(event.CreateEvent{Meta: &running, Object: &running})).To(BeFalse())
+		})
+
+		It("should dequeue queued reports when a reporter job finishes", func() {
+			reports[1].Status.QueuePosition = ptr.Int32(1)
+			reports[2].Status.QueuePosition = ptr.Int32(2)
+
+			scheme := runtime.NewScheme()
+			Expect(marketplacev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			r := &MeterReportReconciler{
+				Client: fake.NewFakeClientWithScheme(scheme, &reports[0], &reports[1], &reports[2]),
+				Log:    logf.Log.WithName("meterreport"),
+			}
+
+			job := newJob("day-1", true)
+			requests := r.queuedReports(handler.MapObject{Meta: &job, Object: &job})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "day-3"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "day-2"}},
+			))
+		})
+	})
+
+	Describe("snapshot meter definitions", func() {
+		var (
+			factory *manifests.Factory
+			report  *marketplacev1alpha1.MeterReport
+		)
+
+		newMeterDefinition := func(name string, size int) marketplacev1beta1.MeterDefinition {
+			return marketplacev1beta1.MeterDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "ns",
+				},
+				Spec: marketplacev1beta1.MeterDefinitionSpec{
+					Group: "example.com",
+					Kind:  "App",
+					Meters: []marketplacev1beta1.MeterWorkload{
+						{
+							Metric:      "rpc_durations_seconds",
+							Description: strings.Repeat("x", size),
+						},
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			cfg, err := config.GetConfig()
+			Expect(err).To(Succeed())
+
+			factory = manifests.NewFactory(cfg, scheme.Scheme)
+			report = &marketplacev1alpha1.MeterReport{
+				ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "ns"},
+			}
+		})
+
+		It("should shard large snapshots across configmaps", func() {
+			meterdefs := []marketplacev1beta1.MeterDefinition{}
+			for i := 0; i < 30; i++ {
+				meterdefs = append(meterdefs, newMeterDefinition(fmt.Sprintf("meterdef-%02d", i), 100*1024))
+			}
+
+			cms, err := factory.ReporterMeterDefinitionSnapshot(report, meterdefs)
+			Expect(err).To(Succeed())
+			Expect(len(cms)).To(BeNumerically(">", 3))
+			Expect(cms[0].Name).To(Equal(manifests.MeterDefinitionSnapshotName(report)))
+
+			shards := []string{}
+			for _, cm := range cms[1:] {
+				shards = append(shards, cm.Name)
+			}
+			Expect(manifests.MeterDefinitionSnapshotShards(cms[0])).To(Equal(shards))
+
+			for _, cm := range cms {
+				size := 0
+				for _, data := range cm.Data {
+					size = size + len(data)
+				}
+				Expect(size).To(BeNumerically("<", 1024*1024))
+			}
+
+			snapshot, err := manifests.MeterDefinitionSnapshotFromConfigMaps(cms...)
+			Expect(err).To(Succeed())
+			Expect(snapshot.ConfigMapName).To(Equal(cms[0].Name))
+			Expect(snapshot.ConfigMapShards).To(Equal(shards))
+			Expect(snapshot.MeterDefinitions).To(HaveLen(30))
+		})
+
+		It("should keep small snapshots in one configmap", func() {
+			cms, err := factory.ReporterMeterDefinitionSnapshot(report, []marketplacev1beta1.MeterDefinition{
+				newMeterDefinition("meterdef", 10),
+			})
+			Expect(err).To(Succeed())
+			Expect(cms).To(HaveLen(1))
+			Expect(manifests.MeterDefinitionSnapshotShards(cms[0])).To(BeEmpty())
+		})
+
+		It("should only snapshot the meter definitions created before the end of the period", func() {
+			end := time.Date(2021, time.March, 2, 0, 0, 0, 0, time.UTC)
+			report.Spec.StartTime = metav1.NewTime(end.Add(-24 * time.Hour))
+			report.Spec.EndTime = metav1.NewTime(end)
+
+			before := newMeterDefinition("before", 10)
+			before.CreationTimestamp = metav1.NewTime(end.Add(-time.Hour))
+			after := newMeterDefinition("after", 10)
+			after.CreationTimestamp = metav1.NewTime(end.Add(time.Hour))
+
+			testScheme := runtime.NewScheme()
+			Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+			Expect(marketplacev1alpha1.AddToScheme(testScheme)).To(Succeed())
+			Expect(marketplacev1beta1.AddToScheme(testScheme)).To(Succeed())
+
+			client := fake.NewFakeClientWithScheme(testScheme, report, &before, &after)
+			r := &MeterReportReconciler{Client: client, factory: factory}
+			cc := reconcileutils.NewClientCommand(client, testScheme, logf.Log.WithName("meterreport"))
+
+			for i := 0; i < 2; i++ {
+				result, _ := cc.Do(context.TODO(), r.snapshotMeterDefinitions(logf.Log.WithName("meterreport"), report)...)
+				Expect(result.Is(reconcileutils.Error)).To(BeFalse())
+			}
+
+			Expect(report.Status.MeterDefinitionSnapshot).ToNot(BeNil())
+			Expect(report.Status.MeterDefinitionSnapshot.MeterDefinitions).To(HaveLen(1))
+			Expect(report.Status.MeterDefinitionSnapshot.MeterDefinitions[0].Name).To(Equal("before"))
+		})
+
+		It("should fail on meter definitions too large to snapshot", func() {
+			_, err := factory.ReporterMeterDefinitionSnapshot(report, []marketplacev1beta1.MeterDefinition{
+				newMeterDefinition("meterdef", 1024*1024),
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
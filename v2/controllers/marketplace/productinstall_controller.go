@@ -0,0 +1,669 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const productInstallTag = "marketplace.redhat.com/productinstall"
+
+const productInstallFinalizer = "productinstall.finalizer.marketplace.redhat.com"
+
+// productInstallPollInterval is how often an install in progress is checked.
+const productInstallPollInterval = 15 * time.Second
+
+// blank assignment to verify that ProductInstallReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ProductInstallReconciler{}
+
+// ProductInstallReconciler installs the operators of a ProductInstall one at a
+// time, in dependency order.
+type ProductInstallReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+}
+
+// Reconcile creates the Subscription of the next operator once the previous
+// ones reached Succeeded, and rolls the install back when one fails. Once
+// installed, the operators keep being checked.
+func (r *ProductInstallReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ProductInstall")
+
+	instance := &marketplacev1alpha1.ProductInstall{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// the subscriptions are garbage collected with the install but their csvs
+	// are not, the finalizer removes both
+	if instance.GetDeletionTimestamp() != nil {
+		if !utils.Contains(instance.GetFinalizers(), productInstallFinalizer) {
+			return reconcile.Result{}, nil
+		}
+
+		if err := r.uninstall(instance); err != nil {
+			reqLogger.Error(err, "failed to uninstall the operators")
+			return reconcile.Result{}, err
+		}
+
+		instance.SetFinalizers(utils.RemoveKey(instance.GetFinalizers(), productInstallFinalizer))
+		return reconcile.Result{}, r.Client.Update(context.TODO(), instance)
+	}
+
+	if !utils.Contains(instance.GetFinalizers(), productInstallFinalizer) {
+		instance.SetFinalizers(append(instance.GetFinalizers(), productInstallFinalizer))
+		if err := r.Client.Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "failed to add the finalizer")
+			return reconcile.Result{}, err
+		}
+	}
+
+	previous := instance.Status.DeepCopy()
+
+	// a new spec starts the install over
+	if instance.Status.ObservedGeneration != instance.Generation {
+		instance.Status.ObservedGeneration = instance.Generation
+		instance.Status.Phase = marketplacev1alpha1.ProductInstallPhasePending
+		instance.Status.Operators = nil
+	} else if instance.Status.Phase == marketplacev1alpha1.ProductInstallPhaseFailed ||
+		instance.Status.Phase == marketplacev1alpha1.ProductInstallPhaseRolledBack {
+		return reconcile.Result{}, nil
+	}
+
+	var result reconcile.Result
+	if instance.Status.Phase == marketplacev1alpha1.ProductInstallPhaseSucceeded {
+		err = r.monitor(instance)
+	} else {
+		result, err = r.install(instance)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !equality.Semantic.DeepEqual(*previous, instance.Status) {
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "failed to update status")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// install moves the install forward and records its progress in the status.
+func (r *ProductInstallReconciler) install(instance *marketplacev1alpha1.ProductInstall) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("ProductInstall.Namespace", instance.Namespace, "ProductInstall.Name", instance.Name)
+
+	operators, err := productInstallOrder(instance.Spec.Operators)
+	if err != nil {
+		instance.Status.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+		instance.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ProductInstallReasonInvalid,
+			Message: err.Error(),
+		})
+		return reconcile.Result{}, nil
+	}
+
+	instance.Status.Operators = productOperatorStatuses(operators, instance.Status.Operators)
+
+	for i, operator := range operators {
+		operatorStatus := &instance.Status.Operators[i]
+
+		// an operator is not checked again during the install once it
+		// succeeded, monitor checks it afterwards
+		if operatorStatus.Phase == marketplacev1alpha1.ProductInstallPhaseSucceeded {
+			continue
+		}
+
+		if err := r.installOperator(instance, operator, operatorStatus); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		switch operatorStatus.Phase {
+		case marketplacev1alpha1.ProductInstallPhaseSucceeded:
+			continue
+		case marketplacev1alpha1.ProductInstallPhaseFailed:
+			reqLogger.Info("operator failed to install", "operator", operator.Name, "message", operatorStatus.Message)
+			return reconcile.Result{}, r.failed(instance, operatorStatus)
+		}
+
+		instance.Status.Phase = marketplacev1alpha1.ProductInstallPhaseInstalling
+		instance.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ProductInstallReasonInstalling,
+			Message: fmt.Sprintf("installing %s", operator.Name),
+		})
+		return reconcile.Result{RequeueAfter: productInstallPollInterval}, nil
+	}
+
+	instance.Status.Phase = marketplacev1alpha1.ProductInstallPhaseSucceeded
+	instance.Status.Conditions.SetCondition(status.Condition{
+		Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+		Status:  corev1.ConditionTrue,
+		Reason:  marketplacev1alpha1.ProductInstallReasonInstalled,
+		Message: "all operators reached Succeeded",
+	})
+	return reconcile.Result{}, nil
+}
+
+// installOperator creates the Subscription of the operator and checks its CSV.
+func (r *ProductInstallReconciler) installOperator(
+	instance *marketplacev1alpha1.ProductInstall,
+	operator marketplacev1alpha1.ProductOperator,
+	operatorStatus *marketplacev1alpha1.ProductOperatorStatus,
+) error {
+	sub := &olmv1alpha1.Subscription{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: operator.Name, Namespace: instance.Namespace}, sub)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	if k8serrors.IsNotFound(err) {
+		sub = newProductSubscription(instance, operator)
+		if err := controllerutil.SetControllerReference(instance, sub, r.Scheme); err != nil {
+			return err
+		}
+
+		if err := r.Client.Create(context.TODO(), sub); err != nil {
+			return err
+		}
+
+		operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseInstalling
+		operatorStatus.StartTime = &metav1.Time{Time: time.Now()}
+		operatorStatus.Message = ""
+		return nil
+	}
+
+	if operatorStatus.StartTime == nil {
+		operatorStatus.StartTime = &metav1.Time{Time: time.Now()}
+	}
+
+	// olm reads the spec of an existing subscription, a changed channel or
+	// starting csv is applied to the subscriptions created by the install
+	if sub.GetLabels()[productInstallTag] == instance.Name && updateProductSubscription(sub, newProductSubscription(instance, operator)) {
+		r.Log.Info("updating subscription", "name", sub.Name, "namespace", sub.Namespace)
+		if err := r.Client.Update(context.TODO(), sub); err != nil {
+			return err
+		}
+	}
+
+	if operator.StartingCSV != "" {
+		if err := r.approveInstallPlan(sub, operator.StartingCSV); err != nil {
+			return err
+		}
+	}
+
+	csvName := sub.Status.InstalledCSV
+	if csvName == "" {
+		productOperatorWaiting(instance, operatorStatus, "waiting for the subscription to install a csv")
+		return nil
+	}
+
+	csv, err := r.getCSV(instance, csvName)
+	if err != nil {
+		return err
+	}
+
+	operatorStatus.CSV = csvName
+
+	switch {
+	case csv == nil:
+		productOperatorWaiting(instance, operatorStatus, fmt.Sprintf("waiting for csv %s", csvName))
+	case operator.StartingCSV != "" && csvName != operator.StartingCSV && csv.Status.Phase != olmv1alpha1.CSVPhaseFailed:
+		productOperatorWaiting(instance, operatorStatus, fmt.Sprintf("waiting for csv %s to replace %s", operator.StartingCSV, csvName))
+	case csv.Status.Phase == olmv1alpha1.CSVPhaseSucceeded:
+		operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseSucceeded
+		operatorStatus.Message = ""
+	case csv.Status.Phase == olmv1alpha1.CSVPhaseFailed:
+		operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+		operatorStatus.Message = fmt.Sprintf("csv %s failed: %s", csvName, csv.Status.Message)
+	default:
+		productOperatorWaiting(instance, operatorStatus, fmt.Sprintf("csv %s is %s", csvName, csv.Status.Phase))
+	}
+
+	return nil
+}
+
+// monitor checks the operators of an installed product. Olm upgrades them, a
+// failed csv or a removed subscription degrades the install.
+func (r *ProductInstallReconciler) monitor(instance *marketplacev1alpha1.ProductInstall) error {
+	degraded := []string{}
+
+	for i := range instance.Status.Operators {
+		operatorStatus := &instance.Status.Operators[i]
+
+		sub := &olmv1alpha1.Subscription{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: operatorStatus.Name, Namespace: instance.Namespace}, sub)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+
+		if k8serrors.IsNotFound(err) {
+			operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+			operatorStatus.Message = fmt.Sprintf("subscription %s was removed", operatorStatus.Name)
+			degraded = append(degraded, operatorStatus.Name)
+			continue
+		}
+
+		// the installed csv is empty while olm replaces it
+		csvName := sub.Status.InstalledCSV
+		if csvName == "" {
+			continue
+		}
+
+		csv, err := r.getCSV(instance, csvName)
+		if err != nil {
+			return err
+		}
+
+		operatorStatus.CSV = csvName
+
+		switch {
+		case csv == nil:
+			operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+			operatorStatus.Message = fmt.Sprintf("csv %s was removed", csvName)
+		case csv.Status.Phase == olmv1alpha1.CSVPhaseFailed:
+			operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+			operatorStatus.Message = fmt.Sprintf("csv %s failed: %s", csvName, csv.Status.Message)
+		case csv.Status.Phase == olmv1alpha1.CSVPhaseSucceeded:
+			operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseSucceeded
+			operatorStatus.Message = ""
+		default:
+			// an upgrade in progress
+			operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseSucceeded
+			operatorStatus.Message = fmt.Sprintf("csv %s is %s", csvName, csv.Status.Phase)
+		}
+
+		if operatorStatus.Phase == marketplacev1alpha1.ProductInstallPhaseFailed {
+			degraded = append(degraded, operatorStatus.Name)
+		}
+	}
+
+	if len(degraded) != 0 {
+		instance.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ProductInstallReasonDegraded,
+			Message: fmt.Sprintf("operators failed after the install: %s", strings.Join(degraded, ", ")),
+		})
+		return nil
+	}
+
+	instance.Status.Conditions.SetCondition(status.Condition{
+		Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+		Status:  corev1.ConditionTrue,
+		Reason:  marketplacev1alpha1.ProductInstallReasonInstalled,
+		Message: "all operators reached Succeeded",
+	})
+	return nil
+}
+
+// uninstall deletes the subscriptions created by the install and their csvs.
+func (r *ProductInstallReconciler) uninstall(instance *marketplacev1alpha1.ProductInstall) error {
+	subs := &olmv1alpha1.SubscriptionList{}
+	err := r.Client.List(context.TODO(), subs,
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{productInstallTag: instance.Name})
+	if err != nil {
+		return err
+	}
+
+	for i := range subs.Items {
+		sub := &subs.Items[i]
+
+		for _, csvName := range []string{sub.Status.InstalledCSV, sub.Status.CurrentCSV} {
+			if csvName == "" {
+				continue
+			}
+
+			r.Log.Info("deleting csv", "name", csvName, "namespace", sub.Namespace)
+			csv := &olmv1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: csvName, Namespace: sub.Namespace},
+			}
+			if err := r.Client.Delete(context.TODO(), csv); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		r.Log.Info("deleting subscription", "name", sub.Name, "namespace", sub.Namespace)
+		if err := r.Client.Delete(context.TODO(), sub); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getCSV returns the csv, or nil when it does not exist.
+func (r *ProductInstallReconciler) getCSV(
+	instance *marketplacev1alpha1.ProductInstall,
+	name string,
+) (*olmv1alpha1.ClusterServiceVersion, error) {
+	csv := &olmv1alpha1.ClusterServiceVersion{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: instance.Namespace}, csv)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return csv, nil
+}
+
+// approveInstallPlan approves the install plan of the subscription when it
+// installs the pinned CSV.
+func (r *ProductInstallReconciler) approveInstallPlan(sub *olmv1alpha1.Subscription, startingCSV string) error {
+	if sub.Status.InstallPlanRef == nil {
+		return nil
+	}
+
+	installPlan := &olmv1alpha1.InstallPlan{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{
+		Name:      sub.Status.InstallPlanRef.Name,
+		Namespace: sub.Status.InstallPlanRef.Namespace,
+	}, installPlan)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if installPlan.Spec.Approved || !utils.Contains(installPlan.Spec.ClusterServiceVersionNames, startingCSV) {
+		return nil
+	}
+
+	r.Log.Info("approving install plan", "name", installPlan.Name, "namespace", installPlan.Namespace, "csv", startingCSV)
+	installPlan.Spec.Approved = true
+	return r.Client.Update(context.TODO(), installPlan)
+}
+
+// failed rolls back the operators installed so far when the policy asks for it.
+func (r *ProductInstallReconciler) failed(
+	instance *marketplacev1alpha1.ProductInstall,
+	failedStatus *marketplacev1alpha1.ProductOperatorStatus,
+) error {
+	message := fmt.Sprintf("%s failed to install: %s", failedStatus.Name, failedStatus.Message)
+
+	if !instance.ShouldRollbackOnFailure() {
+		instance.Status.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+		instance.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ProductInstallReasonFailed,
+			Message: message,
+		})
+		return nil
+	}
+
+	// the subscription reconciler deletes the subscriptions and their csvs,
+	// subscriptions the install did not create are left in place
+	for i := len(instance.Status.Operators) - 1; i >= 0; i-- {
+		operatorStatus := &instance.Status.Operators[i]
+		if operatorStatus.Phase == marketplacev1alpha1.ProductInstallPhasePending {
+			continue
+		}
+
+		sub := &olmv1alpha1.Subscription{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: operatorStatus.Name, Namespace: instance.Namespace}, sub)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+
+		if err == nil && sub.GetLabels()[productInstallTag] != instance.Name {
+			if operatorStatus != failedStatus {
+				operatorStatus.Message = fmt.Sprintf("subscription %s was not created by the install and is not rolled back", sub.Name)
+			}
+			continue
+		}
+
+		if err == nil {
+			sub.Labels[uninstallTag] = "true"
+			if err := r.Client.Update(context.TODO(), sub); err != nil {
+				return err
+			}
+		}
+
+		if operatorStatus != failedStatus {
+			operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseRolledBack
+		}
+	}
+
+	instance.Status.Phase = marketplacev1alpha1.ProductInstallPhaseRolledBack
+	instance.Status.Conditions.SetCondition(status.Condition{
+		Type:    marketplacev1alpha1.ProductInstallConditionInstalled,
+		Status:  corev1.ConditionFalse,
+		Reason:  marketplacev1alpha1.ProductInstallReasonRolledBack,
+		Message: message,
+	})
+	return nil
+}
+
+// productOperatorWaiting keeps the operator installing until the timeout.
+func productOperatorWaiting(
+	instance *marketplacev1alpha1.ProductInstall,
+	operatorStatus *marketplacev1alpha1.ProductOperatorStatus,
+	message string,
+) {
+	operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseInstalling
+	operatorStatus.Message = message
+
+	if time.Since(operatorStatus.StartTime.Time) > instance.GetTimeout() {
+		operatorStatus.Phase = marketplacev1alpha1.ProductInstallPhaseFailed
+		operatorStatus.Message = fmt.Sprintf("timed out after %s: %s", instance.GetTimeout(), message)
+	}
+}
+
+func newProductSubscription(
+	instance *marketplacev1alpha1.ProductInstall,
+	operator marketplacev1alpha1.ProductOperator,
+) *olmv1alpha1.Subscription {
+	sub := &olmv1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      operator.Name,
+			Namespace: instance.Namespace,
+			Labels: map[string]string{
+				operatorTag:       "true",
+				productInstallTag: instance.Name,
+			},
+		},
+		Spec: &olmv1alpha1.SubscriptionSpec{
+			CatalogSource:          operator.CatalogSource,
+			CatalogSourceNamespace: operator.CatalogSourceNamespace,
+			Package:                operator.Package,
+			Channel:                operator.Channel,
+			InstallPlanApproval:    olmv1alpha1.ApprovalAutomatic,
+		},
+	}
+
+	if operator.StartingCSV != "" {
+		sub.Spec.StartingCSV = operator.StartingCSV
+		sub.Spec.InstallPlanApproval = olmv1alpha1.ApprovalManual
+	}
+
+	return sub
+}
+
+// updateProductSubscription copies the install settings of the desired
+// subscription and returns true if the subscription changed.
+func updateProductSubscription(sub, desired *olmv1alpha1.Subscription) bool {
+	if sub.Spec == nil {
+		sub.Spec = &olmv1alpha1.SubscriptionSpec{}
+	}
+
+	updated := *sub.Spec
+	updated.CatalogSource = desired.Spec.CatalogSource
+	updated.CatalogSourceNamespace = desired.Spec.CatalogSourceNamespace
+	updated.Package = desired.Spec.Package
+	updated.Channel = desired.Spec.Channel
+	updated.StartingCSV = desired.Spec.StartingCSV
+	updated.InstallPlanApproval = desired.Spec.InstallPlanApproval
+
+	if equality.Semantic.DeepEqual(*sub.Spec, updated) {
+		return false
+	}
+
+	sub.Spec = &updated
+	return true
+}
+
+// productInstallOrder returns the operators sorted so that each one comes after
+// its dependencies. Operators without an order between them keep the spec order.
+func productInstallOrder(operators []marketplacev1alpha1.ProductOperator) ([]marketplacev1alpha1.ProductOperator, error) {
+	byName := map[string]marketplacev1alpha1.ProductOperator{}
+	for _, operator := range operators {
+		if operator.Name == "" {
+			return nil, fmt.Errorf("operator of package %s has no name", operator.Package)
+		}
+		if _, ok := byName[operator.Name]; ok {
+			return nil, fmt.Errorf("operator %s is listed more than once", operator.Name)
+		}
+		byName[operator.Name] = operator
+	}
+
+	for _, operator := range operators {
+		for _, dep := range operator.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("operator %s depends on unknown operator %s", operator.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+	ordered := make([]marketplacev1alpha1.ProductOperator, 0, len(operators))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("operator %s has a circular dependency", name)
+		}
+
+		state[name] = visiting
+		for _, dep := range byName[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		ordered = append(ordered, byName[name])
+		return nil
+	}
+
+	for _, operator := range operators {
+		if err := visit(operator.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// productOperatorStatuses returns a status per operator in install order,
+// keeping the previous status of each operator.
+func productOperatorStatuses(
+	operators []marketplacev1alpha1.ProductOperator,
+	previous []marketplacev1alpha1.ProductOperatorStatus,
+) []marketplacev1alpha1.ProductOperatorStatus {
+	byName := map[string]marketplacev1alpha1.ProductOperatorStatus{}
+	for _, operatorStatus := range previous {
+		byName[operatorStatus.Name] = operatorStatus
+	}
+
+	statuses := make([]marketplacev1alpha1.ProductOperatorStatus, 0, len(operators))
+	for _, operator := range operators {
+		operatorStatus, ok := byName[operator.Name]
+		if !ok {
+			operatorStatus = marketplacev1alpha1.ProductOperatorStatus{
+				Name:  operator.Name,
+				Phase: marketplacev1alpha1.ProductInstallPhasePending,
+			}
+		}
+		statuses = append(statuses, operatorStatus)
+	}
+
+	return statuses
+}
+
+func (r *ProductInstallReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&marketplacev1alpha1.ProductInstall{}).
+		Owns(&olmv1alpha1.Subscription{}).
+		Watches(
+			&source.Kind{Type: &olmv1alpha1.ClusterServiceVersion{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.productInstallsOfCSV),
+			}).
+		Complete(r)
+}
+
+// productInstallsOfCSV enqueues the installs of the namespace of a csv.
+func (r *ProductInstallReconciler) productInstallsOfCSV(obj handler.MapObject) []reconcile.Request {
+	list := &marketplacev1alpha1.ProductInstallList{}
+	if err := r.Client.List(context.TODO(), list, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list product installs", "namespace", obj.Meta.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, instance := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+		}})
+	}
+	return requests
+}
@@ -0,0 +1,281 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("ProductInstallController", func() {
+	const namespace = "product"
+
+	operator := func(name string, dependsOn ...string) marketplacev1alpha1.ProductOperator {
+		return marketplacev1alpha1.ProductOperator{
+			Name:                   name,
+			Package:                name + "-rhmp",
+			Channel:                "stable",
+			CatalogSource:          "redhat-marketplace",
+			CatalogSourceNamespace: "openshift-marketplace",
+			DependsOn:              dependsOn,
+		}
+	}
+
+	names := func(operators []marketplacev1alpha1.ProductOperator) []string {
+		result := []string{}
+		for _, o := range operators {
+			result = append(result, o.Name)
+		}
+		return result
+	}
+
+	Context("install order", func() {
+		It("should install dependencies first", func() {
+			ordered, err := productInstallOrder([]marketplacev1alpha1.ProductOperator{
+				operator("app", "db", "cache"),
+				operator("cache"),
+				operator("db", "cache"),
+				operator("ui"),
+			})
+
+			Expect(err).To(Succeed())
+			Expect(names(ordered)).To(Equal([]string{"cache", "db", "app", "ui"}))
+		})
+
+		It("should reject unknown and circular dependencies", func() {
+			_, err := productInstallOrder([]marketplacev1alpha1.ProductOperator{
+				operator("app", "db"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("unknown operator db")))
+
+			_, err = productInstallOrder([]marketplacev1alpha1.ProductOperator{
+				operator("app", "db"),
+				operator("db", "app"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("circular dependency")))
+
+			_, err = productInstallOrder([]marketplacev1alpha1.ProductOperator{
+				operator("app"),
+				operator("app"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("more than once")))
+		})
+	})
+
+	Context("reconcile", func() {
+		var (
+			r        *ProductInstallReconciler
+			instance *marketplacev1alpha1.ProductInstall
+			req      reconcile.Request
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(marketplacev1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(olmv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			db := operator("db")
+			db.StartingCSV = "db.v1.0.0"
+
+			instance = &marketplacev1alpha1.ProductInstall{
+				ObjectMeta: metav1.ObjectMeta{Name: "product", Namespace: namespace},
+				Spec: marketplacev1alpha1.ProductInstallSpec{
+					Operators: []marketplacev1alpha1.ProductOperator{operator("app", "db"), db},
+				},
+			}
+			req = reconcile.Request{NamespacedName: types.NamespacedName{Name: "product", Namespace: namespace}}
+
+			r = &ProductInstallReconciler{
+				Client: fake.NewFakeClientWithScheme(scheme, instance),
+				Scheme: scheme,
+				Log:    logf.Log.WithName("productinstall_controller"),
+			}
+		})
+
+		installed := func(name, csvName string, phase olmv1alpha1.ClusterServiceVersionPhase) {
+			sub := &olmv1alpha1.Subscription{}
+			Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, sub)).To(Succeed())
+			sub.Status.InstalledCSV = csvName
+			Expect(r.Client.Update(context.TODO(), sub)).To(Succeed())
+
+			csv := &olmv1alpha1.ClusterServiceVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: csvName, Namespace: namespace},
+				Status:     olmv1alpha1.ClusterServiceVersionStatus{Phase: phase},
+			}
+			Expect(r.Client.Create(context.TODO(), csv)).To(Succeed())
+		}
+
+		subscriptions := func() []olmv1alpha1.Subscription {
+			subs := &olmv1alpha1.SubscriptionList{}
+			Expect(r.Client.List(context.TODO(), subs, client.InNamespace(namespace))).To(Succeed())
+			return subs.Items
+		}
+
+		get := func() *marketplacev1alpha1.ProductInstall {
+			result := &marketplacev1alpha1.ProductInstall{}
+			Expect(r.Client.Get(context.TODO(), req.NamespacedName, result)).To(Succeed())
+			return result
+		}
+
+		It("should install the operators in order and roll back on failure", func() {
+			_, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+
+			subs := subscriptions()
+			Expect(subs).To(HaveLen(1))
+			Expect(subs[0].Name).To(Equal("db"))
+			Expect(subs[0].Spec.StartingCSV).To(Equal("db.v1.0.0"))
+			Expect(subs[0].Spec.InstallPlanApproval).To(Equal(olmv1alpha1.ApprovalManual))
+			Expect(get().Status.Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseInstalling))
+
+			installed("db", "db.v1.0.0", olmv1alpha1.CSVPhaseSucceeded)
+
+			result, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			Expect(result.RequeueAfter).To(Equal(productInstallPollInterval))
+			Expect(subscriptions()).To(HaveLen(2))
+
+			installed("app", "app.v1.0.0", olmv1alpha1.CSVPhaseFailed)
+
+			_, err = r.Reconcile(req)
+			Expect(err).To(Succeed())
+
+			status := get().Status
+			Expect(status.Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseRolledBack))
+			Expect(status.Conditions.IsFalseFor(marketplacev1alpha1.ProductInstallConditionInstalled)).To(BeTrue())
+			Expect(status.Operators).To(HaveLen(2))
+			Expect(status.Operators[0].Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseRolledBack))
+			Expect(status.Operators[1].Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseFailed))
+
+			for _, sub := range subscriptions() {
+				Expect(sub.Labels).To(HaveKeyWithValue(uninstallTag, "true"))
+			}
+		})
+
+		It("should succeed once every csv succeeded", func() {
+			_, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			installed("db", "db.v1.0.0", olmv1alpha1.CSVPhaseSucceeded)
+
+			_, err = r.Reconcile(req)
+			Expect(err).To(Succeed())
+			installed("app", "app.v1.0.0", olmv1alpha1.CSVPhaseSucceeded)
+
+			result, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			Expect(result).To(Equal(reconcile.Result{}))
+
+			status := get().Status
+			Expect(status.Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseSucceeded))
+			Expect(status.Conditions.IsTrueFor(marketplacev1alpha1.ProductInstallConditionInstalled)).To(BeTrue())
+			Expect(status.Operators[1].CSV).To(Equal("app.v1.0.0"))
+			Expect(get().GetFinalizers()).To(ContainElement(productInstallFinalizer))
+
+			csv := &olmv1alpha1.ClusterServiceVersion{}
+			Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: "app.v1.0.0", Namespace: namespace}, csv)).To(Succeed())
+			csv.Status.Phase = olmv1alpha1.CSVPhaseFailed
+			Expect(r.Client.Update(context.TODO(), csv)).To(Succeed())
+
+			_, err = r.Reconcile(req)
+			Expect(err).To(Succeed())
+
+			status = get().Status
+			Expect(status.Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseSucceeded))
+			Expect(status.Conditions.GetCondition(marketplacev1alpha1.ProductInstallConditionInstalled).Reason).
+				To(Equal(marketplacev1alpha1.ProductInstallReasonDegraded))
+			Expect(status.Operators[1].Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseFailed))
+		})
+
+		It("should delete the subscriptions and csvs when the install is deleted", func() {
+			_, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			installed("db", "db.v1.0.0", olmv1alpha1.CSVPhaseSucceeded)
+
+			instance := get()
+			now := metav1.Now()
+			instance.SetDeletionTimestamp(&now)
+			Expect(r.Client.Update(context.TODO(), instance)).To(Succeed())
+
+			_, err = r.Reconcile(req)
+			Expect(err).To(Succeed())
+
+			Expect(subscriptions()).To(BeEmpty())
+			csv := &olmv1alpha1.ClusterServiceVersion{}
+			err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "db.v1.0.0", Namespace: namespace}, csv)
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+			Expect(get().GetFinalizers()).NotTo(ContainElement(productInstallFinalizer))
+		})
+
+		It("should update the subscriptions when the spec changes", func() {
+			_, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			installed("db", "db.v1.0.0", olmv1alpha1.CSVPhaseSucceeded)
+
+			instance := get()
+			instance.Generation = 2
+			instance.Spec.Operators[1].Channel = "beta"
+			instance.Spec.Operators[1].StartingCSV = "db.v1.1.0"
+			Expect(r.Client.Update(context.TODO(), instance)).To(Succeed())
+
+			_, err = r.Reconcile(req)
+			Expect(err).To(Succeed())
+
+			sub := &olmv1alpha1.Subscription{}
+			Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: "db", Namespace: namespace}, sub)).To(Succeed())
+			Expect(sub.Spec.Channel).To(Equal("beta"))
+			Expect(sub.Spec.StartingCSV).To(Equal("db.v1.1.0"))
+
+			status := get().Status
+			Expect(status.Operators[0].Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseInstalling))
+			Expect(status.Operators[0].Message).To(ContainSubstring("db.v1.1.0"))
+		})
+
+		It("should not roll back subscriptions it did not create", func() {
+			sub := &olmv1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: namespace},
+				Spec:       &olmv1alpha1.SubscriptionSpec{Package: "db-rhmp", Channel: "stable"},
+			}
+			Expect(r.Client.Create(context.TODO(), sub)).To(Succeed())
+			installed("db", "db.v1.0.0", olmv1alpha1.CSVPhaseSucceeded)
+
+			_, err := r.Reconcile(req)
+			Expect(err).To(Succeed())
+			installed("app", "app.v1.0.0", olmv1alpha1.CSVPhaseFailed)
+
+			_, err = r.Reconcile(req)
+			Expect(err).To(Succeed())
+
+			status := get().Status
+			Expect(status.Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseRolledBack))
+			Expect(status.Operators[0].Phase).To(Equal(marketplacev1alpha1.ProductInstallPhaseSucceeded))
+			Expect(status.Operators[0].Message).To(ContainSubstring("not rolled back"))
+
+			Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: "db", Namespace: namespace}, sub)).To(Succeed())
+			Expect(sub.Labels).NotTo(HaveKey(uninstallTag))
+		})
+	})
+})
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	emperrors "emperror.dev/errors"
@@ -27,6 +28,7 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/runnables"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/patch"
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
@@ -43,6 +45,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -65,6 +68,13 @@ var (
 // blank assignment to verify that ReconcileRazeeDeployment implements reconcile.Reconciler
 var _ reconcile.Reconciler = &RazeeDeploymentReconciler{}
 
+func init() {
+	runnables.RegisterRemediationPolicies(
+		runnables.AuthCheckRemediation("remoteresources3", map[string]string{"app": utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME}),
+		runnables.AuthCheckRemediation("watch-keeper", map[string]string{"app": utils.RHM_WATCHKEEPER_DEPLOYMENT_NAME}),
+	)
+}
+
 // RazeeDeploymentReconciler reconciles a RazeeDeployment object
 type RazeeDeploymentReconciler struct {
 	// This Client, initialized using mgr.Client() above, is a split Client
@@ -74,9 +84,10 @@ type RazeeDeploymentReconciler struct {
 	Log    logr.Logger
 	CC     ClientCommandRunner
 
-	patcher patch.Patcher
-	cfg     *config.OperatorConfig
-	factory *manifests.Factory
+	patcher  patch.Patcher
+	cfg      *config.OperatorConfig
+	factory  *manifests.Factory
+	recorder record.EventRecorder
 }
 
 func (r *RazeeDeploymentReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
@@ -104,6 +115,11 @@ func (r *RazeeDeploymentReconciler) InjectOperatorConfig(cfg *config.OperatorCon
 	return nil
 }
 
+func (r *RazeeDeploymentReconciler) InjectEventRecorder(rec record.EventRecorder) error {
+	r.recorder = rec
+	return nil
+}
+
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func (r *RazeeDeploymentReconciler) SetupWithManager(mgr manager.Manager) error {
 
@@ -226,6 +242,27 @@ func (r *RazeeDeploymentReconciler) Reconcile(request reconcile.Request) (reconc
 		return reconcile.Result{}, err
 	}
 
+	result, err := r.reconcileRazee(request, instance, reqLogger)
+	if err != nil {
+		reason := marketplacev1alpha1.RazeeEventReasonInstallFailed
+		if instance.GetDeletionTimestamp() != nil {
+			reason = marketplacev1alpha1.RazeeEventReasonUninstallFailed
+		}
+
+		r.recorder.Event(instance, corev1.EventTypeWarning, reason, err.Error())
+	}
+
+	return result, err
+}
+
+// reconcileRazee installs razee for the RazeeDeployment instance, or
+// uninstalls it once the instance is deleted.
+func (r *RazeeDeploymentReconciler) reconcileRazee(
+	request reconcile.Request,
+	instance *marketplacev1alpha1.RazeeDeployment,
+	reqLogger logr.Logger,
+) (reconcile.Result, error) {
+	var err error
 	cc := r.CC
 	factory := r.factory
 
@@ -359,6 +396,8 @@ func (r *RazeeDeploymentReconciler) Reconcile(request reconcile.Request) (reconc
 	if err != nil {
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Failed to find operator secret")
+			r.recorder.Eventf(instance, corev1.EventTypeWarning, marketplacev1alpha1.RazeeEventReasonSecretMissing,
+				"operator secret %s/%s not found", request.Namespace, secretName)
 			return reconcile.Result{RequeueAfter: time.Second * 60}, nil
 		} else {
 			return reconcile.Result{}, err
@@ -397,6 +436,8 @@ func (r *RazeeDeploymentReconciler) Reconcile(request reconcile.Request) (reconc
 
 	if len(instance.Status.MissingDeploySecretValues) > 0 {
 		reqLogger.Info("Missing required razee configuration values, will wait until the secret is updated")
+		r.recorder.Eventf(instance, corev1.EventTypeWarning, marketplacev1alpha1.RazeeEventReasonSecretValuesMissing,
+			"operator secret %s is missing the values %s", secretName, strings.Join(instance.Status.MissingDeploySecretValues, ", "))
 		return reconcile.Result{}, nil
 	}
 
@@ -970,7 +1011,26 @@ func (r *RazeeDeploymentReconciler) Reconcile(request reconcile.Request) (reconc
 		Patcher: r.patcher,
 	}
 
-	if rrs3DeploymentEnabled {
+	// the operator syncs the RemoteResourceS3 natively, the razee remoteresources3
+	// controller and watch-keeper are not deployed.
+	nativeSync := r.cfg.RemoteResourceS3.Native
+	if nativeSync {
+		res, err := r.removeNativeSyncReplacedDeployments(instance)
+		if res != nil {
+			return *res, err
+		}
+
+		if registrationEnabled && instance.Status.Conditions.SetCondition(status.Condition{
+			Type:    marketplacev1alpha1.ConditionRegistrationEnabled,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.ReasonRhmRegistrationWatchkeeperEnabled,
+			Message: "Registration deployment replaced by the native RemoteResourceS3 sync",
+		}) {
+			_ = r.Client.Status().Update(context.TODO(), instance)
+		}
+	}
+
+	if rrs3DeploymentEnabled && !nativeSync {
 		if result, _ := cc.Do(context.TODO(),
 			HandleResult(
 				manifests.CreateOrUpdateFactoryItemAction(
@@ -996,7 +1056,7 @@ func (r *RazeeDeploymentReconciler) Reconcile(request reconcile.Request) (reconc
 		}
 	}
 
-	if registrationEnabled {
+	if registrationEnabled && !nativeSync {
 		watchKeeperDeployment := &appsv1.Deployment{}
 		reqLogger.V(0).Info("Finding watch-keeper deployment")
 
@@ -1532,6 +1592,42 @@ func (r *RazeeDeploymentReconciler) removeRazeeDeployments(
 	return &reconcile.Result{Requeue: true}, nil
 }
 
+// removeNativeSyncReplacedDeployments undeploys the razee deployments the
+// native sync replaces. Watch-keeper only reports the cluster resources to
+// razee, which is not used once the sync is native.
+func (r *RazeeDeploymentReconciler) removeNativeSyncReplacedDeployments(req *marketplacev1alpha1.RazeeDeployment) (*reconcile.Result, error) {
+	res, err := r.removeRemoteResourceS3Deployment(req)
+	if res != nil {
+		return res, err
+	}
+
+	return r.removeWatchkeeperDeployment(req)
+}
+
+// Undeploy the remoteresources3 deployment replaced by the native sync
+func (r *RazeeDeploymentReconciler) removeRemoteResourceS3Deployment(req *marketplacev1alpha1.RazeeDeployment) (*reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME,
+			Namespace: *req.Spec.TargetNamespace,
+		},
+	}
+
+	err := r.Client.Delete(context.TODO(), deployment)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		reqLogger.Error(err, "could not delete deployment", "name", utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME)
+		return &reconcile.Result{}, err
+	}
+
+	reqLogger.Info("deleted deployment replaced by native sync", "name", utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME)
+	return &reconcile.Result{Requeue: true}, nil
+}
+
 //Undeploy the watchkeeper deployment
 func (r *RazeeDeploymentReconciler) removeWatchkeeperDeployment(req *marketplacev1alpha1.RazeeDeployment) (*reconcile.Result, error) {
 	reqLogger := r.Log.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
@@ -15,6 +15,7 @@
 package marketplace
 
 import (
+	"context"
 	"time"
 
 	"github.com/gotidy/ptr"
@@ -32,12 +33,14 @@ import (
 	batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -47,6 +50,7 @@ import (
 var _ = Describe("Testing with Ginkgo", func() {
 	var setup func(r *ReconcilerTest) error
 	var (
+		recorder                       *record.FakeRecorder
 		name                           = utils.RAZEE_NAME
 		namespace                      = "redhat-marketplace"
 		secretName                     = "rhm-operator-secret"
@@ -68,6 +72,7 @@ var _ = Describe("Testing with Ginkgo", func() {
 
 	BeforeEach(func() {
 
+		recorder = record.NewFakeRecorder(10)
 		name = utils.RAZEE_NAME
 		namespace = "redhat-marketplace"
 		secretName = "rhm-operator-secret"
@@ -215,13 +220,14 @@ var _ = Describe("Testing with Ginkgo", func() {
 			)
 
 			r.SetReconciler(&RazeeDeploymentReconciler{
-				Client:  r.GetClient(),
-				Scheme:  scheme.Scheme,
-				Log:     log,
-				CC:      reconcileutils.NewClientCommand(r.GetClient(), scheme.Scheme, log),
-				cfg:     cfg,
-				factory: factory,
-				patcher: patch.RHMDefaultPatcher,
+				Client:   r.GetClient(),
+				Scheme:   scheme.Scheme,
+				Log:      log,
+				CC:       reconcileutils.NewClientCommand(r.GetClient(), scheme.Scheme, log),
+				cfg:      cfg,
+				factory:  factory,
+				patcher:  patch.RHMDefaultPatcher,
+				recorder: recorder,
 			})
 			return nil
 		}
@@ -301,6 +307,7 @@ var _ = Describe("Testing with Ginkgo", func() {
 					RequeueResult,
 					RequeueAfterResult(time.Second*60)),
 			))
+		Expect(recorder.Events).To(Receive(ContainSubstring(marketplacev1alpha1.RazeeEventReasonSecretMissing)))
 	})
 
 	It("bad name", func() {
@@ -437,3 +444,35 @@ var _ = Describe("Testing with Ginkgo", func() {
 		)
 	})
 })
+
+var _ = Describe("RazeeDeployment native sync", func() {
+	It("should remove the remoteresources3 and watch-keeper deployments", func() {
+		namespace := "redhat-marketplace"
+		razeeDeployment := &marketplacev1alpha1.RazeeDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: utils.RAZEE_NAME, Namespace: namespace},
+			Spec:       marketplacev1alpha1.RazeeDeploymentSpec{TargetNamespace: ptr.String(namespace)},
+		}
+
+		client := fake.NewFakeClientWithScheme(scheme.Scheme,
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME, Namespace: namespace}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: utils.RHM_WATCHKEEPER_DEPLOYMENT_NAME, Namespace: namespace}},
+		)
+		r := &RazeeDeploymentReconciler{Client: client, Log: logf.Log.WithName("razeedeployment_controller")}
+
+		for i := 0; i < 2; i++ {
+			res, err := r.removeNativeSyncReplacedDeployments(razeeDeployment)
+			Expect(err).To(Succeed())
+			Expect(res).ToNot(BeNil())
+			Expect(res.Requeue).To(BeTrue())
+		}
+
+		res, err := r.removeNativeSyncReplacedDeployments(razeeDeployment)
+		Expect(err).To(Succeed())
+		Expect(res).To(BeNil())
+
+		for _, name := range []string{utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME, utils.RHM_WATCHKEEPER_DEPLOYMENT_NAME} {
+			err := client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, &appsv1.Deployment{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+		}
+	})
+})
@@ -16,16 +16,32 @@ package marketplace
 
 import (
 	"context"
+	"crypto"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
+	emperrors "emperror.dev/errors"
 	"github.com/go-logr/logr"
 	"github.com/gotidy/ptr"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifestsync"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -37,16 +53,43 @@ import (
 // blank assignment to verify that ReconcileNode implements reconcile.Reconciler
 var _ reconcile.Reconciler = &RemoteResourceS3Reconciler{}
 
-// ReconcileNode reconciles a Node object
+// RemoteResourceS3Reconciler syncs the remote resources of a RemoteResourceS3
+// when the native sync is enabled. Otherwise razee applies them and the
+// reconciler reports the errors razee logged as conditions.
 type RemoteResourceS3Reconciler struct {
 	// This Client, initialized using mgr.Client() above, is a split Client
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+
+	cfg    *config.OperatorConfig
+	mapper meta.RESTMapper
+
+	// httpClient replaces the client of the shared transport in tests
+	httpClient *http.Client
+}
+
+func (r *RemoteResourceS3Reconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *RemoteResourceS3Reconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
 }
 
 func (r *RemoteResourceS3Reconciler) SetupWithManager(mgr manager.Manager) error {
+	if r.cfg.RemoteResourceS3.Native {
+		r.mapper = mgr.GetRESTMapper()
+
+		// status updates must not trigger another sync
+		return ctrl.NewControllerManagedBy(mgr).
+			For(&marketplacev1alpha1.RemoteResourceS3{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+			Complete(r)
+	}
+
 	labelPreds := []predicate.Predicate{
 		predicate.Funcs{
 			UpdateFunc: func(evt event.UpdateEvent) bool {
@@ -91,10 +134,16 @@ func (r *RemoteResourceS3Reconciler) Reconcile(request reconcile.Request) (recon
 		return reconcile.Result{}, err
 	}
 
-	if instance.Status.Touched == nil {
-		instance.Status = marketplacev1alpha1.RemoteResourceS3Status{
-			Touched: ptr.Bool(true),
-		}
+	if r.cfg.RemoteResourceS3.Native {
+		return r.sync(reqLogger, instance)
+	}
+
+	// the razee deployment fetches the requests and logs its errors on the status
+	previous := instance.Status.DeepCopy()
+	instance.Status.Touched = ptr.Bool(true)
+	razeeSyncStatus(instance)
+
+	if !equality.Semantic.DeepEqual(*previous, instance.Status) {
 		err := r.Client.Status().Update(context.TODO(), instance)
 		if err != nil {
 			return reconcile.Result{}, err
@@ -105,3 +154,326 @@ func (r *RemoteResourceS3Reconciler) Reconcile(request reconcile.Request) (recon
 	reqLogger.Info("finished reconcile")
 	return reconcile.Result{}, nil
 }
+
+// sync fetches the requests, applies their objects and prunes the objects
+// that are no longer in the remote resources.
+func (r *RemoteResourceS3Reconciler) sync(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.RemoteResourceS3,
+) (reconcile.Result, error) {
+	ctx := context.TODO()
+
+	// the shared transport is taken on every sync to trust an updated CA bundle
+	httpClient := r.httpClient
+	if httpClient == nil {
+		t, err := r.cfg.Infrastructure.Transport()
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		httpClient = &http.Client{
+			Transport: t,
+			Timeout:   time.Minute,
+		}
+	}
+
+	syncer := &manifestsync.Syncer{
+		Client: r.Client,
+		Mapper: r.mapper,
+		Fetcher: &manifestsync.Fetcher{
+			Client:     r.Client,
+			HTTPClient: httpClient,
+			Namespace:  instance.Namespace,
+			Auth:       instance.Spec.Auth,
+		},
+		AllowedNamespaces: r.cfg.RemoteResourceS3.AllowedNamespaces,
+	}
+
+	for _, kind := range r.cfg.RemoteResourceS3.AllowedKinds {
+		syncer.AllowedKinds = append(syncer.AllowedKinds, schema.ParseGroupKind(kind))
+	}
+
+	// unsigned remote resources are never applied
+	publicKey, err := r.publicKey(ctx, instance)
+	if err != nil {
+		return r.syncFailed(reqLogger, instance, marketplacev1alpha1.RemoteResourceS3ReasonVerificationFailed, err)
+	}
+	syncer.PublicKey = publicKey
+
+	instance.Status.Requests = remoteResourceS3RequestStatuses(instance.Spec.Requests, instance.Status.Requests)
+
+	previous := instance.Status.Objects
+	applied := []marketplacev1alpha1.AppliedObject{}
+	complete := true
+
+	for i, request := range instance.Spec.Requests {
+		requestApplied, reason, err := r.syncRequest(ctx, syncer, instance, &instance.Status.Requests[i], request)
+		applied = append(applied, requestApplied...)
+
+		if err == nil {
+			continue
+		}
+
+		complete = false
+
+		if request.Optional {
+			reqLogger.Info("skipping optional request", "url", manifestsync.RequestURL(request.Options), "error", err.Error())
+			continue
+		}
+
+		// keep the previous objects so they can still be pruned
+		instance.Status.Objects = mergeAppliedObjects(previous, applied)
+		return r.syncFailed(reqLogger, instance, reason, err)
+	}
+
+	// objects of a skipped request are only pruned once it is fetched again
+	if complete && (instance.Spec.Prune == nil || *instance.Spec.Prune) {
+		if err := syncer.Prune(ctx, instance, previous, applied); err != nil {
+			instance.Status.Objects = mergeAppliedObjects(previous, applied)
+			return r.syncFailed(reqLogger, instance, marketplacev1alpha1.RemoteResourceS3ReasonPruneFailed, err)
+		}
+		instance.Status.Objects = applied
+	} else {
+		instance.Status.Objects = mergeAppliedObjects(previous, applied)
+	}
+
+	instance.Status.Touched = ptr.Bool(true)
+	instance.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
+	instance.Status.Conditions.SetCondition(status.Condition{
+		Type:    marketplacev1alpha1.RemoteResourceS3ConditionSynced,
+		Status:  corev1.ConditionTrue,
+		Reason:  marketplacev1alpha1.RemoteResourceS3ReasonSynced,
+		Message: fmt.Sprintf("Applied %d objects.", len(applied)),
+	})
+
+	if err := r.Client.Status().Update(ctx, instance); err != nil {
+		reqLogger.Error(err, "failed to update status")
+		return reconcile.Result{}, err
+	}
+
+	reqLogger.Info("synced remoteresources3", "objects", len(applied))
+	return reconcile.Result{RequeueAfter: r.cfg.RemoteResourceS3.SyncInterval}, nil
+}
+
+// syncRequest fetches and applies a request and records its status and
+// condition on the request status.
+func (r *RemoteResourceS3Reconciler) syncRequest(
+	ctx context.Context,
+	syncer *manifestsync.Syncer,
+	instance *marketplacev1alpha1.RemoteResourceS3,
+	requestStatus *marketplacev1alpha1.RequestStatus,
+	request marketplacev1alpha1.Request,
+) ([]marketplacev1alpha1.AppliedObject, status.ConditionReason, error) {
+	applied := []marketplacev1alpha1.AppliedObject{}
+	reason := marketplacev1alpha1.RemoteResourceS3ReasonSynced
+
+	result, err := syncer.FetchRequest(ctx, request)
+	if err != nil {
+		reason = marketplacev1alpha1.RemoteResourceS3ReasonFetchFailed
+
+		var verificationErr *manifestsync.VerificationError
+		if emperrors.As(err, &verificationErr) {
+			reason = marketplacev1alpha1.RemoteResourceS3ReasonVerificationFailed
+		}
+	} else {
+		applied, err = syncer.Apply(ctx, instance, result.Objects)
+		if err != nil {
+			reason = marketplacev1alpha1.RemoteResourceS3ReasonApplyFailed
+
+			var notAllowedErr *manifestsync.NotAllowedError
+			if emperrors.As(err, &notAllowedErr) {
+				reason = marketplacev1alpha1.RemoteResourceS3ReasonNotAllowed
+			}
+		}
+	}
+
+	*requestStatus = marketplacev1alpha1.RequestStatus{
+		URL:            result.URL,
+		LastFetchTime:  &metav1.Time{Time: time.Now()},
+		HTTPStatus:     result.StatusCode,
+		ContentHash:    result.ContentHash,
+		AppliedObjects: len(applied),
+		Conditions:     requestStatus.Conditions,
+	}
+
+	cond := status.Condition{
+		Type:    marketplacev1alpha1.RemoteResourceS3ConditionSynced,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("Applied %d objects from %s.", len(applied), result.URL),
+	}
+
+	if err != nil {
+		requestStatus.LastError = err.Error()
+		cond.Status = corev1.ConditionFalse
+		cond.Message = err.Error()
+	}
+
+	requestStatus.Conditions.SetCondition(cond)
+
+	return applied, reason, err
+}
+
+// publicKey returns the key of the verification of the instance, or the key
+// configured on the operator.
+func (r *RemoteResourceS3Reconciler) publicKey(
+	ctx context.Context,
+	instance *marketplacev1alpha1.RemoteResourceS3,
+) (crypto.PublicKey, error) {
+	namespace := instance.Namespace
+	ref := corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: r.cfg.RemoteResourceS3.PublicKeySecret},
+		Key:                  r.cfg.RemoteResourceS3.PublicKeySecretKey,
+	}
+
+	if verification := instance.Spec.Verification; verification != nil {
+		ref = verification.PublicKeyRef
+	} else {
+		namespace = r.cfg.DeployedNamespace
+	}
+
+	data, err := manifestsync.SecretValue(ctx, r.Client, namespace, ref)
+	if err != nil {
+		return nil, emperrors.WrapWithDetails(err, "no public key to verify the remote resources, set spec.verification or create the operator public key secret",
+			"secret", ref.Name, "namespace", namespace)
+	}
+
+	return manifestsync.ParsePublicKey([]byte(data))
+}
+
+func (r *RemoteResourceS3Reconciler) syncFailed(
+	reqLogger logr.Logger,
+	instance *marketplacev1alpha1.RemoteResourceS3,
+	reason status.ConditionReason,
+	syncErr error,
+) (reconcile.Result, error) {
+	reqLogger.Error(syncErr, "failed to sync remoteresources3", "reason", reason)
+
+	instance.Status.Touched = ptr.Bool(true)
+	instance.Status.Conditions.SetCondition(status.Condition{
+		Type:    marketplacev1alpha1.RemoteResourceS3ConditionSynced,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: syncErr.Error(),
+	})
+
+	if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+		reqLogger.Error(err, "failed to update status")
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, syncErr
+}
+
+// remoteResourceS3RequestStatuses returns a status for every request in spec
+// order. The previous status of the url of a request is kept, so a request
+// that is not reached or was moved keeps its last observation.
+func remoteResourceS3RequestStatuses(
+	requests []marketplacev1alpha1.Request,
+	previous []marketplacev1alpha1.RequestStatus,
+) []marketplacev1alpha1.RequestStatus {
+	byURL := map[string]marketplacev1alpha1.RequestStatus{}
+	for _, requestStatus := range previous {
+		if _, ok := byURL[requestStatus.URL]; !ok {
+			byURL[requestStatus.URL] = requestStatus
+		}
+	}
+
+	statuses := make([]marketplacev1alpha1.RequestStatus, len(requests))
+
+	for i, request := range requests {
+		url := manifestsync.RequestURL(request.Options)
+
+		if requestStatus, ok := byURL[url]; ok {
+			statuses[i] = requestStatus
+			continue
+		}
+
+		statuses[i] = marketplacev1alpha1.RequestStatus{URL: url}
+	}
+
+	return statuses
+}
+
+// razeeSyncStatus sets the synced conditions from the errors the razee
+// remoteresources3 deployment logged on the status. An error that names the
+// url of a request fails that request.
+func razeeSyncStatus(instance *marketplacev1alpha1.RemoteResourceS3) {
+	messages := make([]string, 0, len(instance.Status.RazeeLogs.Log))
+	for _, message := range instance.Status.RazeeLogs.Log {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+
+	instance.Status.Requests = remoteResourceS3RequestStatuses(instance.Spec.Requests, instance.Status.Requests)
+
+	fetchFailed := false
+
+	for i := range instance.Status.Requests {
+		requestStatus := &instance.Status.Requests[i]
+
+		requestErrors := []string{}
+		for _, message := range messages {
+			if requestStatus.URL != "" && strings.Contains(message, requestStatus.URL) {
+				requestErrors = append(requestErrors, message)
+			}
+		}
+
+		cond := status.Condition{
+			Type:    marketplacev1alpha1.RemoteResourceS3ConditionSynced,
+			Status:  corev1.ConditionTrue,
+			Reason:  marketplacev1alpha1.RemoteResourceS3ReasonSynced,
+			Message: "No errors reported by razee.",
+		}
+		requestStatus.LastError = ""
+
+		if len(requestErrors) != 0 {
+			fetchFailed = true
+			requestStatus.LastError = strings.Join(requestErrors, "; ")
+			cond.Status = corev1.ConditionFalse
+			cond.Reason = marketplacev1alpha1.RemoteResourceS3ReasonFetchFailed
+			cond.Message = requestStatus.LastError
+		}
+
+		requestStatus.Conditions.SetCondition(cond)
+	}
+
+	cond := status.Condition{
+		Type:    marketplacev1alpha1.RemoteResourceS3ConditionSynced,
+		Status:  corev1.ConditionTrue,
+		Reason:  marketplacev1alpha1.RemoteResourceS3ReasonSynced,
+		Message: "No errors reported by razee.",
+	}
+
+	if len(messages) != 0 {
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = marketplacev1alpha1.RemoteResourceS3ReasonRazeeFailed
+		cond.Message = strings.Join(messages, "; ")
+
+		if fetchFailed {
+			cond.Reason = marketplacev1alpha1.RemoteResourceS3ReasonFetchFailed
+		}
+	}
+
+	instance.Status.Conditions.SetCondition(cond)
+}
+
+// mergeAppliedObjects returns the objects of both lists without duplicates,
+// the applied reference wins over the previous one.
+func mergeAppliedObjects(previous, applied []marketplacev1alpha1.AppliedObject) []marketplacev1alpha1.AppliedObject {
+	seen := map[marketplacev1alpha1.AppliedObject]bool{}
+	merged := []marketplacev1alpha1.AppliedObject{}
+
+	for _, list := range [][]marketplacev1alpha1.AppliedObject{applied, previous} {
+		for _, obj := range list {
+			key := manifestsync.AppliedObjectKey(obj)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, obj)
+		}
+	}
+
+	return merged
+}
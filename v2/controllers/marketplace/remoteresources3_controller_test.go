@@ -0,0 +1,177 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("RemoteResourceS3Controller", func() {
+	request := func(url string) marketplacev1alpha1.Request {
+		return marketplacev1alpha1.Request{
+			Options: marketplacev1alpha1.S3Options{URL: url},
+		}
+	}
+
+	object := func(name string) marketplacev1alpha1.AppliedObject {
+		return marketplacev1alpha1.AppliedObject{APIVersion: "v1", Kind: "ConfigMap", Namespace: "ns", Name: name}
+	}
+
+	It("should keep the status of unchanged requests", func() {
+		fetched := marketplacev1alpha1.RequestStatus{
+			URL:            "https://example.com/a.yaml",
+			LastFetchTime:  &metav1.Time{},
+			HTTPStatus:     200,
+			AppliedObjects: 2,
+		}
+
+		statuses := remoteResourceS3RequestStatuses(
+			[]marketplacev1alpha1.Request{
+				request("https://example.com/c.yaml"),
+				request("https://example.com/a.yaml"),
+				request("https://example.com/d.yaml"),
+			},
+			[]marketplacev1alpha1.RequestStatus{
+				fetched,
+				{URL: "https://example.com/b.yaml", HTTPStatus: 404},
+			},
+		)
+
+		Expect(statuses).To(Equal([]marketplacev1alpha1.RequestStatus{
+			{URL: "https://example.com/c.yaml"},
+			fetched,
+			{URL: "https://example.com/d.yaml"},
+		}))
+	})
+
+	It("should report the errors logged by razee", func() {
+		instance := &marketplacev1alpha1.RemoteResourceS3{
+			Spec: marketplacev1alpha1.RemoteResourceS3Spec{
+				Requests: []marketplacev1alpha1.Request{
+					request("https://example.com/a.yaml"),
+					request("https://example.com/b.yaml"),
+				},
+			},
+			Status: marketplacev1alpha1.RemoteResourceS3Status{
+				RazeeLogs: marketplacev1alpha1.RazeeLogs{
+					Log: marketplacev1alpha1.Log{
+						"1f2e": "download failed: 404 https://example.com/b.yaml",
+					},
+				},
+			},
+		}
+
+		razeeSyncStatus(instance)
+
+		Expect(instance.Status.Conditions.IsFalseFor(marketplacev1alpha1.RemoteResourceS3ConditionSynced)).To(BeTrue())
+		Expect(instance.Status.Requests).To(HaveLen(2))
+		Expect(instance.Status.Requests[0].Conditions.IsTrueFor(marketplacev1alpha1.RemoteResourceS3ConditionSynced)).To(BeTrue())
+		Expect(instance.Status.Requests[1].Conditions.GetCondition(marketplacev1alpha1.RemoteResourceS3ConditionSynced).Reason).
+			To(Equal(marketplacev1alpha1.RemoteResourceS3ReasonFetchFailed))
+		Expect(instance.Status.Requests[1].LastError).To(ContainSubstring("404"))
+
+		instance.Status.RazeeLogs.Log = nil
+		razeeSyncStatus(instance)
+
+		Expect(instance.Status.Conditions.IsTrueFor(marketplacev1alpha1.RemoteResourceS3ConditionSynced)).To(BeTrue())
+		Expect(instance.Status.Requests[1].LastError).To(BeEmpty())
+	})
+
+	It("should report a failed download of the native sync", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(Succeed())
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		Expect(err).To(Succeed())
+
+		cfg := &config.OperatorConfig{DeployedNamespace: "openshift-redhat-marketplace"}
+		cfg.RemoteResourceS3 = config.RemoteResourceS3Config{
+			Native:             true,
+			PublicKeySecret:    "rhm-remoteresources3-public-key",
+			PublicKeySecretKey: "public.pem",
+		}
+
+		publicKey := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rhm-remoteresources3-public-key", Namespace: cfg.DeployedNamespace},
+			Data: map[string][]byte{
+				"public.pem": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+			},
+		}
+
+		instance := &marketplacev1alpha1.RemoteResourceS3{
+			ObjectMeta: metav1.ObjectMeta{Name: "parent", Namespace: cfg.DeployedNamespace},
+			Spec: marketplacev1alpha1.RemoteResourceS3Spec{
+				Requests: []marketplacev1alpha1.Request{request(server.URL + "/a.yaml")},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(marketplacev1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		r := &RemoteResourceS3Reconciler{
+			Client:     fake.NewFakeClientWithScheme(scheme, instance, publicKey),
+			Scheme:     scheme,
+			Log:        logf.Log.WithName("remoteresources3_controller"),
+			cfg:        cfg,
+			httpClient: server.Client(),
+		}
+
+		name := types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+		_, err = r.Reconcile(reconcile.Request{NamespacedName: name})
+		Expect(err).To(HaveOccurred())
+
+		result := &marketplacev1alpha1.RemoteResourceS3{}
+		Expect(r.Client.Get(context.TODO(), name, result)).To(Succeed())
+
+		cond := result.Status.Conditions.GetCondition(marketplacev1alpha1.RemoteResourceS3ConditionSynced)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(marketplacev1alpha1.RemoteResourceS3ReasonFetchFailed))
+
+		Expect(result.Status.Requests).To(HaveLen(1))
+		Expect(result.Status.Requests[0].HTTPStatus).To(Equal(http.StatusNotFound))
+		Expect(result.Status.Requests[0].LastError).ToNot(BeEmpty())
+		Expect(result.Status.Requests[0].Conditions.IsFalseFor(marketplacev1alpha1.RemoteResourceS3ConditionSynced)).To(BeTrue())
+	})
+
+	It("should merge applied objects without duplicates", func() {
+		Expect(mergeAppliedObjects(
+			[]marketplacev1alpha1.AppliedObject{object("a"), object("b")},
+			[]marketplacev1alpha1.AppliedObject{object("b"), object("c")},
+		)).To(Equal([]marketplacev1alpha1.AppliedObject{object("b"), object("c"), object("a")}))
+	})
+})
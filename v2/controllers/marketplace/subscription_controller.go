@@ -16,12 +16,18 @@ package marketplace
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 
 	"github.com/go-logr/logr"
 	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -29,9 +35,11 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const operatorTag = "marketplace.redhat.com/operator"
@@ -76,8 +84,38 @@ func (r *SubscriptionReconciler) SetupWithManager(mgr manager.Manager) error {
 		},
 	}
 
+	// policy and operator group changes reconcile the marketplace subscriptions of the namespace
+	namespaceSubscriptions := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(
+			func(a handler.MapObject) []reconcile.Request {
+				subs := &olmv1alpha1.SubscriptionList{}
+				err := r.Client.List(context.TODO(), subs,
+					client.InNamespace(a.Meta.GetNamespace()),
+					client.MatchingLabels{operatorTag: "true"})
+				if err != nil {
+					r.Log.Error(err, "failed to list subscriptions", "namespace", a.Meta.GetNamespace())
+					return nil
+				}
+
+				requests := make([]reconcile.Request, 0, len(subs.Items))
+				for _, sub := range subs.Items {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: sub.Name, Namespace: sub.Namespace},
+					})
+				}
+				return requests
+			}),
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&olmv1alpha1.Subscription{}, builder.WithPredicates(labelPreds...)).
+		Watches(
+			&source.Kind{Type: &marketplacev1alpha1.OperatorGroupPolicy{}},
+			namespaceSubscriptions,
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &olmv1.OperatorGroup{}},
+			namespaceSubscriptions).
 		Complete(r)
 }
 
@@ -106,6 +144,15 @@ func (r *SubscriptionReconciler) Reconcile(request reconcile.Request) (reconcile
 		return r.uninstall(instance)
 	}
 
+	policy, err := r.operatorGroupPolicy(instance.GetNamespace())
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if policy != nil {
+		return r.reconcileOperatorGroupPolicy(policy)
+	}
+
 	groups := &olmv1.OperatorGroupList{}
 
 	// find operator groups
@@ -180,18 +227,20 @@ func (r *SubscriptionReconciler) Reconcile(request reconcile.Request) (reconcile
 }
 
 func (r *SubscriptionReconciler) createOperatorGroup(instance *olmv1alpha1.Subscription) *olmv1.OperatorGroup {
+	return newOperatorGroup(instance.Namespace, []string{instance.Namespace})
+}
+
+func newOperatorGroup(namespace string, targetNamespaces []string) *olmv1.OperatorGroup {
 	return &olmv1.OperatorGroup{
 		ObjectMeta: v1.ObjectMeta{
-			Namespace:    instance.Namespace,
+			Namespace:    namespace,
 			GenerateName: "redhat-marketplace-og-",
 			Labels: map[string]string{
 				operatorTag: "true",
 			},
 		},
 		Spec: olmv1.OperatorGroupSpec{
-			TargetNamespaces: []string{
-				instance.Namespace,
-			},
+			TargetNamespaces: targetNamespaces,
 		},
 	}
 }
@@ -229,3 +278,251 @@ func (r *SubscriptionReconciler) uninstall(sub *olmv1alpha1.Subscription) (recon
 	reqLogger.Info("uninstalling operator complete")
 	return reconcile.Result{}, nil
 }
+
+// operatorGroupPlan is the change to the OperatorGroups of a namespace that
+// satisfies its OperatorGroupPolicy.
+type operatorGroupPlan struct {
+	create        *olmv1.OperatorGroup
+	update        *olmv1.OperatorGroup
+	delete        []*olmv1.OperatorGroup
+	operatorGroup string
+	condition     status.Condition
+}
+
+// operatorGroupPolicy returns the OperatorGroupPolicy of the namespace. When
+// the namespace has several, the first by name is used and the others report
+// a conflict.
+func (r *SubscriptionReconciler) operatorGroupPolicy(namespace string) (*marketplacev1alpha1.OperatorGroupPolicy, error) {
+	policies := &marketplacev1alpha1.OperatorGroupPolicyList{}
+
+	err := r.Client.List(context.TODO(), policies, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(policies.Items, func(i, j int) bool {
+		return policies.Items[i].Name < policies.Items[j].Name
+	})
+
+	policy := &policies.Items[0]
+
+	for i := range policies.Items[1:] {
+		other := &policies.Items[i+1]
+		err := r.updateOperatorGroupPolicyStatus(other, "", status.Condition{
+			Type:    marketplacev1alpha1.OperatorGroupPolicyConditionReady,
+			Status:  corev1.ConditionFalse,
+			Reason:  marketplacev1alpha1.OperatorGroupPolicyReasonMultiplePolicy,
+			Message: fmt.Sprintf("operatorgrouppolicy %s is used for the namespace", policy.Name),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// reconcileOperatorGroupPolicy applies the policy to the OperatorGroups of its
+// namespace. OperatorGroups not created by the operator are never changed.
+func (r *SubscriptionReconciler) reconcileOperatorGroupPolicy(policy *marketplacev1alpha1.OperatorGroupPolicy) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("OperatorGroupPolicy.Namespace", policy.Namespace, "OperatorGroupPolicy.Name", policy.Name)
+
+	groups := &olmv1.OperatorGroupList{}
+	err := r.Client.List(context.TODO(), groups, client.InNamespace(policy.Namespace))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	plan := planOperatorGroups(policy, groups.Items)
+	requeue := false
+
+	for _, og := range plan.delete {
+		reqLogger.Info("deleting operator group", "name", og.GetName())
+
+		err := r.Client.Delete(context.TODO(), og)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "failed to delete", "name", og.GetName())
+			return reconcile.Result{}, err
+		}
+
+		requeue = true
+	}
+
+	if plan.update != nil {
+		reqLogger.Info("updating operator group", "name", plan.update.GetName(),
+			"targetNamespaces", plan.update.Spec.TargetNamespaces)
+
+		if err := r.Client.Update(context.TODO(), plan.update); err != nil {
+			reqLogger.Error(err, "failed to update", "name", plan.update.GetName())
+			return reconcile.Result{}, err
+		}
+	}
+
+	if plan.create != nil {
+		reqLogger.Info("creating an operator group",
+			"generate-name", plan.create.GetGenerateName(),
+			"targetNamespaces", plan.create.Spec.TargetNamespaces)
+
+		if err := r.Client.Create(context.TODO(), plan.create); err != nil {
+			reqLogger.Error(err, "failed to create", "generate-name", plan.create.GetGenerateName())
+			return reconcile.Result{}, err
+		}
+
+		plan.operatorGroup = plan.create.GetName()
+		requeue = true
+	}
+
+	if err := r.updateOperatorGroupPolicyStatus(policy, plan.operatorGroup, plan.condition); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{Requeue: requeue}, nil
+}
+
+func (r *SubscriptionReconciler) updateOperatorGroupPolicyStatus(
+	policy *marketplacev1alpha1.OperatorGroupPolicy,
+	operatorGroup string,
+	condition status.Condition,
+) error {
+	changed := policy.Status.Conditions.SetCondition(condition)
+
+	if policy.Status.OperatorGroup != operatorGroup {
+		policy.Status.OperatorGroup = operatorGroup
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return r.Client.Status().Update(context.TODO(), policy)
+}
+
+// planOperatorGroups returns the changes that leave the namespace with a single
+// OperatorGroup that satisfies the policy. Groups created by the operator are
+// kept in line with the policy; any other group is adopted as is or reported
+// as a conflict.
+func planOperatorGroups(
+	policy *marketplacev1alpha1.OperatorGroupPolicy,
+	groups []olmv1.OperatorGroup,
+) operatorGroupPlan {
+	plan := operatorGroupPlan{}
+
+	if policy.Spec.OwnNamespace && len(policy.Spec.TargetNamespaces) != 0 {
+		plan.condition = operatorGroupPolicyCondition(false,
+			marketplacev1alpha1.OperatorGroupPolicyReasonInvalid,
+			"ownNamespace and targetNamespaces can't both be set")
+		return plan
+	}
+
+	targets := policy.Spec.TargetNamespaces
+	if policy.Spec.OwnNamespace {
+		targets = []string{policy.Namespace}
+	}
+
+	owned := []*olmv1.OperatorGroup{}
+	foreign := []*olmv1.OperatorGroup{}
+
+	for i := range groups {
+		og := &groups[i]
+		if og.GetLabels()[operatorTag] == "true" {
+			owned = append(owned, og)
+		} else {
+			foreign = append(foreign, og)
+		}
+	}
+
+	switch {
+	case len(foreign) > 1:
+		names := make([]string, 0, len(foreign))
+		for _, og := range foreign {
+			names = append(names, og.GetName())
+		}
+		sort.Strings(names)
+
+		plan.condition = operatorGroupPolicyCondition(false,
+			marketplacev1alpha1.OperatorGroupPolicyReasonMultipleGroups,
+			fmt.Sprintf("namespace has several operatorgroups: %s", strings.Join(names, ", ")))
+	case len(foreign) == 1 && !policy.ShouldAdoptExisting():
+		plan.condition = operatorGroupPolicyCondition(false,
+			marketplacev1alpha1.OperatorGroupPolicyReasonConflict,
+			fmt.Sprintf("operatorgroup %s was not created by the marketplace operator and adoptExisting is false", foreign[0].GetName()))
+	case len(foreign) == 1:
+		plan.delete = owned
+		plan.operatorGroup = foreign[0].GetName()
+
+		message := fmt.Sprintf("using operatorgroup %s", foreign[0].GetName())
+		if !sameTargetNamespaces(foreign[0].Spec.TargetNamespaces, targets) {
+			message = fmt.Sprintf("using operatorgroup %s, its target namespaces differ from the policy", foreign[0].GetName())
+		}
+
+		plan.condition = operatorGroupPolicyCondition(true,
+			marketplacev1alpha1.OperatorGroupPolicyReasonAdopted, message)
+	case len(owned) == 0:
+		plan.create = newOperatorGroup(policy.Namespace, targets)
+		plan.condition = operatorGroupPolicyCondition(true,
+			marketplacev1alpha1.OperatorGroupPolicyReasonCreated, "operatorgroup created by the marketplace operator")
+	default:
+		// keep the oldest group and drop the duplicates
+		sort.Slice(owned, func(i, j int) bool {
+			if !owned[i].CreationTimestamp.Equal(&owned[j].CreationTimestamp) {
+				return owned[i].CreationTimestamp.Before(&owned[j].CreationTimestamp)
+			}
+			return owned[i].GetName() < owned[j].GetName()
+		})
+
+		keep := owned[0]
+		plan.delete = owned[1:]
+		plan.operatorGroup = keep.GetName()
+
+		if !sameTargetNamespaces(keep.Spec.TargetNamespaces, targets) {
+			update := keep.DeepCopy()
+			update.Spec.TargetNamespaces = targets
+			plan.update = update
+		}
+
+		plan.condition = operatorGroupPolicyCondition(true,
+			marketplacev1alpha1.OperatorGroupPolicyReasonCreated, "operatorgroup created by the marketplace operator")
+	}
+
+	return plan
+}
+
+func operatorGroupPolicyCondition(ready bool, reason status.ConditionReason, message string) status.Condition {
+	condition := status.Condition{
+		Type:    marketplacev1alpha1.OperatorGroupPolicyConditionReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+
+	if ready {
+		condition.Status = corev1.ConditionTrue
+	}
+
+	return condition
+}
+
+// sameTargetNamespaces compares target namespaces regardless of order.
+func sameTargetNamespaces(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
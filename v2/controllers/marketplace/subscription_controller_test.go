@@ -20,9 +20,11 @@ import (
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/tests/rectest"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
 	opsrcApi "github.com/operator-framework/api/pkg/operators/v1"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -193,6 +195,47 @@ var _ = Describe("Testing with Ginkgo", func() {
 		)
 	}
 
+	var testNewSubscriptionWithPolicy = func(t GinkgoTInterface) {
+		t.Parallel()
+		policy := &marketplacev1alpha1.OperatorGroupPolicy{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "policy",
+				Namespace: namespace,
+			},
+			Spec: marketplacev1alpha1.OperatorGroupPolicySpec{
+				TargetNamespaces: []string{"tenant-a"},
+			},
+		}
+		reconcilerTest := NewReconcilerTest(setup, subscription, policy)
+		reconcilerTest.TestAll(t,
+			ReconcileStep(opts,
+				ReconcileWithExpectedResults(RequeueResult, DoneResult)),
+			ListStep(opts,
+				ListWithObj(&olmv1.OperatorGroupList{}),
+				ListWithFilter(
+					client.InNamespace(namespace)),
+				ListWithCheckResult(func(r *ReconcilerTest, t ReconcileTester, i runtime.Object) {
+					list, ok := i.(*olmv1.OperatorGroupList)
+
+					assert.Truef(t, ok, "expected operator group list got type %T", i)
+					assert.Equal(t, 1, len(list.Items))
+					assert.Equal(t, []string{"tenant-a"}, list.Items[0].Spec.TargetNamespaces)
+				}),
+			),
+			GetStep(opts,
+				GetWithObj(&marketplacev1alpha1.OperatorGroupPolicy{}),
+				GetWithNamespacedName("policy", namespace),
+				GetWithCheckResult(func(r *ReconcilerTest, t ReconcileTester, i runtime.Object) {
+					p, ok := i.(*marketplacev1alpha1.OperatorGroupPolicy)
+
+					assert.Truef(t, ok, "expected operator group policy got type %T", i)
+					assert.True(t, p.Status.Conditions.IsTrueFor(marketplacev1alpha1.OperatorGroupPolicyConditionReady))
+					assert.NotEmpty(t, p.Status.OperatorGroup)
+				}),
+			),
+		)
+	}
+
 	var testNewSubscriptionWithOperatorGroup = func(t GinkgoTInterface) {
 		t.Parallel()
 		reconcilerTest := NewReconcilerTest(setup, subscription, preExistingOperatorGroup)
@@ -260,9 +303,122 @@ var _ = Describe("Testing with Ginkgo", func() {
 		_ = opsrcApi.AddToScheme(scheme.Scheme)
 		_ = olmv1alpha1.AddToScheme(scheme.Scheme)
 		_ = olmv1.AddToScheme(scheme.Scheme)
+		_ = marketplacev1alpha1.AddToScheme(scheme.Scheme)
 		testNewSubscription(GinkgoT())
 		testNewSubscriptionWithOperatorGroup(GinkgoT())
 		testDeleteOperatorGroupIfTooMany(GinkgoT())
 		testSubscriptionDelete(GinkgoT())
+		testNewSubscriptionWithPolicy(GinkgoT())
+	})
+
+	Context("operator group policy", func() {
+		var policy *marketplacev1alpha1.OperatorGroupPolicy
+
+		owned := func(name string, targets ...string) olmv1.OperatorGroup {
+			og := newOperatorGroup(namespace, targets)
+			og.Name = name
+			return *og
+		}
+
+		BeforeEach(func() {
+			policy = &marketplacev1alpha1.OperatorGroupPolicy{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "policy",
+					Namespace: namespace,
+				},
+				Spec: marketplacev1alpha1.OperatorGroupPolicySpec{
+					TargetNamespaces: []string{"tenant-b", "tenant-a"},
+				},
+			}
+		})
+
+		It("should create an operator group for the target namespaces", func() {
+			plan := planOperatorGroups(policy, nil)
+
+			Expect(plan.create).ToNot(BeNil())
+			Expect(plan.create.Spec.TargetNamespaces).To(ConsistOf("tenant-a", "tenant-b"))
+			Expect(plan.condition.Reason).To(Equal(marketplacev1alpha1.OperatorGroupPolicyReasonCreated))
+		})
+
+		It("should target the own namespace", func() {
+			policy.Spec.TargetNamespaces = nil
+			policy.Spec.OwnNamespace = true
+
+			plan := planOperatorGroups(policy, nil)
+			Expect(plan.create.Spec.TargetNamespaces).To(Equal([]string{namespace}))
+		})
+
+		It("should reject own namespace with target namespaces", func() {
+			policy.Spec.OwnNamespace = true
+
+			plan := planOperatorGroups(policy, nil)
+			Expect(plan.create).To(BeNil())
+			Expect(plan.condition.IsFalse()).To(BeTrue())
+			Expect(plan.condition.Reason).To(Equal(marketplacev1alpha1.OperatorGroupPolicyReasonInvalid))
+		})
+
+		It("should leave a matching operator group alone", func() {
+			plan := planOperatorGroups(policy, []olmv1.OperatorGroup{
+				owned("og", "tenant-a", "tenant-b"),
+			})
+
+			Expect(plan.create).To(BeNil())
+			Expect(plan.update).To(BeNil())
+			Expect(plan.delete).To(BeEmpty())
+			Expect(plan.operatorGroup).To(Equal("og"))
+			Expect(plan.condition.IsTrue()).To(BeTrue())
+		})
+
+		It("should update its own operator group and drop duplicates", func() {
+			plan := planOperatorGroups(policy, []olmv1.OperatorGroup{
+				owned("og-b", namespace),
+				owned("og-a", namespace),
+			})
+
+			Expect(plan.operatorGroup).To(Equal("og-a"))
+			Expect(plan.update).ToNot(BeNil())
+			Expect(plan.update.Spec.TargetNamespaces).To(ConsistOf("tenant-a", "tenant-b"))
+			Expect(plan.delete).To(HaveLen(1))
+			Expect(plan.delete[0].Name).To(Equal("og-b"))
+		})
+
+		It("should adopt an existing operator group", func() {
+			plan := planOperatorGroups(policy, []olmv1.OperatorGroup{
+				*preExistingOperatorGroup,
+				owned("og", "tenant-a", "tenant-b"),
+			})
+
+			Expect(plan.operatorGroup).To(Equal(preExistingOperatorGroup.Name))
+			Expect(plan.update).To(BeNil())
+			Expect(plan.delete).To(HaveLen(1))
+			Expect(plan.delete[0].Name).To(Equal("og"))
+			Expect(plan.condition.Reason).To(Equal(marketplacev1alpha1.OperatorGroupPolicyReasonAdopted))
+		})
+
+		It("should report a conflict instead of adopting", func() {
+			adopt := false
+			policy.Spec.AdoptExisting = &adopt
+
+			plan := planOperatorGroups(policy, []olmv1.OperatorGroup{
+				*preExistingOperatorGroup,
+				owned("og", "tenant-a", "tenant-b"),
+			})
+
+			Expect(plan.create).To(BeNil())
+			Expect(plan.update).To(BeNil())
+			Expect(plan.delete).To(BeEmpty())
+			Expect(plan.condition.IsFalse()).To(BeTrue())
+			Expect(plan.condition.Reason).To(Equal(marketplacev1alpha1.OperatorGroupPolicyReasonConflict))
+		})
+
+		It("should report several existing operator groups", func() {
+			other := preExistingOperatorGroup.DeepCopy()
+			other.Name = "other-group"
+
+			plan := planOperatorGroups(policy, []olmv1.OperatorGroup{*preExistingOperatorGroup, *other})
+
+			Expect(plan.delete).To(BeEmpty())
+			Expect(plan.condition.Reason).To(Equal(marketplacev1alpha1.OperatorGroupPolicyReasonMultipleGroups))
+		})
 	})
 })
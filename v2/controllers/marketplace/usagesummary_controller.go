@@ -0,0 +1,381 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// blank assignment to verify that UsageSummaryReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &UsageSummaryReconciler{}
+
+// UsageSummaryReconciler fills the UsageSummaries in with the usage the
+// completed MeterReports uploaded for billing.
+type UsageSummaryReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+
+	cfg *config.OperatorConfig
+}
+
+// Reconcile summarizes the usage of the latest reports in the UsageSummary status.
+func (r *UsageSummaryReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.Log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling UsageSummary")
+
+	instance := &marketplacev1alpha1.UsageSummary{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			reqLogger.Info("UsageSummary resource not found. Ignoring since object must be deleted.")
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	reports := &marketplacev1alpha1.MeterReportList{}
+	if err := r.Client.List(context.TODO(), reports, client.InNamespace(r.cfg.DeployedNamespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// the summaries outside of the operator namespace only see their own usage
+	namespace := ""
+	if instance.Namespace != r.cfg.DeployedNamespace {
+		namespace = instance.Namespace
+	}
+
+	prices, invalidRates := ratePrices(instance.Spec.RateCard)
+	periods := usagePeriods(reports.Items, namespace, instance.GetPeriods(), prices)
+
+	condition := marketplacev1alpha1.UsageSummaryConditionSummarized
+	if len(periods) == 0 {
+		condition = marketplacev1alpha1.UsageSummaryConditionNoReports
+	}
+
+	changed := instance.Status.Conditions.SetCondition(condition)
+
+	truncated := marketplacev1alpha1.UsageSummaryConditionNotTruncated
+	for _, period := range periods {
+		if period.ResourcesTruncated {
+			truncated = marketplacev1alpha1.UsageSummaryConditionTruncated
+			break
+		}
+	}
+
+	changed = instance.Status.Conditions.SetCondition(truncated) || changed
+
+	switch {
+	case instance.Spec.RateCard == nil:
+		changed = instance.Status.Conditions.RemoveCondition(marketplacev1alpha1.UsageSummaryConditionTypeRateCardValid) || changed
+	case len(invalidRates) != 0:
+		rateCard := marketplacev1alpha1.UsageSummaryConditionRateCardInvalid
+		rateCard.Message = fmt.Sprintf("%s: %s", rateCard.Message, strings.Join(invalidRates, ", "))
+		changed = instance.Status.Conditions.SetCondition(rateCard) || changed
+	default:
+		changed = instance.Status.Conditions.SetCondition(marketplacev1alpha1.UsageSummaryConditionRateCardValid) || changed
+	}
+
+	if !changed && equality.Semantic.DeepEqual(instance.Status.Periods, periods) {
+		reqLogger.Info("usage unchanged")
+		return reconcile.Result{}, nil
+	}
+
+	instance.Status.Periods = periods
+	instance.Status.LastUpdateTime = &metav1.Time{Time: time.Now()}
+
+	if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+		reqLogger.Error(err, "failed to update status")
+		return reconcile.Result{}, err
+	}
+
+	reqLogger.Info("updated usage", "periods", len(periods))
+	return reconcile.Result{}, nil
+}
+
+// reportHasUsage returns true if the report job finished and recorded the
+// usage it uploaded.
+func reportHasUsage(report *marketplacev1alpha1.MeterReport) bool {
+	cond := report.Status.Conditions.GetCondition(marketplacev1alpha1.ReportConditionTypeJobRunning)
+	if cond == nil || cond.Reason != marketplacev1alpha1.ReportConditionReasonJobFinished {
+		return false
+	}
+
+	// reports from before the usage was recorded have metrics and no usage
+	return len(report.Status.Usage) != 0 ||
+		(report.Status.MetricUploadCount != nil && *report.Status.MetricUploadCount == 0)
+}
+
+// ratePrices returns the prices of the rate card by metric and the rates with
+// a price that isn't a number. The prices are nil without a rate card.
+func ratePrices(rateCard *marketplacev1alpha1.RateCard) (map[common.UsageTotal]float64, []string) {
+	if rateCard == nil {
+		return nil, nil
+	}
+
+	prices := map[common.UsageTotal]float64{}
+	invalid := []string{}
+
+	for _, rate := range rateCard.Rates {
+		price, err := strconv.ParseFloat(rate.Price, 64)
+		if err != nil || math.IsNaN(price) || math.IsInf(price, 0) {
+			invalid = append(invalid, fmt.Sprintf("%s/%s %s %q", rate.MeterGroup, rate.MeterKind, rate.Metric, rate.Price))
+			continue
+		}
+
+		prices[common.UsageTotal{
+			MeterGroup: rate.MeterGroup,
+			MeterKind:  rate.MeterKind,
+			Metric:     rate.Metric,
+		}] = price
+	}
+
+	return prices, invalid
+}
+
+// usagePeriods summarizes the usage of the latest reports, the latest first.
+// The usage is limited to the namespace unless it is empty. The usage is only
+// priced when the prices are not nil.
+func usagePeriods(
+	reports []marketplacev1alpha1.MeterReport,
+	namespace string,
+	count int,
+	prices map[common.UsageTotal]float64,
+) []marketplacev1alpha1.UsagePeriod {
+	completed := []*marketplacev1alpha1.MeterReport{}
+	for i := range reports {
+		if reportHasUsage(&reports[i]) {
+			completed = append(completed, &reports[i])
+		}
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Spec.EndTime.After(completed[j].Spec.EndTime.Time)
+	})
+
+	if len(completed) > count {
+		completed = completed[:count]
+	}
+
+	periods := make([]marketplacev1alpha1.UsagePeriod, 0, len(completed))
+	for _, report := range completed {
+		periods = append(periods, usagePeriod(report, namespace, prices))
+	}
+
+	return periods
+}
+
+// usagePeriod sums the usage of the report per meter, namespace and
+// resource and prices it with the rate card prices.
+func usagePeriod(
+	report *marketplacev1alpha1.MeterReport,
+	namespace string,
+	prices map[common.UsageTotal]float64,
+) marketplacev1alpha1.UsagePeriod {
+	meterDefinitions := map[common.UsageTotal]float64{}
+	namespaces := map[common.UsageTotal]float64{}
+	resources := map[common.UsageTotal]float64{}
+
+	for _, usage := range report.Status.Usage {
+		if namespace != "" && usage.Namespace != namespace {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(usage.Value, 64)
+		if err != nil {
+			continue
+		}
+
+		key := usage
+		key.Value = ""
+
+		if key.ResourceName != "" {
+			resources[key] += value
+		}
+
+		key.ResourceName = ""
+		namespaces[key] += value
+
+		key.Namespace = ""
+		meterDefinitions[key] += value
+	}
+
+	period := marketplacev1alpha1.UsagePeriod{
+		Report:             report.Name,
+		StartTime:          report.Spec.StartTime,
+		EndTime:            report.Spec.EndTime,
+		MeterDefinitions:   usageTotals(meterDefinitions, prices),
+		Namespaces:         usageTotals(namespaces, prices),
+		Resources:          usageTotals(resources, prices),
+		ResourcesTruncated: report.Status.UsageTruncated,
+	}
+
+	if prices != nil {
+		// sum the unrounded costs in a stable order and round once
+		cost := 0.0
+		for _, total := range period.MeterDefinitions {
+			key := total.UsageTotal
+			key.Value = ""
+
+			if price, ok := prices[usagePriceKey(key)]; ok {
+				cost += meterDefinitions[key] * price
+			}
+		}
+
+		period.Cost = formatCost(cost)
+	}
+
+	return period
+}
+
+func usageTotals(
+	totals map[common.UsageTotal]float64,
+	prices map[common.UsageTotal]float64,
+) []marketplacev1alpha1.UsageTotal {
+	if len(totals) == 0 {
+		return nil
+	}
+
+	result := make([]marketplacev1alpha1.UsageTotal, 0, len(totals))
+
+	for key, value := range totals {
+		total := marketplacev1alpha1.UsageTotal{UsageTotal: key}
+		total.Value = strconv.FormatFloat(value, 'f', -1, 64)
+
+		if price, ok := prices[usagePriceKey(key)]; ok {
+			total.Cost = formatCost(value * price)
+		}
+
+		result = append(result, total)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Less(&result[j].UsageTotal)
+	})
+
+	return result
+}
+
+// usagePriceKey returns the key of the price of the usage in the rate card.
+func usagePriceKey(usage common.UsageTotal) common.UsageTotal {
+	return common.UsageTotal{
+		MeterGroup: usage.MeterGroup,
+		MeterKind:  usage.MeterKind,
+		Metric:     usage.Metric,
+	}
+}
+
+// formatCost rounds the cost to 4 decimals.
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 4, 64)
+}
+
+func (r *UsageSummaryReconciler) Inject(injector *inject.Injector) inject.SetupWithManager {
+	injector.SetCustomFields(r)
+	return r
+}
+
+func (r *UsageSummaryReconciler) InjectOperatorConfig(cfg *config.OperatorConfig) error {
+	r.cfg = cfg
+	return nil
+}
+
+func (r *UsageSummaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// a report with new usage updates every summary
+	mapFn := handler.ToRequestsFunc(
+		func(a handler.MapObject) []reconcile.Request {
+			summaries := &marketplacev1alpha1.UsageSummaryList{}
+			if err := r.Client.List(context.TODO(), summaries); err != nil {
+				r.Log.Error(err, "failed to list usagesummaries")
+				return nil
+			}
+
+			requests := make([]reconcile.Request, 0, len(summaries.Items))
+			for _, summary := range summaries.Items {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      summary.Name,
+						Namespace: summary.Namespace,
+					},
+				})
+			}
+
+			return requests
+		})
+
+	reportPreds := predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return evt.Meta.GetNamespace() == r.cfg.DeployedNamespace
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			oldReport, ok := evt.ObjectOld.(*marketplacev1alpha1.MeterReport)
+			if !ok {
+				return false
+			}
+			newReport, ok := evt.ObjectNew.(*marketplacev1alpha1.MeterReport)
+			if !ok {
+				return false
+			}
+
+			return newReport.Namespace == r.cfg.DeployedNamespace &&
+				(reportHasUsage(oldReport) != reportHasUsage(newReport) ||
+					!equality.Semantic.DeepEqual(oldReport.Status.Usage, newReport.Status.Usage))
+		},
+		DeleteFunc: func(evt event.DeleteEvent) bool {
+			return evt.Meta.GetNamespace() == r.cfg.DeployedNamespace
+		},
+		GenericFunc: func(evt event.GenericEvent) bool {
+			return false
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&marketplacev1alpha1.UsageSummary{}).
+		Watches(
+			&source.Kind{Type: &marketplacev1alpha1.MeterReport{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: mapFn,
+			},
+			builder.WithPredicates(reportPreds)).
+		Complete(r)
+}
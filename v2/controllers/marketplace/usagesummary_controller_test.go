@@ -0,0 +1,204 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"time"
+
+	"github.com/gotidy/ptr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("UsageSummaryController", func() {
+	var reports []marketplacev1alpha1.MeterReport
+
+	usage := func(metric, namespace, resourceName, value string) common.UsageTotal {
+		return common.UsageTotal{
+			MeterGroup:   "apps.partner.metering.com",
+			MeterKind:    "App",
+			Metric:       metric,
+			Namespace:    namespace,
+			ResourceName: resourceName,
+			Value:        value,
+		}
+	}
+
+	total := func(metric, namespace, resourceName, value, cost string) marketplacev1alpha1.UsageTotal {
+		return marketplacev1alpha1.UsageTotal{
+			UsageTotal: usage(metric, namespace, resourceName, value),
+			Cost:       cost,
+		}
+	}
+
+	report := func(name string, day int, condition status.Condition, totals ...common.UsageTotal) marketplacev1alpha1.MeterReport {
+		start := time.Date(2021, time.March, day, 0, 0, 0, 0, time.UTC)
+
+		return marketplacev1alpha1.MeterReport{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: marketplacev1alpha1.MeterReportSpec{
+				StartTime: metav1.NewTime(start),
+				EndTime:   metav1.NewTime(start.Add(24 * time.Hour)),
+			},
+			Status: marketplacev1alpha1.MeterReportStatus{
+				Conditions:        status.NewConditions(condition),
+				MetricUploadCount: ptr.Int(len(totals)),
+				Usage:             totals,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		reports = []marketplacev1alpha1.MeterReport{
+			report("day-1", 1, marketplacev1alpha1.ReportConditionJobFinished,
+				usage("rpc_count", "foo", "pod-a", "1"),
+			),
+			report("day-3", 3, marketplacev1alpha1.ReportConditionJobFinished,
+				usage("rpc_count", "bar", "pod-c", "4"),
+				usage("rpc_count", "foo", "pod-a", "1.5"),
+				usage("rpc_count", "foo", "pod-b", "2"),
+				usage("rpc_sum", "foo", "", "10"),
+			),
+			report("day-2", 2, marketplacev1alpha1.ReportConditionJobFinished,
+				usage("rpc_count", "foo", "pod-a", "2"),
+			),
+			report("day-4", 4, marketplacev1alpha1.ReportConditionJobSubmitted),
+		}
+	})
+
+	It("should summarize the latest completed reports", func() {
+		periods := usagePeriods(reports, "", 2, nil)
+		Expect(periods).To(HaveLen(2))
+		Expect(periods[0].Report).To(Equal("day-3"))
+		Expect(periods[1].Report).To(Equal("day-2"))
+
+		Expect(periods[0].MeterDefinitions).To(Equal([]marketplacev1alpha1.UsageTotal{
+			total("rpc_count", "", "", "7.5", ""),
+			total("rpc_sum", "", "", "10", ""),
+		}))
+		Expect(periods[0].Namespaces).To(Equal([]marketplacev1alpha1.UsageTotal{
+			total("rpc_count", "bar", "", "4", ""),
+			total("rpc_count", "foo", "", "3.5", ""),
+			total("rpc_sum", "foo", "", "10", ""),
+		}))
+		Expect(periods[0].Resources).To(Equal([]marketplacev1alpha1.UsageTotal{
+			total("rpc_count", "bar", "pod-c", "4", ""),
+			total("rpc_count", "foo", "pod-a", "1.5", ""),
+			total("rpc_count", "foo", "pod-b", "2", ""),
+		}))
+		Expect(periods[0].Cost).To(BeEmpty())
+	})
+
+	It("should only summarize the usage of the namespace", func() {
+		periods := usagePeriods(reports, "bar", 7, nil)
+		Expect(periods).To(HaveLen(3))
+		Expect(periods[0].Namespaces).To(Equal([]marketplacev1alpha1.UsageTotal{
+			total("rpc_count", "bar", "", "4", ""),
+		}))
+		Expect(periods[1].Namespaces).To(BeEmpty())
+	})
+
+	It("should price the usage with the rate card", func() {
+		rateCard := &marketplacev1alpha1.RateCard{
+			Currency: "USD",
+			Rates: []marketplacev1alpha1.Rate{
+				{
+					MeterGroup: "apps.partner.metering.com",
+					MeterKind:  "App",
+					Metric:     "rpc_count",
+					Price:      "0.5",
+				},
+			},
+		}
+
+		prices, invalid := ratePrices(rateCard)
+		Expect(invalid).To(BeEmpty())
+
+		periods := usagePeriods(reports, "foo", 1, prices)
+		Expect(periods).To(HaveLen(1))
+		Expect(periods[0].MeterDefinitions).To(Equal([]marketplacev1alpha1.UsageTotal{
+			total("rpc_count", "", "", "3.5", "1.7500"),
+			total("rpc_sum", "", "", "10", ""),
+		}))
+		Expect(periods[0].Cost).To(Equal("1.7500"))
+	})
+
+	It("should round the period cost once", func() {
+		rate := func(metric string) marketplacev1alpha1.Rate {
+			return marketplacev1alpha1.Rate{
+				MeterGroup: "apps.partner.metering.com",
+				MeterKind:  "App",
+				Metric:     metric,
+				Price:      "0.00004",
+			}
+		}
+		rateCard := &marketplacev1alpha1.RateCard{
+			Currency: "USD",
+			Rates:    []marketplacev1alpha1.Rate{rate("rpc_count"), rate("rpc_sum")},
+		}
+
+		prices, _ := ratePrices(rateCard)
+		periods := usagePeriods([]marketplacev1alpha1.MeterReport{
+			report("day-1", 1, marketplacev1alpha1.ReportConditionJobFinished,
+				usage("rpc_count", "foo", "", "1"),
+				usage("rpc_sum", "foo", "", "1"),
+			),
+		}, "", 1, prices)
+
+		Expect(periods[0].MeterDefinitions).To(Equal([]marketplacev1alpha1.UsageTotal{
+			total("rpc_count", "", "", "1", "0.0000"),
+			total("rpc_sum", "", "", "1", "0.0000"),
+		}))
+		Expect(periods[0].Cost).To(Equal("0.0001"))
+	})
+
+	It("should report the rates with invalid prices", func() {
+		prices, invalid := ratePrices(&marketplacev1alpha1.RateCard{
+			Rates: []marketplacev1alpha1.Rate{
+				{MeterGroup: "apps.partner.metering.com", MeterKind: "App", Metric: "rpc_count", Price: "0.5"},
+				{MeterGroup: "apps.partner.metering.com", MeterKind: "App", Metric: "rpc_sum", Price: "1e400"},
+			},
+		})
+
+		Expect(prices).To(HaveLen(1))
+		Expect(invalid).To(Equal([]string{`apps.partner.metering.com/App rpc_sum "1e400"`}))
+
+		prices, invalid = ratePrices(nil)
+		Expect(prices).To(BeNil())
+		Expect(invalid).To(BeEmpty())
+	})
+
+	It("should mark the periods of reports with truncated usage", func() {
+		reports[1].Status.UsageTruncated = true
+
+		periods := usagePeriods(reports, "", 2, nil)
+		Expect(periods[0].ResourcesTruncated).To(BeTrue())
+		Expect(periods[1].ResourcesTruncated).To(BeFalse())
+	})
+
+	It("should skip the reports without recorded usage", func() {
+		reports[0].Status.Usage = nil
+		Expect(reportHasUsage(&reports[0])).To(BeFalse())
+
+		reports[0].Status.MetricUploadCount = ptr.Int(0)
+		Expect(reportHasUsage(&reports[0])).To(BeTrue())
+
+		Expect(reportHasUsage(&reports[3])).To(BeFalse())
+	})
+})
@@ -6,6 +6,7 @@ require (
 	emperror.dev/errors v0.8.0
 	github.com/Masterminds/semver/v3 v3.1.1
 	github.com/Shyp/bump_version v0.0.0-20180222180749-d7594d2951e2
+	github.com/aws/aws-sdk-go v1.35.5
 	github.com/banzaicloud/k8s-objectmatcher v1.5.0
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/caarlos0/env/v6 v6.4.0
@@ -14,6 +15,7 @@ require (
 	github.com/go-logr/zapr v0.3.0 // indirect
 	github.com/golang/mock v1.4.4
 	github.com/golangci/golangci-lint v1.33.0
+	github.com/google/gofuzz v1.1.0
 	github.com/google/uuid v1.1.2
 	github.com/google/wire v0.4.0
 	github.com/goph/emperror v0.17.2
@@ -33,13 +35,14 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus-operator/prometheus-operator v0.44.0
 	github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring v0.44.0
+	github.com/prometheus/prometheus v1.8.2-0.20201015110737-0a7fdd3b7696
 	github.com/spf13/cast v1.3.1 // indirect
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.6.1
 	github.com/tcnksm/ghr v0.13.0
-	golang.org/x/net v0.0.0-20201202161906-c7110b5ffcbb // indirect
+	golang.org/x/net v0.0.0-20201202161906-c7110b5ffcbb
 	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.19.4
@@ -17,12 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
-	"path/filepath"
 
+	"github.com/caarlos0/env/v6"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -38,6 +40,8 @@ import (
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
 	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
 	controllers "github.com/redhat-marketplace/redhat-marketplace-operator/v2/controllers/marketplace"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/certificates"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/inject"
 	// +kubebuilder:scaffold:imports
 )
@@ -48,10 +52,7 @@ var (
 )
 
 const (
-	WebhookCertDir  = "/apiserver.local.config/certificates"
-	WebhookCertName = "apiserver.crt"
-	WebhookKeyName  = "apiserver.key"
-	WebhookPort     = 9443
+	WebhookPort = 9443
 )
 
 func init() {
@@ -95,15 +96,26 @@ func main() {
 		os.Exit(1)
 	}
 
-	openshiftPath := filepath.Join(WebhookCertDir, WebhookKeyName)
-	ctrl.Log.Info("looking up path", "path", openshiftPath)
-	if _, err := os.Stat(openshiftPath); !os.IsNotExist(err) {
-		ctrl.Log.Info("path exists using openshift path", "path", WebhookCertDir)
-		server := mgr.GetWebhookServer()
-		server.CertDir = WebhookCertDir
-		server.KeyName = WebhookKeyName
-		server.CertName = WebhookCertName
-		server.Port = WebhookPort
+	server := mgr.GetWebhookServer()
+	server.Port = WebhookPort
+
+	if certificates.IsOLMManaged() {
+		ctrl.Log.Info("path exists using openshift path", "path", certificates.OLMCertDir)
+		server.CertDir = certificates.OLMCertDir
+		server.KeyName = certificates.OLMKeyName
+		server.CertName = certificates.OLMCertName
+	} else {
+		// the webhook server needs its certificate before the manager starts,
+		// the runnables rotate it and inject its CA afterwards
+		ctrl.Log.Info("using managed webhook certificates", "path", certificates.ManagedCertDir)
+		if err := syncWebhookCertificates(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook certificates")
+			os.Exit(1)
+		}
+
+		server.CertDir = certificates.ManagedCertDir
+		server.KeyName = certificates.KeyName
+		server.CertName = certificates.CertName
 	}
 
 	injector, err := inject.ProvideInjector(mgr)
@@ -125,7 +137,7 @@ func main() {
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("ClusterServiceVersion"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterServiceVersion")
 		os.Exit(1)
 	}
@@ -197,7 +209,7 @@ func main() {
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("RemoteResourceS3"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RemoteResourceS3")
 		os.Exit(1)
 	}
@@ -211,6 +223,60 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.ClusterProxyReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ClusterProxy"),
+		Scheme: mgr.GetScheme(),
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterProxy")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.FleetReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("Fleet"),
+		Scheme: mgr.GetScheme(),
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Fleet")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ManagedClusterStatusReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ManagedClusterStatus"),
+		Scheme: mgr.GetScheme(),
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ManagedClusterStatus")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ClusterInventoryReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ClusterInventory"),
+		Scheme: mgr.GetScheme(),
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterInventory")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.ProductInstallReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ProductInstall"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProductInstall")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.UsageSummaryReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("UsageSummary"),
+		Scheme: mgr.GetScheme(),
+	}).Inject(injector).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "UsageSummary")
+		os.Exit(1)
+	}
+
 	if err = (&marketplacev1beta1.MeterDefinition{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "MeterDefinition")
 		os.Exit(1)
@@ -232,3 +298,22 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func syncWebhookCertificates(mgr ctrl.Manager) error {
+	// parsed apart from config.ProvideConfig, which caches the config before
+	// the infrastructure is known
+	cfg := config.OperatorConfig{}
+	if err := env.Parse(&cfg); err != nil {
+		return err
+	}
+
+	client, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	_, err = certificates.NewWebhookCertificates(
+		ctrl.Log, client, cfg.DeployedNamespace, certificates.WebhookServiceName, certificates.ManagedCertDir,
+	).Sync(context.Background())
+	return err
+}
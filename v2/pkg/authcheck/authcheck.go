@@ -0,0 +1,201 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authcheck holds the permission manifest checked by the authchecker
+// sidecar and the status it serves.
+package authcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// ContainerName is the name of the authchecker sidecar
+	ContainerName = "authcheck"
+
+	// StatusPort is the port the authchecker serves its status on
+	StatusPort = 8089
+
+	// StatusPath is the path of the permission status
+	StatusPath = "/status"
+)
+
+// PermissionManifest lists the permissions a workload needs.
+type PermissionManifest struct {
+	Permissions []PermissionRule `json:"permissions"`
+}
+
+// PermissionRule is a set of verbs on resources of an API group in a set of
+// namespaces. No namespaces means the cluster scope.
+type PermissionRule struct {
+	Group      string   `json:"group,omitempty"`
+	Resources  []string `json:"resources"`
+	Verbs      []string `json:"verbs"`
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// Permission is a single verb on a resource.
+type Permission struct {
+	Namespace string `json:"namespace,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Resource  string `json:"resource"`
+	// Subresource is the part of the resource after the slash, nodes/metrics is
+	// the metrics subresource of nodes.
+	Subresource string `json:"subresource,omitempty"`
+	Verb        string `json:"verb"`
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Subresource != "" {
+		resource = resource + "/" + p.Subresource
+	}
+
+	if p.Group != "" {
+		resource = resource + "." + p.Group
+	}
+
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+
+	return fmt.Sprintf("%s %s in %s", p.Verb, resource, p.Namespace)
+}
+
+// PermissionResult is the access review of a permission.
+type PermissionResult struct {
+	Permission `json:",inline"`
+	Allowed    bool   `json:"allowed"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Status is the result of the last permission self-test.
+type Status struct {
+	CheckTime metav1.Time        `json:"checkTime"`
+	Results   []PermissionResult `json:"results"`
+}
+
+// Missing returns the permissions that are not allowed.
+func (s *Status) Missing() []PermissionResult {
+	missing := []PermissionResult{}
+	for _, result := range s.Results {
+		if !result.Allowed {
+			missing = append(missing, result)
+		}
+	}
+	return missing
+}
+
+// ParseManifest reads a yaml or json permission manifest.
+func ParseManifest(r io.Reader) (*PermissionManifest, error) {
+	manifest := &PermissionManifest{}
+	if err := k8syaml.NewYAMLOrJSONDecoder(r, 4096).Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the permission manifest")
+	}
+	return manifest, nil
+}
+
+// String returns the manifest as json, the format of the --permissions flag.
+func (m *PermissionManifest) String() string {
+	data, _ := json.Marshal(m)
+	return string(data)
+}
+
+// Checks expands the manifest into one permission per verb, resource and namespace.
+func (m *PermissionManifest) Checks() []Permission {
+	permissions := []Permission{}
+
+	for _, rule := range m.Permissions {
+		namespaces := rule.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{""}
+		}
+
+		for _, namespace := range namespaces {
+			for _, resource := range rule.Resources {
+				parts := strings.SplitN(resource, "/", 2)
+
+				for _, verb := range rule.Verbs {
+					permission := Permission{
+						Namespace: namespace,
+						Group:     rule.Group,
+						Resource:  parts[0],
+						Verb:      verb,
+					}
+
+					if len(parts) == 2 {
+						permission.Subresource = parts[1]
+					}
+
+					permissions = append(permissions, permission)
+				}
+			}
+		}
+	}
+
+	return permissions
+}
+
+// HasAuthCheck returns true if the pod runs the authchecker sidecar.
+func HasAuthCheck(pod *corev1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == ContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStatus reads the permission status from the authchecker of the pod.
+func GetStatus(ctx context.Context, client *http.Client, pod *corev1.Pod) (*Status, error) {
+	if pod.Status.PodIP == "" {
+		return nil, errors.Errorf("pod %s has no ip", pod.Name)
+	}
+
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(StatusPort)), StatusPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the permission status of %s", pod.Name)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("permission status of %s returned %s", pod.Name, resp.Status)
+	}
+
+	status := &Status{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the permission status of %s", pod.Name)
+	}
+
+	return status, nil
+}
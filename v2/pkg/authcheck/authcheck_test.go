@@ -0,0 +1,68 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authcheck
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PermissionManifest", func() {
+	It("should parse a yaml manifest", func() {
+		manifest, err := ParseManifest(strings.NewReader(`
+permissions:
+- resources: [pods, nodes/metrics]
+  verbs: [get, list]
+- group: apps
+  resources: [statefulsets]
+  verbs: [update]
+  namespaces: [openshift-redhat-marketplace, default]
+`))
+		Expect(err).To(Succeed())
+
+		permissions := manifest.Checks()
+		Expect(permissions).To(HaveLen(6))
+		Expect(permissions[0]).To(Equal(Permission{Resource: "pods", Verb: "get"}))
+		Expect(permissions[3]).To(Equal(Permission{Resource: "nodes", Subresource: "metrics", Verb: "list"}))
+		Expect(permissions[5]).To(Equal(Permission{Namespace: "default", Group: "apps", Resource: "statefulsets", Verb: "update"}))
+		Expect(permissions[3].String()).To(Equal("list nodes/metrics"))
+		Expect(permissions[5].String()).To(Equal("update statefulsets.apps in default"))
+	})
+
+	It("should round trip through the permissions flag", func() {
+		manifest := &PermissionManifest{
+			Permissions: []PermissionRule{
+				{Resources: []string{"pods"}, Verbs: []string{"get"}, Namespaces: []string{"ns"}},
+			},
+		}
+
+		parsed, err := ParseManifest(strings.NewReader(manifest.String()))
+		Expect(err).To(Succeed())
+		Expect(parsed).To(Equal(manifest))
+	})
+
+	It("should list the missing permissions", func() {
+		status := &Status{
+			Results: []PermissionResult{
+				{Permission: Permission{Resource: "pods", Verb: "get"}, Allowed: true},
+				{Permission: Permission{Resource: "pods", Verb: "delete"}, Reason: "forbidden"},
+			},
+		}
+
+		Expect(status.Missing()).To(Equal([]PermissionResult{status.Results[1]}))
+	})
+})
@@ -0,0 +1,235 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package certificates generates and rotates the serving certificates of the
+// operator webhooks when they are not provided by OLM.
+package certificates
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+const (
+	// OLMCertDir is where OLM mounts the webhook certificates.
+	OLMCertDir = "/apiserver.local.config/certificates"
+	// OLMCertName is the certificate file OLM mounts.
+	OLMCertName = "apiserver.crt"
+	// OLMKeyName is the key file OLM mounts.
+	OLMKeyName = "apiserver.key"
+
+	// ManagedCertDir is where the operator writes the certificates it manages.
+	ManagedCertDir = "/tmp/k8s-webhook-server/managed-certs"
+	// CertName is the certificate file the operator writes.
+	CertName = "tls.crt"
+	// KeyName is the key file the operator writes.
+	KeyName = "tls.key"
+
+	// CAValidity is how long a generated CA is valid.
+	CAValidity = 5 * 365 * 24 * time.Hour
+	// CARenewBefore is how long before it expires the CA is replaced. The
+	// replaced CA stays in the bundle until it expires.
+	CARenewBefore = 180 * 24 * time.Hour
+	// ServingValidity is how long a generated serving certificate is valid.
+	ServingValidity = 365 * 24 * time.Hour
+	// ServingRenewBefore is how long before it expires the serving
+	// certificate is replaced.
+	ServingRenewBefore = 30 * 24 * time.Hour
+)
+
+// IsOLMManaged returns true when OLM mounted the webhook certificates.
+func IsOLMManaged() bool {
+	_, err := os.Stat(filepath.Join(OLMCertDir, OLMKeyName))
+	return !os.IsNotExist(err)
+}
+
+// KeyPair is a PEM encoded certificate and key.
+type KeyPair struct {
+	Cert []byte
+	Key  []byte
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodeKeyPair(der []byte, key *ecdsa.PrivateKey) (*KeyPair, error) {
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}, nil
+}
+
+// GenerateCA returns a self-signed CA valid from now.
+func GenerateCA(commonName string, now time.Time) (*KeyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(CAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the ca certificate")
+	}
+
+	return encodeKeyPair(der, key)
+}
+
+// GenerateServingCert returns a serving certificate for the DNS names signed
+// by the CA and valid from now.
+func GenerateServingCert(ca *KeyPair, dnsNames []string, now time.Time) (*KeyPair, error) {
+	caCert, err := ParseCertificate(ca.Cert)
+	if err != nil {
+		return nil, err
+	}
+
+	caKeyBlock, _ := pem.Decode(ca.Key)
+	if caKeyBlock == nil {
+		return nil, errors.New("failed to decode the ca key")
+	}
+
+	caKey, err := x509.ParseECPrivateKey(caKeyBlock.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the ca key")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	notAfter := now.Add(ServingValidity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the serving certificate")
+	}
+
+	return encodeKeyPair(der, key)
+}
+
+// ParseCertificate parses the first certificate of the PEM data.
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode the certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// NeedsRenewal returns true if the certificate can't be parsed or expires
+// within renewBefore of now.
+func NeedsRenewal(data []byte, now time.Time, renewBefore time.Duration) bool {
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return true
+	}
+
+	return now.Add(renewBefore).After(cert.NotAfter)
+}
+
+// Bundle concatenates the certificates that have not expired at now.
+func Bundle(now time.Time, certs ...[]byte) []byte {
+	var bundle bytes.Buffer
+
+	for _, data := range certs {
+		for len(data) != 0 {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil || now.After(cert.NotAfter) {
+				continue
+			}
+
+			_ = pem.Encode(&bundle, block)
+		}
+	}
+
+	return bundle.Bytes()
+}
+
+// Verify returns an error if the serving certificate is not valid for the
+// DNS name under a CA of the bundle.
+func Verify(bundle, cert []byte, dnsName string, now time.Time) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return errors.New("ca bundle has no certificates")
+	}
+
+	serving, err := ParseCertificate(cert)
+	if err != nil {
+		return err
+	}
+
+	_, err = serving.Verify(x509.VerifyOptions{
+		DNSName:     dnsName,
+		Roots:       pool,
+		CurrentTime: now,
+	})
+	return err
+}
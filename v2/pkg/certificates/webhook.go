@@ -0,0 +1,222 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// WebhookServiceName is the service of the operator webhooks.
+	WebhookServiceName = "redhat-marketplace-controller-manager-service"
+
+	// WebhookSecretName is the secret holding the managed webhook certificates.
+	WebhookSecretName = "redhat-marketplace-webhook-server-cert"
+
+	// Keys of the webhook secret
+	SecretCACertKey         = "ca.crt"
+	SecretCAKeyKey          = "ca.key"
+	SecretPreviousCACertKey = "ca-previous.crt"
+
+	caCommonName = "redhat-marketplace-webhook-ca"
+)
+
+// WebhookCertificates keeps the CA and serving certificate of the webhook
+// service in a secret and writes the serving certificate for the webhook
+// server. Every replica of the operator can sync, the secret updates use
+// optimistic concurrency.
+type WebhookCertificates struct {
+	logger      logr.Logger
+	client      kubernetes.Interface
+	namespace   string
+	serviceName string
+	certDir     string
+}
+
+func NewWebhookCertificates(
+	logger logr.Logger,
+	client kubernetes.Interface,
+	namespace, serviceName, certDir string,
+) *WebhookCertificates {
+	return &WebhookCertificates{
+		logger:      logger.WithName("webhookCertificates"),
+		client:      client,
+		namespace:   namespace,
+		serviceName: serviceName,
+		certDir:     certDir,
+	}
+}
+
+// DNSNames are the names of the webhook service.
+func (w *WebhookCertificates) DNSNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", w.serviceName, w.namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", w.serviceName, w.namespace),
+		fmt.Sprintf("%s.%s", w.serviceName, w.namespace),
+		w.serviceName,
+	}
+}
+
+// Sync creates or rotates the certificates in the secret, writes the serving
+// certificate to the cert dir and returns the CA bundle.
+func (w *WebhookCertificates) Sync(ctx context.Context) ([]byte, error) {
+	secrets := w.client.CoreV1().Secrets(w.namespace)
+	var data map[string][]byte
+
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return kerrors.IsConflict(err) || kerrors.IsAlreadyExists(err)
+	}, func() error {
+		secret, err := secrets.Get(ctx, WebhookSecretName, metav1.GetOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+
+		create := kerrors.IsNotFound(err)
+		if create {
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      WebhookSecretName,
+					Namespace: w.namespace,
+				},
+				Type: corev1.SecretTypeTLS,
+			}
+		}
+
+		var changed bool
+		data, changed, err = RotateWebhookSecretData(secret.Data, w.DNSNames(), time.Now())
+		if err != nil {
+			return err
+		}
+
+		if !changed {
+			return nil
+		}
+
+		secret.Data = data
+
+		if create {
+			w.logger.Info("creating webhook certificates", "secret", WebhookSecretName)
+			_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+			return err
+		}
+
+		w.logger.Info("rotating webhook certificates", "secret", WebhookSecretName)
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.writeCertDir(data); err != nil {
+		return nil, err
+	}
+
+	return Bundle(time.Now(), data[SecretCACertKey], data[SecretPreviousCACertKey]), nil
+}
+
+// writeCertDir writes the serving certificate files that changed. The webhook
+// server watches the files and reloads them.
+func (w *WebhookCertificates) writeCertDir(data map[string][]byte) error {
+	if err := os.MkdirAll(w.certDir, 0700); err != nil {
+		return err
+	}
+
+	for name, key := range map[string]string{
+		CertName: corev1.TLSCertKey,
+		KeyName:  corev1.TLSPrivateKeyKey,
+	} {
+		path := filepath.Join(w.certDir, name)
+
+		if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, data[key]) {
+			continue
+		}
+
+		tmp := path + ".tmp"
+		if err := ioutil.WriteFile(tmp, data[key], 0600); err != nil {
+			return err
+		}
+
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RotateWebhookSecretData returns the secret data with a CA and a serving
+// certificate for the DNS names that are valid past their renewal windows.
+// A replaced CA is kept as the previous CA so the webhook clients trust both
+// until the new bundle reaches them.
+func RotateWebhookSecretData(
+	current map[string][]byte,
+	dnsNames []string,
+	now time.Time,
+) (map[string][]byte, bool, error) {
+	data := map[string][]byte{}
+	for key, value := range current {
+		data[key] = value
+	}
+
+	changed := false
+
+	if len(data[SecretCAKeyKey]) == 0 || NeedsRenewal(data[SecretCACertKey], now, CARenewBefore) {
+		ca, err := GenerateCA(caCommonName, now)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if len(data[SecretCACertKey]) != 0 {
+			data[SecretPreviousCACertKey] = Bundle(now, data[SecretCACertKey])
+		}
+
+		data[SecretCACertKey] = ca.Cert
+		data[SecretCAKeyKey] = ca.Key
+		changed = true
+	}
+
+	if changed ||
+		NeedsRenewal(data[corev1.TLSCertKey], now, ServingRenewBefore) ||
+		Verify(data[SecretCACertKey], data[corev1.TLSCertKey], dnsNames[0], now) != nil {
+		serving, err := GenerateServingCert(&KeyPair{
+			Cert: data[SecretCACertKey],
+			Key:  data[SecretCAKeyKey],
+		}, dnsNames, now)
+		if err != nil {
+			return nil, false, err
+		}
+
+		data[corev1.TLSCertKey] = serving.Cert
+		data[corev1.TLSPrivateKeyKey] = serving.Key
+		changed = true
+	}
+
+	return data, changed, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificates
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("WebhookCertificates", func() {
+	dnsNames := []string{"webhook.openshift-redhat-marketplace.svc"}
+	now := time.Now()
+
+	It("should generate a serving certificate trusted by the ca", func() {
+		data, changed, err := RotateWebhookSecretData(nil, dnsNames, now)
+		Expect(err).To(Succeed())
+		Expect(changed).To(BeTrue())
+		Expect(Verify(data[SecretCACertKey], data[corev1.TLSCertKey], dnsNames[0], now)).To(Succeed())
+		Expect(Verify(data[SecretCACertKey], data[corev1.TLSCertKey], "other.svc", now)).ToNot(Succeed())
+
+		_, changed, err = RotateWebhookSecretData(data, dnsNames, now)
+		Expect(err).To(Succeed())
+		Expect(changed).To(BeFalse())
+	})
+
+	It("should renew the serving certificate before it expires", func() {
+		data, _, err := RotateWebhookSecretData(nil, dnsNames, now)
+		Expect(err).To(Succeed())
+
+		later := now.Add(ServingValidity - ServingRenewBefore + time.Hour)
+		rotated, changed, err := RotateWebhookSecretData(data, dnsNames, later)
+		Expect(err).To(Succeed())
+		Expect(changed).To(BeTrue())
+		Expect(rotated[SecretCACertKey]).To(Equal(data[SecretCACertKey]))
+		Expect(rotated[corev1.TLSCertKey]).ToNot(Equal(data[corev1.TLSCertKey]))
+		Expect(Verify(rotated[SecretCACertKey], rotated[corev1.TLSCertKey], dnsNames[0], later)).To(Succeed())
+	})
+
+	It("should keep trusting the replaced ca until it expires", func() {
+		data, _, err := RotateWebhookSecretData(nil, dnsNames, now)
+		Expect(err).To(Succeed())
+
+		later := now.Add(CAValidity - CARenewBefore + time.Hour)
+		rotated, changed, err := RotateWebhookSecretData(data, dnsNames, later)
+		Expect(err).To(Succeed())
+		Expect(changed).To(BeTrue())
+		Expect(rotated[SecretCACertKey]).ToNot(Equal(data[SecretCACertKey]))
+		Expect(rotated[SecretPreviousCACertKey]).To(Equal(data[SecretCACertKey]))
+
+		bundle := Bundle(later, rotated[SecretCACertKey], rotated[SecretPreviousCACertKey])
+		Expect(Verify(bundle, rotated[corev1.TLSCertKey], dnsNames[0], later)).To(Succeed())
+		Expect(Verify(bundle, data[corev1.TLSCertKey], dnsNames[0], now)).To(Succeed())
+
+		Expect(Bundle(now.Add(CAValidity+time.Hour), rotated[SecretPreviousCACertKey])).To(BeEmpty())
+	})
+
+	It("should store the certificates and write the serving files", func() {
+		dir, err := ioutil.TempDir("", "certs")
+		Expect(err).To(Succeed())
+		defer os.RemoveAll(dir)
+
+		client := fake.NewSimpleClientset()
+		certs := NewWebhookCertificates(logf.Log, client, "openshift-redhat-marketplace", "webhook", filepath.Join(dir, "certs"))
+
+		bundle, err := certs.Sync(context.TODO())
+		Expect(err).To(Succeed())
+
+		secret, err := client.CoreV1().Secrets("openshift-redhat-marketplace").Get(context.TODO(), WebhookSecretName, metav1.GetOptions{})
+		Expect(err).To(Succeed())
+		Expect(bundle).To(Equal(secret.Data[SecretCACertKey]))
+
+		cert, err := ioutil.ReadFile(filepath.Join(dir, "certs", CertName))
+		Expect(err).To(Succeed())
+		Expect(cert).To(Equal(secret.Data[corev1.TLSCertKey]))
+		Expect(Verify(bundle, cert, "webhook.openshift-redhat-marketplace.svc", now)).To(Succeed())
+
+		again, err := certs.Sync(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(again).To(Equal(bundle))
+	})
+})
@@ -34,6 +34,9 @@ type OperatorConfig struct {
 	DeployedNamespace string `env:"POD_NAMESPACE"`
 	DeployedPodName   string `env:"POD_NAME"`
 	ReportController  ReportControllerConfig
+	Fleet             FleetConfig
+	RemoteResourceS3  RemoteResourceS3Config
+	Metering          MeteringConfig
 	RelatedImages
 	Features
 	Marketplace
@@ -65,12 +68,70 @@ type Features struct {
 type Marketplace struct {
 	URL            string `env:"MARKETPLACE_URL" envDefault:"https://marketplace.redhat.com"`
 	InsecureClient bool   `env:"MARKETPLACE_HTTP_INSECURE_MODE" envDefault:"false"`
+
+	// UploadURL is where the reporter jobs upload the reports to.
+	UploadURL string `env:"MARKETPLACE_UPLOAD_URL" envDefault:"https://cloud.redhat.com"`
+
+	// PullSecretExpiryWarning is how long before the pull secret token expires it is reported as expiring.
+	PullSecretExpiryWarning time.Duration `env:"MARKETPLACE_PULL_SECRET_EXPIRY_WARNING" envDefault:"168h"`
+	// OperatorSecretRefreshInterval is how often the rhm-operator-secret is refreshed from the marketplace.
+	OperatorSecretRefreshInterval time.Duration `env:"MARKETPLACE_OPERATOR_SECRET_REFRESH_INTERVAL" envDefault:"24h"`
 }
 
 // ReportConfig stores some changeable information for creating a report
 type ReportControllerConfig struct {
 	RetryTime  time.Duration `env:"REPORT_RETRY_TIME_DURATION" envDefault:"6h"`
 	RetryLimit *int32        `env:"REPORT_RETRY_LIMIT"`
+
+	// MaxConcurrentJobs is the number of reporter jobs allowed to run at once
+	// across the cluster. A value of 0 or less removes the limit.
+	MaxConcurrentJobs int `env:"REPORT_MAX_CONCURRENT_JOBS" envDefault:"2"`
+}
+
+// FleetConfig stores the configuration of the fleet view
+type FleetConfig struct {
+	// Hub runs the hub controller that evaluates the ManagedClusterStatus of managed clusters.
+	Hub bool `env:"FLEET_HUB" envDefault:"false"`
+	// StatusInterval is how often a managed cluster pushes its summary to the hub.
+	StatusInterval time.Duration `env:"FLEET_STATUS_INTERVAL" envDefault:"15m"`
+	// HeartbeatTimeout is how long the hub waits for a summary before the cluster is not reporting.
+	HeartbeatTimeout time.Duration `env:"FLEET_HEARTBEAT_TIMEOUT" envDefault:"1h"`
+	// ReportTimeout is how old the last successful report can be before the cluster is not reporting.
+	ReportTimeout time.Duration `env:"FLEET_REPORT_TIMEOUT" envDefault:"48h"`
+}
+
+// RemoteResourceS3Config stores the configuration of the RemoteResourceS3 sync
+type RemoteResourceS3Config struct {
+	// Native syncs RemoteResourceS3 in the operator instead of the razee
+	// remoteresources3 deployment. The parent RemoteResourceS3 and the COS
+	// reader key are still created, the native sync reads them.
+	Native bool `env:"REMOTE_RESOURCE_S3_NATIVE" envDefault:"false"`
+	// SyncInterval is how often the remote resources are fetched and applied.
+	SyncInterval time.Duration `env:"REMOTE_RESOURCE_S3_SYNC_INTERVAL" envDefault:"15m"`
+	// PublicKeySecret is the secret in the operator namespace with the public key
+	// that verifies a RemoteResourceS3 without a verification.
+	PublicKeySecret string `env:"REMOTE_RESOURCE_S3_PUBLIC_KEY_SECRET" envDefault:"rhm-remoteresources3-public-key"`
+	// PublicKeySecretKey is the key of the public key in the secret.
+	PublicKeySecretKey string `env:"REMOTE_RESOURCE_S3_PUBLIC_KEY_SECRET_KEY" envDefault:"public.pem"`
+	// AllowedKinds are the kinds applied from the remote resources, as Kind.group.
+	AllowedKinds []string `env:"REMOTE_RESOURCE_S3_ALLOWED_KINDS" envSeparator:"," envDefault:"ConfigMap,Secret,RemoteResourceS3.marketplace.redhat.com,MeterDefinition.marketplace.redhat.com"`
+	// AllowedNamespaces are the namespaces objects are applied to besides the
+	// namespace of their RemoteResourceS3.
+	AllowedNamespaces []string `env:"REMOTE_RESOURCE_S3_ALLOWED_NAMESPACES" envSeparator:","`
+}
+
+// MeteringConfig stores the configuration of the metering Prometheus checks
+type MeteringConfig struct {
+	// PrometheusTokenFile is a service account token with the audience of the
+	// metering Prometheus service. The series checks are skipped without it.
+	PrometheusTokenFile string `env:"METERING_PROMETHEUS_TOKEN_FILE" envDefault:"/etc/auth-service-account/token"`
+	// SeriesBudgetWarning is the share of the series budget at which meter definitions get a warning.
+	SeriesBudgetWarning float64 `env:"METERING_SERIES_BUDGET_WARNING" envDefault:"0.8"`
+	// SeriesBudgetInterval is the time between the series counts of the meter definitions.
+	SeriesBudgetInterval time.Duration `env:"METERING_SERIES_BUDGET_INTERVAL" envDefault:"1h"`
+	// KubeletService is the namespace/name of the kubelet service scraped on
+	// clusters without openshift-monitoring when the meterbase sets none.
+	KubeletService string `env:"METERING_KUBELET_SERVICE" envDefault:"kube-system/kubelet"`
 }
 
 type OLMInformation struct {
@@ -15,9 +15,12 @@ package config
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
+	"golang.org/x/net/http/httpproxy"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/discovery"
@@ -40,6 +43,12 @@ type Infrastructure struct {
 	sync.Mutex
 	openshift  *OpenshiftInfra
 	kubernetes *KubernetesInfra
+	proxy      *httpproxy.Config
+	transport  *http.Transport
+
+	// transportCABundle is the version of the trusted CA bundle the
+	// transport was built with
+	transportCABundle string
 }
 
 func NewInfrastructure(
@@ -58,9 +67,19 @@ func NewInfrastructure(
 		return nil, err
 	}
 
+	var proxy *httpproxy.Config
+	if openshift != nil {
+		proxy, err = openshiftProxy(c)
+		if err != nil {
+			log.Error(err, "unable to get cluster proxy")
+			return nil, err
+		}
+	}
+
 	return &Infrastructure{
 		openshift:  openshift,
 		kubernetes: kubernetes,
+		proxy:      proxy,
 	}, nil
 }
 
@@ -85,6 +104,19 @@ func openshiftInfrastructure(c client.Client) (*OpenshiftInfra, error) {
 	}, nil
 }
 
+func openshiftProxy(c client.Client) (*httpproxy.Config, error) {
+	proxy := &openshiftconfigv1.Proxy{}
+	err := c.Get(context.TODO(), client.ObjectKey{Name: "cluster"}, proxy)
+	if err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return transport.ProxyFromClusterProxy(proxy), nil
+}
+
 func kubernetesInfrastructure(discoveryClient *discovery.DiscoveryClient) (*KubernetesInfra, error) {
 	serverVersion, err := discoveryClient.ServerVersion()
 	if err != nil {
@@ -139,6 +171,57 @@ func (i *Infrastructure) HasOpenshift() bool {
 	return i.openshift != nil
 }
 
+// Proxy returns the cluster proxy. Nil means the environment is used.
+func (i *Infrastructure) Proxy() *httpproxy.Config {
+	if i == nil {
+		return nil
+	}
+
+	i.Lock()
+	defer i.Unlock()
+	return i.proxy
+}
+
+// SetProxy replaces the cluster proxy when the cluster proxy changes.
+func (i *Infrastructure) SetProxy(proxy *httpproxy.Config) {
+	i.Lock()
+	defer i.Unlock()
+	i.proxy = proxy
+}
+
+// Transport returns the transport shared by the outbound clients of the
+// operator. It uses the cluster proxy of the time of each request and trusts
+// the trusted CA bundle. The transport is built again once the trusted CA
+// bundle changed, so clients should get the transport for each use.
+func (i *Infrastructure) Transport() (*http.Transport, error) {
+	if i == nil {
+		return transport.NewTransport(nil)
+	}
+
+	i.Lock()
+	defer i.Unlock()
+
+	caBundle := transport.TrustedCABundleVersion()
+
+	if i.transport == nil || i.transportCABundle != caBundle {
+		t, err := transport.NewTransport(&transport.Config{
+			ProxySource: i.Proxy,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if i.transport != nil {
+			i.transport.CloseIdleConnections()
+		}
+
+		i.transport = t
+		i.transportCABundle = caBundle
+	}
+
+	return i.transport, nil
+}
+
 // IsDefined tells you if the infrastructure has been created
 func (i *Infrastructure) IsDefined() bool {
 	i.Lock()
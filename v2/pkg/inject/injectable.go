@@ -19,13 +19,19 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/managers"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/runnables"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/patch"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/recorder"
 )
 
+// EventRecorderName is the component name used on events emitted by the operator.
+const EventRecorderName = "redhat-marketplace-operator"
+
 var injectLog = ctrl.Log.WithName("injector")
 
 type SetupWithManager interface {
@@ -47,8 +53,10 @@ func ProvideInjectables(
 	i2 *OperatorConfigInjector,
 	i3 *PatchInjector,
 	i4 *FactoryInjector,
+	i5 *EventRecorderInjector,
+	i6 *MarketplaceBackendInjector,
 ) Injectables {
-	return []Injectable{i1, i2, i3, i4}
+	return []Injectable{i1, i2, i3, i4, i5, i6}
 }
 
 type Injector struct {
@@ -83,7 +91,7 @@ func ProvideInjector(
 		return nil, errors.Wrap(err, "failed set fields")
 	}
 
-	dependencies, err := initializeInjectDependencies(mgr.GetCache(), fields)
+	dependencies, err := initializeInjectDependencies(mgr.GetCache(), fields, mgr)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to init dependencies")
 	}
@@ -118,6 +126,14 @@ type Factory interface {
 	InjectFactory(*manifests.Factory) error
 }
 
+type EventRecorder interface {
+	InjectEventRecorder(record.EventRecorder) error
+}
+
+type MarketplaceBackend interface {
+	InjectMarketplaceBackend(marketplace.MarketplaceBackendProvider) error
+}
+
 type ClientCommandInjector struct {
 	Fields        *managers.ControllerFields
 	CommandRunner reconcileutils.ClientCommandRunner
@@ -164,3 +180,30 @@ func (a *FactoryInjector) SetCustomFields(i interface{}) error {
 	}
 	return nil
 }
+
+type EventRecorderInjector struct {
+	Provider recorder.Provider
+}
+
+func (a *EventRecorderInjector) SetCustomFields(i interface{}) error {
+	if ii, ok := i.(EventRecorder); ok {
+		return ii.InjectEventRecorder(a.Provider.GetEventRecorderFor(EventRecorderName))
+	}
+	return nil
+}
+
+// ProvideEventRecorder returns the event recorder of the operator for the runnables.
+func ProvideEventRecorder(provider recorder.Provider) record.EventRecorder {
+	return provider.GetEventRecorderFor(EventRecorderName)
+}
+
+type MarketplaceBackendInjector struct {
+	Provider marketplace.MarketplaceBackendProvider
+}
+
+func (a *MarketplaceBackendInjector) SetCustomFields(i interface{}) error {
+	if ii, ok := i.(MarketplaceBackend); ok {
+		return ii.InjectMarketplaceBackend(a.Provider)
+	}
+	return nil
+}
@@ -23,29 +23,36 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/managers"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/runnables"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/recorder"
 )
 
 func initializeInjectDependencies(
 	cache cache.Cache,
 	fields *managers.ControllerFields,
+	recorderProvider recorder.Provider,
 ) (injectorDependencies, error) {
 	panic(wire.Build(
 		managers.ProvideManagerSet,
 		runnables.RunnableSet,
 		reconcileutils.NewClientCommand,
-		managers.ProvidePodMonitorConfig,
+		managers.ProvidePodRemediatorConfig,
+		ProvideEventRecorder,
 		config.ProvideInfrastructureAwareConfig,
 		ProvideInjectables,
 		wire.Struct(new(ClientCommandInjector), "*"),
 		wire.Struct(new(OperatorConfigInjector), "*"),
 		wire.Struct(new(PatchInjector), "*"),
 		wire.Struct(new(FactoryInjector), "*"),
+		wire.Struct(new(EventRecorderInjector), "*"),
+		wire.Struct(new(MarketplaceBackendInjector), "*"),
 		wire.Struct(new(injectorDependencies), "*"),
 		ProvideNamespace,
 		manifests.NewFactory,
+		marketplace.ProvideMarketplaceBackendProvider,
 	))
 }
 
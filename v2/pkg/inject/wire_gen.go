@@ -9,29 +9,30 @@ import (
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/managers"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/runnables"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/recorder"
 )
 
 // Injectors from wire.go:
 
-func initializeInjectDependencies(cache2 cache.Cache, fields *managers.ControllerFields) (injectorDependencies, error) {
+func initializeInjectDependencies(cache2 cache.Cache, fields *managers.ControllerFields, recorderProvider recorder.Provider) (injectorDependencies, error) {
 	logger := fields.Logger
 	restConfig := fields.Config
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return injectorDependencies{}, err
 	}
-	client := fields.Client
-	scheme := fields.Scheme
-	clientCommandRunner := reconcileutils.NewClientCommand(client, scheme, logger)
+	eventRecorder := ProvideEventRecorder(recorderProvider)
 	restMapper, err := managers.NewDynamicRESTMapper(restConfig)
 	if err != nil {
 		return injectorDependencies{}, err
 	}
+	scheme := fields.Scheme
 	simpleClient, err := managers.ProvideSimpleClient(restConfig, restMapper, scheme)
 	if err != nil {
 		return injectorDependencies{}, err
@@ -45,8 +46,13 @@ func initializeInjectDependencies(cache2 cache.Cache, fields *managers.Controlle
 		return injectorDependencies{}, err
 	}
 	deployedNamespace := ProvideNamespace(operatorConfig)
-	podMonitorConfig := managers.ProvidePodMonitorConfig(deployedNamespace)
-	podMonitor := runnables.NewPodMonitor(logger, clientset, clientCommandRunner, podMonitorConfig)
+	podRemediatorConfig := managers.ProvidePodRemediatorConfig(deployedNamespace)
+	podRemediator, err := runnables.NewPodRemediator(logger, clientset, eventRecorder, podRemediatorConfig)
+	if err != nil {
+		return injectorDependencies{}, err
+	}
+	client := fields.Client
+	clientCommandRunner := reconcileutils.NewClientCommand(client, scheme, logger)
 	factory := manifests.NewFactory(operatorConfig, scheme)
 	crdUpdater := &runnables.CRDUpdater{
 		Logger:  logger,
@@ -56,7 +62,12 @@ func initializeInjectDependencies(cache2 cache.Cache, fields *managers.Controlle
 		Client:  clientset,
 		Factory: factory,
 	}
-	runnablesRunnables := runnables.ProvideRunnables(podMonitor, crdUpdater)
+	webhookCertificateSyncer := &runnables.WebhookCertificateSyncer{
+		Logger: logger,
+		Config: operatorConfig,
+		Client: clientset,
+	}
+	runnablesRunnables := runnables.ProvideRunnables(podRemediator, crdUpdater, webhookCertificateSyncer)
 	clientCommandInjector := &ClientCommandInjector{
 		Fields:        fields,
 		CommandRunner: clientCommandRunner,
@@ -72,7 +83,14 @@ func initializeInjectDependencies(cache2 cache.Cache, fields *managers.Controlle
 		Scheme:    scheme,
 		Factory:   factory,
 	}
-	injectables := ProvideInjectables(clientCommandInjector, operatorConfigInjector, patchInjector, factoryInjector)
+	eventRecorderInjector := &EventRecorderInjector{
+		Provider: recorderProvider,
+	}
+	marketplaceBackendProvider := marketplace.ProvideMarketplaceBackendProvider(operatorConfig)
+	marketplaceBackendInjector := &MarketplaceBackendInjector{
+		Provider: marketplaceBackendProvider,
+	}
+	injectables := ProvideInjectables(clientCommandInjector, operatorConfigInjector, patchInjector, factoryInjector, eventRecorderInjector, marketplaceBackendInjector)
 	injectInjectorDependencies := injectorDependencies{
 		Runnables:   runnablesRunnables,
 		Injectables: injectables,
@@ -137,10 +137,10 @@ func (m *ControllerFields) InjectConfig(cfg *rest.Config) error {
 	return nil
 }
 
-func ProvidePodMonitorConfig(namespace DeployedNamespace) runnables.PodMonitorConfig {
-	return runnables.PodMonitorConfig{
+func ProvidePodRemediatorConfig(namespace DeployedNamespace) runnables.PodRemediatorConfig {
+	return runnables.PodRemediatorConfig{
 		Namespace: string(namespace),
-		RetryTime: 30 * time.Second,
+		Policies:  runnables.OperandRemediationPolicies(),
 	}
 }
 
@@ -0,0 +1,82 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifests
+
+import (
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/authcheck"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"get", "create", "update", "delete"}
+)
+
+// PrometheusPermissions are the permissions the metering prometheus needs to
+// discover and scrape its targets.
+func (f *Factory) PrometheusPermissions() *authcheck.PermissionManifest {
+	return &authcheck.PermissionManifest{
+		Permissions: []authcheck.PermissionRule{
+			{Resources: []string{"services", "endpoints", "pods"}, Verbs: readVerbs},
+			{Resources: []string{"namespaces"}, Verbs: []string{"get"}},
+			{Resources: []string{"configmaps"}, Verbs: []string{"get"}, Namespaces: []string{f.namespace}},
+		},
+	}
+}
+
+// PrometheusOperatorPermissions are the permissions the prometheus operator
+// needs to run the metering prometheus of the namespaces it watches.
+func (f *Factory) PrometheusOperatorPermissions(ns []string) *authcheck.PermissionManifest {
+	return &authcheck.PermissionManifest{
+		Permissions: []authcheck.PermissionRule{
+			{
+				Group:      "monitoring.coreos.com",
+				Resources:  []string{"prometheuses", "servicemonitors", "podmonitors", "prometheusrules"},
+				Verbs:      readVerbs,
+				Namespaces: ns,
+			},
+			{Group: "apps", Resources: []string{"statefulsets"}, Verbs: writeVerbs, Namespaces: []string{f.namespace}},
+			{Resources: []string{"configmaps", "secrets"}, Verbs: writeVerbs, Namespaces: []string{f.namespace}},
+			{Resources: []string{"services", "endpoints"}, Verbs: []string{"get", "create", "update"}, Namespaces: []string{f.namespace}},
+			{Resources: []string{"namespaces"}, Verbs: readVerbs},
+		},
+	}
+}
+
+// MetricStatePermissions are the permissions metric-state needs to watch the
+// resources meter definitions select.
+func (f *Factory) MetricStatePermissions() *authcheck.PermissionManifest {
+	return &authcheck.PermissionManifest{
+		Permissions: []authcheck.PermissionRule{
+			{Resources: []string{"pods", "services", "persistentvolumeclaims"}, Verbs: readVerbs},
+			{Group: "monitoring.coreos.com", Resources: []string{"servicemonitors"}, Verbs: readVerbs},
+			{Group: "marketplace.redhat.com", Resources: []string{"meterdefinitions"}, Verbs: readVerbs},
+		},
+	}
+}
+
+// setAuthCheckPermissions passes the permission manifest to the authcheck
+// sidecar, which serves the result of its self-test.
+func setAuthCheckPermissions(containers []corev1.Container, manifest *authcheck.PermissionManifest) {
+	for i := range containers {
+		container := &containers[i]
+
+		if container.Name != authcheck.ContainerName {
+			continue
+		}
+
+		container.Args = append(container.Args, "--permissions", manifest.String())
+	}
+}
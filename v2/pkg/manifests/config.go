@@ -24,6 +24,17 @@ import (
 	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 )
 
+const (
+	// MeterReportRetentionDays is the number of days of meter reports the
+	// MeterBase keeps.
+	MeterReportRetentionDays = 30
+
+	// PrometheusRetentionMarginDays is how much longer than the reports
+	// Prometheus keeps its data, so the oldest report can still be generated
+	// after an outage.
+	PrometheusRetentionMarginDays = 7
+)
+
 type Config struct {
 	RelatedImages            config.RelatedImages      `json:"relatedImages"`
 	PrometheusOperatorConfig *PrometheusOperatorConfig `json:"prometheusOperator"`
@@ -82,6 +93,6 @@ func (c *Config) applyDefaults() {
 
 	if c.PrometheusConfig == nil {
 		c.PrometheusConfig = &PrometheusConfig{}
-		c.PrometheusConfig.Retention = "30d"
+		c.PrometheusConfig.Retention = fmt.Sprintf("%dd", MeterReportRetentionDays+PrometheusRetentionMarginDays)
 	}
 }
@@ -21,14 +21,21 @@ import (
 	"crypto/rand"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
+	"emperror.dev/errors"
 	"github.com/gotidy/ptr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/common"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	marketplacev1beta1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1beta1"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	prom "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/prometheus"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -65,6 +72,13 @@ const (
 	MetricStateService        = "assets/metric-state/service.yaml"
 )
 
+const (
+	servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	injectCABundleAnnotation    = "service.beta.openshift.io/inject-cabundle"
+
+	prometheusOAuthProxyContainer = "prometheus-proxy"
+)
+
 var log = logf.Log.WithName("manifests_factory")
 
 func MustAssetReader(asset string) io.Reader {
@@ -102,7 +116,7 @@ func (f *Factory) ReplaceImages(container *corev1.Container) {
 		container.Args = append(container.Args, "--namespace", f.namespace)
 	case container.Name == "prometheus-operator":
 		container.Image = f.config.RelatedImages.PrometheusOperator
-	case container.Name == "prometheus-proxy":
+	case container.Name == prometheusOAuthProxyContainer:
 		container.Image = f.config.RelatedImages.OAuthProxy
 	}
 }
@@ -211,6 +225,20 @@ func (f *Factory) PrometheusService(instanceName string) (*v1.Service, error) {
 
 	s.Spec.Selector["prometheus"] = instanceName
 
+	// without the openshift service CA the operator issues the serving
+	// certificate and the oauth proxy port is not served
+	if !f.hasOpenshift() {
+		delete(s.Annotations, servingCertSecretAnnotation)
+
+		ports := []v1.ServicePort{}
+		for _, port := range s.Spec.Ports {
+			if port.Name != "https" {
+				ports = append(ports, port)
+			}
+		}
+		s.Spec.Ports = ports
+	}
+
 	return s, nil
 }
 
@@ -290,12 +318,15 @@ func (f *Factory) NewPrometheusOperatorDeployment(ns []string) (*appsv1.Deployme
 		container.Args = newArgs
 	}
 
+	setAuthCheckPermissions(dep.Spec.Template.Spec.Containers, f.PrometheusOperatorPermissions(ns))
+
 	return dep, err
 }
 
 func (f *Factory) NewPrometheusDeployment(
 	cr *marketplacev1alpha1.MeterBase,
 	cfg *corev1.Secret,
+	meterdefs []marketplacev1beta1.MeterDefinition,
 ) (*monitoringv1.Prometheus, error) {
 	logger := log.WithValues("func", "NewPrometheusDeployment")
 	p, err := f.NewPrometheus(MustAssetReader(PrometheusDeployment))
@@ -314,6 +345,28 @@ func (f *Factory) NewPrometheusDeployment(
 		p.Spec.Replicas = cr.Spec.Prometheus.Replicas
 	}
 
+	// each replica scrapes every target; the replica label tells their
+	// series apart in remote storage and keeps the replicas on separate nodes
+	p.Spec.ReplicaExternalLabelName = ptr.String(utils.PrometheusReplicaLabel)
+	p.Spec.Affinity = &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								"app":        "prometheus",
+								"prometheus": cr.Name,
+							},
+						},
+						TopologyKey: "kubernetes.io/hostname",
+					},
+				},
+			},
+		},
+	}
+
 	if f.config.PrometheusConfig.Retention != "" {
 		p.Spec.Retention = f.config.PrometheusConfig.Retention
 	}
@@ -328,18 +381,15 @@ func (f *Factory) NewPrometheusDeployment(
 		storageClass = cr.Spec.Prometheus.Storage.Class
 	}
 
-	quanBytes := cr.Spec.Prometheus.Storage.Size.DeepCopy()
-	quanBytes.Sub(resource.MustParse("2Gi"))
-	replacer := strings.NewReplacer("Mi", "MB", "Gi", "GB", "Ti", "TB")
-	storageSize := replacer.Replace(quanBytes.String())
-	p.Spec.RetentionSize = storageSize
+	size := cr.PrometheusStorageSize()
+	p.Spec.RetentionSize = PrometheusRetentionSize(size)
 
 	pvc, err := utils.NewPersistentVolumeClaim(utils.PersistentVolume{
 		ObjectMeta: &metav1.ObjectMeta{
 			Name: "storage-volume",
 		},
 		StorageClass: storageClass,
-		StorageSize:  &cr.Spec.Prometheus.Storage.Size,
+		StorageSize:  &size,
 	})
 
 	p.Spec.Storage.VolumeClaimTemplate = monitoringv1.EmbeddedPersistentVolumeClaim{
@@ -355,21 +405,168 @@ func (f *Factory) NewPrometheusDeployment(
 		}
 	}
 
+	f.setPrometheusRemoteStorage(p, cr.Spec.Prometheus, meterdefs)
+
+	// the oauth proxy authenticates against openshift, other distributions
+	// only get the kube-rbac-proxy
+	if !f.hasOpenshift() {
+		containers := []corev1.Container{}
+		for _, container := range p.Spec.Containers {
+			if container.Name != prometheusOAuthProxyContainer {
+				containers = append(containers, container)
+			}
+		}
+		p.Spec.Containers = containers
+	}
+
 	for i := range p.Spec.Containers {
 		f.ReplaceImages(&p.Spec.Containers[i])
 	}
 
+	setAuthCheckPermissions(p.Spec.Containers, f.PrometheusPermissions())
+
 	return p, err
 }
 
+// PrometheusRetentionSize is the retention size of a Prometheus volume of the
+// given size. The volume keeps a tenth of its size, and at least 2Gi, free for
+// the write-ahead log and for compactions, which write the new block before
+// deleting the blocks it replaces.
+func PrometheusRetentionSize(size resource.Quantity) string {
+	const mebibyte = 1024 * 1024
+
+	headroom := size.Value() / 10
+	if min := resource.MustParse("2Gi"); headroom < min.Value() {
+		headroom = min.Value()
+	}
+
+	retention := (size.Value() - headroom) / mebibyte
+	if retention <= 0 {
+		return ""
+	}
+
+	// prometheus units are powers of 1024
+	return fmt.Sprintf("%dMB", retention)
+}
+
+// setPrometheusRemoteStorage adds the remote write and remote read endpoints of the
+// meterbase to the prometheus. Bearer token secrets are mounted in the prometheus pods.
+func (f *Factory) setPrometheusRemoteStorage(
+	p *monitoringv1.Prometheus,
+	spec *marketplacev1alpha1.PrometheusSpec,
+	meterdefs []marketplacev1beta1.MeterDefinition,
+) {
+	p.Spec.RemoteWrite = nil
+	p.Spec.RemoteRead = nil
+
+	addSecret := func(sel *corev1.SecretKeySelector) string {
+		if sel == nil {
+			return ""
+		}
+
+		found := false
+		for _, name := range p.Spec.Secrets {
+			if name == sel.Name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			p.Spec.Secrets = append(p.Spec.Secrets, sel.Name)
+		}
+
+		return fmt.Sprintf("/etc/prometheus/secrets/%s/%s", sel.Name, sel.Key)
+	}
+
+	if len(spec.RemoteWrite) != 0 {
+		keep := MeteringSeriesRelabelConfig(meterdefs)
+
+		for _, target := range spec.RemoteWrite {
+			p.Spec.RemoteWrite = append(p.Spec.RemoteWrite, monitoringv1.RemoteWriteSpec{
+				URL:                 target.URL,
+				Name:                target.Name,
+				TLSConfig:           target.TLSConfig.DeepCopy(),
+				BasicAuth:           target.BasicAuth.DeepCopy(),
+				BearerTokenFile:     addSecret(target.BearerTokenSecret),
+				WriteRelabelConfigs: append([]monitoringv1.RelabelConfig{keep}, target.WriteRelabelConfigs...),
+			})
+		}
+	}
+
+	for _, target := range spec.RemoteRead {
+		p.Spec.RemoteRead = append(p.Spec.RemoteRead, monitoringv1.RemoteReadSpec{
+			URL:             target.URL,
+			Name:            target.Name,
+			TLSConfig:       target.TLSConfig.DeepCopy(),
+			BasicAuth:       target.BasicAuth.DeepCopy(),
+			BearerTokenFile: addSecret(target.BearerTokenSecret),
+			ReadRecent:      target.ReadRecent,
+		})
+	}
+}
+
+// MeteringSeriesRelabelConfig returns a relabel config that keeps the meterdef_*
+// series and the metrics used by the queries of the meter definitions. Queries
+// that can't be parsed are left out, the MeterBase reports them in its status.
+func MeteringSeriesRelabelConfig(
+	meterdefs []marketplacev1beta1.MeterDefinition,
+) monitoringv1.RelabelConfig {
+	found := map[string]bool{}
+	names := []string{"meterdef_.*"}
+
+	for _, meterdef := range meterdefs {
+		for _, meter := range meterdef.Spec.Meters {
+			queryNames, err := prom.QueryMetricNames(meter.Query)
+			if err != nil {
+				log.Error(err, "failed to parse meter query", "meterdef", meterdef.Name, "query", meter.Query)
+				continue
+			}
+
+			for _, name := range queryNames {
+				if found[name] {
+					continue
+				}
+
+				found[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names[1:])
+
+	return monitoringv1.RelabelConfig{
+		SourceLabels: []string{"__name__"},
+		Action:       "keep",
+		Regex:        fmt.Sprintf("(%s)", strings.Join(names, "|")),
+	}
+}
+
 func (f *Factory) NewPrometheusOperatorService() (*corev1.Service, error) {
 	service, err := f.NewService(MustAssetReader(PrometheusOperatorService))
+	if err != nil {
+		return nil, err
+	}
 
-	return service, err
+	if !f.hasOpenshift() {
+		delete(service.Annotations, servingCertSecretAnnotation)
+	}
+
+	return service, nil
 }
 
 func (f *Factory) NewPrometheusOperatorCertsCABundle() (*corev1.ConfigMap, error) {
-	return f.NewConfigMap(MustAssetReader(PrometheusOperatorCertsCABundle))
+	c, err := f.NewConfigMap(MustAssetReader(PrometheusOperatorCertsCABundle))
+	if err != nil {
+		return nil, err
+	}
+
+	if !f.hasOpenshift() {
+		delete(c.Annotations, injectCABundleAnnotation)
+	}
+
+	return c, nil
 }
 
 func (f *Factory) PrometheusKubeletServingCABundle(data string) (*v1.ConfigMap, error) {
@@ -434,6 +631,10 @@ func (f *Factory) PrometheusServingCertsCABundle() (*v1.ConfigMap, error) {
 
 	c.Namespace = f.namespace
 
+	if !f.hasOpenshift() {
+		delete(c.Annotations, injectCABundleAnnotation)
+	}
+
 	return c, nil
 }
 
@@ -457,6 +658,8 @@ func (f *Factory) ReporterJob(
 		report.Name,
 		"--namespace",
 		report.Namespace,
+		"--uploadURL",
+		f.operatorConfig.Marketplace.UploadURL,
 	)
 
 	if len(report.Spec.ExtraArgs) > 0 {
@@ -466,10 +669,264 @@ func (f *Factory) ReporterJob(
 	// Keep last 3 days of data
 	j.Spec.TTLSecondsAfterFinished = ptr.Int32(86400 * 3)
 	j.Spec.Template.Spec.Containers[0] = container
+	f.setOutboundTransport(&j.Spec.Template.Spec)
 
 	return j, nil
 }
 
+// TrustedCABundleConfigMap returns the configmap OpenShift injects the
+// trusted CA bundle into.
+func (f *Factory) TrustedCABundleConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      transport.TrustedCABundleConfigMapName,
+			Namespace: f.namespace,
+			Labels: map[string]string{
+				transport.TrustedCABundleInjectLabel: "true",
+			},
+		},
+	}
+}
+
+// setOutboundTransport passes the cluster proxy and the trusted CA bundle to
+// the containers of a pod that connects outside the cluster.
+func (f *Factory) setOutboundTransport(spec *corev1.PodSpec) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "trusted-ca-bundle",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: transport.TrustedCABundleConfigMapName,
+				},
+				Optional: ptr.Bool(true),
+			},
+		},
+	})
+
+	proxyEnv := transport.ProxyEnvVars(f.operatorConfig.Infrastructure.Proxy())
+
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		container.Env = append(container.Env, proxyEnv...)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "trusted-ca-bundle",
+			MountPath: transport.TrustedCABundleMountPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// ReportArchivePVC returns the claim that keeps the reports of a disconnected
+// cluster until they are exported.
+func (f *Factory) ReportArchivePVC(
+	marketplaceConfig *marketplacev1alpha1.MarketplaceConfig,
+) (*corev1.PersistentVolumeClaim, error) {
+	size := resource.MustParse("1Gi")
+	var storageClass *string
+
+	if marketplaceConfig.Spec.Disconnected != nil &&
+		marketplaceConfig.Spec.Disconnected.ArchiveStorage != nil {
+		storage := marketplaceConfig.Spec.Disconnected.ArchiveStorage
+		storageClass = storage.Class
+
+		if !storage.Size.IsZero() {
+			size = storage.Size
+		}
+	}
+
+	pvc, err := utils.NewPersistentVolumeClaim(utils.PersistentVolume{
+		ObjectMeta: &metav1.ObjectMeta{
+			Name:      utils.ReportArchivePVCName,
+			Namespace: f.namespace,
+		},
+		StorageSize: &size,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// use the default storage class unless one is set
+	pvc.Spec.StorageClassName = storageClass
+
+	return &pvc, nil
+}
+
+// SetReporterJobArchive makes the reporter job write its reports to the
+// report archive instead of uploading them.
+func (f *Factory) SetReporterJobArchive(j *batchv1.Job) {
+	j.Spec.Template.Spec.Volumes = append(j.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "report-archive",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: utils.ReportArchivePVCName,
+			},
+		},
+	})
+
+	container := &j.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "report-archive",
+		MountPath: utils.ReportArchivePath,
+	})
+	container.Args = append(container.Args,
+		"--uploadTarget",
+		"local-path",
+		"--localFilePath",
+		utils.ReportArchivePath,
+	)
+}
+
+const (
+	// MeterDefinitionSnapshotShardsAnnotation lists the names of the configmaps that hold
+	// the rest of a snapshot too large for a single configmap.
+	MeterDefinitionSnapshotShardsAnnotation = "marketplace.redhat.com/meterdefinition-snapshot-shards"
+
+	// meterDefinitionSnapshotShardSize is the most meter definition data stored in one
+	// snapshot configmap, leaving room for the metadata under the 1MiB object limit.
+	meterDefinitionSnapshotShardSize = 900 * 1024
+)
+
+// ReporterMeterDefinitionSnapshot returns the immutable configmaps that hold a copy
+// of every meter definition in effect for the report. The first configmap is named
+// by MeterDefinitionSnapshotName and lists the other shards in its annotations.
+func (f *Factory) ReporterMeterDefinitionSnapshot(
+	report *marketplacev1alpha1.MeterReport,
+	meterdefs []marketplacev1beta1.MeterDefinition,
+) ([]*corev1.ConfigMap, error) {
+	newShard := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: report.GetNamespace(),
+				Labels: map[string]string{
+					"marketplace.redhat.com/meterreport": report.GetName(),
+				},
+			},
+			Immutable: ptr.Bool(true),
+			Data:      map[string]string{},
+		}
+	}
+
+	keys := []string{}
+	data := map[string]string{}
+
+	for _, meterdef := range meterdefs {
+		snapshot := marketplacev1beta1.MeterDefinition{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: marketplacev1beta1.GroupVersion.String(),
+				Kind:       "MeterDefinition",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            meterdef.Name,
+				Namespace:       meterdef.Namespace,
+				UID:             meterdef.UID,
+				ResourceVersion: meterdef.ResourceVersion,
+				Generation:      meterdef.Generation,
+			},
+			Spec: meterdef.Spec,
+		}
+
+		ref, err := snapshot.ToReference()
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := json.Marshal(&snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(raw) > meterDefinitionSnapshotShardSize {
+			return nil, errors.Errorf("meterdefinition %s/%s is too large to snapshot", meterdef.Namespace, meterdef.Name)
+		}
+
+		keys = append(keys, ref.Key())
+		data[ref.Key()] = string(raw)
+	}
+
+	sort.Strings(keys)
+
+	cms := []*corev1.ConfigMap{newShard(MeterDefinitionSnapshotName(report))}
+	size := 0
+
+	for _, key := range keys {
+		if size+len(data[key]) > meterDefinitionSnapshotShardSize {
+			cms = append(cms, newShard(fmt.Sprintf("%s-%d", MeterDefinitionSnapshotName(report), len(cms))))
+			size = 0
+		}
+
+		cms[len(cms)-1].Data[key] = data[key]
+		size = size + len(data[key])
+	}
+
+	if len(cms) > 1 {
+		shards := []string{}
+		for _, cm := range cms[1:] {
+			shards = append(shards, cm.Name)
+		}
+
+		cms[0].Annotations = map[string]string{
+			MeterDefinitionSnapshotShardsAnnotation: strings.Join(shards, ","),
+		}
+	}
+
+	return cms, nil
+}
+
+// MeterDefinitionSnapshotName is the name of the snapshot configmap for a report.
+func MeterDefinitionSnapshotName(report *marketplacev1alpha1.MeterReport) string {
+	return report.GetName() + "-meterdefinitions"
+}
+
+// MeterDefinitionSnapshotShards returns the names of the other configmaps of the
+// snapshot that starts with cm.
+func MeterDefinitionSnapshotShards(cm *corev1.ConfigMap) []string {
+	shards, ok := cm.GetAnnotations()[MeterDefinitionSnapshotShardsAnnotation]
+	if !ok || shards == "" {
+		return nil
+	}
+
+	return strings.Split(shards, ",")
+}
+
+// MeterDefinitionSnapshotFromConfigMaps rebuilds the snapshot record from the
+// configmaps created by ReporterMeterDefinitionSnapshot, first configmap first.
+func MeterDefinitionSnapshotFromConfigMaps(cms ...*corev1.ConfigMap) (*common.MeterDefinitionSnapshot, error) {
+	if len(cms) == 0 {
+		return nil, errors.New("no meterdefinition snapshot configmaps")
+	}
+
+	snapshot := &common.MeterDefinitionSnapshot{
+		ConfigMapName:    cms[0].GetName(),
+		CreatedAt:        cms[0].GetCreationTimestamp(),
+		MeterDefinitions: []common.MeterDefinitionReference{},
+	}
+
+	for i, cm := range cms {
+		if i > 0 {
+			snapshot.ConfigMapShards = append(snapshot.ConfigMapShards, cm.GetName())
+		}
+
+		for key, data := range cm.Data {
+			meterdef := &marketplacev1beta1.MeterDefinition{}
+			if err := json.Unmarshal([]byte(data), meterdef); err != nil {
+				return nil, errors.Wrapf(err, "failed to decode meterdefinition %s", key)
+			}
+
+			ref, err := meterdef.ToReference()
+			if err != nil {
+				return nil, err
+			}
+
+			snapshot.MeterDefinitions = append(snapshot.MeterDefinitions, *ref)
+		}
+	}
+
+	common.SortMeterDefinitionReferences(snapshot.MeterDefinitions)
+	return snapshot, nil
+}
+
 func (f *Factory) MetricStateDeployment() (*appsv1.Deployment, error) {
 	d, err := f.NewDeployment(MustAssetReader(MetricStateDeployment))
 	if err != nil {
@@ -480,6 +937,8 @@ func (f *Factory) MetricStateDeployment() (*appsv1.Deployment, error) {
 		f.ReplaceImages(&d.Spec.Template.Spec.Containers[i])
 	}
 
+	setAuthCheckPermissions(d.Spec.Template.Spec.Containers, f.MetricStatePermissions())
+
 	d.Namespace = f.namespace
 
 	return d, nil
@@ -498,6 +957,63 @@ func (f *Factory) MetricStateServiceMonitor() (*monitoringv1.ServiceMonitor, err
 	return sm, nil
 }
 
+// KubernetesServiceMonitor returns a service monitor that scrapes the paths of
+// a service. It stands in for the openshift-monitoring service monitors on
+// other distributions.
+func (f *Factory) KubernetesServiceMonitor(
+	name string,
+	service *corev1.Service,
+	target marketplacev1alpha1.MonitoredService,
+	paths ...string,
+) *monitoringv1.ServiceMonitor {
+	sm := &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: service.Namespace,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: service.Labels,
+			},
+			NamespaceSelector: monitoringv1.NamespaceSelector{
+				MatchNames: []string{service.Namespace},
+			},
+		},
+	}
+
+	for _, path := range paths {
+		ep := monitoringv1.Endpoint{
+			Port:          target.Port,
+			Path:          path,
+			Scheme:        target.Scheme,
+			Interval:      "2m",
+			ScrapeTimeout: "2m",
+			HonorLabels:   true,
+			// the service labels may select other services
+			RelabelConfigs: []*monitoringv1.RelabelConfig{
+				{
+					SourceLabels: []string{"__meta_kubernetes_service_name"},
+					Regex:        service.Name,
+					Action:       "keep",
+				},
+			},
+		}
+
+		if target.Scheme == "https" {
+			ep.BearerTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+			ep.TLSConfig = &monitoringv1.TLSConfig{
+				SafeTLSConfig: monitoringv1.SafeTLSConfig{
+					InsecureSkipVerify: target.InsecureSkipVerify,
+				},
+			}
+		}
+
+		sm.Spec.Endpoints = append(sm.Spec.Endpoints, ep)
+	}
+
+	return sm
+}
+
 func (f *Factory) MetricStateService() (*v1.Service, error) {
 	s, err := f.NewService(MustAssetReader(MetricStateService))
 	if err != nil {
@@ -506,6 +1022,10 @@ func (f *Factory) MetricStateService() (*v1.Service, error) {
 
 	s.Namespace = f.namespace
 
+	if !f.hasOpenshift() {
+		delete(s.Annotations, servingCertSecretAnnotation)
+	}
+
 	return s, nil
 }
 
@@ -601,6 +1121,12 @@ func NewServiceMonitor(manifest io.Reader) (*monitoringv1.ServiceMonitor, error)
 	return &sm, nil
 }
 
+// hasOpenshift returns if the openshift service CA and oauth are available.
+// An unknown infrastructure is treated as openshift.
+func (f *Factory) hasOpenshift() bool {
+	return f.operatorConfig.Infrastructure == nil || f.operatorConfig.Infrastructure.HasOpenshift()
+}
+
 func (f *Factory) NewWatchKeeperDeployment(instance *marketplacev1alpha1.RazeeDeployment) *appsv1.Deployment {
 	var securityContext *corev1.PodSecurityContext
 	if !f.operatorConfig.Infrastructure.HasOpenshift() {
@@ -612,7 +1138,7 @@ func (f *Factory) NewWatchKeeperDeployment(instance *marketplacev1alpha1.RazeeDe
 	maxSurge := intstr.FromString("25%")
 	maxUnavailable := intstr.FromString("25%")
 
-	return &appsv1.Deployment{
+	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      utils.RHM_WATCHKEEPER_DEPLOYMENT_NAME,
 			Namespace: f.namespace,
@@ -751,6 +1277,9 @@ func (f *Factory) NewWatchKeeperDeployment(instance *marketplacev1alpha1.RazeeDe
 			},
 		},
 	}
+
+	f.setOutboundTransport(&dep.Spec.Template.Spec)
+	return dep
 }
 
 type Owner metav1.Object
@@ -774,7 +1303,7 @@ func (f *Factory) NewRemoteResourceS3Deployment(instance *marketplacev1alpha1.Ra
 	maxSurge := intstr.FromString("25%")
 	maxUnavailable := intstr.FromString("25%")
 
-	return &appsv1.Deployment{
+	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      utils.RHM_REMOTE_RESOURCE_S3_DEPLOYMENT_NAME,
 			Namespace: f.namespace,
@@ -915,4 +1444,7 @@ func (f *Factory) NewRemoteResourceS3Deployment(instance *marketplacev1alpha1.Ra
 			},
 		},
 	}
+
+	f.setOutboundTransport(&dep.Spec.Template.Spec)
+	return dep
 }
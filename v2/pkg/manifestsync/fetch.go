@@ -0,0 +1,273 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxDocumentSize is the largest remote document that is downloaded.
+const MaxDocumentSize = 10 << 20
+
+// DefaultRegion is the signing region used when the hmac auth has none.
+const DefaultRegion = "us-east-1"
+
+// HeaderValueKey is the key of a request header that holds the value sent.
+const HeaderValueKey = "value"
+
+// Fetcher downloads remote documents with the auth of a RemoteResourceS3.
+type Fetcher struct {
+	// Client reads the secrets referenced by the auth.
+	Client client.Client
+	// HTTPClient sends the requests.
+	HTTPClient *http.Client
+	// Namespace is the namespace of the referenced secrets.
+	Namespace string
+	// Auth is the auth of the RemoteResourceS3.
+	Auth marketplacev1alpha1.Auth
+
+	now      func() time.Time
+	iamToken string
+}
+
+// Document is a downloaded document.
+type Document struct {
+	URL        string
+	StatusCode int
+	Data       []byte
+}
+
+// HTTPError is returned when the remote location answers with an error.
+type HTTPError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RequestURL returns the url of the request options.
+func RequestURL(options marketplacev1alpha1.S3Options) string {
+	if options.URL != "" {
+		return options.URL
+	}
+	return options.URI
+}
+
+// Fetch downloads the document at the url with the headers of the request options.
+func (f *Fetcher) Fetch(
+	ctx context.Context,
+	rawURL string,
+	headers map[string]marketplacev1alpha1.Header,
+) (*Document, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build request")
+	}
+	req = req.WithContext(ctx)
+
+	for name, header := range headers {
+		if value, ok := header[HeaderValueKey]; ok {
+			req.Header.Set(name, value)
+		}
+	}
+
+	if err := f.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %s", rawURL)
+	}
+	defer resp.Body.Close()
+
+	doc := &Document{URL: rawURL, StatusCode: resp.StatusCode}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return doc, &HTTPError{URL: rawURL, StatusCode: resp.StatusCode}
+	}
+
+	doc.Data, err = ioutil.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return doc, errors.Wrapf(err, "failed to read %s", rawURL)
+	}
+
+	if len(doc.Data) > MaxDocumentSize {
+		return doc, errors.Errorf("document %s is larger than %d bytes", rawURL, MaxDocumentSize)
+	}
+
+	return doc, nil
+}
+
+func (f *Fetcher) authorize(ctx context.Context, req *http.Request) error {
+	switch {
+	case f.Auth.Iam != nil:
+		token, err := f.iamAccessToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case f.Auth.Hmac != nil:
+		hmac := f.Auth.Hmac
+
+		accessKeyID, err := f.value(ctx, hmac.AccessKeyID, hmac.AccesKeyIDRef.ValueFrom.SecretKeyRef)
+		if err != nil {
+			return errors.Wrap(err, "failed to get hmac access key id")
+		}
+
+		secretAccessKey, err := f.value(ctx, hmac.SecretAccessKey, hmac.SecretAccessKeyRef.ValueFrom.SecretKeyRef)
+		if err != nil {
+			return errors.Wrap(err, "failed to get hmac secret access key")
+		}
+
+		region := hmac.Region
+		if region == "" {
+			region = DefaultRegion
+		}
+
+		now := time.Now
+		if f.now != nil {
+			now = f.now
+		}
+
+		if err := signS3Request(req, accessKeyID, secretAccessKey, region, now()); err != nil {
+			return errors.Wrap(err, "failed to sign request")
+		}
+	}
+
+	return nil
+}
+
+// signS3Request signs a bodyless S3 request with the AWS signature version 4.
+func signS3Request(req *http.Request, accessKeyID, secretAccessKey, region string, now time.Time) error {
+	signer := v4.NewSigner(
+		credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		func(s *v4.Signer) {
+			// S3 signs the path as sent
+			s.DisableURIPathEscaping = true
+		},
+	)
+
+	_, err := signer.Sign(req, nil, "s3", region, now)
+	return err
+}
+
+type iamTokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// iamAccessToken exchanges the api key for an access token. The token is
+// kept for the lifetime of the fetcher.
+func (f *Fetcher) iamAccessToken(ctx context.Context) (string, error) {
+	if f.iamToken != "" {
+		return f.iamToken, nil
+	}
+
+	iam := f.Auth.Iam
+
+	apiKey, err := f.value(ctx, iam.APIKey, iam.APIKeyRef.ValueFrom.SecretKeyRef)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get iam api key")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", iam.GrantType)
+	form.Set("apikey", apiKey)
+	form.Set("response_type", iam.ResponseType)
+
+	req, err := http.NewRequest(http.MethodPost, iam.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build iam request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get iam token")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", errors.WithStack(&HTTPError{URL: iam.URL, StatusCode: resp.StatusCode})
+	}
+
+	token := iamTokenResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "failed to decode iam token")
+	}
+
+	if token.AccessToken == "" {
+		return "", errors.New("iam response has no access token")
+	}
+
+	f.iamToken = token.AccessToken
+	return f.iamToken, nil
+}
+
+// value returns the literal value or reads it from the secret key.
+func (f *Fetcher) value(ctx context.Context, literal string, ref corev1.SecretKeySelector) (string, error) {
+	if literal != "" {
+		return literal, nil
+	}
+
+	if ref.Name == "" {
+		return "", errors.New("value and secret reference are empty")
+	}
+
+	return SecretValue(ctx, f.Client, f.Namespace, ref)
+}
+
+// SecretValue reads the key of a secret.
+func SecretValue(ctx context.Context, c client.Client, namespace string, ref corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret %s", ref.Name)
+	}
+
+	data, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("secret %s is missing key %s", ref.Name, ref.Key)
+	}
+
+	return string(data), nil
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestsync
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"strings"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// ManifestIndexKind is the kind of a document that lists other manifests.
+	ManifestIndexKind = "ManifestIndex"
+
+	// SignatureSuffix is appended to the url of a document to find its signature.
+	SignatureSuffix = ".sig"
+)
+
+// ManifestIndex lists the manifests to apply. A signed index covers the
+// manifests through their digests; a manifest without a digest needs its own
+// signature.
+//
+//	apiVersion: marketplace.redhat.com/v1alpha1
+//	kind: ManifestIndex
+//	manifests:
+//	- url: meterdefinitions.yaml
+//	  sha256: 5d41402abc4b2a76b9719d911017c592...
+type ManifestIndex struct {
+	Manifests []ManifestIndexEntry `json:"manifests"`
+}
+
+// ManifestIndexEntry is a manifest of the index. Relative urls are resolved
+// against the url of the index.
+type ManifestIndexEntry struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// ParseDocument returns the objects of a multi document yaml or json file, or
+// the index when the file is a ManifestIndex. Lists are flattened.
+func ParseDocument(data []byte) ([]*unstructured.Unstructured, *ManifestIndex, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	objs := []*unstructured.Unstructured{}
+
+	for {
+		obj := &unstructured.Unstructured{}
+		err := decoder.Decode(&obj.Object)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to decode document")
+		}
+
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if obj.GetKind() == ManifestIndexKind {
+			index := &ManifestIndex{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, index); err != nil {
+				return nil, nil, errors.Wrap(err, "failed to decode manifest index")
+			}
+			return nil, index, nil
+		}
+
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objs = append(objs, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to decode list")
+			}
+			continue
+		}
+
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" || obj.GetName() == "" {
+			return nil, nil, errors.New("object is missing apiVersion, kind or metadata.name")
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil, nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX or PKCS1 public key, or the key of
+// a PEM encoded certificate.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		return cert.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+		return key, nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse public key")
+		}
+		return key, nil
+	}
+}
+
+// VerifySignature verifies the sha256 signature of the data. The signature
+// may be raw or base64 encoded.
+func VerifySignature(data, signature []byte, key crypto.PublicKey) error {
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+
+	digest := sha256.Sum256(data)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err == nil {
+			return nil
+		}
+		if err := rsa.VerifyPSS(pub, crypto.SHA256, digest[:], signature, nil); err != nil {
+			return errors.New("signature does not match")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest[:], signature) {
+			return errors.New("signature does not match")
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// VerifyDigest checks the hex encoded sha256 digest of the data.
+func VerifyDigest(data []byte, digest string) error {
+	if actual := hexSHA256(data); !strings.EqualFold(actual, digest) {
+		return errors.Errorf("digest %s does not match %s", actual, digest)
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifestsync
+
+import (
+	"context"
+	"crypto"
+	"net/url"
+
+	"emperror.dev/errors"
+	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// FieldOwner is the field manager of the applied objects.
+	FieldOwner = "redhat-marketplace-remoteresources3"
+
+	// SourceAnnotation is set on applied objects to the namespace/name of the RemoteResourceS3.
+	SourceAnnotation = "marketplace.redhat.com/remoteresources3"
+)
+
+// VerificationError is returned when a document does not match its signature or digest.
+type VerificationError struct {
+	URL string
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return "failed to verify " + e.URL + ": " + e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// NotAllowedError is returned when an object is outside of the kinds and
+// namespaces the syncer applies.
+type NotAllowedError struct {
+	GroupKind schema.GroupKind
+	Namespace string
+	Name      string
+	Reason    string
+}
+
+func (e *NotAllowedError) Error() string {
+	return "not allowed to apply " + e.GroupKind.String() + " " + e.Namespace + "/" + e.Name + ": " + e.Reason
+}
+
+// Syncer fetches the requests of a RemoteResourceS3 and applies their objects.
+type Syncer struct {
+	Client  client.Client
+	Mapper  meta.RESTMapper
+	Fetcher *Fetcher
+
+	// PublicKey verifies the documents. Every document is refused without it.
+	PublicKey crypto.PublicKey
+
+	// AllowedKinds are the kinds of the objects that are applied.
+	AllowedKinds []schema.GroupKind
+	// AllowedNamespaces are the namespaces objects are applied to besides
+	// the namespace of the source.
+	AllowedNamespaces []string
+}
+
+// RequestResult is the outcome of fetching a request.
+type RequestResult struct {
+	// URL is the url of the request.
+	URL string
+	// StatusCode is the http status of the request document.
+	StatusCode int
+	// ContentHash is the hex encoded sha256 of the request document.
+	ContentHash string
+	// Objects are the objects of the request and of the manifests it lists.
+	Objects []*unstructured.Unstructured
+}
+
+// FetchRequest fetches the objects of a request. An index is followed one
+// level deep. The result is returned with the error as far as it is known.
+func (s *Syncer) FetchRequest(
+	ctx context.Context,
+	request marketplacev1alpha1.Request,
+) (*RequestResult, error) {
+	result := &RequestResult{URL: RequestURL(request.Options)}
+	if result.URL == "" {
+		return result, errors.New("request has no url")
+	}
+
+	doc, err := s.fetchVerified(ctx, result.URL, request.Options.Headers)
+	if doc != nil {
+		result.StatusCode = doc.StatusCode
+		if doc.Data != nil {
+			result.ContentHash = hexSHA256(doc.Data)
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+
+	objs, index, err := ParseDocument(doc.Data)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to parse %s", result.URL)
+	}
+
+	if index == nil {
+		result.Objects = objs
+		return result, nil
+	}
+
+	base, err := url.Parse(result.URL)
+	if err != nil {
+		return result, errors.Wrapf(err, "failed to parse url %s", result.URL)
+	}
+
+	for _, entry := range index.Manifests {
+		ref, err := url.Parse(entry.URL)
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to parse url %s", entry.URL)
+		}
+		entryURL := base.ResolveReference(ref).String()
+
+		var doc *Document
+
+		if entry.SHA256 != "" {
+			doc, err = s.Fetcher.Fetch(ctx, entryURL, request.Options.Headers)
+			if err != nil {
+				return result, err
+			}
+
+			if err := VerifyDigest(doc.Data, entry.SHA256); err != nil {
+				return result, &VerificationError{URL: entryURL, Err: err}
+			}
+		} else {
+			doc, err = s.fetchVerified(ctx, entryURL, request.Options.Headers)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		entryObjs, entryIndex, err := ParseDocument(doc.Data)
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to parse %s", entryURL)
+		}
+
+		if entryIndex != nil {
+			return result, errors.Errorf("manifest index %s lists another index %s", result.URL, entryURL)
+		}
+
+		objs = append(objs, entryObjs...)
+	}
+
+	result.Objects = objs
+	return result, nil
+}
+
+// fetchVerified fetches the document and checks it against its signature.
+func (s *Syncer) fetchVerified(
+	ctx context.Context,
+	rawURL string,
+	headers map[string]marketplacev1alpha1.Header,
+) (*Document, error) {
+	doc, err := s.Fetcher.Fetch(ctx, rawURL, headers)
+	if err != nil {
+		return doc, err
+	}
+
+	if s.PublicKey == nil {
+		return doc, &VerificationError{URL: rawURL, Err: errors.New("no public key to verify the signature")}
+	}
+
+	signature, err := s.Fetcher.Fetch(ctx, rawURL+SignatureSuffix, headers)
+	if err != nil {
+		return doc, &VerificationError{URL: rawURL, Err: err}
+	}
+
+	if err := VerifySignature(doc.Data, signature.Data, s.PublicKey); err != nil {
+		return doc, &VerificationError{URL: rawURL, Err: err}
+	}
+
+	return doc, nil
+}
+
+// Apply applies the objects with server side apply. Namespaced objects
+// without a namespace are applied to the namespace of the source. Nothing is
+// applied if an object is not allowed.
+func (s *Syncer) Apply(
+	ctx context.Context,
+	source *marketplacev1alpha1.RemoteResourceS3,
+	objs []*unstructured.Unstructured,
+) ([]marketplacev1alpha1.AppliedObject, error) {
+	applied := make([]marketplacev1alpha1.AppliedObject, 0, len(objs))
+
+	for _, obj := range objs {
+		if err := s.allow(source, obj); err != nil {
+			return applied, err
+		}
+	}
+
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[SourceAnnotation] = sourceOf(source)
+		obj.SetAnnotations(annotations)
+
+		err := s.Client.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldOwner), client.ForceOwnership)
+		if err != nil {
+			return applied, errors.Wrapf(err, "failed to apply %s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+
+		applied = append(applied, AppliedObjectOf(obj))
+	}
+
+	return applied, nil
+}
+
+// allow defaults the namespace of the object and checks it is one of the
+// allowed kinds in an allowed namespace. Cluster scoped objects are never
+// applied.
+func (s *Syncer) allow(
+	source *marketplacev1alpha1.RemoteResourceS3,
+	obj *unstructured.Unstructured,
+) error {
+	gvk := obj.GroupVersionKind()
+
+	mapping, err := s.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find resource of %s", gvk)
+	}
+
+	notAllowed := func(reason string) error {
+		return &NotAllowedError{
+			GroupKind: gvk.GroupKind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Reason:    reason,
+		}
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return notAllowed("cluster scoped objects are not applied")
+	}
+
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(source.Namespace)
+	}
+
+	if !s.kindAllowed(gvk.GroupKind()) {
+		return notAllowed("kind is not allowed")
+	}
+
+	if !s.namespaceAllowed(source, obj.GetNamespace()) {
+		return notAllowed("namespace is not allowed")
+	}
+
+	return nil
+}
+
+func (s *Syncer) kindAllowed(gk schema.GroupKind) bool {
+	for _, allowed := range s.AllowedKinds {
+		if allowed == gk {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Syncer) namespaceAllowed(source *marketplacev1alpha1.RemoteResourceS3, namespace string) bool {
+	if namespace == source.Namespace {
+		return true
+	}
+
+	for _, allowed := range s.AllowedNamespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune deletes the previously applied objects that are no longer applied.
+// An object is only deleted while it is still the object applied from the
+// source, one that was replaced or taken over by another source is kept.
+func (s *Syncer) Prune(
+	ctx context.Context,
+	source *marketplacev1alpha1.RemoteResourceS3,
+	previous, current []marketplacev1alpha1.AppliedObject,
+) error {
+	keep := map[marketplacev1alpha1.AppliedObject]bool{}
+	for _, obj := range current {
+		keep[AppliedObjectKey(obj)] = true
+	}
+
+	for _, old := range previous {
+		if keep[AppliedObjectKey(old)] {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(old.APIVersion)
+		obj.SetKind(old.Kind)
+
+		err := s.Client.Get(ctx, types.NamespacedName{Namespace: old.Namespace, Name: old.Name}, obj)
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s %s/%s", old.Kind, old.Namespace, old.Name)
+		}
+
+		if obj.GetAnnotations()[SourceAnnotation] != sourceOf(source) {
+			continue
+		}
+
+		if old.UID != "" && obj.GetUID() != old.UID {
+			continue
+		}
+
+		err = s.Client.Delete(ctx, obj,
+			client.PropagationPolicy(metav1.DeletePropagationBackground),
+			client.Preconditions(*metav1.NewUIDPreconditions(string(obj.GetUID()))),
+		)
+		if err != nil && !k8serrors.IsNotFound(err) && !k8serrors.IsConflict(err) {
+			return errors.Wrapf(err, "failed to prune %s %s/%s", old.Kind, old.Namespace, old.Name)
+		}
+	}
+
+	return nil
+}
+
+func sourceOf(source *marketplacev1alpha1.RemoteResourceS3) string {
+	return source.Namespace + "/" + source.Name
+}
+
+// AppliedObjectOf returns the reference of an applied object.
+func AppliedObjectOf(obj *unstructured.Unstructured) marketplacev1alpha1.AppliedObject {
+	return marketplacev1alpha1.AppliedObject{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		UID:        obj.GetUID(),
+	}
+}
+
+// AppliedObjectKey returns the reference without the uid, it identifies the
+// object across deletes.
+func AppliedObjectKey(obj marketplacev1alpha1.AppliedObject) marketplacev1alpha1.AppliedObject {
+	obj.UID = ""
+	return obj
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake is a stand-in for the marketplace API. It serves the
+// registration status, the rhm-operator-secret and report uploads so the
+// registration and upload flows can run without marketplace.redhat.com.
+package fake
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+var logger = logf.Log.WithName("fake_marketplace")
+
+// OperatorSecretName is the name of the secret returned by the server.
+const OperatorSecretName = "rhm-operator-secret"
+
+// Upload is a report received by the server.
+type Upload struct {
+	FileName string
+	Data     []byte
+}
+
+// Server is a fake marketplace. It is safe for concurrent use.
+type Server struct {
+	// Token is the bearer token the requests must carry. Any token is
+	// accepted when empty.
+	Token string
+
+	mutex          sync.Mutex
+	registrations  []marketplace.RegisteredAccount
+	operatorSecret *corev1.Secret
+	uploads        []Upload
+	mux            *http.ServeMux
+}
+
+var _ http.Handler = &Server{}
+
+// NewServer returns a fake marketplace with a default rhm-operator-secret
+// and no registered clusters.
+func NewServer() *Server {
+	s := &Server{
+		operatorSecret: &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Secret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: OperatorSecretName,
+			},
+			Data: map[string][]byte{
+				"BUCKET_NAME":              []byte("bucket"),
+				"CHILD_RRS3_YAML_FILENAME": []byte("child"),
+				"FILE_SOURCE_URL":          []byte("http://localhost"),
+				"IBM_COS_READER_KEY":       []byte("key"),
+				"IBM_COS_URL":              []byte("http://localhost"),
+				"RAZEE_DASH_ORG_KEY":       []byte("org"),
+				"RAZEE_DASH_URL":           []byte("http://localhost"),
+			},
+		},
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/"+marketplace.RegistrationEndpoint, s.handleRegistration)
+	s.mux.HandleFunc("/"+marketplace.PullSecretEndpoint, s.handleOperatorSecret)
+	s.mux.HandleFunc("/"+marketplace.UploadEndpoint, s.handleUpload)
+
+	return s
+}
+
+// NewTLSServer starts a TLS server for the fake marketplace. The caller
+// should close the server when finished.
+func NewTLSServer(s *Server) *httptest.Server {
+	server := httptest.NewUnstartedServer(s)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	return server
+}
+
+// SetRegistration adds or replaces the registration of a cluster.
+func (s *Server) SetRegistration(accountID, clusterUUID, status string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.registrations {
+		if s.registrations[i].Uuid == clusterUUID {
+			s.registrations[i].AccountId = accountID
+			s.registrations[i].Status = status
+			return
+		}
+	}
+
+	s.registrations = append(s.registrations, marketplace.RegisteredAccount{
+		AccountId: accountID,
+		Uuid:      clusterUUID,
+		Status:    status,
+	})
+}
+
+// SetOperatorSecret replaces the rhm-operator-secret returned by the server.
+func (s *Server) SetOperatorSecret(secret *corev1.Secret) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.operatorSecret = secret.DeepCopy()
+}
+
+// Uploads returns the reports uploaded to the server.
+func (s *Server) Uploads() []Upload {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Upload{}, s.uploads...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleRegistration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	clusterUUID := r.URL.Query().Get("uuid")
+
+	s.mutex.Lock()
+	registrations := []map[string]string{}
+	for _, registration := range s.registrations {
+		if registration.AccountId != accountID || (clusterUUID != "" && registration.Uuid != clusterUUID) {
+			continue
+		}
+
+		registrations = append(registrations, map[string]string{
+			"accountId": registration.AccountId,
+			"uuid":      registration.Uuid,
+			"status":    registration.Status,
+		})
+	}
+	s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(registrations); err != nil {
+		logger.Error(err, "failed to write registrations")
+	}
+}
+
+func (s *Server) handleOperatorSecret(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	data, err := yaml.Marshal(s.operatorSecret)
+	s.mutex.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err := w.Write(data); err != nil {
+		logger.Error(err, "failed to write operator secret")
+	}
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	received := []Upload{}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if part.FormName() != "file" {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		received = append(received, Upload{
+			FileName: part.FileName(),
+			Data:     data,
+		})
+	}
+
+	if len(received) == 0 {
+		http.Error(w, "file is missing", http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.uploads = append(s.uploads, received...)
+	s.mutex.Unlock()
+
+	names := []string{}
+	for _, upload := range received {
+		names = append(names, upload.FileName)
+	}
+
+	logger.Info("received upload", "files", strings.Join(names, ","))
+	w.WriteHeader(http.StatusAccepted)
+}
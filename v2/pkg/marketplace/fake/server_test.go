@@ -0,0 +1,116 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/marketplace"
+)
+
+var _ = Describe("FakeMarketplace", func() {
+	var (
+		fake    *Server
+		server  *httptest.Server
+		backend marketplace.MarketplaceBackend
+		account *marketplace.MarketplaceClientAccount
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		fake = NewServer()
+		fake.Token = "token"
+		server = NewTLSServer(fake)
+
+		cfg := &config.OperatorConfig{}
+		cfg.Marketplace.URL = server.URL
+		cfg.Marketplace.InsecureClient = true
+
+		backend, err = marketplace.ProvideMarketplaceBackendProvider(cfg).NewMarketplaceBackend("token")
+		Expect(err).To(Succeed())
+
+		account = &marketplace.MarketplaceClientAccount{
+			AccountId:   "accountid",
+			ClusterUuid: "cluster",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should serve the registration status", func() {
+		out, err := backend.RegistrationStatus(account)
+		Expect(err).To(Succeed())
+		Expect(out.RegistrationStatus).To(Equal("UNREGISTERED"))
+
+		fake.SetRegistration("accountid", "cluster", marketplace.RegistrationStatusInstalled)
+
+		out, err = backend.RegistrationStatus(account)
+		Expect(err).To(Succeed())
+		Expect(out.RegistrationStatus).To(Equal(marketplace.RegistrationStatusInstalled))
+	})
+
+	It("should serve the operator secret", func() {
+		secret, err := backend.GetMarketplaceSecret()
+		Expect(err).To(Succeed())
+		Expect(secret.Name).To(Equal(OperatorSecretName))
+		Expect(secret.Data).To(HaveKey("RAZEE_DASH_URL"))
+	})
+
+	It("should reject the wrong token", func() {
+		cfg := &config.OperatorConfig{}
+		cfg.Marketplace.URL = server.URL
+		cfg.Marketplace.InsecureClient = true
+
+		other, err := marketplace.ProvideMarketplaceBackendProvider(cfg).NewMarketplaceBackend("other")
+		Expect(err).To(Succeed())
+
+		out, _ := other.RegistrationStatus(account)
+		Expect(out.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should record uploads", func() {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "report.tar.gz")
+		Expect(err).To(Succeed())
+		_, err = part.Write([]byte("data"))
+		Expect(err).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/"+marketplace.UploadEndpoint, body)
+		Expect(err).To(Succeed())
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer token")
+
+		resp, err := server.Client().Do(req)
+		Expect(err).To(Succeed())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+
+		Expect(fake.Uploads()).To(ConsistOf(Upload{
+			FileName: "report.tar.gz",
+			Data:     []byte("data"),
+		}))
+	})
+})
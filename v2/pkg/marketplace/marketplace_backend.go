@@ -0,0 +1,58 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MarketplaceBackend is the set of marketplace calls used by the operator.
+type MarketplaceBackend interface {
+	RegistrationStatus(account *MarketplaceClientAccount) (RegistrationStatusOutput, error)
+	GetMarketplaceSecret() (*corev1.Secret, error)
+}
+
+var _ MarketplaceBackend = &MarketplaceClient{}
+
+// MarketplaceBackendProvider creates a backend authenticated with the
+// token from the redhat-marketplace-pull-secret.
+type MarketplaceBackendProvider interface {
+	NewMarketplaceBackend(token string) (MarketplaceBackend, error)
+}
+
+type marketplaceClientProvider struct {
+	cfg *config.OperatorConfig
+}
+
+// ProvideMarketplaceBackendProvider returns a provider of clients for the
+// marketplace configured on the operator.
+func ProvideMarketplaceBackendProvider(cfg *config.OperatorConfig) MarketplaceBackendProvider {
+	return &marketplaceClientProvider{cfg: cfg}
+}
+
+func (p *marketplaceClientProvider) NewMarketplaceBackend(token string) (MarketplaceBackend, error) {
+	httpTransport, err := p.cfg.Infrastructure.Transport()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMarketplaceClient(&MarketplaceClientConfig{
+		Url:       p.cfg.Marketplace.URL,
+		Token:     token,
+		Insecure:  p.cfg.Marketplace.InsecureClient,
+		Transport: httpTransport,
+	})
+}
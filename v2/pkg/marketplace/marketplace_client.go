@@ -16,16 +16,17 @@ package marketplace
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	ioutil "io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"emperror.dev/errors"
 	jwt "github.com/dgrijalva/jwt-go"
 	marketplacev1alpha1 "github.com/redhat-marketplace/redhat-marketplace-operator/v2/apis/marketplace/v1alpha1"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
 	status "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/status"
 	corev1 "k8s.io/api/core/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -40,8 +41,9 @@ const (
 
 // endpoints
 const (
-	pullSecretEndpoint   = "provisioning/v1/rhm-operator/rhm-operator-secret"
-	registrationEndpoint = "provisioning/v1/registered-clusters"
+	PullSecretEndpoint   = "provisioning/v1/rhm-operator/rhm-operator-secret"
+	RegistrationEndpoint = "provisioning/v1/registered-clusters"
+	UploadEndpoint       = "api/ingress/v1/upload"
 )
 
 const (
@@ -52,6 +54,9 @@ type MarketplaceClientConfig struct {
 	Url      string
 	Token    string
 	Insecure bool
+	// Transport is the shared outbound transport. A transport that uses the
+	// environment proxy is built when nil.
+	Transport *http.Transport
 }
 
 type MarketplaceClientAccount struct {
@@ -71,27 +76,26 @@ type RegisteredAccount struct {
 }
 
 func NewMarketplaceClient(clientConfig *MarketplaceClientConfig) (*MarketplaceClient, error) {
-	var tlsConfig *tls.Config
+	httpTransport := clientConfig.Transport
 
-	if clientConfig.Insecure {
-		tlsConfig = &tls.Config{InsecureSkipVerify: true}
-	} else {
-		caCertPool, err := x509.SystemCertPool()
+	switch {
+	case httpTransport == nil:
+		var err error
+		httpTransport, err = transport.NewTransport(&transport.Config{
+			InsecureSkipVerify: clientConfig.Insecure,
+		})
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to get cert pool")
-		}
-
-		tlsConfig = &tls.Config{
-			RootCAs: caCertPool,
+			return nil, errors.Wrap(err, "failed to build transport")
 		}
+	case clientConfig.Insecure:
+		httpTransport = httpTransport.Clone()
+		httpTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
 
-	var transport http.RoundTripper = &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}
+	var rt http.RoundTripper = httpTransport
 
 	if clientConfig.Token != "" {
-		transport = WithBearerAuth(transport, clientConfig.Token)
+		rt = WithBearerAuth(rt, clientConfig.Token)
 	}
 
 	u, err := url.Parse(clientConfig.Url)
@@ -102,7 +106,7 @@ func NewMarketplaceClient(clientConfig *MarketplaceClientConfig) (*MarketplaceCl
 	return &MarketplaceClient{
 		endpoint: u,
 		httpClient: http.Client{
-			Transport: transport,
+			Transport: rt,
 		},
 	}, nil
 }
@@ -170,7 +174,7 @@ func (m *MarketplaceClient) RegistrationStatus(account *MarketplaceClientAccount
 		return RegistrationStatusOutput{Err: err}, err
 	}
 
-	u, err := buildQuery(m.endpoint, registrationEndpoint,
+	u, err := buildQuery(m.endpoint, RegistrationEndpoint,
 		"accountId", account.AccountId,
 		"uuid", account.ClusterUuid)
 
@@ -284,7 +288,7 @@ func (resp RegistrationStatusOutput) TransformConfigStatus() status.Conditions {
 }
 
 func (mhttp *MarketplaceClient) GetMarketplaceSecret() (*corev1.Secret, error) {
-	u, err := buildQuery(mhttp.endpoint, pullSecretEndpoint)
+	u, err := buildQuery(mhttp.endpoint, PullSecretEndpoint)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build query")
@@ -322,19 +326,40 @@ type MarketplaceClaims struct {
 
 // GetAccountIdFromJWTToken will parse JWT token and fetch the rhmAccountId
 func GetAccountIdFromJWTToken(jwtToken string) (string, error) {
+	claims, err := GetJWTTokenClaims(jwtToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	return claims.AccountID, nil
+}
+
+// GetJWTTokenClaims will parse JWT token and return the marketplace claims
+func GetJWTTokenClaims(jwtToken string) (*MarketplaceClaims, error) {
 	// TODO: add verification of public key
 	//token, err := jwt.Parse(jwtToken, nil)
 	token, _, err := new(jwt.Parser).ParseUnverified(jwtToken, &MarketplaceClaims{})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*MarketplaceClaims)
 
 	if !ok {
-		return "", errors.New("token claims is not *MarketplaceClaims")
+		return nil, errors.New("token claims is not *MarketplaceClaims")
 	}
 
-	return claims.AccountID, nil
+	return claims, nil
+}
+
+// ExpiresAt returns the expiry time of the token, or nil if it does not expire.
+func (c *MarketplaceClaims) ExpiresAt() *time.Time {
+	if c.StandardClaims.ExpiresAt == 0 {
+		return nil
+	}
+
+	expiresAt := time.Unix(c.StandardClaims.ExpiresAt, 0).UTC()
+	return &expiresAt
 }
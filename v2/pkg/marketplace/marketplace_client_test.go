@@ -18,7 +18,9 @@ import (
 	"crypto/tls"
 	ioutil "io/ioutil"
 	"net/http"
+	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
@@ -68,7 +70,7 @@ var _ = Describe("Marketplace Config Status", func() {
 	Context("Marketplace Pull Secret without any error", func() {
 		BeforeEach(func() {
 			statusCode = 200
-			path = "/" + pullSecretEndpoint
+			path = "/" + PullSecretEndpoint
 			body, err := ioutil.ReadFile("../../tests/mockresponses/marketplace-pull-secret.yaml")
 			if err != nil {
 				panic(err)
@@ -97,10 +99,31 @@ var _ = Describe("Marketplace Config Status", func() {
 		})
 	})
 
+	Context("token claims", func() {
+		It("should read the account and expiry", func() {
+			expiresAt := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &MarketplaceClaims{
+				AccountID: "accountid",
+				StandardClaims: jwt.StandardClaims{
+					ExpiresAt: expiresAt.Unix(),
+				},
+			}).SignedString([]byte("secret"))
+			Expect(err).ToNot(HaveOccurred())
+
+			claims, err := GetJWTTokenClaims(token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claims.AccountID).To(Equal("accountid"))
+			Expect(claims.ExpiresAt()).To(Equal(&expiresAt))
+
+			claims.StandardClaims.ExpiresAt = 0
+			Expect(claims.ExpiresAt()).To(BeNil())
+		})
+	})
+
 	Context("Cluster Registration Status is INSTALLED", func() {
 		BeforeEach(func() {
 			statusCode = 200
-			path = "/" + registrationEndpoint
+			path = "/" + RegistrationEndpoint
 
 			body, _ = ioutil.ReadFile("../../tests/mockresponses/registration-response.json")
 			server.AppendHandlers(
@@ -119,7 +142,7 @@ var _ = Describe("Marketplace Config Status", func() {
 	Context("Cluster Registration Status is blank", func() {
 		BeforeEach(func() {
 			statusCode = 200
-			path = "/" + registrationEndpoint
+			path = "/" + RegistrationEndpoint
 			body = []byte("[]")
 			server.AppendHandlers(
 				ghttp.CombineHandlers(
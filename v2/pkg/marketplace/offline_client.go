@@ -0,0 +1,148 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+
+	"emperror.dev/errors"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// OfflineActivation is the activation file downloaded from Red Hat Marketplace
+// to register a cluster that has no access to the marketplace.
+type OfflineActivation struct {
+	AccountID   string `json:"accountId"`
+	ClusterUUID string `json:"clusterUuid,omitempty"`
+	// Status is the registration status of the cluster. Default is INSTALLED.
+	Status string `json:"status,omitempty"`
+	// OperatorSecret is the data of the rhm-operator-secret.
+	OperatorSecret map[string][]byte `json:"operatorSecret,omitempty"`
+}
+
+// SignedOfflineActivation is the activation file as downloaded from Red Hat
+// Marketplace: the activation and the marketplace signature over it.
+type SignedOfflineActivation struct {
+	Activation []byte `json:"activation"`
+	Signature  []byte `json:"signature"`
+}
+
+// OfflineMarketplaceClient answers marketplace calls from an offline activation file.
+type OfflineMarketplaceClient struct {
+	activation OfflineActivation
+}
+
+var _ MarketplaceBackend = &OfflineMarketplaceClient{}
+
+// SignOfflineActivation returns a signed activation file for activation.
+func SignOfflineActivation(activation *OfflineActivation, signer crypto.Signer) ([]byte, error) {
+	data, err := json.Marshal(activation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal activation")
+	}
+
+	signature, err := Sign(data, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&SignedOfflineActivation{
+		Activation: data,
+		Signature:  signature,
+	})
+}
+
+// NewOfflineMarketplaceClient reads a signed offline activation file in json or yaml.
+// The activation has to be signed by key, the Red Hat Marketplace public key.
+func NewOfflineMarketplaceClient(activationFile []byte, key crypto.PublicKey) (*OfflineMarketplaceClient, error) {
+	signed := SignedOfflineActivation{}
+
+	if err := yaml.Unmarshal(activationFile, &signed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse activation file")
+	}
+
+	if len(signed.Activation) == 0 || len(signed.Signature) == 0 {
+		return nil, errors.New("activation file is not signed")
+	}
+
+	if err := VerifySignature(signed.Activation, signed.Signature, key); err != nil {
+		return nil, errors.Wrap(err, "failed to verify activation file")
+	}
+
+	activation := OfflineActivation{}
+
+	if err := json.Unmarshal(signed.Activation, &activation); err != nil {
+		return nil, errors.Wrap(err, "failed to parse activation")
+	}
+
+	if activation.AccountID == "" {
+		return nil, errors.New("activation file is missing the account id")
+	}
+
+	if activation.Status == "" {
+		activation.Status = RegistrationStatusInstalled
+	}
+
+	return &OfflineMarketplaceClient{activation: activation}, nil
+}
+
+func (m *OfflineMarketplaceClient) RegistrationStatus(account *MarketplaceClientAccount) (RegistrationStatusOutput, error) {
+	if account == nil {
+		err := errors.New("account info missing")
+		return RegistrationStatusOutput{Err: err}, err
+	}
+
+	if account.AccountId != m.activation.AccountID ||
+		(m.activation.ClusterUUID != "" && account.ClusterUuid != m.activation.ClusterUUID) {
+		return RegistrationStatusOutput{
+			StatusCode:         http.StatusOK,
+			RegistrationStatus: "UNREGISTERED",
+		}, nil
+	}
+
+	return RegistrationStatusOutput{
+		StatusCode: http.StatusOK,
+		Registration: &RegisteredAccount{
+			AccountId: account.AccountId,
+			Uuid:      account.ClusterUuid,
+			Status:    m.activation.Status,
+		},
+		RegistrationStatus: m.activation.Status,
+	}, nil
+}
+
+func (m *OfflineMarketplaceClient) GetMarketplaceSecret() (*corev1.Secret, error) {
+	if len(m.activation.OperatorSecret) == 0 {
+		return nil, errors.New("activation file is missing the operator secret")
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: utils.RHMOperatorSecretName,
+		},
+		Data: map[string][]byte{},
+	}
+
+	for k, v := range m.activation.OperatorSecret {
+		secret.Data[k] = v
+	}
+
+	return secret, nil
+}
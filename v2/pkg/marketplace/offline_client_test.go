@@ -0,0 +1,125 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package marketplace
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils"
+)
+
+var _ = Describe("OfflineMarketplaceClient", func() {
+	var (
+		key            *ecdsa.PrivateKey
+		activationFile []byte
+
+		account = &MarketplaceClientAccount{
+			AccountId:   "accountid",
+			ClusterUuid: "cluster",
+		}
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(Succeed())
+
+		activationFile, err = SignOfflineActivation(&OfflineActivation{
+			AccountID:   "accountid",
+			ClusterUUID: "cluster",
+			OperatorSecret: map[string][]byte{
+				"RAZEE_DASH_URL": []byte("http://localhost"),
+			},
+		}, key)
+		Expect(err).To(Succeed())
+	})
+
+	It("should require an account id", func() {
+		file, err := SignOfflineActivation(&OfflineActivation{ClusterUUID: "cluster"}, key)
+		Expect(err).To(Succeed())
+
+		_, err = NewOfflineMarketplaceClient(file, key.Public())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should require a marketplace signature", func() {
+		_, err := NewOfflineMarketplaceClient([]byte(`accountId: accountid`), key.Public())
+		Expect(err).To(HaveOccurred())
+
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).To(Succeed())
+
+		_, err = NewOfflineMarketplaceClient(activationFile, other.Public())
+		Expect(err).To(HaveOccurred())
+
+		signed := &SignedOfflineActivation{}
+		Expect(json.Unmarshal(activationFile, signed)).To(Succeed())
+		signed.Activation = []byte(`{"accountId":"other"}`)
+		tampered, err := json.Marshal(signed)
+		Expect(err).To(Succeed())
+
+		_, err = NewOfflineMarketplaceClient(tampered, key.Public())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should parse the marketplace public key", func() {
+		der, err := x509.MarshalPKIXPublicKey(key.Public())
+		Expect(err).To(Succeed())
+
+		pub, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		Expect(err).To(Succeed())
+		Expect(pub).To(Equal(key.Public()))
+	})
+
+	It("should register the activated cluster", func() {
+		mclient, err := NewOfflineMarketplaceClient(activationFile, key.Public())
+		Expect(err).To(Succeed())
+
+		out, err := mclient.RegistrationStatus(account)
+		Expect(err).To(Succeed())
+		Expect(out.RegistrationStatus).To(Equal(RegistrationStatusInstalled))
+		Expect(out.Registration.AccountId).To(Equal("accountid"))
+	})
+
+	It("should not register other clusters", func() {
+		mclient, err := NewOfflineMarketplaceClient(activationFile, key.Public())
+		Expect(err).To(Succeed())
+
+		out, err := mclient.RegistrationStatus(&MarketplaceClientAccount{
+			AccountId:   "accountid",
+			ClusterUuid: "other",
+		})
+		Expect(err).To(Succeed())
+		Expect(out.RegistrationStatus).To(Equal("UNREGISTERED"))
+	})
+
+	It("should return the operator secret", func() {
+		mclient, err := NewOfflineMarketplaceClient(activationFile, key.Public())
+		Expect(err).To(Succeed())
+
+		secret, err := mclient.GetMarketplaceSecret()
+		Expect(err).To(Succeed())
+		Expect(secret.Name).To(Equal(utils.RHMOperatorSecretName))
+		Expect(secret.Data).To(HaveKeyWithValue("RAZEE_DASH_URL", []byte("http://localhost")))
+	})
+})
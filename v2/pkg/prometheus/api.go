@@ -0,0 +1,235 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/transport"
+)
+
+// APIClient reads the TSDB status, series counts and disk usage of a Prometheus server.
+type APIClient struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// NewAPIClient returns a client for the Prometheus at address. The token is
+// sent as a bearer token and caData is trusted in addition to the cluster CA bundle.
+func NewAPIClient(address, token string, caData []byte) (*APIClient, error) {
+	rt, err := transport.NewTransport(&transport.Config{CAData: caData})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transport")
+	}
+
+	return &APIClient{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  &http.Client{Transport: rt},
+	}, nil
+}
+
+// TSDBStatus is the cardinality of the Prometheus head block.
+type TSDBStatus struct {
+	HeadStats               TSDBHeadStats `json:"headStats"`
+	SeriesCountByMetricName []TSDBStat    `json:"seriesCountByMetricName"`
+}
+
+// TSDBHeadStats are the totals of the head block.
+type TSDBHeadStats struct {
+	NumSeries     uint64 `json:"numSeries"`
+	ChunkCount    int64  `json:"chunkCount"`
+	MinTime       int64  `json:"minTime"`
+	MaxTime       int64  `json:"maxTime"`
+	NumLabelPairs int    `json:"numLabelPairs"`
+}
+
+// TSDBStat is the series count of a metric name.
+type TSDBStat struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+type vectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+type queryData struct {
+	ResultType string         `json:"resultType"`
+	Result     []vectorSample `json:"result"`
+}
+
+// TSDBStatus returns the head block statistics of the /api/v1/status/tsdb endpoint.
+func (c *APIClient) TSDBStatus(ctx context.Context) (*TSDBStatus, error) {
+	status := &TSDBStatus{}
+	if err := c.get(ctx, "/api/v1/status/tsdb", nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// SeriesCount returns the number of series matched by any of the selectors.
+func (c *APIClient) SeriesCount(ctx context.Context, selectors []string) (uint64, error) {
+	if len(selectors) == 0 {
+		return 0, nil
+	}
+
+	query := fmt.Sprintf(`count(%s)`, strings.Join(selectors, " or "))
+
+	data := &queryData{}
+	if err := c.get(ctx, "/api/v1/query", url.Values{"query": []string{query}}, data); err != nil {
+		return 0, err
+	}
+
+	// no series matched
+	if len(data.Result) == 0 || len(data.Result[0].Value) != 2 {
+		return 0, nil
+	}
+
+	value, ok := data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, errors.Errorf("invalid series count %v", data.Result[0].Value[1])
+	}
+
+	count, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid series count %s", value)
+	}
+
+	return uint64(count), nil
+}
+
+// Self-metrics holding the bytes Prometheus writes to its volume
+const (
+	tsdbBlocksBytesMetric     = "prometheus_tsdb_storage_blocks_bytes"
+	tsdbWALBytesMetric        = "prometheus_tsdb_wal_storage_size_bytes"
+	tsdbHeadChunksBytesMetric = "prometheus_tsdb_head_chunks_storage_size_bytes"
+)
+
+// StorageUsage returns the bytes used by the TSDB blocks, the write-ahead log
+// and the head chunks, read from the self-metrics of the Prometheus server.
+func (c *APIClient) StorageUsage(ctx context.Context) (int64, error) {
+	resp, err := c.do(ctx, "/metrics", nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("failed to get /metrics, status %s", resp.Status)
+	}
+
+	values, err := parseMetricValues(bufio.NewScanner(resp.Body),
+		tsdbBlocksBytesMetric, tsdbWALBytesMetric, tsdbHeadChunksBytesMetric)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := values[tsdbBlocksBytesMetric]; !ok {
+		return 0, errors.Errorf("metric %s not found", tsdbBlocksBytesMetric)
+	}
+
+	var used int64
+	for _, value := range values {
+		used += int64(value)
+	}
+
+	return used, nil
+}
+
+// parseMetricValues reads the unlabeled samples of names from the text
+// exposition format.
+func parseMetricValues(scanner *bufio.Scanner, names ...string) (map[string]float64, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	values := map[string]float64{}
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !wanted[fields[0]] {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of %s", fields[0])
+		}
+
+		values[fields[0]] = value
+	}
+
+	return values, scanner.Err()
+}
+
+func (c *APIClient) do(ctx context.Context, path string, params url.Values) (*http.Response, error) {
+	u := c.address + path
+	if len(params) > 0 {
+		u = u + "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s", path)
+	}
+
+	return resp, nil
+}
+
+func (c *APIClient) get(ctx context.Context, path string, params url.Values, data interface{}) error {
+	resp, err := c.do(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	result := &apiResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return errors.Wrapf(err, "failed to decode %s, status %s", path, resp.Status)
+	}
+
+	if result.Status != "success" {
+		return errors.Errorf("%s failed, %s: %s", path, result.ErrorType, result.Error)
+	}
+
+	return json.Unmarshal(result.Data, data)
+}
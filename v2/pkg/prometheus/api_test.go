@@ -0,0 +1,99 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIClient", func() {
+	var (
+		server *httptest.Server
+		client *APIClient
+		query  string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"status":"error","errorType":"unauthorized","error":"bad token"}`)
+				return
+			}
+
+			switch r.URL.Path {
+			case "/api/v1/status/tsdb":
+				fmt.Fprint(w, `{"status":"success","data":{"headStats":{"numSeries":1200},"seriesCountByMetricName":[{"name":"kube_pod_info","value":300}]}}`)
+			case "/api/v1/query":
+				query = r.URL.Query().Get("query")
+				fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"300"]}]}}`)
+			case "/metrics":
+				fmt.Fprint(w, `# HELP prometheus_tsdb_storage_blocks_bytes The number of bytes that are currently used for local storage by all blocks.
+# TYPE prometheus_tsdb_storage_blocks_bytes gauge
+prometheus_tsdb_storage_blocks_bytes 7.516192768e+09
+prometheus_tsdb_wal_storage_size_bytes 1.073741824e+09
+prometheus_tsdb_head_chunks 1200
+prometheus_http_requests_total{code="200",handler="/metrics"} 5
+`)
+			}
+		}))
+
+		var err error
+		client, err = NewAPIClient(server.URL+"/", "token", nil)
+		Expect(err).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should read the tsdb status", func() {
+		status, err := client.TSDBStatus(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(status.HeadStats.NumSeries).To(Equal(uint64(1200)))
+		Expect(status.SeriesCountByMetricName).To(Equal([]TSDBStat{{Name: "kube_pod_info", Value: 300}}))
+	})
+
+	It("should count the series of the selectors", func() {
+		count, err := client.SeriesCount(context.TODO(), []string{`{__name__="kube_pod_info",namespace="app"}`, `{__name__="job:up"}`})
+		Expect(err).To(Succeed())
+		Expect(count).To(Equal(uint64(300)))
+		Expect(query).To(Equal(`count({__name__="kube_pod_info",namespace="app"} or {__name__="job:up"})`))
+
+		query = ""
+		count, err = client.SeriesCount(context.TODO(), nil)
+		Expect(err).To(Succeed())
+		Expect(count).To(BeZero())
+		Expect(query).To(BeEmpty())
+	})
+
+	It("should read the storage usage", func() {
+		used, err := client.StorageUsage(context.TODO())
+		Expect(err).To(Succeed())
+		Expect(used).To(Equal(int64(8 * 1024 * 1024 * 1024)))
+	})
+
+	It("should return the prometheus error", func() {
+		client.token = "other"
+		_, err := client.TSDBStatus(context.TODO())
+		Expect(err).To(MatchError(ContainSubstring("bad token")))
+	})
+})
@@ -47,6 +47,14 @@ var (
 
 type configGenerator struct {
 	logger log.Logger
+	limits map[string]ScrapeLimit
+}
+
+// ScrapeLimit limits the samples and labels a target can return per scrape.
+// A scrape fails when it goes over a limit. Zero means no limit.
+type ScrapeLimit struct {
+	SampleLimit uint64
+	LabelLimit  uint64
 }
 
 func NewConfigGenerator(logger log.Logger) *configGenerator {
@@ -56,6 +64,13 @@ func NewConfigGenerator(logger log.Logger) *configGenerator {
 	return cg
 }
 
+// WithScrapeLimits sets the limits of the monitors by their identifier in
+// the monitor maps passed to GenerateConfig.
+func (cg *configGenerator) WithScrapeLimits(limits map[string]ScrapeLimit) *configGenerator {
+	cg.limits = limits
+	return cg
+}
+
 func sanitizeLabelName(name string) string {
 	return invalidLabelCharRE.ReplaceAllString(name, "_")
 }
@@ -70,6 +85,7 @@ func honorLabels(userHonorLabels, overrideHonorLabels bool) bool {
 func (cg *configGenerator) GenerateConfig(
 	p *v1.Prometheus,
 	sMons map[string]*v1.ServiceMonitor,
+	pMons map[string]*v1.PodMonitor,
 	basicAuthSecrets map[string]assets.BasicAuthCredentials,
 	bearerTokens map[string]assets.BearerToken,
 	ruleConfigMapNames []string,
@@ -129,28 +145,85 @@ func (cg *configGenerator) GenerateConfig(
 	// Sorting ensures, that we always generate the config in the same order.
 	sort.Strings(sMonIdentifiers)
 
+	pMonIdentifiers := make([]string, len(pMons))
+	i = 0
+	for k := range pMons {
+		pMonIdentifiers[i] = k
+		i++
+	}
+
+	sort.Strings(pMonIdentifiers)
+
 	apiserverConfig := p.Spec.APIServerConfig
 
 	var scrapeConfigs []yaml.MapSlice
 	for _, identifier := range sMonIdentifiers {
 		for i, ep := range sMons[identifier].Spec.Endpoints {
 			scrapeConfigs = append(scrapeConfigs,
-				cg.generateServiceMonitorConfig(
+				cg.addScrapeLimits(
 					version,
-					sMons[identifier],
-					ep, i,
-					apiserverConfig,
-					basicAuthSecrets,
-					bearerTokens,
-					p.Spec.OverrideHonorLabels,
-					p.Spec.OverrideHonorTimestamps,
-					p.Spec.IgnoreNamespaceSelectors))
+					cg.generateServiceMonitorConfig(
+						version,
+						sMons[identifier],
+						ep, i,
+						apiserverConfig,
+						basicAuthSecrets,
+						bearerTokens,
+						p.Spec.OverrideHonorLabels,
+						p.Spec.OverrideHonorTimestamps,
+						p.Spec.IgnoreNamespaceSelectors),
+					identifier,
+					sMons[identifier].Spec.SampleLimit))
+		}
+	}
+	for _, identifier := range pMonIdentifiers {
+		for i, ep := range pMons[identifier].Spec.PodMetricsEndpoints {
+			scrapeConfigs = append(scrapeConfigs,
+				cg.addScrapeLimits(
+					version,
+					cg.generatePodMonitorConfig(
+						version,
+						pMons[identifier],
+						ep, i,
+						apiserverConfig,
+						basicAuthSecrets,
+						bearerTokens,
+						p.Spec.OverrideHonorLabels,
+						p.Spec.OverrideHonorTimestamps,
+						p.Spec.IgnoreNamespaceSelectors),
+					identifier,
+					pMons[identifier].Spec.SampleLimit))
 		}
 	}
 
 	return yaml.Marshal(scrapeConfigs)
 }
 
+// addScrapeLimits adds the sample and label limits of the monitor to its
+// scrape config. The limit set with WithScrapeLimits takes precedence over
+// the sample limit of the monitor.
+func (cg *configGenerator) addScrapeLimits(version semver.Version, cfg yaml.MapSlice, identifier string, sampleLimit uint64) yaml.MapSlice {
+	limit := cg.limits[identifier]
+
+	if limit.SampleLimit > 0 {
+		sampleLimit = limit.SampleLimit
+	}
+
+	if sampleLimit > 0 {
+		cfg = append(cfg, yaml.MapItem{Key: "sample_limit", Value: sampleLimit})
+	}
+
+	if limit.LabelLimit > 0 {
+		if version.LT(semver.MustParse("2.27.0")) {
+			cg.logger.Info("label limit is set but it will not take effect because prometheus version is < 2.27", "monitor", identifier)
+		} else {
+			cfg = append(cfg, yaml.MapItem{Key: "label_limit", Value: limit.LabelLimit})
+		}
+	}
+
+	return cfg
+}
+
 func honorTimestamps(cfg yaml.MapSlice, userHonorTimestamps *bool, overrideHonorTimestamps bool) yaml.MapSlice {
 	// Ensuring backwards compatibility by checking if user set any option
 	if userHonorTimestamps == nil && !overrideHonorTimestamps {
@@ -459,6 +532,227 @@ func (cg *configGenerator) generateServiceMonitorConfig(
 	return cfg
 }
 
+func (cg *configGenerator) generatePodMonitorConfig(
+	version semver.Version,
+	m *v1.PodMonitor,
+	ep v1.PodMetricsEndpoint,
+	i int,
+	apiserverConfig *v1.APIServerConfig,
+	basicAuthSecrets map[string]assets.BasicAuthCredentials,
+	bearerTokens map[string]assets.BearerToken,
+	overrideHonorLabels bool,
+	overrideHonorTimestamps bool,
+	ignoreNamespaceSelectors bool) yaml.MapSlice {
+
+	hl := honorLabels(ep.HonorLabels, overrideHonorLabels)
+	cfg := yaml.MapSlice{
+		{
+			Key:   "job_name",
+			Value: fmt.Sprintf("%s/%s/%d", m.Namespace, m.Name, i),
+		},
+		{
+			Key:   "honor_labels",
+			Value: hl,
+		},
+	}
+	if version.Major == 2 && version.Minor >= 9 {
+		cfg = honorTimestamps(cfg, ep.HonorTimestamps, overrideHonorTimestamps)
+	}
+
+	if version.Major == 1 && version.Minor < 7 {
+		if apiserverConfig != nil {
+			cg.logger.Info("custom apiserver config is set but it will not take effect because prometheus version is < 1.7")
+		}
+		cfg = append(cfg, cg.generateK8SSDConfig(nil, nil, nil, kubernetesSDRolePod))
+	} else {
+		selectedNamespaces := getNamespacesFromNamespaceSelector(&m.Spec.NamespaceSelector, m.Namespace, ignoreNamespaceSelectors)
+		cfg = append(cfg, cg.generateK8SSDConfig(selectedNamespaces, apiserverConfig, basicAuthSecrets, kubernetesSDRolePod))
+	}
+
+	if ep.Interval != "" {
+		cfg = append(cfg, yaml.MapItem{Key: "scrape_interval", Value: ep.Interval})
+	}
+	if ep.ScrapeTimeout != "" {
+		cfg = append(cfg, yaml.MapItem{Key: "scrape_timeout", Value: ep.ScrapeTimeout})
+	}
+	if ep.Path != "" {
+		cfg = append(cfg, yaml.MapItem{Key: "metrics_path", Value: ep.Path})
+	}
+	if ep.ProxyURL != nil {
+		cfg = append(cfg, yaml.MapItem{Key: "proxy_url", Value: ep.ProxyURL})
+	}
+	if ep.Params != nil {
+		cfg = append(cfg, yaml.MapItem{Key: "params", Value: ep.Params})
+	}
+	if ep.Scheme != "" {
+		cfg = append(cfg, yaml.MapItem{Key: "scheme", Value: ep.Scheme})
+	}
+
+	if ep.TLSConfig != nil {
+		cfg = addTLStoYaml(cfg, m.Namespace, &v1.TLSConfig{SafeTLSConfig: ep.TLSConfig.SafeTLSConfig})
+	}
+
+	if ep.BearerTokenSecret.Name != "" {
+		if s, ok := bearerTokens[fmt.Sprintf("podMonitor/%s/%s/%d", m.Namespace, m.Name, i)]; ok {
+			cfg = append(cfg, yaml.MapItem{Key: "bearer_token", Value: s})
+		}
+	}
+
+	if ep.BasicAuth != nil {
+		if s, ok := basicAuthSecrets[fmt.Sprintf("podMonitor/%s/%s/%d", m.Namespace, m.Name, i)]; ok {
+			cfg = append(cfg, yaml.MapItem{
+				Key: "basic_auth", Value: yaml.MapSlice{
+					{Key: "username", Value: s.Username},
+					{Key: "password", Value: s.Password},
+				},
+			})
+		}
+	}
+
+	var relabelings []yaml.MapSlice
+
+	// Filter targets by pods selected by the monitor.
+
+	// Exact label matches.
+	var labelKeys []string
+	for k := range m.Spec.Selector.MatchLabels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+
+	for _, k := range labelKeys {
+		relabelings = append(relabelings, yaml.MapSlice{
+			{Key: "action", Value: "keep"},
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(k)}},
+			{Key: "regex", Value: m.Spec.Selector.MatchLabels[k]},
+		})
+	}
+	// Set based label matching. We have to map the valid relations
+	// `In`, `NotIn`, `Exists`, and `DoesNotExist`, into relabeling rules.
+	for _, exp := range m.Spec.Selector.MatchExpressions {
+		switch exp.Operator {
+		case metav1.LabelSelectorOpIn:
+			relabelings = append(relabelings, yaml.MapSlice{
+				{Key: "action", Value: "keep"},
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(exp.Key)}},
+				{Key: "regex", Value: strings.Join(exp.Values, "|")},
+			})
+		case metav1.LabelSelectorOpNotIn:
+			relabelings = append(relabelings, yaml.MapSlice{
+				{Key: "action", Value: "drop"},
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(exp.Key)}},
+				{Key: "regex", Value: strings.Join(exp.Values, "|")},
+			})
+		case metav1.LabelSelectorOpExists:
+			relabelings = append(relabelings, yaml.MapSlice{
+				{Key: "action", Value: "keep"},
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(exp.Key)}},
+				{Key: "regex", Value: ".+"},
+			})
+		case metav1.LabelSelectorOpDoesNotExist:
+			relabelings = append(relabelings, yaml.MapSlice{
+				{Key: "action", Value: "drop"},
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(exp.Key)}},
+				{Key: "regex", Value: ".+"},
+			})
+		}
+	}
+
+	// Filter targets based on correct port for the endpoint.
+	if ep.Port != "" {
+		relabelings = append(relabelings, yaml.MapSlice{
+			{Key: "action", Value: "keep"},
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_container_port_name"}},
+			{Key: "regex", Value: ep.Port},
+		})
+	} else if ep.TargetPort != nil {
+		if ep.TargetPort.StrVal != "" {
+			relabelings = append(relabelings, yaml.MapSlice{
+				{Key: "action", Value: "keep"},
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_container_port_name"}},
+				{Key: "regex", Value: ep.TargetPort.String()},
+			})
+		} else if ep.TargetPort.IntVal != 0 {
+			relabelings = append(relabelings, yaml.MapSlice{
+				{Key: "action", Value: "keep"},
+				{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_container_port_number"}},
+				{Key: "regex", Value: ep.TargetPort.String()},
+			})
+		}
+	}
+
+	// Relabel namespace and pod labels into proper labels.
+	relabelings = append(relabelings, []yaml.MapSlice{
+		{
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_namespace"}},
+			{Key: "target_label", Value: "namespace"},
+		},
+		{
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_container_name"}},
+			{Key: "target_label", Value: "container"},
+		},
+		{
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_name"}},
+			{Key: "target_label", Value: "pod"},
+		},
+	}...)
+
+	// Relabel targetLabels from Pod onto target.
+	for _, l := range m.Spec.PodTargetLabels {
+		relabelings = append(relabelings, yaml.MapSlice{
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(l)}},
+			{Key: "target_label", Value: sanitizeLabelName(l)},
+			{Key: "regex", Value: "(.+)"},
+			{Key: "replacement", Value: "${1}"},
+		})
+	}
+
+	// By default, generate a safe job name from the PodMonitor. We also keep
+	// this around if a jobLabel is set in case the targets don't actually have a
+	// value for it.
+	relabelings = append(relabelings, yaml.MapSlice{
+		{Key: "target_label", Value: "job"},
+		{Key: "replacement", Value: fmt.Sprintf("%s/%s", m.GetNamespace(), m.GetName())},
+	})
+	if m.Spec.JobLabel != "" {
+		relabelings = append(relabelings, yaml.MapSlice{
+			{Key: "source_labels", Value: []string{"__meta_kubernetes_pod_label_" + sanitizeLabelName(m.Spec.JobLabel)}},
+			{Key: "target_label", Value: "job"},
+			{Key: "regex", Value: "(.+)"},
+			{Key: "replacement", Value: "${1}"},
+		})
+	}
+
+	if ep.Port != "" {
+		relabelings = append(relabelings, yaml.MapSlice{
+			{Key: "target_label", Value: "endpoint"},
+			{Key: "replacement", Value: ep.Port},
+		})
+	} else if ep.TargetPort != nil && ep.TargetPort.String() != "" {
+		relabelings = append(relabelings, yaml.MapSlice{
+			{Key: "target_label", Value: "endpoint"},
+			{Key: "replacement", Value: ep.TargetPort.String()},
+		})
+	}
+
+	if ep.RelabelConfigs != nil {
+		for _, c := range ep.RelabelConfigs {
+			relabelings = append(relabelings, generateRelabelConfig(c))
+		}
+	}
+	cfg = append(cfg, yaml.MapItem{Key: "relabel_configs", Value: relabelings})
+
+	if ep.MetricRelabelConfigs != nil {
+		var metricRelabelings []yaml.MapSlice
+		for _, c := range ep.MetricRelabelConfigs {
+			metricRelabelings = append(metricRelabelings, generateRelabelConfig(c))
+		}
+		cfg = append(cfg, yaml.MapItem{Key: "metric_relabel_configs", Value: metricRelabelings})
+	}
+
+	return cfg
+}
+
 // getNamespacesFromNamespaceSelector gets a list of namespaces to select based on
 // the given namespace selector, the given default namespace, and whether to ignore namespace selectors
 func getNamespacesFromNamespaceSelector(nsel *v1.NamespaceSelector, namespace string, ignoreNamespaceSelectors bool) []string {
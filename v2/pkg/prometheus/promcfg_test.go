@@ -0,0 +1,55 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus-operator/prometheus-operator/pkg/assets"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("ScrapeLimits", func() {
+	It("should add the sample and label limits", func() {
+		sMons := map[string]*v1.ServiceMonitor{
+			"limited": {
+				Spec: v1.ServiceMonitorSpec{
+					SampleLimit: 50,
+					Endpoints:   []v1.Endpoint{{Port: "metrics"}},
+				},
+			},
+		}
+		sMons["limited"].Name = "limited"
+		sMons["limited"].Namespace = "ns"
+
+		generate := func(version string, limits map[string]ScrapeLimit) string {
+			cfg, err := NewConfigGenerator(logf.Log).WithScrapeLimits(limits).GenerateConfig(
+				&v1.Prometheus{Spec: v1.PrometheusSpec{Version: version}},
+				sMons, nil, map[string]assets.BasicAuthCredentials{}, map[string]assets.BearerToken{}, nil)
+			Expect(err).To(Succeed())
+			return string(cfg)
+		}
+
+		Expect(generate("v2.27.0", nil)).To(ContainSubstring("sample_limit: 50"))
+
+		cfg := generate("v2.27.0", map[string]ScrapeLimit{"limited": {SampleLimit: 100, LabelLimit: 20}})
+		Expect(cfg).To(ContainSubstring("sample_limit: 100"))
+		Expect(cfg).To(ContainSubstring("label_limit: 20"))
+
+		cfg = generate("v2.22.0", map[string]ScrapeLimit{"limited": {LabelLimit: 20}})
+		Expect(cfg).NotTo(ContainSubstring("label_limit"))
+	})
+})
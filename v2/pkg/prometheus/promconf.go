@@ -162,8 +162,8 @@ func LoadBearerTokensFromSecrets(client client.Client, mons map[string]*monitori
 			token, err := getCredFromSecret(
 				client,
 				ep.BearerTokenSecret,
-				"bearertoken",
 				mon.GetNamespace(),
+				"bearertoken",
 				mon.Namespace+"/"+ep.BearerTokenSecret.Name,
 				nsSecretCache,
 			)
@@ -180,3 +180,57 @@ func LoadBearerTokensFromSecrets(client client.Client, mons map[string]*monitori
 
 	return tokens, nil
 }
+
+func LoadPodMonitorBasicAuthSecrets(client client.Client, mons map[string]*monitoringv1.PodMonitor) (map[string]assets.BasicAuthCredentials, error) {
+	secrets := map[string]assets.BasicAuthCredentials{}
+	nsSecretCache := make(map[string]*corev1.Secret)
+
+	for _, mon := range mons {
+		for i, ep := range mon.Spec.PodMetricsEndpoints {
+			if ep.BasicAuth == nil {
+				continue
+			}
+
+			credentials, err := loadBasicAuthSecretFromAPI(ep.BasicAuth, client, mon.Namespace, nsSecretCache)
+			if err != nil {
+				return nil, fmt.Errorf("could not generate basicAuth for podmonitor %s. %s", mon.Name, err)
+			}
+
+			secrets[fmt.Sprintf("podMonitor/%s/%s/%d", mon.Namespace, mon.Name, i)] = credentials
+		}
+	}
+
+	return secrets, nil
+}
+
+func LoadPodMonitorBearerTokensFromSecrets(client client.Client, mons map[string]*monitoringv1.PodMonitor) (map[string]assets.BearerToken, error) {
+	tokens := map[string]assets.BearerToken{}
+	nsSecretCache := make(map[string]*corev1.Secret)
+
+	for _, mon := range mons {
+		for i, ep := range mon.Spec.PodMetricsEndpoints {
+			if ep.BearerTokenSecret.Name == "" {
+				continue
+			}
+
+			token, err := getCredFromSecret(
+				client,
+				ep.BearerTokenSecret,
+				mon.GetNamespace(),
+				"bearertoken",
+				mon.Namespace+"/"+ep.BearerTokenSecret.Name,
+				nsSecretCache,
+			)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to extract endpoint bearertoken for podmonitor %v from secret %v in namespace %v",
+					mon.Name, ep.BearerTokenSecret.Name, mon.Namespace,
+				)
+			}
+
+			tokens[fmt.Sprintf("podMonitor/%s/%s/%d", mon.Namespace, mon.Name, i)] = assets.BearerToken(token)
+		}
+	}
+
+	return tokens, nil
+}
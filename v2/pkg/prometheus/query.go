@@ -0,0 +1,112 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// QueryMetricNames returns the metric names used by the vector selectors of a
+// PromQL query, including the ones set with a __name__ matcher. A regular
+// expression __name__ matcher returns its expression.
+func QueryMetricNames(query string) ([]string, error) {
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	names := []string{}
+
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+
+		for _, name := range selectorMetricNames(selector) {
+			if !found[name] {
+				found[name] = true
+				names = append(names, name)
+			}
+		}
+
+		return nil
+	})
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func selectorMetricNames(selector *parser.VectorSelector) []string {
+	names := []string{}
+
+	if selector.Name != "" {
+		names = append(names, selector.Name)
+	}
+
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name != labels.MetricName || matcher.Value == selector.Name {
+			continue
+		}
+
+		switch matcher.Type {
+		case labels.MatchEqual, labels.MatchRegexp:
+			names = append(names, matcher.Value)
+		}
+	}
+
+	return names
+}
+
+// QuerySelectors returns the vector selectors of a PromQL query with their
+// label matchers, without offsets or ranges.
+func QuerySelectors(query string) ([]string, error) {
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	selectors := []string{}
+
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+
+		matchers := make([]string, 0, len(selector.LabelMatchers))
+		for _, matcher := range selector.LabelMatchers {
+			matchers = append(matchers, matcher.String())
+		}
+		sort.Strings(matchers)
+
+		s := "{" + strings.Join(matchers, ",") + "}"
+		if !found[s] {
+			found[s] = true
+			selectors = append(selectors, s)
+		}
+
+		return nil
+	})
+
+	sort.Strings(selectors)
+	return selectors, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueryMetricNames", func() {
+	It("should return the metric names of the selectors", func() {
+		tests := []struct {
+			query string
+			names []string
+		}{
+			{`foo_total`, []string{"foo_total"}},
+			{`{__name__="foo_total"}`, []string{"foo_total"}},
+			{`{__name__="foo_total", job="app"}`, []string{"foo_total"}},
+			{`{__name__=~"foo_.*"}`, []string{"foo_.*"}},
+			{`{__name__!="foo_total", job="app"}`, []string{}},
+			{`sum by (pod) (rate(app_requests_total{job="app"}[5m]))`, []string{"app_requests_total"}},
+			{`min_over_time((kube_pod_info{created_by_kind="DaemonSet"} or on() vector(0))[60m:60m])`, []string{"kube_pod_info"}},
+			{`a / on(pod) group_left(node) b offset 1h`, []string{"a", "b"}},
+			{`label_replace(up{job="by"}, "dst", "$1", "src", "(.*)") and up`, []string{"up"}},
+			{`sum without (instance) (bar) * 1e3 + count(foo{name="sum"})`, []string{"bar", "foo"}},
+		}
+
+		for _, test := range tests {
+			names, err := QueryMetricNames(test.query)
+			Expect(err).To(Succeed(), test.query)
+			Expect(names).To(Equal(test.names), test.query)
+		}
+	})
+
+	It("should fail on invalid queries", func() {
+		_, err := QueryMetricNames(`sum(foo`)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("QuerySelectors", func() {
+	It("should return the label matchers of the selectors", func() {
+		tests := []struct {
+			query     string
+			selectors []string
+		}{
+			{`foo_total`, []string{`{__name__="foo_total"}`}},
+			{`rate(foo_total{job="app"}[5m] offset 1h)`, []string{`{__name__="foo_total",job="app"}`}},
+			{`{job="app", __name__="foo_total"} + foo_total{job="app"}`, []string{`{__name__="foo_total",job="app"}`}},
+			{`a / on(pod) b{pod=~"x.*"}`, []string{`{__name__="a"}`, `{__name__="b",pod=~"x.*"}`}},
+		}
+
+		for _, test := range tests {
+			selectors, err := QuerySelectors(test.query)
+			Expect(err).To(Succeed(), test.query)
+			Expect(selectors).To(Equal(test.selectors), test.query)
+		}
+	})
+})
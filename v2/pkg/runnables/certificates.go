@@ -0,0 +1,70 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runnables
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/certificates"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WebhookCertificateSyncer keeps the serving certificate files of every
+// operator replica in sync with the managed webhook certificates. The CRD
+// updater watches their secret and injects a rotated CA on the leader.
+type WebhookCertificateSyncer struct {
+	Logger logr.Logger
+	Config *config.OperatorConfig
+	Client kubernetes.Interface
+}
+
+func (a *WebhookCertificateSyncer) NeedLeaderElection() bool {
+	return false
+}
+
+func (a *WebhookCertificateSyncer) Start(stop <-chan struct{}) error {
+	if certificates.IsOLMManaged() {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	log := a.Logger.WithValues("function", "webhookCertificateSyncer")
+	certs := certificates.NewWebhookCertificates(
+		log, a.Client, a.Config.DeployedNamespace, certificates.WebhookServiceName, certificates.ManagedCertDir)
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := certs.Sync(ctx); err != nil {
+				log.Error(err, "failed to sync webhook certificates")
+			}
+		}
+	}
+}
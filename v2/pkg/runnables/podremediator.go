@@ -0,0 +1,562 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runnables
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RemediationAction is what the pod remediator does to a pod that matches a policy.
+type RemediationAction string
+
+const (
+	// RemediationDeletePod deletes the pod so its controller creates a new one.
+	RemediationDeletePod RemediationAction = "DeletePod"
+	// RemediationRestartWorkload rolls the pods of the deployment or
+	// statefulset that owns the pod.
+	RemediationRestartWorkload RemediationAction = "RestartWorkload"
+	// RemediationAlert sets the RemediationNeeded condition on the pod.
+	RemediationAlert RemediationAction = "Alert"
+)
+
+const (
+	// PodConditionRemediationNeeded is set on pods by the alert action.
+	PodConditionRemediationNeeded corev1.PodConditionType = "marketplace.redhat.com/RemediationNeeded"
+
+	// Reasons of the events recorded for remediations
+	EventReasonPodDeleted             = "PodRemediationDeleted"
+	EventReasonWorkloadRestarted      = "PodRemediationRestarted"
+	EventReasonRemediationNeeded      = "PodRemediationNeeded"
+	EventReasonRemediationRateLimited = "PodRemediationRateLimited"
+	EventReasonRemediationFailed      = "PodRemediationFailed"
+
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+const (
+	defaultRemediationDelay      = 5 * time.Second
+	defaultRemediationsPerHour   = 10
+	defaultRemediationBackoff    = time.Minute
+	defaultRemediationMaxBackoff = 30 * time.Minute
+)
+
+// RemediationCondition is the container state that triggers a remediation.
+// Every field that is set has to match.
+type RemediationCondition struct {
+	// NotReady matches containers that are not ready.
+	NotReady bool
+	// RestartsOver matches containers restarted more times than the count.
+	// Pods that never restart match without restarts.
+	RestartsOver *int32
+	// ExitCodes matches containers that last terminated with one of the codes.
+	ExitCodes []int32
+}
+
+func (c *RemediationCondition) isEmpty() bool {
+	return !c.NotReady && c.RestartsOver == nil && len(c.ExitCodes) == 0
+}
+
+// Matches returns true if the container status of the pod matches the condition.
+func (c *RemediationCondition) Matches(pod *corev1.Pod, status *corev1.ContainerStatus) bool {
+	if c.isEmpty() {
+		return false
+	}
+
+	if c.NotReady && status.Ready {
+		return false
+	}
+
+	if c.RestartsOver != nil &&
+		pod.Spec.RestartPolicy != corev1.RestartPolicyNever &&
+		status.RestartCount <= *c.RestartsOver {
+		return false
+	}
+
+	if len(c.ExitCodes) != 0 {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+
+		if terminated == nil {
+			return false
+		}
+
+		found := false
+		for _, code := range c.ExitCodes {
+			if terminated.ExitCode == code {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RemediationPolicy is the remediation of a container of the pods an operand
+// deploys.
+type RemediationPolicy struct {
+	// Name of the policy, used in events and logs.
+	Name string
+	// Selector selects the pods of the operand.
+	Selector map[string]string
+	// Container is the name of the container to check.
+	Container string
+	// Condition triggers the remediation.
+	Condition RemediationCondition
+	// Action is taken once the condition matched for the jittered delay.
+	Action RemediationAction
+	// Delay before the action, defaults to 5s.
+	Delay time.Duration
+	// ActionsPerHour limits the actions of the policy across pods, defaults to 10.
+	ActionsPerHour int
+	// Backoff is the time before the pods of a workload are remediated again.
+	// It doubles on every remediation of the workload up to MaxBackoff.
+	// Defaults to 1m and 30m.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Validate returns an error if the policy can't be used.
+func (p *RemediationPolicy) Validate() error {
+	if p.Name == "" {
+		return errors.New("remediation policy has no name")
+	}
+
+	if p.Container == "" {
+		return errors.Errorf("remediation policy %s has no container", p.Name)
+	}
+
+	if p.Condition.isEmpty() {
+		return errors.Errorf("remediation policy %s has no condition", p.Name)
+	}
+
+	switch p.Action {
+	case RemediationDeletePod, RemediationRestartWorkload, RemediationAlert:
+	default:
+		return errors.Errorf("remediation policy %s has unknown action %q", p.Name, p.Action)
+	}
+
+	return nil
+}
+
+// Matches returns true if the pod is selected by the policy and its container
+// matches the condition.
+func (p *RemediationPolicy) Matches(pod *corev1.Pod) bool {
+	if !labels.SelectorFromSet(p.Selector).Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+
+	for i := range pod.Status.ContainerStatuses {
+		status := &pod.Status.ContainerStatuses[i]
+
+		if status.Name == p.Container {
+			return p.Condition.Matches(pod, status)
+		}
+	}
+
+	return false
+}
+
+type remediationPolicy struct {
+	RemediationPolicy
+
+	limiter *rate.Limiter
+	backoff *flowcontrol.Backoff
+
+	mutex      sync.Mutex
+	remediated map[string]time.Time
+}
+
+func newRemediationPolicy(policy RemediationPolicy) *remediationPolicy {
+	if policy.Delay == 0 {
+		policy.Delay = defaultRemediationDelay
+	}
+
+	if policy.ActionsPerHour == 0 {
+		policy.ActionsPerHour = defaultRemediationsPerHour
+	}
+
+	if policy.Backoff == 0 {
+		policy.Backoff = defaultRemediationBackoff
+	}
+
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = defaultRemediationMaxBackoff
+	}
+
+	return &remediationPolicy{
+		RemediationPolicy: policy,
+		limiter:           rate.NewLimiter(rate.Limit(float64(policy.ActionsPerHour)/3600), policy.ActionsPerHour),
+		backoff:           flowcontrol.NewBackOff(policy.Backoff, policy.MaxBackoff),
+		remediated:        map[string]time.Time{},
+	}
+}
+
+// backoffRemaining is the time until the workload of the key can be
+// remediated again.
+func (p *remediationPolicy) backoffRemaining(key string, now time.Time) time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	remediated, ok := p.remediated[key]
+	if !ok || !p.backoff.IsInBackOffSinceUpdate(key, now) {
+		return 0
+	}
+
+	return remediated.Add(p.backoff.Get(key)).Sub(now)
+}
+
+// limiterDelay takes a token of the rate limit, or returns the time until a
+// token is available.
+func (p *remediationPolicy) limiterDelay(now time.Time) time.Duration {
+	reservation := p.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+
+	if delay > 0 {
+		reservation.CancelAt(now)
+	}
+
+	return delay
+}
+
+func (p *remediationPolicy) next(key string, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.backoff.Next(key, now)
+	p.remediated[key] = now
+}
+
+func (p *remediationPolicy) gc(now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.backoff.GC()
+	for key, remediated := range p.remediated {
+		if now.Sub(remediated) > 2*p.MaxBackoff {
+			delete(p.remediated, key)
+		}
+	}
+}
+
+// PodRemediator watches the pods of the operands and remediates the ones that
+// match a remediation policy.
+type PodRemediator struct {
+	logger   logr.Logger
+	client   kubernetes.Interface
+	recorder record.EventRecorder
+	config   PodRemediatorConfig
+	policies []*remediationPolicy
+
+	mutex   sync.Mutex
+	pending map[string]*time.Timer
+}
+
+type PodRemediatorConfig struct {
+	Namespace string
+	Policies  []RemediationPolicy
+}
+
+func NewPodRemediator(
+	logger logr.Logger,
+	client kubernetes.Interface,
+	recorder record.EventRecorder,
+	config PodRemediatorConfig,
+) (*PodRemediator, error) {
+	remediator := &PodRemediator{
+		logger:   logger.WithName("podRemediator"),
+		client:   client,
+		recorder: recorder,
+		config:   config,
+		pending:  map[string]*time.Timer{},
+	}
+
+	for _, policy := range config.Policies {
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+
+		remediator.policies = append(remediator.policies, newRemediationPolicy(policy))
+	}
+
+	return remediator, nil
+}
+
+func (a *PodRemediator) NeedLeaderElection() bool {
+	return true
+}
+
+func (a *PodRemediator) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go a.Run(ctx)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-stop:
+		cancel()
+		return nil
+	}
+}
+
+// Run watches the pods with a shared informer, which lists the pods again
+// when its watch expires, until the context is done.
+func (a *PodRemediator) Run(ctx context.Context) error {
+	log := a.logger
+	log.Info("starting pod remediator", "policies", len(a.policies))
+
+	factory := informers.NewSharedInformerFactoryWithOptions(a.client, 0, informers.WithNamespace(a.config.Namespace))
+	podInformer := factory.Core().V1().Pods().Informer()
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			a.handle(ctx, obj)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			a.handle(ctx, obj)
+		},
+	})
+
+	factory.Start(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), podInformer.HasSynced) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		err := errors.New("failed to sync the pod informer")
+		log.Error(err, "error on watch")
+		return err
+	}
+
+	gc := time.NewTicker(time.Hour)
+	defer gc.Stop()
+
+	for {
+		select {
+		case now := <-gc.C:
+			for _, policy := range a.policies {
+				policy.gc(now)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (a *PodRemediator) handle(ctx context.Context, obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	for _, policy := range a.policies {
+		if policy.Matches(pod) {
+			a.schedule(ctx, policy, pod, wait.Jitter(policy.Delay, 1.0))
+		}
+	}
+}
+
+// schedule remediates the pod after the delay, once per pod and policy.
+func (a *PodRemediator) schedule(ctx context.Context, policy *remediationPolicy, pod *corev1.Pod, delay time.Duration) {
+	key := fmt.Sprintf("%s/%s", policy.Name, pod.UID)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, ok := a.pending[key]; ok {
+		return
+	}
+
+	a.pending[key] = time.AfterFunc(delay, func() {
+		a.mutex.Lock()
+		delete(a.pending, key)
+		a.mutex.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		a.remediate(ctx, policy, pod.Namespace, pod.Name)
+	})
+}
+
+// remediate takes the action of the policy if the pod still matches it, is not
+// backing off and the policy is within its rate limit. A pod that is backing
+// off or rate limited is remediated again once the backoff or the limit
+// passed, since the informer doesn't resync the pods.
+func (a *PodRemediator) remediate(ctx context.Context, policy *remediationPolicy, namespace, name string) {
+	log := a.logger.WithValues("policy", policy.Name, "pod", name)
+
+	pod, err := a.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			log.Error(err, "failed to get pod")
+		}
+		return
+	}
+
+	if !policy.Matches(pod) {
+		log.V(2).Info("pod recovered before remediation")
+		return
+	}
+
+	key := a.backoffKey(ctx, pod)
+	now := time.Now()
+
+	if remaining := policy.backoffRemaining(key, now); remaining > 0 {
+		log.V(2).Info("pod remediation is backing off", "backoff", policy.backoff.Get(key), "remaining", remaining)
+		a.schedule(ctx, policy, pod, remaining)
+		return
+	}
+
+	if delay := policy.limiterDelay(now); delay > 0 {
+		log.Info("pod remediation is rate limited", "delay", delay)
+		a.recorder.Eventf(pod, corev1.EventTypeWarning, EventReasonRemediationRateLimited,
+			"Remediation policy %s is over %d actions per hour", policy.Name, policy.ActionsPerHour)
+		a.schedule(ctx, policy, pod, delay)
+		return
+	}
+
+	policy.next(key, now)
+
+	if err := a.act(ctx, policy, pod); err != nil {
+		log.Error(err, "pod remediation failed")
+		a.recorder.Eventf(pod, corev1.EventTypeWarning, EventReasonRemediationFailed,
+			"Remediation policy %s failed to %s: %s", policy.Name, policy.Action, err.Error())
+	}
+}
+
+func (a *PodRemediator) act(ctx context.Context, policy *remediationPolicy, pod *corev1.Pod) error {
+	log := a.logger.WithValues("policy", policy.Name, "pod", pod.Name)
+
+	switch policy.Action {
+	case RemediationDeletePod:
+		log.Info("deleting pod")
+		if err := a.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+
+		a.recorder.Eventf(pod, corev1.EventTypeNormal, EventReasonPodDeleted,
+			"Deleted pod, container %s matched remediation policy %s", policy.Container, policy.Name)
+	case RemediationRestartWorkload:
+		kind, name, err := a.podWorkload(ctx, pod)
+		if err != nil {
+			return err
+		}
+
+		log.Info("restarting workload", "kind", kind, "name", name)
+		if err := a.restartWorkload(ctx, pod.Namespace, kind, name); err != nil {
+			return err
+		}
+
+		a.recorder.Eventf(pod, corev1.EventTypeNormal, EventReasonWorkloadRestarted,
+			"Restarted %s %s, container %s matched remediation policy %s", kind, name, policy.Container, policy.Name)
+	case RemediationAlert:
+		log.Info("raising remediation condition")
+		message := fmt.Sprintf("Container %s matched remediation policy %s", policy.Container, policy.Name)
+		patch := fmt.Sprintf(`{"status":{"conditions":[{"type":%q,"status":"True","reason":"PolicyMatched","message":%q,"lastTransitionTime":%q}]}}`,
+			PodConditionRemediationNeeded, message, time.Now().UTC().Format(time.RFC3339))
+
+		if _, err := a.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name,
+			types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "status"); err != nil {
+			return err
+		}
+
+		a.recorder.Event(pod, corev1.EventTypeWarning, EventReasonRemediationNeeded, message)
+	}
+
+	return nil
+}
+
+// backoffKey is the workload that owns the pod, so the backoff holds for the
+// pods that replace a remediated pod. Pods without a controller are keyed by
+// their name.
+func (a *PodRemediator) backoffKey(ctx context.Context, pod *corev1.Pod) string {
+	if kind, name, err := a.podWorkload(ctx, pod); err == nil {
+		return fmt.Sprintf("%s/%s/%s", pod.Namespace, kind, name)
+	}
+
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return fmt.Sprintf("%s/%s/%s", pod.Namespace, owner.Kind, owner.Name)
+	}
+
+	return fmt.Sprintf("%s/Pod/%s", pod.Namespace, pod.Name)
+}
+
+// podWorkload returns the kind and name of the deployment or statefulset
+// that owns the pod.
+func (a *PodRemediator) podWorkload(ctx context.Context, pod *corev1.Pod) (string, string, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "", "", errors.Errorf("pod %s has no controller", pod.Name)
+	}
+
+	switch owner.Kind {
+	case "StatefulSet":
+		return owner.Kind, owner.Name, nil
+	case "ReplicaSet":
+		replicaSet, err := a.client.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", err
+		}
+
+		if deployment := metav1.GetControllerOf(replicaSet); deployment != nil && deployment.Kind == "Deployment" {
+			return deployment.Kind, deployment.Name, nil
+		}
+	}
+
+	return "", "", errors.Errorf("pod %s is not owned by a deployment or statefulset", pod.Name)
+}
+
+func (a *PodRemediator) restartWorkload(ctx context.Context, namespace, kind, name string) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().UTC().Format(time.RFC3339)))
+
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = a.client.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = a.client.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	}
+
+	return err
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runnables
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotidy/ptr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("PodRemediator", func() {
+	const namespace = "openshift-redhat-marketplace"
+
+	newPod := func(name string, status corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "test"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "main", Ready: true},
+					status,
+				},
+			},
+		}
+	}
+
+	policy := RemediationPolicy{
+		Name:      "test",
+		Selector:  map[string]string{"app": "test"},
+		Container: "authcheck",
+		Condition: RemediationCondition{NotReady: true, RestartsOver: ptr.Int32(2)},
+		Action:    RemediationDeletePod,
+	}
+
+	Context("matching", func() {
+		It("should match not ready containers restarted too often", func() {
+			Expect(policy.Matches(newPod("a", corev1.ContainerStatus{Name: "authcheck", RestartCount: 3}))).To(BeTrue())
+			Expect(policy.Matches(newPod("a", corev1.ContainerStatus{Name: "authcheck", RestartCount: 2}))).To(BeFalse())
+			Expect(policy.Matches(newPod("a", corev1.ContainerStatus{Name: "authcheck", Ready: true, RestartCount: 3}))).To(BeFalse())
+
+			pod := newPod("a", corev1.ContainerStatus{Name: "authcheck"})
+			pod.Spec.RestartPolicy = corev1.RestartPolicyNever
+			Expect(policy.Matches(pod)).To(BeTrue())
+
+			pod.Labels = map[string]string{"app": "other"}
+			Expect(policy.Matches(pod)).To(BeFalse())
+		})
+
+		It("should match exit codes of the last termination", func() {
+			condition := RemediationCondition{ExitCodes: []int32{137}}
+			pod := newPod("a", corev1.ContainerStatus{})
+
+			Expect(condition.Matches(pod, &corev1.ContainerStatus{})).To(BeFalse())
+			Expect(condition.Matches(pod, &corev1.ContainerStatus{
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137}},
+			})).To(BeTrue())
+			Expect(condition.Matches(pod, &corev1.ContainerStatus{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+			})).To(BeFalse())
+		})
+
+		It("should validate policies", func() {
+			Expect(policy.Validate()).To(Succeed())
+			Expect((&RemediationPolicy{Name: "empty", Container: "authcheck", Action: RemediationAlert}).Validate()).ToNot(Succeed())
+			Expect((&RemediationPolicy{Name: "action", Container: "authcheck", Condition: policy.Condition}).Validate()).ToNot(Succeed())
+		})
+
+		It("should register valid operand policies", func() {
+			authCheck := AuthCheckRemediation("operand", map[string]string{"app": "operand"})
+			RegisterRemediationPolicies(authCheck)
+			Expect(OperandRemediationPolicies()).To(ContainElement(authCheck))
+
+			registered := len(OperandRemediationPolicies())
+			Expect(func() {
+				RegisterRemediationPolicies(RemediationPolicy{Name: "invalid"})
+			}).To(Panic())
+			Expect(OperandRemediationPolicies()).To(HaveLen(registered))
+		})
+	})
+
+	Context("remediating", func() {
+		var (
+			client     *fake.Clientset
+			recorder   *record.FakeRecorder
+			remediator *PodRemediator
+		)
+
+		setup := func(policy RemediationPolicy, objs ...runtime.Object) *remediationPolicy {
+			client = fake.NewSimpleClientset(objs...)
+			recorder = record.NewFakeRecorder(10)
+
+			var err error
+			remediator, err = NewPodRemediator(logf.Log, client, recorder, PodRemediatorConfig{
+				Namespace: namespace,
+				Policies:  []RemediationPolicy{policy},
+			})
+			Expect(err).To(Succeed())
+			return remediator.policies[0]
+		}
+
+		unhealthy := corev1.ContainerStatus{Name: "authcheck", RestartCount: 3}
+
+		It("should delete matching pods within the rate limit", func() {
+			limited := policy
+			limited.ActionsPerHour = 1
+			p := setup(limited, newPod("a", unhealthy), newPod("b", unhealthy), newPod("c", corev1.ContainerStatus{Name: "authcheck", Ready: true}))
+
+			remediator.remediate(context.TODO(), p, namespace, "c")
+			remediator.remediate(context.TODO(), p, namespace, "a")
+			remediator.remediate(context.TODO(), p, namespace, "b")
+
+			_, err := client.CoreV1().Pods(namespace).Get(context.TODO(), "a", metav1.GetOptions{})
+			Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+			_, err = client.CoreV1().Pods(namespace).Get(context.TODO(), "b", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+
+			_, err = client.CoreV1().Pods(namespace).Get(context.TODO(), "c", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+
+			Expect(<-recorder.Events).To(HavePrefix("Normal " + EventReasonPodDeleted))
+			Expect(<-recorder.Events).To(HavePrefix("Warning " + EventReasonRemediationRateLimited))
+			Expect(remediator.pending).To(HaveLen(1))
+		})
+
+		It("should back off a pod after an action", func() {
+			alert := policy
+			alert.Action = RemediationAlert
+			p := setup(alert, newPod("a", unhealthy))
+
+			remediator.remediate(context.TODO(), p, namespace, "a")
+			remediator.remediate(context.TODO(), p, namespace, "a")
+
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(HavePrefix("Warning " + EventReasonRemediationNeeded))
+			Expect(remediator.pending).To(HaveLen(1))
+		})
+
+		It("should remediate a pod again once the backoff passed", func() {
+			alert := policy
+			alert.Action = RemediationAlert
+			alert.Backoff = 50 * time.Millisecond
+			p := setup(alert, newPod("a", unhealthy))
+
+			remediator.remediate(context.TODO(), p, namespace, "a")
+			remediator.remediate(context.TODO(), p, namespace, "a")
+
+			Expect(<-recorder.Events).To(HavePrefix("Warning " + EventReasonRemediationNeeded))
+			Eventually(recorder.Events, 5*time.Second).Should(Receive(HavePrefix("Warning " + EventReasonRemediationNeeded)))
+		})
+
+		It("should back off the replaced pods of a workload", func() {
+			controller := true
+			owner := []metav1.OwnerReference{{Kind: "StatefulSet", Name: "test", Controller: &controller}}
+			a, b := newPod("test-0", unhealthy), newPod("test-1", unhealthy)
+			a.OwnerReferences, b.OwnerReferences = owner, owner
+
+			p := setup(policy, a, b)
+			remediator.remediate(context.TODO(), p, namespace, "test-0")
+			remediator.remediate(context.TODO(), p, namespace, "test-1")
+
+			_, err := client.CoreV1().Pods(namespace).Get(context.TODO(), "test-1", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(recorder.Events).To(HaveLen(1))
+		})
+
+		It("should remediate the pods seen by the informer", func() {
+			fast := policy
+			fast.Delay = time.Millisecond
+			setup(fast)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error)
+			go func() {
+				done <- remediator.Run(ctx)
+			}()
+
+			_, err := client.CoreV1().Pods(namespace).Create(context.TODO(), newPod("a", unhealthy), metav1.CreateOptions{})
+			Expect(err).To(Succeed())
+
+			Eventually(recorder.Events, 5*time.Second).Should(Receive(HavePrefix("Normal " + EventReasonPodDeleted)))
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		It("should restart the deployment of the pod", func() {
+			restart := policy
+			restart.Action = RemediationRestartWorkload
+
+			controller := true
+			replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name:            "test-1234",
+				Namespace:       namespace,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "test", Controller: &controller}},
+			}}
+			deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace}}
+			pod := newPod("a", unhealthy)
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "test-1234", Controller: &controller}}
+
+			p := setup(restart, pod, replicaSet, deployment)
+			remediator.remediate(context.TODO(), p, namespace, "a")
+
+			Expect(<-recorder.Events).To(HavePrefix("Normal " + EventReasonWorkloadRestarted))
+
+			deployment, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), "test", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).To(HaveKey(restartedAtAnnotation))
+		})
+	})
+})
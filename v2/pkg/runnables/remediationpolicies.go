@@ -0,0 +1,68 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runnables
+
+import (
+	"sync"
+
+	"github.com/gotidy/ptr"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/authcheck"
+)
+
+var (
+	operandPoliciesMutex sync.Mutex
+	operandPolicies      []RemediationPolicy
+)
+
+// RegisterRemediationPolicies adds remediation policies for the pods of an
+// operand. Operands register their policies next to the code that deploys
+// them. It panics on a policy that is not valid.
+func RegisterRemediationPolicies(policies ...RemediationPolicy) {
+	operandPoliciesMutex.Lock()
+	defer operandPoliciesMutex.Unlock()
+
+	for _, policy := range policies {
+		if err := policy.Validate(); err != nil {
+			panic(err)
+		}
+
+		operandPolicies = append(operandPolicies, policy)
+	}
+}
+
+// OperandRemediationPolicies are the remediation policies registered by the
+// operands the operator deploys.
+func OperandRemediationPolicies() []RemediationPolicy {
+	operandPoliciesMutex.Lock()
+	defer operandPoliciesMutex.Unlock()
+
+	return append([]RemediationPolicy{}, operandPolicies...)
+}
+
+// AuthCheckRemediation deletes the pods of an operand once its authcheck
+// sidecar is not ready and has restarted more than twice, which is how the
+// sidecar reports a service account token that is no longer valid.
+func AuthCheckRemediation(name string, selector map[string]string) RemediationPolicy {
+	return RemediationPolicy{
+		Name:      name + "-authcheck",
+		Selector:  selector,
+		Container: authcheck.ContainerName,
+		Condition: RemediationCondition{
+			NotReady:     true,
+			RestartsOver: ptr.Int32(2),
+		},
+		Action: RemediationDeletePod,
+	}
+}
@@ -22,17 +22,20 @@ import (
 type Runnables []manager.Runnable
 
 var RunnableSet = wire.NewSet(
-	NewPodMonitor,
+	NewPodRemediator,
 	ProvideRunnables,
 	wire.Struct(new(CRDUpdater), "*"),
+	wire.Struct(new(WebhookCertificateSyncer), "*"),
 )
 
 func ProvideRunnables(
-	podMonitor *PodMonitor,
+	podRemediator *PodRemediator,
 	crdUpdater *CRDUpdater,
+	certificateSyncer *WebhookCertificateSyncer,
 ) Runnables {
 	return []manager.Runnable{
-		podMonitor,
+		podRemediator,
 		crdUpdater,
+		certificateSyncer,
 	}
 }
@@ -17,25 +17,30 @@ package runnables
 import (
 	"bytes"
 	"context"
-	"os"
-	"path/filepath"
 	"reflect"
 	"sync"
 	"time"
 
+	"emperror.dev/errors"
 	"github.com/go-logr/logr"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/certificates"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/manifests"
 	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
 	. "github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/utils/reconcileutils"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	typedapiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
 )
 
@@ -45,6 +50,8 @@ const (
 	OLMOwnerNamespace = "olm.owner.namespace"
 
 	InjectCAAnnotation = "service.beta.openshift.io/inject-cabundle"
+
+	marketplaceGroup = "marketplace.redhat.com"
 )
 
 type CRDUpdater struct {
@@ -55,15 +62,15 @@ type CRDUpdater struct {
 	Client  kubernetes.Interface
 	Factory *manifests.Factory
 
-	caInfo *CAInformation `wire:"-"`
+	caInfo       *CAInformation                    `wire:"-"`
+	certificates *certificates.WebhookCertificates `wire:"-"`
 }
 
 type CAInformation struct {
 	sync.Mutex
 	logr.Logger
-	configMap *corev1.ConfigMap
-	secret    *corev1.Secret
-	port      *int32
+	secret *corev1.Secret
+	port   *int32
 }
 
 func (c *CAInformation) updateSecret(s *corev1.Secret) {
@@ -73,13 +80,6 @@ func (c *CAInformation) updateSecret(s *corev1.Secret) {
 	c.secret = s
 }
 
-func (c *CAInformation) updateConfigMap(cm *corev1.ConfigMap) {
-	c.Lock()
-	defer c.Unlock()
-
-	c.configMap = cm
-}
-
 func (c *CAInformation) updatePort(port int32) {
 	c.Lock()
 	defer c.Unlock()
@@ -93,41 +93,27 @@ func (c *CAInformation) GetPort() *int32 {
 	return c.port
 }
 
+// GetCA returns the CA of the certificates mounted by OLM.
 func (c *CAInformation) GetCA() ([]byte, bool) {
 	c.Lock()
 	defer c.Unlock()
 
-	if c.configMap == nil && c.secret == nil {
+	if c.secret == nil {
 		return []byte{}, false
 	}
 
-	openshiftPath := filepath.Join(WebhookCertDir, WebhookKeyName)
-	if _, err := os.Stat(openshiftPath); !os.IsNotExist(err) {
-		if c.secret != nil {
-			olmCAKey, ok := c.secret.Data["olmCAKey"]
+	olmCAKey, ok := c.secret.Data["olmCAKey"]
 
-			if !ok {
-				olmCAKey, ok = c.secret.Data["tls.crt"]
-				return olmCAKey, ok
-			}
-
-			return olmCAKey, ok
-		}
-
-		return []byte{}, false
+	if !ok {
+		olmCAKey, ok = c.secret.Data["tls.crt"]
+		return olmCAKey, ok
 	}
 
-	if c.configMap != nil {
-		olmCAKey, ok := c.configMap.Data["service-ca.crt"]
-		return []byte(olmCAKey), ok
-	}
-
-	return []byte{}, false
+	return olmCAKey, ok
 }
 
 func (c *CAInformation) Load(ctx context.Context, a *CRDUpdater) error {
 	c.Logger.Info("loading ca info")
-	cm := &corev1.ConfigMap{}
 	secret := &corev1.Secret{}
 	managerService := &corev1.Service{}
 
@@ -159,19 +145,6 @@ func (c *CAInformation) Load(ctx context.Context, a *CRDUpdater) error {
 		c.updateSecret(secret)
 	}
 
-	// get cm for ca
-	result, _ = a.CC.Exec(ctx, reconcileutils.Do(
-		reconcileutils.GetAction(types.NamespacedName{
-			Name:      configMapName,
-			Namespace: a.Config.DeployedNamespace,
-		}, cm),
-	))
-
-	if result.Is(reconcileutils.Continue) {
-		c.Logger.Info("setting configmap")
-		c.updateConfigMap(cm)
-	}
-
 	return nil
 }
 
@@ -179,11 +152,6 @@ func (a *CRDUpdater) NeedLeaderElection() bool {
 	return true
 }
 
-const (
-	WebhookCertDir = "/apiserver.local.config/certificates"
-	WebhookKeyName = "apiserver.key"
-)
-
 func (a *CRDUpdater) Start(stop <-chan struct{}) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -196,18 +164,15 @@ func (a *CRDUpdater) Start(stop <-chan struct{}) error {
 
 	a.Logger = a.Logger.WithValues("function", "crdUpdater")
 
-	crdsAvailable := map[string]bool{
-		"meterdefinitions.marketplace.redhat.com": false,
-	}
-
-	crds := &CRDToUpdate{
-		CRDs: crdsAvailable,
+	if !certificates.IsOLMManaged() {
+		a.certificates = certificates.NewWebhookCertificates(
+			a.Logger, a.Client, a.Config.DeployedNamespace, serviceName, certificates.ManagedCertDir)
 	}
 
 	errChan := make(chan error)
 
 	go func() {
-		err := a.Run(ctx, crds)
+		err := a.Run(ctx)
 		defer close(errChan)
 
 		if err != nil {
@@ -228,15 +193,9 @@ func (a *CRDUpdater) Start(stop <-chan struct{}) error {
 	}
 }
 
-type CRDToUpdate struct {
-	CRDs map[string]bool
-	sync.Mutex
-}
-
 const operatorDeployment = "redhat-marketplace-controller-manager"
-const configMapName = "serving-certs-ca-bundle"
 const secretName = "redhat-marketplace-controller-manager-service-cert"
-const serviceName = "redhat-marketplace-controller-manager-service"
+const serviceName = certificates.WebhookServiceName
 const meteringServiceName = "redhat-marketplace-controller-manager-metrics-service"
 
 func (a *CRDUpdater) reviewAndUpdateOwnerReferences(
@@ -339,10 +298,218 @@ func (a *CRDUpdater) createCMIfMissing(ctx context.Context) error {
 	return nil
 }
 
-func (a *CRDUpdater) ensureConfigmapExists(
+// caBundle returns the CA bundle of the webhook certificates, either the
+// certificates OLM mounted or the ones the operator manages.
+func (a *CRDUpdater) caBundle(ctx context.Context) ([]byte, bool, error) {
+	if a.certificates != nil {
+		bundle, err := a.certificates.Sync(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return bundle, len(bundle) != 0, nil
+	}
+
+	caInfo, ok := a.caInfo.GetCA()
+	return caInfo, ok, nil
+}
+
+// updateCRDs sets the CA bundle and port of the conversion webhook of every
+// marketplace CRD.
+func (a *CRDUpdater) updateCRDs(
 	ctx context.Context,
-	crds *CRDToUpdate,
+	extendedClient typedapiextensionsv1beta1.ApiextensionsV1beta1Interface,
+	caBundle []byte,
+	port *int32,
 ) error {
+	crds, err := extendedClient.CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+
+		if crd.Spec.Group != marketplaceGroup || !hasConversionWebhook(crd) {
+			continue
+		}
+
+		crdName := crd.Name
+		a.Logger.Info("updating crd", "name", crdName)
+
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			crd, err := extendedClient.CustomResourceDefinitions().Get(ctx, crdName, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+
+			if !hasConversionWebhook(crd) {
+				return nil
+			}
+
+			clientConfig := crd.Spec.Conversion.WebhookClientConfig
+			next := bytes.Equal(clientConfig.CABundle, caBundle)
+
+			if port != nil && (clientConfig.Service.Port == nil || *clientConfig.Service.Port != *port) {
+				next = false
+			}
+
+			if a.certificates != nil && removeInjectCAAnnotation(&crd.ObjectMeta) {
+				next = false
+			}
+
+			if next {
+				return nil
+			}
+
+			clientConfig.CABundle = caBundle
+			if port != nil {
+				clientConfig.Service.Port = port
+			}
+
+			_, err = extendedClient.CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
+			return err
+		})
+
+		if err != nil {
+			a.Logger.Error(err, "failed to update crd", "name", crdName)
+			errs = errors.Append(errs, err)
+		} else {
+			a.Logger.Info("updated crd", "name", crdName)
+		}
+	}
+
+	return errs
+}
+
+// updateWebhookConfigurations sets the CA bundle of the webhooks served by the
+// operator in the mutating and validating webhook configurations.
+func (a *CRDUpdater) updateWebhookConfigurations(ctx context.Context, caBundle []byte) error {
+	admission := a.Client.AdmissionregistrationV1beta1()
+
+	isOperatorWebhook := func(clientConfig *admissionregistrationv1beta1.WebhookClientConfig) bool {
+		return clientConfig.Service != nil &&
+			clientConfig.Service.Name == serviceName &&
+			clientConfig.Service.Namespace == a.Config.DeployedNamespace
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		mutating, err := admission.MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+
+		for i := range mutating.Items {
+			config := &mutating.Items[i]
+			changed := false
+
+			for j := range config.Webhooks {
+				clientConfig := &config.Webhooks[j].ClientConfig
+				if isOperatorWebhook(clientConfig) && !bytes.Equal(clientConfig.CABundle, caBundle) {
+					clientConfig.CABundle = caBundle
+					changed = true
+				}
+			}
+
+			if !changed {
+				continue
+			}
+
+			removeInjectCAAnnotation(&config.ObjectMeta)
+			a.Logger.Info("updating mutating webhook configuration", "name", config.Name)
+			if _, err := admission.MutatingWebhookConfigurations().Update(ctx, config, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+
+		validating, err := admission.ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+
+		for i := range validating.Items {
+			config := &validating.Items[i]
+			changed := false
+
+			for j := range config.Webhooks {
+				clientConfig := &config.Webhooks[j].ClientConfig
+				if isOperatorWebhook(clientConfig) && !bytes.Equal(clientConfig.CABundle, caBundle) {
+					clientConfig.CABundle = caBundle
+					changed = true
+				}
+			}
+
+			if !changed {
+				continue
+			}
+
+			removeInjectCAAnnotation(&config.ObjectMeta)
+			a.Logger.Info("updating validating webhook configuration", "name", config.Name)
+			if _, err := admission.ValidatingWebhookConfigurations().Update(ctx, config, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func hasConversionWebhook(crd *apiextensionsv1beta1.CustomResourceDefinition) bool {
+	return crd.Spec.Conversion != nil &&
+		crd.Spec.Conversion.WebhookClientConfig != nil &&
+		crd.Spec.Conversion.WebhookClientConfig.Service != nil
+}
+
+// removeInjectCAAnnotation removes the service-ca injection annotation, which
+// would overwrite the CA bundle of the managed certificates.
+func removeInjectCAAnnotation(meta *metav1.ObjectMeta) bool {
+	if _, ok := meta.Annotations[InjectCAAnnotation]; !ok {
+		return false
+	}
+
+	delete(meta.Annotations, InjectCAAnnotation)
+	return true
+}
+
+// watchWebhookSecret signals when the managed webhook certificates change, so
+// a CA rotated by any replica is injected right away instead of on the next
+// tick. The CA bundle keeps the previous CA, the serving certificates signed
+// by it stay trusted until then.
+func (a *CRDUpdater) watchWebhookSecret(ctx context.Context) <-chan struct{} {
+	changed := make(chan struct{}, 1)
+
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		a.Client, 0,
+		informers.WithNamespace(a.Config.DeployedNamespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", certificates.WebhookSecretName).String()
+		}),
+	)
+	factory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldSecret, ok := oldObj.(*corev1.Secret)
+			if !ok {
+				return
+			}
+
+			newSecret, ok := newObj.(*corev1.Secret)
+			if !ok || reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+				return
+			}
+
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	factory.Start(ctx.Done())
+	return changed
+}
+
+func (a *CRDUpdater) injectCABundles(ctx context.Context) error {
 	cfg := a.Rest
 	cfg.WarningHandler = rest.NoWarnings{}
 	extendedClient, err := typedapiextensionsv1beta1.NewForConfig(cfg)
@@ -352,85 +519,56 @@ func (a *CRDUpdater) ensureConfigmapExists(
 		return err
 	}
 
-	workComplete := false
-
-	work := func() error {
+	work := func() (bool, error) {
 		// update service ownerrefs for < 4.5
 		err = a.reviewAndUpdateOwnerReferences(ctx)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		// create cm for ca cert
 		err = a.createCMIfMissing(ctx)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		// update if necessary
 		err = a.caInfo.Load(ctx, a)
 		if err != nil {
-			return err
+			return false, err
 		}
 
-		caInfo, ok := a.caInfo.GetCA()
-		port := a.caInfo.GetPort()
+		caBundle, ok, err := a.caBundle(ctx)
+		if err != nil {
+			a.Logger.Error(err, "failed to sync webhook certificates")
+			return false, nil
+		}
 
 		if !ok {
 			a.Logger.Info("caInfo isn't set")
-			return nil
+			return false, nil
 		}
 
-		for crdName := range crds.CRDs {
-			a.Logger.Info("updating crd", "name", crdName)
-
-			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-				crd, err := extendedClient.CustomResourceDefinitions().Get(ctx, crdName, metav1.GetOptions{})
-				if err != nil {
-					return err
-				}
+		complete := true
 
-				if crd.Spec.Conversion == nil ||
-					crd.Spec.Conversion.WebhookClientConfig == nil ||
-					crd.Spec.Conversion.WebhookClientConfig.Service == nil {
-					return nil
-				}
-
-				if crd.Spec.Conversion.WebhookClientConfig.Service.Port == nil ||
-					port == nil {
-					return nil
-				}
-
-				next := true
-
-				if bytes.Compare(crd.Spec.Conversion.WebhookClientConfig.CABundle, caInfo) != 0 {
-					next = next && false
-				}
-
-				if *crd.Spec.Conversion.WebhookClientConfig.Service.Port != *port {
-					next = next && false
-				}
-
-				if next {
-					return nil
-				}
-
-				workComplete = true
-
-				crd.Spec.Conversion.WebhookClientConfig.CABundle = caInfo
-				crd.Spec.Conversion.WebhookClientConfig.Service.Port = port
-				_, err = extendedClient.CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
-				return err
-			})
+		if err := a.updateCRDs(ctx, extendedClient, caBundle, a.caInfo.GetPort()); err != nil {
+			complete = false
+		}
 
-			if err != nil {
-				a.Logger.Error(err, "updated crd", "name", crdName)
-			} else {
-				a.Logger.Info("updated crd", "name", crdName)
+		// OLM injects the CA of the webhook configurations it installs
+		if a.certificates != nil {
+			if err := a.updateWebhookConfigurations(ctx, caBundle); err != nil {
+				a.Logger.Error(err, "failed to update webhook configurations")
+				complete = false
 			}
 		}
 
-		return nil
+		return complete, nil
+	}
+
+	var certificatesChanged <-chan struct{}
+	if a.certificates != nil {
+		certificatesChanged = a.watchWebhookSecret(ctx)
 	}
 
 	// start with a 30 second ticker then drop down to 1 hour once it was successful
@@ -440,7 +578,7 @@ func (a *CRDUpdater) ensureConfigmapExists(
 
 	for {
 		a.Logger.Info("starting work")
-		err := work()
+		workComplete, err := work()
 
 		if err != nil {
 			a.Logger.Error(err, "error doing work")
@@ -460,13 +598,16 @@ func (a *CRDUpdater) ensureConfigmapExists(
 			return nil
 		case <-ticker.C:
 			continue
+		case <-certificatesChanged:
+			a.Logger.Info("webhook certificates changed")
+			continue
 		}
 	}
 }
 
-func (a *CRDUpdater) Run(ctx context.Context, crds *CRDToUpdate) error {
+func (a *CRDUpdater) Run(ctx context.Context) error {
 	a.Logger.Info("starting")
-	err := a.ensureConfigmapExists(ctx, crds)
+	err := a.injectCABundles(ctx)
 
 	if err != nil {
 		a.Logger.Error(err, "error running")
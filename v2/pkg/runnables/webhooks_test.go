@@ -0,0 +1,244 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runnables
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+
+	"github.com/gotidy/ptr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/certificates"
+	"github.com/redhat-marketplace/redhat-marketplace-operator/v2/pkg/config"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("CRDUpdater", func() {
+	const namespace = "openshift-redhat-marketplace"
+
+	var (
+		ctx     context.Context
+		updater *CRDUpdater
+		certDir string
+
+		caBundle = []byte("ca-bundle")
+	)
+
+	newUpdater := func(client *fake.Clientset) *CRDUpdater {
+		return &CRDUpdater{
+			Logger: logf.Log.WithName("crdUpdater"),
+			Config: &config.OperatorConfig{DeployedNamespace: namespace},
+			Client: client,
+			certificates: certificates.NewWebhookCertificates(
+				logf.Log, client, namespace, serviceName, certDir),
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		certDir, err = ioutil.TempDir("", "certs")
+		Expect(err).To(Succeed())
+
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(certDir)
+	})
+
+	Context("updateCRDs", func() {
+		newCRD := func(name, group string) *apiextensionsv1beta1.CustomResourceDefinition {
+			return &apiextensionsv1beta1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Annotations: map[string]string{InjectCAAnnotation: "true"},
+				},
+				Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+					Group: group,
+					Conversion: &apiextensionsv1beta1.CustomResourceConversion{
+						Strategy: apiextensionsv1beta1.WebhookConverter,
+						WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+							Service: &apiextensionsv1beta1.ServiceReference{
+								Name:      serviceName,
+								Namespace: namespace,
+							},
+						},
+					},
+				},
+			}
+		}
+
+		It("should set the ca bundle and port of the marketplace conversion webhooks", func() {
+			plain := newCRD("meterbases.marketplace.redhat.com", marketplaceGroup)
+			plain.Spec.Conversion = nil
+
+			extendedClient := apiextensionsfake.NewSimpleClientset(
+				newCRD("meterdefinitions.marketplace.redhat.com", marketplaceGroup),
+				newCRD("others.example.com", "example.com"),
+				plain,
+			)
+			updater = newUpdater(fake.NewSimpleClientset())
+
+			Expect(updater.updateCRDs(ctx, extendedClient.ApiextensionsV1beta1(), caBundle, ptr.Int32(9443))).To(Succeed())
+
+			crd, err := extendedClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(
+				ctx, "meterdefinitions.marketplace.redhat.com", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(crd.Spec.Conversion.WebhookClientConfig.CABundle).To(Equal(caBundle))
+			Expect(crd.Spec.Conversion.WebhookClientConfig.Service.Port).To(Equal(ptr.Int32(9443)))
+			Expect(crd.Annotations).ToNot(HaveKey(InjectCAAnnotation))
+
+			other, err := extendedClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(
+				ctx, "others.example.com", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(other.Spec.Conversion.WebhookClientConfig.CABundle).To(BeEmpty())
+			Expect(other.Annotations).To(HaveKey(InjectCAAnnotation))
+
+			plain, err = extendedClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(
+				ctx, "meterbases.marketplace.redhat.com", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(plain.Annotations).To(HaveKey(InjectCAAnnotation))
+		})
+
+		It("should not update crds that are current", func() {
+			crd := newCRD("meterdefinitions.marketplace.redhat.com", marketplaceGroup)
+			crd.Annotations = nil
+			crd.Spec.Conversion.WebhookClientConfig.CABundle = caBundle
+			crd.Spec.Conversion.WebhookClientConfig.Service.Port = ptr.Int32(9443)
+
+			extendedClient := apiextensionsfake.NewSimpleClientset(crd)
+			updater = newUpdater(fake.NewSimpleClientset())
+
+			Expect(updater.updateCRDs(ctx, extendedClient.ApiextensionsV1beta1(), caBundle, ptr.Int32(9443))).To(Succeed())
+
+			for _, action := range extendedClient.Actions() {
+				Expect(action.GetVerb()).ToNot(Equal("update"))
+			}
+		})
+	})
+
+	Context("updateWebhookConfigurations", func() {
+		newWebhook := func(name, service string) admissionregistrationv1beta1.MutatingWebhook {
+			return admissionregistrationv1beta1.MutatingWebhook{
+				Name: name,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Name:      service,
+						Namespace: namespace,
+					},
+				},
+			}
+		}
+
+		It("should set the ca bundle of the operator webhooks only", func() {
+			client := fake.NewSimpleClientset(
+				&admissionregistrationv1beta1.MutatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "mutating",
+						Annotations: map[string]string{InjectCAAnnotation: "true"},
+					},
+					Webhooks: []admissionregistrationv1beta1.MutatingWebhook{
+						newWebhook("operator.marketplace.redhat.com", serviceName),
+						newWebhook("other.example.com", "other"),
+					},
+				},
+				&admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "validating",
+						Annotations: map[string]string{InjectCAAnnotation: "true"},
+					},
+					Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{
+						{
+							Name:         "operator.marketplace.redhat.com",
+							ClientConfig: newWebhook("", serviceName).ClientConfig,
+						},
+					},
+				},
+				&admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "other",
+						Annotations: map[string]string{InjectCAAnnotation: "true"},
+					},
+					Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{
+						{
+							Name:         "other.example.com",
+							ClientConfig: newWebhook("", "other").ClientConfig,
+						},
+					},
+				},
+			)
+			updater = newUpdater(client)
+
+			Expect(updater.updateWebhookConfigurations(ctx, caBundle)).To(Succeed())
+
+			admission := client.AdmissionregistrationV1beta1()
+
+			mutating, err := admission.MutatingWebhookConfigurations().Get(ctx, "mutating", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(mutating.Webhooks[0].ClientConfig.CABundle).To(Equal(caBundle))
+			Expect(mutating.Webhooks[1].ClientConfig.CABundle).To(BeEmpty())
+			Expect(mutating.Annotations).ToNot(HaveKey(InjectCAAnnotation))
+
+			validating, err := admission.ValidatingWebhookConfigurations().Get(ctx, "validating", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(validating.Webhooks[0].ClientConfig.CABundle).To(Equal(caBundle))
+			Expect(validating.Annotations).ToNot(HaveKey(InjectCAAnnotation))
+
+			other, err := admission.ValidatingWebhookConfigurations().Get(ctx, "other", metav1.GetOptions{})
+			Expect(err).To(Succeed())
+			Expect(other.Webhooks[0].ClientConfig.CABundle).To(BeEmpty())
+			Expect(other.Annotations).To(HaveKey(InjectCAAnnotation))
+
+			client.ClearActions()
+			Expect(updater.updateWebhookConfigurations(ctx, caBundle)).To(Succeed())
+
+			for _, action := range client.Actions() {
+				Expect(action.GetVerb()).ToNot(Equal("update"))
+			}
+		})
+	})
+
+	Context("watchWebhookSecret", func() {
+		It("should signal when the webhook certificates rotate", func() {
+			client := fake.NewSimpleClientset()
+			updater = newUpdater(client)
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			changed := updater.watchWebhookSecret(ctx)
+
+			_, err := updater.certificates.Sync(ctx)
+			Expect(err).To(Succeed())
+			Consistently(changed).ShouldNot(Receive())
+
+			secrets := client.CoreV1().Secrets(namespace)
+			secret, err := secrets.Get(ctx, certificates.WebhookSecretName, metav1.GetOptions{})
+			Expect(err).To(Succeed())
+
+			secret.Data[certificates.SecretCACertKey] = []byte("rotated")
+			_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+			Expect(err).To(Succeed())
+			Eventually(changed).Should(Receive())
+		})
+	})
+})
@@ -0,0 +1,246 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transport builds the http transports used for outbound connections.
+// Every transport honors the cluster proxy and trusts the cluster CA bundle
+// so the operator works behind corporate proxies with TLS interception.
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TrustedCABundleConfigMapName is the configmap the cluster network operator
+	// injects the trusted CA bundle into.
+	TrustedCABundleConfigMapName = "rhm-trusted-ca-bundle"
+	// TrustedCABundleKey is the key of the CA bundle in the configmap.
+	TrustedCABundleKey = "ca-bundle.crt"
+	// TrustedCABundleInjectLabel asks OpenShift to inject the trusted CA bundle.
+	TrustedCABundleInjectLabel = "config.openshift.io/inject-trusted-cabundle"
+	// TrustedCABundleMountPath is where the configmap is mounted in our pods.
+	TrustedCABundleMountPath = "/etc/pki/rhm-trusted-ca"
+	// TrustedCABundleFile is the CA bundle trusted by every transport when present.
+	TrustedCABundleFile = TrustedCABundleMountPath + "/" + TrustedCABundleKey
+)
+
+// trustedCABundleFile is replaced in tests.
+var trustedCABundleFile = TrustedCABundleFile
+
+// TrustedCABundleVersion identifies the content of the trusted CA bundle. It
+// changes when the cluster network operator updates the mounted configmap,
+// transports built before trust the previous bundle.
+func TrustedCABundleVersion() string {
+	info, err := os.Stat(trustedCABundleFile)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
+
+// Config configures an outbound transport.
+type Config struct {
+	// Proxy is the proxy to use. The HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables are used when nil.
+	Proxy *httpproxy.Config
+
+	// ProxySource returns the proxy to use on each request in place of
+	// Proxy, so the transport follows changes of the cluster proxy.
+	ProxySource func() *httpproxy.Config
+
+	// CAFiles are PEM encoded CA bundles trusted in addition to the system
+	// pool and the trusted CA bundle.
+	CAFiles []string
+
+	// CAData is a PEM encoded CA bundle trusted in addition to CAFiles.
+	CAData []byte
+
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+
+	// ServerName is verified against the server certificate in place of the
+	// host of the request, to reach a pod by address behind its service name.
+	ServerName string
+
+	// DisableHTTP2 keeps the transport on HTTP/1.1.
+	DisableHTTP2 bool
+}
+
+// NewTransport returns a transport that uses the proxy and trusts the CA
+// bundles of the config.
+func NewTransport(config *Config) (*http.Transport, error) {
+	if config == nil {
+		config = &Config{}
+	}
+
+	tlsConfig, err := NewTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := ProxyFunc(config.Proxy)
+	if config.ProxySource != nil {
+		proxy = DynamicProxyFunc(config.ProxySource)
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     !config.DisableHTTP2,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if config.DisableHTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport, nil
+}
+
+// NewTLSConfig returns a tls config that trusts the system pool, the trusted
+// CA bundle and the CA bundles of the config.
+func NewTLSConfig(config *Config) (*tls.Config, error) {
+	if config.InsecureSkipVerify {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get system cert pool")
+	}
+
+	if caCert, err := ioutil.ReadFile(trustedCABundleFile); err == nil {
+		caCertPool.AppendCertsFromPEM(caCert)
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to load trusted ca bundle")
+	}
+
+	for _, file := range config.CAFiles {
+		if file == "" {
+			continue
+		}
+
+		caCert, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load cert file")
+		}
+		caCertPool.AppendCertsFromPEM(caCert)
+	}
+
+	if len(config.CAData) != 0 {
+		caCertPool.AppendCertsFromPEM(config.CAData)
+	}
+
+	return &tls.Config{
+		RootCAs:    caCertPool,
+		ServerName: config.ServerName,
+	}, nil
+}
+
+// ProxyFunc returns the proxy function for the proxy config. The environment
+// is used when proxy is nil.
+func ProxyFunc(proxy *httpproxy.Config) func(*http.Request) (*url.URL, error) {
+	if proxy == nil {
+		proxy = httpproxy.FromEnvironment()
+	}
+
+	proxyFunc := proxy.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// DynamicProxyFunc returns a proxy function that uses the proxy config
+// returned by source at the time of the request. The environment is used
+// while source returns nil.
+func DynamicProxyFunc(source func() *httpproxy.Config) func(*http.Request) (*url.URL, error) {
+	var (
+		mutex     sync.Mutex
+		current   *httpproxy.Config
+		proxyFunc = ProxyFunc(nil)
+	)
+
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := source()
+
+		mutex.Lock()
+		if proxy != current {
+			current = proxy
+			proxyFunc = ProxyFunc(proxy)
+		}
+		f := proxyFunc
+		mutex.Unlock()
+
+		return f(req)
+	}
+}
+
+// ProxyFromClusterProxy returns the proxy config of the OpenShift cluster
+// proxy. It returns nil when the cluster has no proxy.
+func ProxyFromClusterProxy(proxy *openshiftconfigv1.Proxy) *httpproxy.Config {
+	if proxy == nil {
+		return nil
+	}
+
+	status := proxy.Status
+	if status.HTTPProxy == "" && status.HTTPSProxy == "" {
+		return nil
+	}
+
+	return &httpproxy.Config{
+		HTTPProxy:  status.HTTPProxy,
+		HTTPSProxy: status.HTTPSProxy,
+		NoProxy:    status.NoProxy,
+	}
+}
+
+// ProxyEnvVars returns the env vars that pass the proxy config to a
+// container. The environment is used when proxy is nil.
+func ProxyEnvVars(proxy *httpproxy.Config) []corev1.EnvVar {
+	if proxy == nil {
+		proxy = httpproxy.FromEnvironment()
+	}
+
+	envVars := []corev1.EnvVar{}
+
+	for _, env := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+		{Name: "NO_PROXY", Value: proxy.NoProxy},
+	} {
+		if env.Value != "" {
+			envVars = append(envVars, env)
+		}
+	}
+
+	return envVars
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 IBM Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	openshiftconfigv1 "github.com/openshift/api/config/v1"
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Transport", func() {
+	proxy := &httpproxy.Config{
+		HTTPProxy:  "http://proxy.example.com:3128",
+		HTTPSProxy: "http://proxy.example.com:3129",
+		NoProxy:    ".svc,.cluster.local",
+	}
+
+	It("should use the proxy", func() {
+		t, err := NewTransport(&Config{Proxy: proxy})
+		Expect(err).To(Succeed())
+
+		req, _ := http.NewRequest(http.MethodGet, "https://marketplace.redhat.com", nil)
+		u, err := t.Proxy(req)
+		Expect(err).To(Succeed())
+		Expect(u.String()).To(Equal("http://proxy.example.com:3129"))
+
+		req, _ = http.NewRequest(http.MethodGet, "https://prometheus.openshift-monitoring.svc", nil)
+		u, err = t.Proxy(req)
+		Expect(err).To(Succeed())
+		Expect(u).To(BeNil())
+	})
+
+	It("should follow the proxy source", func() {
+		var current *httpproxy.Config
+		t, err := NewTransport(&Config{ProxySource: func() *httpproxy.Config { return current }})
+		Expect(err).To(Succeed())
+
+		req, _ := http.NewRequest(http.MethodGet, "https://marketplace.redhat.com", nil)
+		u, err := t.Proxy(req)
+		Expect(err).To(Succeed())
+		Expect(u).To(BeNil())
+
+		current = proxy
+		u, err = t.Proxy(req)
+		Expect(err).To(Succeed())
+		Expect(u.String()).To(Equal("http://proxy.example.com:3129"))
+
+		current = &httpproxy.Config{}
+		u, err = t.Proxy(req)
+		Expect(err).To(Succeed())
+		Expect(u).To(BeNil())
+	})
+
+	It("should configure http versions", func() {
+		t, err := NewTransport(&Config{})
+		Expect(err).To(Succeed())
+		Expect(t.ForceAttemptHTTP2).To(BeTrue())
+		Expect(t.TLSClientConfig.RootCAs).ToNot(BeNil())
+
+		t, err = NewTransport(&Config{DisableHTTP2: true, InsecureSkipVerify: true})
+		Expect(err).To(Succeed())
+		Expect(t.ForceAttemptHTTP2).To(BeFalse())
+		Expect(t.TLSNextProto).To(BeEmpty())
+		Expect(t.TLSClientConfig.InsecureSkipVerify).To(BeTrue())
+	})
+
+	It("should fail on missing ca files", func() {
+		_, err := NewTransport(&Config{CAFiles: []string{"/does/not/exist.crt"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should change the version of the trusted ca bundle on updates", func() {
+		dir, err := ioutil.TempDir("", "trusted-ca")
+		Expect(err).To(Succeed())
+		defer os.RemoveAll(dir)
+
+		defer func(file string) {
+			trustedCABundleFile = file
+		}(trustedCABundleFile)
+		trustedCABundleFile = filepath.Join(dir, TrustedCABundleKey)
+
+		Expect(TrustedCABundleVersion()).To(BeEmpty())
+
+		Expect(ioutil.WriteFile(trustedCABundleFile, []byte("bundle"), 0600)).To(Succeed())
+		version := TrustedCABundleVersion()
+		Expect(version).ToNot(BeEmpty())
+		Expect(TrustedCABundleVersion()).To(Equal(version))
+
+		Expect(ioutil.WriteFile(trustedCABundleFile, []byte("updated bundle"), 0600)).To(Succeed())
+		Expect(os.Chtimes(trustedCABundleFile, time.Now(), time.Now().Add(time.Minute))).To(Succeed())
+		Expect(TrustedCABundleVersion()).ToNot(Equal(version))
+	})
+
+	It("should read the cluster proxy", func() {
+		Expect(ProxyFromClusterProxy(nil)).To(BeNil())
+		Expect(ProxyFromClusterProxy(&openshiftconfigv1.Proxy{})).To(BeNil())
+
+		clusterProxy := &openshiftconfigv1.Proxy{
+			Status: openshiftconfigv1.ProxyStatus{
+				HTTPProxy:  proxy.HTTPProxy,
+				HTTPSProxy: proxy.HTTPSProxy,
+				NoProxy:    proxy.NoProxy,
+			},
+		}
+		Expect(ProxyFromClusterProxy(clusterProxy)).To(Equal(proxy))
+	})
+
+	It("should pass the proxy to containers", func() {
+		Expect(ProxyEnvVars(proxy)).To(ConsistOf(
+			corev1.EnvVar{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+			corev1.EnvVar{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+			corev1.EnvVar{Name: "NO_PROXY", Value: proxy.NoProxy},
+		))
+		Expect(ProxyEnvVars(&httpproxy.Config{HTTPSProxy: proxy.HTTPSProxy})).To(ConsistOf(
+			corev1.EnvVar{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+		))
+	})
+})
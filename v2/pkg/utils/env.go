@@ -40,6 +40,7 @@ const (
 	WATCH_KEEPER_LIMITPOLL_NAME            = "watch-keeper-limit-poll"
 	WATCH_KEEPER_CONFIG_NAME               = "watch-keeper-config"
 	WATCH_KEEPER_SECRET_NAME               = "watch-keeper-secret"
+	CLUSTER_INVENTORY_NAME                 = "rhm-cluster-inventory"
 
 	/* All Controllers */
 	CONTROLLER_FINALIZER = "finalizer.marketplace.redhat.com"
@@ -83,6 +84,13 @@ const (
 
 	LicenseServerTag = "marketplace.redhat.com/operator"
 
+	/* Disconnected Mode */
+	ReportArchivePVCName = "rhm-report-archive"
+	ReportArchivePath    = "/var/lib/redhat-marketplace/reports"
+
+	/* Prometheus */
+	PrometheusReplicaLabel = "prometheus_replica"
+
 	/* Time and Date */
 	DATE_FORMAT         = "2006-01-02"
 	METER_REPORT_PREFIX = "meter-report-"
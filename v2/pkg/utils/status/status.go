@@ -181,7 +181,8 @@ func (conditions *Conditions) RemoveCondition(t ConditionType) bool {
 // MarshalJSON marshals the set of conditions as a JSON array, sorted by
 // condition type.
 func (conditions Conditions) MarshalJSON() ([]byte, error) {
-	conds := []Condition(conditions)
+	conds := make([]Condition, len(conditions))
+	copy(conds, conditions)
 	sort.Slice(conds, func(a, b int) bool {
 		return conds[a].Type < conds[b].Type
 	})
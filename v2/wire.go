@@ -36,7 +36,7 @@ func InitializeMarketplaceController(mgr ctrl.Manager) (*InjectableManager, erro
 		wire.InterfaceValue(new(logr.Logger), ctrl.Log),
 		reconcileutils.CommandRunnerProviderSet,
 		managers.ProvideManagerSet,
-		runnables.NewPodMonitor,
-		providePodMonitorConfig,
+		runnables.NewPodRemediator,
+		providePodRemediatorConfig,
 	))
 }